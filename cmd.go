@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/MunifTanjim/stremthru/internal/db"
+)
+
+type command struct {
+	// space separated, e.g. `torrents export`
+	name  string
+	args  string
+	about string
+	run   func(cmd *command, args []string) error
+
+	stdout io.Writer
+	stderr io.Writer // also for usage
+}
+
+var errUsage = errors.New("invalid usage")
+
+var commands = []*command{
+	{name: "serve", about: "start the server, same as running without command", run: runServeCommand},
+	{name: "migrate up", about: "apply the pending database migrations", run: runMigrateUpCommand},
+	{name: "migrate down", about: "revert the last database migration", run: runMigrateDownCommand},
+	{name: "migrate status", about: "print the database migration status", run: runMigrateStatusCommand},
+	{name: "worker run", args: "<name>", about: "run a worker once, in the foreground", run: runWorkerRunCommand},
+	{name: "torrents export", args: "[-format ndjson|dmm] [-since <rfc3339>] [-output <file>]", about: "export the torrents, to stdout by default", run: runTorrentsExportCommand},
+	{name: "torrents import", args: "[-format ndjson|dmm] [-source <source>] [<file>]", about: "import the torrents, from stdin by default", run: runTorrentsImportCommand},
+	{name: "userdata list", args: "<addon>", about: "list the saved userdata of a stremio addon", run: runUserdataListCommand},
+	{name: "userdata delete", args: "<addon> <key>", about: "delete a saved userdata of a stremio addon", run: runUserdataDeleteCommand},
+	{name: "peer-token create", args: "[-quota <requests per hour>] <name>", about: "create a peer token", run: runPeerTokenCreateCommand},
+	{name: "peer-token revoke", args: "<id>", about: "revoke a peer token", run: runPeerTokenRevokeCommand},
+	{name: "config validate", args: "[-print]", about: "check the config, without starting the server", run: runConfigValidateCommand},
+}
+
+func (cmd *command) usage() string {
+	if cmd.args == "" {
+		return "stremthru " + cmd.name
+	}
+	return "stremthru " + cmd.name + " " + cmd.args
+}
+
+func (cmd *command) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	if cmd.stderr != nil {
+		fs.SetOutput(cmd.stderr)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s\n", cmd.usage())
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: stremthru [command]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-20s %s\n", cmd.name, cmd.about)
+	}
+}
+
+func findCommand(args []string) (*command, []string) {
+	for _, cmd := range commands {
+		names := strings.Fields(cmd.name)
+		if len(args) >= len(names) && strings.Join(args[:len(names)], " ") == cmd.name {
+			return cmd, args[len(names):]
+		}
+	}
+	return nil, nil
+}
+
+func runCommand(args []string, stdout, stderr io.Writer) int {
+	cmd, rest := findCommand(args)
+	if cmd == nil {
+		if len(args) > 0 {
+			switch args[0] {
+			case "help", "-h", "-help", "--help":
+				printUsage(stdout)
+				return 0
+			}
+		}
+		fmt.Fprintf(stderr, "unknown command: %s\n\n", strings.Join(args, " "))
+		printUsage(stderr)
+		return 2
+	}
+
+	cmd.stdout, cmd.stderr = stdout, stderr
+	if err := cmd.run(cmd, rest); err != nil {
+		switch {
+		case errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, errUsage):
+			fmt.Fprintf(stderr, "usage: %s\n", cmd.usage())
+			return 2
+		default:
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+func openDB() *db.DB {
+	database := db.Open()
+	db.Ping()
+	return database
+}
+
+func runServeCommand(cmd *command, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	serve()
+	return nil
+}
+
+func runMigrateUpCommand(cmd *command, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	database := openDB()
+	defer db.Close()
+
+	RunSchemaMigration(database.URI, database)
+	return nil
+}
+
+func runMigrateDownCommand(cmd *command, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	database := openDB()
+	defer db.Close()
+
+	return RollbackSchemaMigration(database.URI, database)
+}
+
+func runMigrateStatusCommand(cmd *command, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	database := openDB()
+	defer db.Close()
+
+	return PrintSchemaMigrationStatus(database.URI, database)
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/db"
+	"github.com/MunifTanjim/stremthru/internal/peer_token"
+	stremio_userdata "github.com/MunifTanjim/stremthru/internal/stremio/userdata"
+	"github.com/MunifTanjim/stremthru/internal/torrent_dump"
+	ti "github.com/MunifTanjim/stremthru/internal/torrent_info"
+	"github.com/MunifTanjim/stremthru/internal/worker"
+)
+
+func runWorkerRunCommand(cmd *command, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	openDB()
+	defer db.Close()
+
+	name := args[0]
+	err := worker.ExecWorker(name)
+	if errors.Is(err, worker.ErrWorkerNotFound) {
+		return fmt.Errorf("%w: %s, available: %s", err, name, strings.Join(worker.WorkerNames(), ", "))
+	}
+	return err
+}
+
+func runTorrentsExportCommand(cmd *command, args []string) error {
+	fs := cmd.flagSet()
+	format := fs.String("format", string(torrent_dump.FormatNDJSON), "ndjson or dmm")
+	since := fs.String("since", "", "only the torrents changed since, e.g. 2025-01-01T00:00:00Z")
+	output := fs.String("output", "", "output file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 || !torrent_dump.Format(*format).IsValid() {
+		return errUsage
+	}
+
+	sinceTime := time.Time{}
+	if *since != "" {
+		t, err := time.Parse(time.RFC3339, *since)
+		if err != nil {
+			return fmt.Errorf("invalid since: %w", err)
+		}
+		sinceTime = t
+	}
+
+	var w io.Writer = cmd.stdout
+	if *output != "" && *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	openDB()
+	defer db.Close()
+
+	count, err := torrent_dump.Export(w, torrent_dump.Format(*format), sinceTime)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.stderr, "exported %d torrents\n", count)
+	return nil
+}
+
+func runTorrentsImportCommand(cmd *command, args []string) error {
+	fs := cmd.flagSet()
+	format := fs.String("format", string(torrent_dump.FormatNDJSON), "ndjson or dmm")
+	source := fs.String("source", "", "source for the torrents, overrides the one in the input")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 || !torrent_dump.Format(*format).IsValid() {
+		return errUsage
+	}
+	if *source != "" && !ti.TorrentInfoSource(*source).IsValid() {
+		return errUsage
+	}
+
+	var r io.Reader = os.Stdin
+	if input := fs.Arg(0); input != "" && input != "-" {
+		file, err := os.Open(input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	openDB()
+	defer db.Close()
+
+	result, err := torrent_dump.Import(r, torrent_dump.Format(*format), ti.TorrentInfoSource(*source))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.stdout, "imported %d torrents, %d imdb mappings, %d anidb mappings\n", result.Torrents, result.IMDBMappings, result.AniDBMappings)
+	return nil
+}
+
+func runUserdataListCommand(cmd *command, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	openDB()
+	defer db.Close()
+
+	items, err := stremio_userdata.List[json.RawMessage](args[0])
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(cmd.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tNAME\tCREATED AT\tUPDATED AT")
+	for _, item := range items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", item.Key, item.Name, item.CAt.Format(time.DateTime), item.UAt.Format(time.DateTime))
+	}
+	return tw.Flush()
+}
+
+func runUserdataDeleteCommand(cmd *command, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	openDB()
+	defer db.Close()
+
+	addon, key := args[0], args[1]
+	if _, err := stremio_userdata.Get[json.RawMessage](addon, key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("userdata not found")
+		}
+		return err
+	}
+	if err := stremio_userdata.Delete(addon, key); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.stdout, "deleted userdata: %s\n", key)
+	return nil
+}
+
+func runPeerTokenCreateCommand(cmd *command, args []string) error {
+	fs := cmd.flagSet()
+	quota := fs.Int("quota", 0, "max requests per hour, 0 means unlimited")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+	openDB()
+	defer db.Close()
+
+	t, err := peer_token.Create(fs.Arg(0), *quota)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.stdout, t.Id)
+	return nil
+}
+
+func runPeerTokenRevokeCommand(cmd *command, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	openDB()
+	defer db.Close()
+
+	t, err := peer_token.GetById(args[0])
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return errors.New("peer token not found")
+	}
+	if err := peer_token.Revoke(t.Id); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.stdout, "revoked peer token: %s\n", t.Name)
+	return nil
+}
+
+// The config is parsed while initializing, and the problems are reported
+// before reaching here.
+func runConfigValidateCommand(cmd *command, args []string) error {
+	fs := cmd.flagSet()
+	printConfig := fs.Bool("print", false, "print the config, resolving the ip addresses")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errUsage
+	}
+
+	if *printConfig {
+		config.PrintConfig(appState)
+	}
+	fmt.Fprintln(cmd.stdout, "config is valid")
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindCommand(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		name string
+		rest []string
+	}{
+		{[]string{"serve"}, "serve", []string{}},
+		{[]string{"migrate", "up"}, "migrate up", []string{}},
+		{[]string{"worker", "run", "sync-imdb"}, "worker run", []string{"sync-imdb"}},
+		{[]string{"torrents", "export", "-format", "dmm"}, "torrents export", []string{"-format", "dmm"}},
+		{[]string{"migrate"}, "", nil},
+		{[]string{"migrate", "sideways"}, "", nil},
+		{[]string{"up", "migrate"}, "", nil},
+		{[]string{}, "", nil},
+	} {
+		cmd, rest := findCommand(tc.args)
+		if tc.name == "" {
+			assert.Nil(t, cmd, tc.args)
+			continue
+		}
+		if assert.NotNil(t, cmd, tc.args) {
+			assert.Equal(t, tc.name, cmd.name)
+			assert.Equal(t, tc.rest, rest)
+		}
+	}
+}
+
+func TestRunCommandUsage(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{"help", []string{"help"}, 0, "usage: stremthru [command]", ""},
+		{"help flag", []string{"--help"}, 0, "usage: stremthru [command]", ""},
+		{"no command", []string{}, 2, "", "usage: stremthru [command]"},
+		{"unknown command", []string{"migrate", "sideways"}, 2, "", "unknown command: migrate sideways"},
+		{"extra args", []string{"serve", "now"}, 2, "", "usage: stremthru serve\n"},
+		{"missing args", []string{"worker", "run"}, 2, "", "usage: stremthru worker run <name>\n"},
+		{"invalid flag value", []string{"torrents", "export", "-format", "xml"}, 2, "", "usage: stremthru torrents export"},
+		{"invalid source", []string{"torrents", "import", "-source", "unknown"}, 2, "", "usage: stremthru torrents import"},
+		{"flag help", []string{"torrents", "export", "-h"}, 0, "", "-since"},
+		{"output", []string{"config", "validate"}, 0, "config is valid\n", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := runCommand(tc.args, &stdout, &stderr)
+			assert.Equal(t, tc.code, code)
+			if tc.stdout == "" {
+				assert.Empty(t, stdout.String())
+			} else {
+				assert.Contains(t, stdout.String(), tc.stdout)
+			}
+			if tc.stderr == "" {
+				assert.Empty(t, stderr.String())
+			} else {
+				assert.Contains(t, stderr.String(), tc.stderr)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ module github.com/MunifTanjim/stremthru
 go 1.24.1
 
 require (
+	github.com/alicebob/miniredis/v2 v2.37.0
 	github.com/alitto/pond/v2 v2.3.4
 	github.com/elastic/go-freelru v0.15.0
 	github.com/golang-jwt/jwt/v5 v5.2.1
@@ -11,6 +12,7 @@ require (
 	github.com/zeebo/xxh3 v1.0.2
 	golang.org/x/sync v0.13.0
 	golang.org/x/text v0.24.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -29,11 +31,11 @@ require (
 	github.com/vmihailenco/go-tinylfu v0.2.2 // indirect
 	github.com/vmihailenco/msgpack/v5 v5.3.5 // indirect
 	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	golang.org/x/crypto v0.37.0 // indirect
 	golang.org/x/net v0.39.0 // indirect
 	golang.org/x/sys v0.32.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 require (
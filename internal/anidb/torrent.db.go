@@ -123,3 +123,43 @@ func UpsertTorrents(items []AniDBTorrent) error {
 
 	return nil
 }
+
+var query_get_torrents_by_hashes = fmt.Sprintf(
+	"SELECT %s FROM %s WHERE %s IN ",
+	db.JoinColumnNames(TorrentColumns...),
+	TorrentTableName,
+	TorrentColumn.Hash,
+)
+
+func GetTorrentsByHashes(hashes []string) (map[string][]AniDBTorrent, error) {
+	torrentsByHash := map[string][]AniDBTorrent{}
+	if len(hashes) == 0 {
+		return torrentsByHash, nil
+	}
+
+	for cHashes := range slices.Chunk(hashes, 1000) {
+		args := make([]any, len(cHashes))
+		for i, hash := range cHashes {
+			args[i] = hash
+		}
+		query := query_get_torrents_by_hashes + "(" + util.RepeatJoin("?", len(cHashes), ",") + ")"
+		rows, err := db.Query(query, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			t := AniDBTorrent{}
+			if err := rows.Scan(&t.TId, &t.Hash, &t.SeasonType, &t.Season, &t.EpisodeStart, &t.EpisodeEnd, &t.Episodes, &t.UAt); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			torrentsByHash[t.Hash] = append(torrentsByHash[t.Hash], t)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return torrentsByHash, nil
+}
@@ -305,6 +305,66 @@ func GetTypeByKitsuIds(ids []int) (map[int]AnimeIdMapType, error) {
 	return typeById, nil
 }
 
+var query_get_imdb_id_by_kitsu_ids = fmt.Sprintf(
+	"SELECT %s, %s FROM %s WHERE %s IS NOT NULL AND %s IN ",
+	IdMapColumn.Kitsu,
+	IdMapColumn.IMDB,
+	IdMapTableName,
+	IdMapColumn.IMDB,
+	IdMapColumn.Kitsu,
+)
+
+var query_get_imdb_id_by_mal_ids = fmt.Sprintf(
+	"SELECT %s, %s FROM %s WHERE %s IS NOT NULL AND %s IN ",
+	IdMapColumn.MAL,
+	IdMapColumn.IMDB,
+	IdMapTableName,
+	IdMapColumn.IMDB,
+	IdMapColumn.MAL,
+)
+
+func getIMDBIdByIds(query string, ids []string) (map[string]string, error) {
+	count := len(ids)
+	if count == 0 {
+		return map[string]string{}, nil
+	}
+
+	query = query + "(" + util.RepeatJoin("?", count, ",") + ")"
+	args := make([]any, count)
+	for i := range ids {
+		args[i] = ids[i]
+	}
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	imdbIdById := make(map[string]string, count)
+	for rows.Next() {
+		var id, imdbId string
+		if err := rows.Scan(&id, &imdbId); err != nil {
+			return nil, err
+		}
+		if imdbId != "" {
+			imdbIdById[id] = imdbId
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return imdbIdById, nil
+}
+
+func GetIMDBIdByKitsuIds(ids []string) (map[string]string, error) {
+	return getIMDBIdByIds(query_get_imdb_id_by_kitsu_ids, ids)
+}
+
+func GetIMDBIdByMALIds(ids []string) (map[string]string, error) {
+	return getIMDBIdByIds(query_get_imdb_id_by_mal_ids, ids)
+}
+
 type cachedAniDBId struct {
 	Id     string
 	Season string
@@ -23,11 +23,6 @@ var Buddy = NewAPIClient(&APIClientConfig{
 
 var buddyLog = logger.Scoped("buddy")
 
-var Peer = peer.NewAPIClient(&peer.APIClientConfig{
-	BaseURL: config.PeerURL,
-	APIKey:  config.PeerAuthToken,
-})
-
 var peerLog = logger.Scoped("buddy:upstream")
 
 func TrackMagnet(s store.Store, hash string, name string, size int64, files []store.MagnetFile, tInfoCategory torrent_info.TorrentInfoCategory, cacheMiss bool, storeToken string) {
@@ -72,7 +67,7 @@ func TrackMagnet(s store.Store, hash string, name string, size int64, files []st
 		}
 	}
 
-	if config.HasPeer && config.PeerAuthToken != "" {
+	if peer.Upstreams.HasRole(config.PeerRoleCheckMagnet, true) {
 		params := &peer.TrackMagnetParams{
 			StoreName:           s.GetName(),
 			StoreToken:          storeToken,
@@ -89,7 +84,7 @@ func TrackMagnet(s store.Store, hash string, name string, size int64, files []st
 		}
 		go func() {
 			start := time.Now()
-			if _, err := Peer.TrackMagnet(params); err != nil {
+			if err := peer.Upstreams.TrackMagnet(params); err != nil {
 				peerLog.Error("failed to track magnet cache", "store", s.GetName(), "hash", hash, "error", core.PackError(err), "duration", time.Since(start))
 			} else {
 				peerLog.Info("track magnet cache", "store", s.GetName(), "hash", hash, "duration", time.Since(start))
@@ -131,7 +126,7 @@ func BulkTrackMagnet(s store.Store, tInfos []TorrentInfoInput, cached map[string
 		}
 	}
 
-	if config.HasPeer && config.PeerAuthToken != "" {
+	if peer.Upstreams.HasRole(config.PeerRoleCheckMagnet, true) {
 		params := &peer.TrackMagnetParams{
 			StoreName:           s.GetName(),
 			StoreToken:          storeToken,
@@ -141,7 +136,7 @@ func BulkTrackMagnet(s store.Store, tInfos []TorrentInfoInput, cached map[string
 		}
 		go func() {
 			start := time.Now()
-			if _, err := Peer.TrackMagnet(params); err != nil {
+			if err := peer.Upstreams.TrackMagnet(params); err != nil {
 				peerLog.Error("failed to bulk track magnet cache", "error", core.PackError(err), "hash_count", len(tInfos), "store", s.GetName(), "duration", time.Since(start))
 			} else {
 				peerLog.Info("bulk track magnet cache", "hash_count", len(tInfos), "store", s.GetName(), "duration", time.Since(start))
@@ -262,7 +257,7 @@ func CheckMagnet(s store.Store, hashes []string, storeToken string, clientIp str
 		}
 	}
 
-	if config.HasPeer {
+	if peer.Upstreams.HasRole(config.PeerRoleCheckMagnet, false) {
 		if config.PeerFlag.Lazy {
 			storeCode := string(s.GetName().Code())
 			for _, hash := range staleOrMissingHashes {
@@ -277,7 +272,7 @@ func CheckMagnet(s store.Store, hashes []string, storeToken string, clientIp str
 			return data, nil
 		}
 
-		if Peer.IsHaltedCheckMagnet() {
+		if peer.Upstreams.IsHaltedCheckMagnet() {
 			return data, nil
 		}
 
@@ -297,11 +292,8 @@ func CheckMagnet(s store.Store, hashes []string, storeToken string, clientIp str
 				params.ClientIP = clientIp
 				params.SId = sid
 				start := time.Now()
-				res, err := Peer.CheckMagnet(params)
+				res, err := peer.Upstreams.CheckMagnet(params)
 				duration := time.Since(start)
-				if duration.Seconds() > 10 {
-					Peer.HaltCheckMagnet()
-				}
 				if err != nil {
 					peerLog.Error("failed partially to check magnet", "store", s.GetName(), "error", core.PackError(err), "duration", duration)
 				} else {
@@ -309,7 +301,7 @@ func CheckMagnet(s store.Store, hashes []string, storeToken string, clientIp str
 					defer mu.Unlock()
 
 					peerLog.Info("check magnet", "store", s.GetName(), "hash_count", len(cHashes), "duration", duration)
-					for _, item := range res.Data.Items {
+					for _, item := range res.Items {
 						files := torrent_stream.Files{}
 						if item.Status == store.MagnetStatusCached {
 							seenByName := map[string]struct{}{}
@@ -1,46 +1,62 @@
 package buddy
 
 import (
+	"slices"
 	"time"
 
 	"github.com/MunifTanjim/stremthru/core"
 	"github.com/MunifTanjim/stremthru/internal/config"
 	"github.com/MunifTanjim/stremthru/internal/logger"
 	"github.com/MunifTanjim/stremthru/internal/peer"
+	"github.com/MunifTanjim/stremthru/internal/request"
 	ti "github.com/MunifTanjim/stremthru/internal/torrent_info"
 	ts "github.com/MunifTanjim/stremthru/internal/torrent_stream"
 	tss "github.com/MunifTanjim/stremthru/internal/torrent_stream/torrent_stream_syncinfo"
 )
 
-var PullPeer, pullLocalOnly = func() (*peer.APIClient, bool) {
-	baseUrl := config.PullPeerURL
-	if baseUrl == "" {
-		baseUrl = config.PeerURL
-	}
-	localOnly := baseUrl == config.PullPeerURL
-	if baseUrl == "" {
-		return nil, localOnly
+// PullPeer is used in buddy mode, it is asked for its local torrents only.
+// Otherwise the torrents are pulled from the upstream peers.
+var PullPeer = func() *peer.APIClient {
+	if config.PullPeerURL == "" {
+		return nil
 	}
 	return peer.NewAPIClient(&peer.APIClientConfig{
-		BaseURL: baseUrl,
-	}), localOnly
+		BaseURL: config.PullPeerURL,
+	})
 }()
 
 var pullPeerLog = logger.Scoped("peer:pull")
 
 // supports imdb or anidb
 func PullTorrentsByStremId(sid string, originInstanceId string) {
-	if PullPeer == nil || !tss.ShouldPull(sid) {
+	pullTorrentsByStremId(sid, originInstanceId, nil)
+}
+
+func pullTorrentsByStremId(sid string, originInstanceId string, via []string) {
+	if PullPeer == nil && !peer.Upstreams.HasRole(config.PeerRoleListTorrents, false) {
+		return
+	}
+	if !tss.ShouldPull(sid) {
 		return
 	}
 
 	cleanSId := ts.CleanStremId(sid)
-	start := time.Now()
-	res, err := PullPeer.ListTorrents(&peer.ListTorrentsByStremIdParams{
+	params := &peer.ListTorrentsByStremIdParams{
 		SId:              cleanSId,
-		LocalOnly:        pullLocalOnly,
 		OriginInstanceId: originInstanceId,
-	})
+		Via:              via,
+	}
+	start := time.Now()
+	var data *peer.ListTorrentsByStremIdData
+	var err error
+	if PullPeer != nil {
+		params.LocalOnly = true
+		var res request.APIResponse[peer.ListTorrentsByStremIdData]
+		res, err = PullPeer.ListTorrents(params)
+		data = &res.Data
+	} else {
+		data, err = peer.Upstreams.ListTorrents(params)
+	}
 	duration := time.Since(start)
 
 	if err != nil {
@@ -48,35 +64,37 @@ func PullTorrentsByStremId(sid string, originInstanceId string) {
 		return
 	}
 
-	count := len(res.Data.Items)
+	count := len(data.Items)
 	pullPeerLog.Info("pulled torrents", "duration", duration, "sid", cleanSId, "count", count)
 
 	items := make([]ti.TorrentInfoInsertData, count)
-	for i := range res.Data.Items {
-		data := &res.Data.Items[i]
+	for i := range data.Items {
+		item := &data.Items[i]
 		items[i] = ti.TorrentInfoInsertData{
-			Hash:         data.Hash,
-			TorrentTitle: data.TorrentTitle,
-			Size:         data.Size,
-			Source:       ti.TorrentInfoSource(data.Source),
-			Category:     ti.TorrentInfoCategory(data.Category),
-			Files:        data.Files,
-			Seeders:      data.Seeders,
-			Leechers:     data.Leechers,
+			Hash:         item.Hash,
+			TorrentTitle: item.TorrentTitle,
+			Size:         item.Size,
+			Source:       ti.TorrentInfoSource(item.Source),
+			Category:     ti.TorrentInfoCategory(item.Category),
+			Files:        item.Files,
+			Seeders:      item.Seeders,
+			Leechers:     item.Leechers,
 		}
 	}
 	ti.Upsert(items, "", false)
 	go tss.MarkPulled(cleanSId)
 }
 
-func ListTorrentsByStremId(sid string, localOnly bool, originInstanceId string, noMissingSize bool) (*ti.ListTorrentsData, error) {
-	if originInstanceId == config.InstanceId && !pullLocalOnly {
-		pullPeerLog.Info("loop detected for list torrents, self-correcting...")
-		pullLocalOnly = true
+// ListTorrentsByStremId pulls from the peers first, unless the request has
+// already passed through this instance, i.e. the peers form a loop.
+func ListTorrentsByStremId(sid string, localOnly bool, originInstanceId string, via []string, noMissingSize bool) (*ti.ListTorrentsData, error) {
+	if !localOnly && (originInstanceId == config.InstanceId || slices.Contains(via, config.InstanceId)) {
+		pullPeerLog.Debug("loop detected for list torrents, skipping pull", "sid", sid, "origin", originInstanceId, "via", via)
+		localOnly = true
 	}
 
 	if !localOnly {
-		PullTorrentsByStremId(sid, originInstanceId)
+		pullTorrentsByStremId(sid, originInstanceId, via)
 	}
 
 	data, err := ti.ListByStremId(sid, noMissingSize)
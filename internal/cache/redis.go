@@ -84,6 +84,12 @@ var redis = func() *r.Client {
 	return redis
 }()
 
+// GetRedisClient returns the shared redis client, nil if redis is not
+// configured.
+func GetRedisClient() *r.Client {
+	return redis
+}
+
 type RedisCache[V any] struct {
 	c        *rc.Cache
 	name     string
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
@@ -10,6 +11,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -50,9 +52,10 @@ var defaultValueByEnv = map[string]map[string]string{
 	},
 	EnvProd: {},
 	EnvTest: {
-		"STREMTHRU_LOG_FORMAT": "text",
-		"STREMTHRU_LOG_LEVEL":  "DEBUG",
-		"STREMTHRU_DATA_DIR":   os.TempDir(),
+		"STREMTHRU_LOG_FORMAT":   "text",
+		"STREMTHRU_LOG_LEVEL":    "DEBUG",
+		"STREMTHRU_DATA_DIR":     os.TempDir(),
+		"STREMTHRU_DATABASE_URI": "sqlite://" + filepath.Join(os.TempDir(), "stremthru-test.db"),
 	},
 	"": {
 		"STREMTHRU_BASE_URL":                               "http://localhost:8080",
@@ -64,38 +67,70 @@ var defaultValueByEnv = map[string]map[string]string{
 		"STREMTHRU_LOG_LEVEL":                              "INFO",
 		"STREMTHRU_PORT":                                   "8080",
 		"STREMTHRU_STORE_CONTENT_PROXY":                    "*:true",
+		"STREMTHRU_STORE_RATE_LIMIT":                       "alldebrid:600,realdebrid:250,torbox:300",
 		"STREMTHRU_STORE_TUNNEL":                           "*:true",
 		"STREMTHRU_STORE_CLIENT_USER_AGENT":                "stremthru",
 		"STREMTHRU_INTEGRATION_ANILIST_LIST_STALE_TIME":    "12h",
+		"STREMTHRU_INTEGRATION_JELLYFIN_LIST_STALE_TIME":   "6h",
 		"STREMTHRU_INTEGRATION_LETTERBOXD_LIST_STALE_TIME": "24h",
 		"STREMTHRU_INTEGRATION_LETTERBOXD_USER_AGENT":      "stremthru",
 		"STREMTHRU_INTEGRATION_MDBLIST_LIST_STALE_TIME":    "12h",
+		"STREMTHRU_INTEGRATION_PLEX_LIST_STALE_TIME":       "6h",
 		"STREMTHRU_INTEGRATION_TMDB_LIST_STALE_TIME":       "12h",
 		"STREMTHRU_INTEGRATION_TRAKT_LIST_STALE_TIME":      "12h",
 		"STREMTHRU_INTEGRATION_TVDB_LIST_STALE_TIME":       "12h",
 		"STREMTHRU_STREMIO_LIST_PUBLIC_MAX_LIST_COUNT":     "10",
+		"STREMTHRU_STREMIO_LIST_FEED_STALE_TIME":           "6h",
 		"STREMTHRU_STREMIO_STORE_CATALOG_ITEM_LIMIT":       "2000",
 		"STREMTHRU_STREMIO_STORE_CATALOG_CACHE_TIME":       "10m",
 		"STREMTHRU_STREMIO_TORZ_PUBLIC_MAX_STORE_COUNT":    "3",
 		"STREMTHRU_STREMIO_WRAP_PUBLIC_MAX_UPSTREAM_COUNT": "5",
 		"STREMTHRU_STREMIO_WRAP_PUBLIC_MAX_STORE_COUNT":    "3",
 		"STREMTHRU_IP_CHECKER":                             "aws",
+		"STREMTHRU_WORKER_QUEUE":                           "memory",
 	},
 }
 
-func getEnv(key string) string {
+// lookupEnv returns the value from the env var, then the config file, then
+// the default value.
+func lookupEnv(cf *configFile, key string) (string, error) {
+	fileValue, inFile, err := cf.get(key)
 	if value, exists := os.LookupEnv(key); exists && len(value) > 0 {
-		return value
+		return value, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if inFile && len(fileValue) > 0 {
+		return fileValue, nil
 	}
 	if val, found := defaultValueByEnv[Environment][key]; found && len(val) > 0 {
-		return val
+		return val, nil
 	}
 	if Environment != "" {
 		if val, found := defaultValueByEnv[""][key]; found && len(val) > 0 {
-			return val
+			return val, nil
 		}
 	}
-	return ""
+	return "", nil
+}
+
+// values read while initializing, to detect the changes on reload
+var loadedEnv = map[string]string{}
+
+func getEnv(key string) string {
+	value, err := lookupEnv(file, key)
+	if err != nil {
+		addProblem(err)
+	}
+	if strings.HasPrefix(key, "STREMTHRU_") {
+		loadedEnv[key] = value
+	}
+	return value
+}
+
+func keyError(key string, err error) error {
+	return file.keyError(key, err)
 }
 
 func parseDuration(key string, value string, minDuration time.Duration) (time.Duration, error) {
@@ -108,28 +143,63 @@ func parseDuration(key string, value string, minDuration time.Duration) (time.Du
 	}
 }
 
-func mustParseDuration(key string, value string, minDuration time.Duration) time.Duration {
-	duration, err := parseDuration(key, value, minDuration)
+func mustParseDuration(key string, name string, minDuration time.Duration) time.Duration {
+	duration, err := parseDuration(name, getEnv(key), minDuration)
 	if err != nil {
-		log.Fatal(err)
+		addProblem(keyError(key, err))
 	}
 	return duration
 }
 
+func getEnvInt(key string) int {
+	value, err := strconv.Atoi(getEnv(key))
+	if err != nil {
+		addProblem(keyError(key, fmt.Errorf("invalid integer: %v", err)))
+	}
+	return value
+}
+
 type StoreAuthTokenMap map[string]map[string]string
 
-func (m StoreAuthTokenMap) GetToken(user, store string) string {
+func (m StoreAuthTokenMap) getToken(user, store string) string {
 	if um, ok := m[user]; ok {
 		if token, ok := um[store]; ok {
 			return token
 		}
 	}
 	if user != "*" {
-		return m.GetToken("*", store)
+		return m.getToken("*", store)
 	}
 	return ""
 }
 
+func (m StoreAuthTokenMap) GetToken(user, store string) string {
+	reloadable.RLock()
+	defer reloadable.RUnlock()
+
+	return m.getToken(user, store)
+}
+
+// HasToken reports whether the token is configured for the store, for any
+// proxy-authorized user.
+func (m StoreAuthTokenMap) HasToken(store, token string) bool {
+	reloadable.RLock()
+	defer reloadable.RUnlock()
+
+	if token == "" {
+		return false
+	}
+	for user, um := range m {
+		if _, ok := ProxyAuthPassword[user]; !ok && user != "*" {
+			continue
+		}
+		if um[store] == token {
+			return true
+		}
+	}
+	return false
+}
+
 func (m StoreAuthTokenMap) setToken(user, store, token string) {
 	if _, ok := m[user]; !ok {
 		m[user] = make(map[string]string)
@@ -170,6 +240,9 @@ func (m StoreAuthTokenMap) addStore(user, store string) {
 type UserPasswordMap map[string]string
 
 func (m UserPasswordMap) GetPassword(user string) string {
+	reloadable.RLock()
+	defer reloadable.RUnlock()
+
 	if password, ok := m[user]; ok {
 		return password
 	}
@@ -179,6 +252,9 @@ func (m UserPasswordMap) GetPassword(user string) string {
 type AuthAdminMap map[string]bool
 
 func (m AuthAdminMap) IsAdmin(userName string) bool {
+	reloadable.RLock()
+	defer reloadable.RUnlock()
+
 	if isAdmin, ok := m[userName]; ok {
 		return isAdmin
 	}
@@ -220,7 +296,7 @@ type FeatureConfig struct {
 	disabled []string
 }
 
-func (f FeatureConfig) IsEnabled(name string) bool {
+func (f *FeatureConfig) isEnabled(name string) bool {
 	if slices.Contains(f.disabled, name) {
 		return false
 	}
@@ -232,32 +308,49 @@ func (f FeatureConfig) IsEnabled(name string) bool {
 	return slices.Contains(f.enabled, name)
 }
 
+func (f *FeatureConfig) IsEnabled(name string) bool {
+	reloadable.RLock()
+	defer reloadable.RUnlock()
+
+	return f.isEnabled(name)
+}
+
 type StoreContentProxyMap map[string]bool
 
-func (scp StoreContentProxyMap) IsEnabled(name string) bool {
+func (scp StoreContentProxyMap) isEnabled(name string) bool {
 	if enabled, ok := scp[name]; ok {
 		return enabled
 	}
 	if name != "*" {
-		scp[name] = scp.IsEnabled("*")
-	} else {
-		scp[name] = true
+		return scp.isEnabled("*")
 	}
-	return scp[name]
+	return true
+}
+
+func (scp StoreContentProxyMap) IsEnabled(name string) bool {
+	reloadable.RLock()
+	defer reloadable.RUnlock()
+
+	return scp.isEnabled(name)
 }
 
 type ContentProxyConnectionLimitMap map[string]int
 
-func (cpcl ContentProxyConnectionLimitMap) Get(user string) int {
+func (cpcl ContentProxyConnectionLimitMap) get(user string) int {
 	if limit, ok := cpcl[user]; ok {
 		return limit
 	}
 	if user != "*" {
-		cpcl[user] = cpcl.Get("*")
-	} else {
-		cpcl[user] = 0
+		return cpcl.get("*")
 	}
-	return cpcl[user]
+	return 0
+}
+
+func (cpcl ContentProxyConnectionLimitMap) Get(user string) int {
+	reloadable.RLock()
+	defer reloadable.RUnlock()
+
+	return cpcl.get(user)
 }
 
 type storeContentCachedStaleTimeMapItem struct {
@@ -324,6 +417,73 @@ func parseStoreContentCachedStaleTime(staleTimeConfig string) (staleTimeMap stor
 	return staleTimeMap, nil
 }
 
+type storeFilePathMapItem struct {
+	remote string
+	local  string
+}
+
+// storeFilePathMap maps the file paths reported by self-hosted stores to the
+// paths where StremThru can read those files from. Only files under the
+// local paths are served.
+type storeFilePathMap []storeFilePathMapItem
+
+func (m storeFilePathMap) Resolve(remotePath string) (localPath string, ok bool) {
+	remotePath = filepath.Clean(remotePath)
+	for _, item := range m {
+		if util.IsPathWithin(item.remote, remotePath) {
+			rel, _ := filepath.Rel(item.remote, remotePath)
+			return filepath.Join(item.local, rel), true
+		}
+	}
+	return "", false
+}
+
+func (m storeFilePathMap) IsAllowed(localPath string) bool {
+	localPath = filepath.Clean(localPath)
+	for _, item := range m {
+		if util.IsPathWithin(item.local, localPath) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseStoreFilePathMap(pathMapConfig string) (storeFilePathMap, error) {
+	pathMap := storeFilePathMap{}
+	for _, pathMapString := range strings.FieldsFunc(pathMapConfig, func(c rune) bool {
+		return c == ','
+	}) {
+		remote, local, ok := strings.Cut(pathMapString, ":")
+		if !ok {
+			local = remote
+		}
+		if !filepath.IsAbs(remote) || !filepath.IsAbs(local) {
+			return nil, fmt.Errorf("invalid store file path map: %s", pathMapString)
+		}
+		pathMap = append(pathMap, storeFilePathMapItem{
+			remote: filepath.Clean(remote),
+			local:  filepath.Clean(local),
+		})
+	}
+	return pathMap, nil
+}
+
+func parseStoreLocalDirs(dirsConfig string) ([]string, error) {
+	dirs := []string{}
+	for _, dir := range strings.FieldsFunc(dirsConfig, func(c rune) bool {
+		return c == ','
+	}) {
+		if !filepath.IsAbs(dir) {
+			return nil, fmt.Errorf("invalid store local dir, expected absolute path: %s", dir)
+		}
+		if exists, err := util.DirExists(dir); err != nil || !exists {
+			return nil, fmt.Errorf("store local dir does not exist: %s", dir)
+		}
+		dirs = append(dirs, filepath.Clean(dir))
+	}
+	return dirs, nil
+}
+
 type configPeerFlag struct {
 	Lazy        bool
 	NoSpillTorz bool
@@ -343,10 +503,12 @@ type Config struct {
 	PeerURL                     string
 	PeerAuthToken               string
 	PeerFlag                    configPeerFlag
+	Peers                       []PeerConfig
 	HasPeer                     bool
 	PullPeerURL                 string
 	RedisURI                    string
 	DatabaseURI                 string
+	WorkerQueue                 string
 	Feature                     FeatureConfig
 	Version                     string
 	LandingPage                 string
@@ -354,16 +516,20 @@ type Config struct {
 	StoreContentProxy           StoreContentProxyMap
 	StoreContentCachedStaleTime storeContentCachedStaleTimeMap
 	StoreClientUserAgent        string
+	StoreFilePathMap            storeFilePathMap
+	StoreLocalDirs              []string
 	ContentProxyConnectionLimit ContentProxyConnectionLimitMap
 	IP                          *IPResolver
 
 	DataDir string
 }
 
-func parseUri(uri string) (parsedUrl, parsedToken string) {
+func parseUri(key string) (parsedUrl, parsedToken string) {
+	uri := getEnv(key)
 	u, err := url.Parse(uri)
 	if err != nil {
-		log.Fatalf("invalid uri: %s", uri)
+		addProblem(keyError(key, fmt.Errorf("invalid uri: %s", uri)))
+		return "", ""
 	}
 	if password, ok := u.User.Password(); ok {
 		parsedToken = password
@@ -375,8 +541,8 @@ func parseUri(uri string) (parsedUrl, parsedToken string) {
 	return
 }
 
-var config = func() Config {
-	proxyAuthCredList := strings.FieldsFunc(getEnv("STREMTHRU_PROXY_AUTH"), func(c rune) bool {
+func parseProxyAuth(proxyAuth string) UserPasswordMap {
+	proxyAuthCredList := strings.FieldsFunc(proxyAuth, func(c rune) bool {
 		return c == ','
 	})
 	proxyAuthPasswordMap := make(UserPasswordMap)
@@ -386,9 +552,19 @@ var config = func() Config {
 			proxyAuthPasswordMap[basicAuth.Username] = basicAuth.Password
 		}
 	}
+	return proxyAuthPasswordMap
+}
+
+// the auto generated admin creds are kept across reloads
+var getGeneratedAdminCreds = sync.OnceValues(func() (username, password string) {
+	username = "st-" + util.GenerateRandomString(7, util.CharSet.AlphaNumeric)
+	password = util.GenerateRandomString(27, util.CharSet.AlphaNumericMixedCase)
+	return username, password
+})
 
+func parseAuthAdmin(authAdmin string, proxyAuthPasswordMap UserPasswordMap) (AuthAdminMap, UserPasswordMap) {
 	authAdminMap := AuthAdminMap{}
-	authAdminList := strings.FieldsFunc(getEnv("STREMTHRU_AUTH_ADMIN"), func(c rune) bool {
+	authAdminList := strings.FieldsFunc(authAdmin, func(c rune) bool {
 		return c == ','
 	})
 	adminPasswordMap := UserPasswordMap{}
@@ -406,59 +582,46 @@ var config = func() Config {
 		}
 	}
 	if len(adminPasswordMap) == 0 {
-		username := "st-" + util.GenerateRandomString(7, util.CharSet.AlphaNumeric)
-		password := util.GenerateRandomString(27, util.CharSet.AlphaNumericMixedCase)
+		username, password := getGeneratedAdminCreds()
 		adminPasswordMap[username] = password
 	}
+	return authAdminMap, adminPasswordMap
+}
 
-	storeAlldebridTokenList := strings.FieldsFunc(getEnv("STREMTHRU_STORE_AUTH"), func(c rune) bool {
+func parseStoreAuth(storeAuth string) (StoreAuthTokenMap, error) {
+	storeAlldebridTokenList := strings.FieldsFunc(storeAuth, func(c rune) bool {
 		return c == ','
 	})
 	storeAuthTokenMap := make(StoreAuthTokenMap)
+	errs := []error{}
 	for _, userStoreToken := range storeAlldebridTokenList {
 		if user, storeToken, ok := strings.Cut(userStoreToken, ":"); ok {
 			if storeName, token, ok := strings.Cut(storeToken, ":"); ok {
 				if !store.StoreName(storeName).IsValid() {
-					log.Fatalf("invalid store name: %s", storeName)
+					errs = append(errs, fmt.Errorf("invalid store name: %s", storeName))
+					continue
 				}
 				storeAuthTokenMap.addStore(user, storeName)
 				storeAuthTokenMap.setToken(user, storeName, token)
 			}
 		}
 	}
+	return storeAuthTokenMap, errors.Join(errs...)
+}
 
-	buddyUrl, _ := parseUri(getEnv("STREMTHRU_BUDDY_URI"))
-	pullPeerUrl := ""
-	if buddyUrl != "" {
-		pullPeerUrl, _ = parseUri(getEnv("STREMTHRU__PULL__PEER_URI"))
-	}
-
-	defaultPeerUri := ""
-	if peerUri, err := core.Base64Decode("aHR0cHM6Ly9zdHJlbXRocnUuMTMzNzcwMDEueHl6"); err == nil && buddyUrl == "" {
-		defaultPeerUri = peerUri
-	}
-	peerUri := getEnv("STREMTHRU_PEER_URI")
-	if peerUri == "" {
-		peerUri = defaultPeerUri
-	}
-	peerUrl, peerAuthToken := "", ""
-	if peerUri != "-" {
-		peerUrl, peerAuthToken = parseUri(peerUri)
-	}
-
-	databaseUri := getEnv("STREMTHRU_DATABASE_URI")
-
+func parseFeature(featureConfig string) (FeatureConfig, error) {
 	feature := FeatureConfig{
 		disabled: []string{FeatureAnime, FeatureStremioP2P},
 	}
-	for _, name := range strings.FieldsFunc(strings.TrimSpace(getEnv("STREMTHRU_FEATURE")), func(c rune) bool {
+	errs := []error{}
+	for _, name := range strings.FieldsFunc(strings.TrimSpace(featureConfig), func(c rune) bool {
 		return c == ','
 	}) {
 		switch {
 		case strings.HasPrefix(name, "-"):
 			name = strings.TrimPrefix(name, "-")
 			if slices.Contains(feature.enabled, name) {
-				log.Fatalf("feature conflict, trying to disable already enabled feature: -%s", name)
+				errs = append(errs, fmt.Errorf("feature conflict, trying to disable already enabled feature: -%s", name))
 			} else {
 				feature.disabled = append(feature.disabled, name)
 			}
@@ -469,18 +632,21 @@ var config = func() Config {
 					return feat == name
 				})
 			} else {
-				log.Fatalf("feature conflict, trying to force enable a not disabled feature: +%s", name)
+				errs = append(errs, fmt.Errorf("feature conflict, trying to force enable a not disabled feature: +%s", name))
 			}
 		default:
 			if slices.Contains(feature.disabled, name) {
-				log.Fatalf("feature conflict, trying to enable already disabled feature: %s", name)
+				errs = append(errs, fmt.Errorf("feature conflict, trying to enable already disabled feature: %s", name))
 			} else {
 				feature.enabled = append(feature.enabled, name)
 			}
 		}
 	}
+	return feature, errors.Join(errs...)
+}
 
-	storeContentProxyList := strings.FieldsFunc(getEnv("STREMTHRU_STORE_CONTENT_PROXY"), func(c rune) bool {
+func parseStoreContentProxy(storeContentProxyConfig string) StoreContentProxyMap {
+	storeContentProxyList := strings.FieldsFunc(storeContentProxyConfig, func(c rune) bool {
 		return c == ','
 	})
 
@@ -490,43 +656,115 @@ var config = func() Config {
 			storeContentProxyMap[store] = enabled == "true"
 		}
 	}
+	return storeContentProxyMap
+}
 
-	var logLevel slog.Level
-	if err := logLevel.UnmarshalText([]byte(getEnv("STREMTHRU_LOG_LEVEL"))); err != nil {
-		log.Fatalf("Invalid log level: %v", err)
-	}
-
-	logFormat := getEnv("STREMTHRU_LOG_FORMAT")
-	if logFormat != "json" && logFormat != "text" {
-		log.Fatalf("Invalid log format: %s, expected: json / text", logFormat)
-	}
-
+func parseContentProxyConnectionLimit(connectionLimitConfig string) (ContentProxyConnectionLimitMap, error) {
 	contentProxyConnectionMap := make(ContentProxyConnectionLimitMap)
-	contentProxyConnectionList := strings.FieldsFunc(getEnv("STREMTHRU_CONTENT_PROXY_CONNECTION_LIMIT"), func(c rune) bool {
+	contentProxyConnectionList := strings.FieldsFunc(connectionLimitConfig, func(c rune) bool {
 		return c == ','
 	})
+	errs := []error{}
 	for _, contentProxyConnection := range contentProxyConnectionList {
 		if user, limitStr, ok := strings.Cut(contentProxyConnection, ":"); ok {
 			limit, err := strconv.Atoi(limitStr)
 			if err != nil {
-				log.Fatalf("Invalid content proxy connection limit: %v", err)
+				errs = append(errs, fmt.Errorf("Invalid content proxy connection limit: %v", err))
 			}
 			contentProxyConnectionMap[user] = max(0, limit)
 		}
 	}
+	return contentProxyConnectionMap, errors.Join(errs...)
+}
+
+var config = func() Config {
+	proxyAuthPasswordMap := parseProxyAuth(getEnv("STREMTHRU_PROXY_AUTH"))
+
+	authAdminMap, adminPasswordMap := parseAuthAdmin(getEnv("STREMTHRU_AUTH_ADMIN"), proxyAuthPasswordMap)
+
+	storeAuthTokenMap, err := parseStoreAuth(getEnv("STREMTHRU_STORE_AUTH"))
+	if err != nil {
+		addProblem(keyError("STREMTHRU_STORE_AUTH", err))
+	}
+
+	buddyUrl, _ := parseUri("STREMTHRU_BUDDY_URI")
+	pullPeerUrl, _ := parseUri("STREMTHRU__PULL__PEER_URI")
+	if buddyUrl == "" {
+		pullPeerUrl = ""
+	}
+
+	defaultPeerUri := ""
+	if peerUri, err := core.Base64Decode("aHR0cHM6Ly9zdHJlbXRocnUuMTMzNzcwMDEueHl6"); err == nil && buddyUrl == "" {
+		defaultPeerUri = peerUri + "?trusted=true&role=check_magnet&role=list_torrents&role=push_torrents&role=letterboxd"
+	}
+	peerUri := getEnv("STREMTHRU_PEER_URI")
+	if peerUri == "" {
+		peerUri = defaultPeerUri
+	}
+	peers := []PeerConfig{}
+	if peerUri != "-" {
+		var err error
+		peers, err = parsePeerURIs(peerUri)
+		if err != nil {
+			addProblem(keyError("STREMTHRU_PEER_URI", err))
+		}
+	}
+	peerUrl, peerAuthToken := "", ""
+	if len(peers) > 0 {
+		peerUrl, peerAuthToken = peers[0].URL, peers[0].AuthToken
+	}
+
+	databaseUri := getEnv("STREMTHRU_DATABASE_URI")
+
+	workerQueue := strings.ToLower(getEnv("STREMTHRU_WORKER_QUEUE"))
+	if workerQueue != "memory" && workerQueue != "db" {
+		addProblem(keyError("STREMTHRU_WORKER_QUEUE", fmt.Errorf("invalid worker queue: %s", workerQueue)))
+	}
+
+	feature, err := parseFeature(getEnv("STREMTHRU_FEATURE"))
+	if err != nil {
+		addProblem(keyError("STREMTHRU_FEATURE", err))
+	}
+
+	storeContentProxyMap := parseStoreContentProxy(getEnv("STREMTHRU_STORE_CONTENT_PROXY"))
+
+	var logLevel slog.Level
+	if err := logLevel.UnmarshalText([]byte(getEnv("STREMTHRU_LOG_LEVEL"))); err != nil {
+		addProblem(keyError("STREMTHRU_LOG_LEVEL", fmt.Errorf("Invalid log level: %v", err)))
+	}
+
+	logFormat := getEnv("STREMTHRU_LOG_FORMAT")
+	if logFormat != "json" && logFormat != "text" {
+		addProblem(keyError("STREMTHRU_LOG_FORMAT", fmt.Errorf("Invalid log format: %s, expected: json / text", logFormat)))
+	}
+
+	contentProxyConnectionMap, err := parseContentProxyConnectionLimit(getEnv("STREMTHRU_CONTENT_PROXY_CONNECTION_LIMIT"))
+	if err != nil {
+		addProblem(keyError("STREMTHRU_CONTENT_PROXY_CONNECTION_LIMIT", err))
+	}
 
 	dataDir, err := filepath.Abs(getEnv("STREMTHRU_DATA_DIR"))
 	if err != nil {
-		log.Fatalf("failed to resolve data directory: %v", err)
+		addProblem(keyError("STREMTHRU_DATA_DIR", fmt.Errorf("failed to resolve data directory: %v", err)))
 	} else if exists, err := util.DirExists(dataDir); err != nil {
-		log.Fatalf("failed to check data directory: %v", err)
+		addProblem(keyError("STREMTHRU_DATA_DIR", fmt.Errorf("failed to check data directory: %v", err)))
 	} else if !exists {
-		log.Fatalf("data directory does not exist: %v", dataDir)
+		addProblem(keyError("STREMTHRU_DATA_DIR", fmt.Errorf("data directory does not exist: %v", dataDir)))
 	}
 
 	storeContentCachedStaleTimeMap, err := parseStoreContentCachedStaleTime(getEnv("STREMTHRU_STORE_CONTENT_CACHED_STALE_TIME"))
 	if err != nil {
-		log.Fatalf("failed to parse store content cached stale time: %v", err)
+		addProblem(keyError("STREMTHRU_STORE_CONTENT_CACHED_STALE_TIME", fmt.Errorf("failed to parse store content cached stale time: %v", err)))
+	}
+
+	storeFilePathMap, err := parseStoreFilePathMap(getEnv("STREMTHRU_STORE_FILE_PATH_MAP"))
+	if err != nil {
+		addProblem(keyError("STREMTHRU_STORE_FILE_PATH_MAP", err))
+	}
+
+	storeLocalDirs, err := parseStoreLocalDirs(getEnv("STREMTHRU_STORE_LOCAL_DIR"))
+	if err != nil {
+		addProblem(keyError("STREMTHRU_STORE_LOCAL_DIR", err))
 	}
 
 	// @deprecated
@@ -563,10 +801,12 @@ var config = func() Config {
 		PeerURL:                     peerUrl,
 		PeerAuthToken:               peerAuthToken,
 		PeerFlag:                    peerFlag,
+		Peers:                       peers,
 		HasPeer:                     len(peerUrl) > 0,
 		PullPeerURL:                 pullPeerUrl,
 		RedisURI:                    getEnv("STREMTHRU_REDIS_URI"),
 		DatabaseURI:                 databaseUri,
+		WorkerQueue:                 workerQueue,
 		Feature:                     feature,
 		Version:                     "0.90.3", // x-release-please-version
 		LandingPage:                 getEnv("STREMTHRU_LANDING_PAGE"),
@@ -574,6 +814,8 @@ var config = func() Config {
 		StoreContentProxy:           storeContentProxyMap,
 		StoreContentCachedStaleTime: storeContentCachedStaleTimeMap,
 		StoreClientUserAgent:        getEnv("STREMTHRU_STORE_CLIENT_USER_AGENT"),
+		StoreFilePathMap:            storeFilePathMap,
+		StoreLocalDirs:              storeLocalDirs,
 		ContentProxyConnectionLimit: contentProxyConnectionMap,
 		IP: &IPResolver{
 			checker: getEnv("STREMTHRU_IP_CHECKER"),
@@ -596,10 +838,12 @@ var HasBuddy = config.HasBuddy
 var PeerURL = config.PeerURL
 var PeerAuthToken = config.PeerAuthToken
 var PeerFlag = config.PeerFlag
+var Peers = config.Peers
 var HasPeer = config.HasPeer
 var PullPeerURL = config.PullPeerURL
 var RedisURI = config.RedisURI
 var DatabaseURI = config.DatabaseURI
+var WorkerQueue = config.WorkerQueue
 var Feature = config.Feature
 var Version = config.Version
 var LandingPage = config.LandingPage
@@ -607,6 +851,8 @@ var ServerStartTime = config.ServerStartTime
 var StoreContentProxy = config.StoreContentProxy
 var StoreContentCachedStaleTime = config.StoreContentCachedStaleTime
 var StoreClientUserAgent = config.StoreClientUserAgent
+var StoreFilePathMap = config.StoreFilePathMap
+var StoreLocalDirs = config.StoreLocalDirs
 var ContentProxyConnectionLimit = config.ContentProxyConnectionLimit
 var InstanceId = strings.ReplaceAll(uuid.NewString(), "-", "")
 var IP = config.IP
@@ -722,6 +968,11 @@ func PrintConfig(state *AppState) {
 			if cpcl := ContentProxyConnectionLimit.Get(user); cpcl > 0 {
 				l.Println("       content_proxy_connection_limit: " + strconv.FormatUint(uint64(cpcl), 10))
 			}
+			for _, storeName := range StoreAuthToken.ListStores(user) {
+				if rule, ok := StoreHousekeeping.Get(user, storeName); ok {
+					l.Println("       store_housekeeping: " + storeName + " (" + rule.String() + ")")
+				}
+			}
 		}
 		l.Println()
 	}
@@ -743,6 +994,12 @@ func PrintConfig(state *AppState) {
 				}
 			}
 		}
+		if rateLimit := StoreRateLimit.Get(string(store)); rateLimit > 0 {
+			if storeConfig != "" {
+				storeConfig += ","
+			}
+			storeConfig += "rate_limit:" + strconv.Itoa(rateLimit) + "/min"
+		}
 		if storeConfig != "" {
 			storeConfig = " (" + storeConfig + ")"
 		}
@@ -750,6 +1007,22 @@ func PrintConfig(state *AppState) {
 	}
 	l.Println()
 
+	if len(StoreFilePathMap) > 0 {
+		l.Println(" Store File Path Map:")
+		for _, item := range StoreFilePathMap {
+			l.Println("   - " + item.remote + " -> " + item.local)
+		}
+		l.Println()
+	}
+
+	if len(StoreLocalDirs) > 0 {
+		l.Println(" Store Local Dirs:")
+		for _, dir := range StoreLocalDirs {
+			l.Println("   - " + dir)
+		}
+		l.Println()
+	}
+
 	if len(AdminPassword) == 1 {
 		for username, password := range AdminPassword {
 			if strings.HasPrefix(username, "st-") {
@@ -767,11 +1040,6 @@ func PrintConfig(state *AppState) {
 	}
 
 	if HasPeer {
-		u, err := url.Parse(PeerURL)
-		if err != nil {
-			l.Panicf(" Invalid Peer URI: %v\n", err)
-		}
-		u.User = url.UserPassword("", PeerAuthToken)
 		peerFlags := ""
 		if PeerFlag.Lazy {
 			peerFlags = "lazy"
@@ -786,7 +1054,9 @@ func PrintConfig(state *AppState) {
 			peerFlags = " (" + peerFlags + ")"
 		}
 		l.Println(" Peer URI" + peerFlags + ":")
-		l.Println("   " + u.Redacted())
+		for _, peer := range Peers {
+			l.Println("   " + peer.String())
+		}
 		l.Println()
 	}
 	if PullPeerURL != "" {
@@ -817,6 +1087,10 @@ func PrintConfig(state *AppState) {
 	l.Println("   " + uri)
 	l.Println()
 
+	l.Println(" Worker Queue:")
+	l.Println("   " + WorkerQueue)
+	l.Println()
+
 	l.Println(" Features:")
 	for _, feature := range features {
 		disabled := ""
@@ -827,6 +1101,7 @@ func PrintConfig(state *AppState) {
 		switch feature {
 		case FeatureStremioList:
 			l.Println("       public max list count: " + strconv.Itoa(Stremio.List.PublicMaxListCount))
+			l.Println("              feed stale time: " + Stremio.List.FeedStaleTime.String())
 		case FeatureStremioStore:
 			l.Println("          catalog item limit: " + strconv.Itoa(Stremio.Store.CatalogItemLimit))
 			l.Println("          catalog cache time: " + Stremio.Store.CatalogCacheTime.String())
@@ -843,7 +1118,7 @@ func PrintConfig(state *AppState) {
 	l.Println()
 
 	l.Println(" Integrations:")
-	for _, integration := range []string{"anilist.co", "bitmagnet.io", "github.com", "kitsu.app", "letterboxd.com", "mdblist.com", "themoviedb.org", "trakt.tv", "thetvdb.com"} {
+	for _, integration := range []string{"anilist.co", "bitmagnet.io", "github.com", "jellyfin.org", "kitsu.app", "letterboxd.com", "mdblist.com", "plex.tv", "themoviedb.org", "trakt.tv", "thetvdb.com"} {
 		switch integration {
 		case "anilist.co":
 			disabled := ""
@@ -870,6 +1145,9 @@ func PrintConfig(state *AppState) {
 				l.Println("                  user: " + Integration.GitHub.User)
 				l.Println("                 token: " + Integration.GitHub.Token[0:13] + "..." + Integration.GitHub.Token[len(Integration.GitHub.Token)-3:])
 			}
+		case "jellyfin.org":
+			l.Println("   - " + integration)
+			l.Println("       list stale time: " + Integration.Jellyfin.ListStaleTime.String())
 		case "kitsu.app":
 			disabled := ""
 			if !Feature.IsEnabled(FeatureAnime) || !Integration.Kitsu.HasDefaultCredentials() {
@@ -907,6 +1185,9 @@ func PrintConfig(state *AppState) {
 		case "mdblist.com":
 			l.Println("   - " + integration)
 			l.Println("       list stale time: " + Integration.MDBList.ListStaleTime.String())
+		case "plex.tv":
+			l.Println("   - " + integration)
+			l.Println("       list stale time: " + Integration.Plex.ListStaleTime.String())
 		case "themoviedb.org":
 			disabled := ""
 			if !Integration.TMDB.IsEnabled() {
@@ -43,6 +43,158 @@ func (s *StoreContentCachedStaleTimeTestSuite) TestStoreContentCachedStaleTime()
 	s.Equal(staleTime.GetStaleTime(false, "torbox"), 8*time.Hour)
 }
 
+type StoreHousekeepingTestSuite struct {
+	suite.Suite
+}
+
+func (s *StoreHousekeepingTestSuite) TestStoreHousekeeping() {
+	_, err := parseStoreHousekeeping("alice:realdebrid")
+	s.ErrorContains(err, "invalid store housekeeping config")
+
+	_, err = parseStoreHousekeeping("alice:unknown:failed=true")
+	s.ErrorContains(err, "invalid store name")
+
+	_, err = parseStoreHousekeeping("alice:realdebrid:keep=10")
+	s.ErrorContains(err, "missing rule")
+
+	_, err = parseStoreHousekeeping("alice:realdebrid:max_age=30m")
+	s.ErrorContains(err, "must be at least 1h")
+
+	_, err = parseStoreHousekeeping("alice:realdebrid:size=10")
+	s.ErrorContains(err, "unknown rule")
+
+	shm, err := parseStoreHousekeeping("*:torbox:failed=true,alice:realdebrid:max_age=720h&unplayed=336h&stalled=48h&keep=20&dry_run=true")
+	s.Nil(err)
+
+	rule, ok := shm.Get("alice", "realdebrid")
+	s.True(ok)
+	s.Equal(StoreHousekeepingRule{
+		MaxAge:      720 * time.Hour,
+		UnplayedFor: 336 * time.Hour,
+		StalledFor:  48 * time.Hour,
+		KeepRecent:  20,
+		DryRun:      true,
+	}, rule)
+
+	rule, ok = shm.Get("alice", "torbox")
+	s.True(ok)
+	s.True(rule.RemoveFailed)
+
+	_, ok = shm.Get("bob", "realdebrid")
+	s.False(ok)
+}
+
+type PeerURIsTestSuite struct {
+	suite.Suite
+}
+
+func (s *PeerURIsTestSuite) TestPeerURIs() {
+	_, err := parsePeerURIs("stremthru.example.com")
+	s.ErrorContains(err, "invalid peer uri")
+
+	_, err = parsePeerURIs("https://stremthru.example.com?weight=0")
+	s.ErrorContains(err, "invalid peer weight")
+
+	_, err = parsePeerURIs("https://stremthru.example.com?role=unknown")
+	s.ErrorContains(err, "invalid peer role")
+
+	_, err = parsePeerURIs("https://stremthru.example.com?lazy=1")
+	s.ErrorContains(err, "unknown peer option")
+
+	_, err = parsePeerURIs("https://stremthru.example.com?trusted=maybe")
+	s.ErrorContains(err, "invalid peer trusted")
+
+	_, err = parsePeerURIs("https://stremthru.example.com?role=check_magnet")
+	s.ErrorContains(err, "requires trusted peer")
+
+	_, err = parsePeerURIs("https://stremthru.example.com,https://:token@stremthru.example.com")
+	s.ErrorContains(err, "duplicate peer uri")
+
+	peers, err := parsePeerURIs("https://:token@a.example.com?weight=3&role=check_magnet&role=list_torrents&trusted=true, https://b.example.com:8080")
+	s.Nil(err)
+	s.Equal([]PeerConfig{
+		{
+			URL:       "https://a.example.com",
+			AuthToken: "token",
+			Weight:    3,
+			Roles:     []PeerRole{PeerRoleCheckMagnet, PeerRoleListTorrents},
+			Trusted:   true,
+		},
+		{
+			URL:    "https://b.example.com:8080",
+			Weight: 1,
+			Roles:  defaultPeerRoles,
+		},
+	}, peers)
+	s.Equal("https://:xxxxx@a.example.com (weight=3, roles=check_magnet,list_torrents, trusted)", peers[0].String())
+}
+
+type ReloadableConfigTestSuite struct {
+	suite.Suite
+}
+
+func (s *ReloadableConfigTestSuite) TestStoreAuth() {
+	_, err := parseStoreAuth("alice:unknown:token")
+	s.ErrorContains(err, "invalid store name")
+
+	storeAuth, err := parseStoreAuth("alice:realdebrid:token1,alice:torbox:token2,*:premiumize:token3")
+	s.Nil(err)
+	s.Equal("token1", storeAuth.GetToken("alice", "realdebrid"))
+	s.Equal("token3", storeAuth.GetToken("bob", "premiumize"))
+	s.Equal([]string{"realdebrid", "torbox"}, storeAuth.ListStores("alice"))
+	s.Equal("premiumize", storeAuth.GetPreferredStore("bob"))
+}
+
+func (s *ReloadableConfigTestSuite) TestAuthAdmin() {
+	authAdmin, adminPassword := parseAuthAdmin("alice", UserPasswordMap{"alice": "pass", "bob": "pass"})
+	s.True(authAdmin.IsAdmin("alice"))
+	s.False(authAdmin.IsAdmin("bob"))
+	s.Len(adminPassword, 1)
+
+	// auto generated admin creds are kept
+	_, adminPasswordAgain := parseAuthAdmin("", UserPasswordMap{})
+	s.Equal(adminPassword, adminPasswordAgain)
+
+	authAdmin, adminPassword = parseAuthAdmin("admin:secret", UserPasswordMap{"alice": "pass"})
+	s.True(authAdmin.IsAdmin("alice"))
+	s.Equal(UserPasswordMap{"admin": "secret"}, adminPassword)
+}
+
+func (s *ReloadableConfigTestSuite) TestFeature() {
+	_, err := parseFeature("-stremio_list,stremio_list")
+	s.ErrorContains(err, "trying to enable already disabled feature")
+	_, err = parseFeature("stremio_list,-stremio_list")
+	s.ErrorContains(err, "trying to disable already enabled feature")
+	_, err = parseFeature("+stremio_list")
+	s.ErrorContains(err, "trying to force enable a not disabled feature")
+
+	feature, err := parseFeature("+anime,-stremio_wrap")
+	s.Nil(err)
+	s.True(feature.IsEnabled(FeatureAnime))
+	s.False(feature.IsEnabled(FeatureStremioWrap))
+	s.False(feature.IsEnabled(FeatureStremioP2P))
+	s.True(feature.IsEnabled(FeatureStremioList))
+}
+
+func (s *ReloadableConfigTestSuite) TestContentProxy() {
+	_, err := parseContentProxyConnectionLimit("*:many")
+	s.ErrorContains(err, "Invalid content proxy connection limit")
+
+	limit, err := parseContentProxyConnectionLimit("*:2,alice:5")
+	s.Nil(err)
+	s.Equal(5, limit.Get("alice"))
+	s.Equal(2, limit.Get("bob"))
+	s.Len(limit, 2)
+
+	contentProxy := parseStoreContentProxy("*:false,realdebrid:true")
+	s.True(contentProxy.IsEnabled("realdebrid"))
+	s.False(contentProxy.IsEnabled("torbox"))
+	s.Len(contentProxy, 2)
+}
+
 func TestConfig(t *testing.T) {
 	suite.Run(t, new(StoreContentCachedStaleTimeTestSuite))
+	suite.Run(t, new(StoreHousekeepingTestSuite))
+	suite.Run(t, new(PeerURIsTestSuite))
+	suite.Run(t, new(ReloadableConfigTestSuite))
 }
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFile is the optional yaml config file. The keys are the env vars
+// without the `STREMTHRU_` prefix, in lowercase, and can be nested at `_`,
+// i.e. `store_tunnel` and `store: { tunnel: ... }` are the same.
+//
+// The values are converted to the env var format:
+//   - list: items joined with `,`
+//   - map: `key:value` entries joined with `,`, nested maps are flattened
+//     and list values are joined with `:`
+type configFile struct {
+	path string
+	root *yaml.Node
+	used map[*yaml.Node]struct{}
+}
+
+func loadConfigFile(path string) (*configFile, error) {
+	cf := &configFile{path: path, used: map[*yaml.Node]struct{}{}}
+	if path == "" {
+		return cf, nil
+	}
+
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return cf, err
+	}
+
+	doc := yaml.Node{}
+	if err := yaml.Unmarshal(blob, &doc); err != nil {
+		return cf, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return cf, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return cf, fmt.Errorf("%s: expected a map at the top level", path)
+	}
+	cf.root = doc.Content[0]
+	return cf, nil
+}
+
+func normalizeFileKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "-", "_")
+}
+
+func findFileNode(node *yaml.Node, key string, path string) (*yaml.Node, string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k, v := node.Content[i].Value, node.Content[i+1]
+		name := normalizeFileKey(k)
+		if name == key {
+			return v, path + k
+		}
+		if v.Kind == yaml.MappingNode && strings.HasPrefix(key, name+"_") {
+			if n, p := findFileNode(v, strings.TrimPrefix(key, name+"_"), path+k+"."); n != nil {
+				return n, p
+			}
+		}
+	}
+	return nil, ""
+}
+
+func (cf *configFile) lookup(envKey string) (node *yaml.Node, path string) {
+	if cf.root == nil {
+		return nil, ""
+	}
+	key, ok := strings.CutPrefix(envKey, "STREMTHRU_")
+	if !ok {
+		return nil, ""
+	}
+	return findFileNode(cf.root, strings.ToLower(key), "")
+}
+
+func renderFileEntries(node *yaml.Node) ([]string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return []string{node.Value}, nil
+	case yaml.SequenceNode:
+		parts := make([]string, len(node.Content))
+		for i, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: expected a scalar value", item.Line)
+			}
+			parts[i] = item.Value
+		}
+		return []string{strings.Join(parts, ":")}, nil
+	case yaml.MappingNode:
+		entries := []string{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			items, err := renderFileEntries(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				entries = append(entries, node.Content[i].Value+":"+item)
+			}
+		}
+		return entries, nil
+	case yaml.AliasNode:
+		return renderFileEntries(node.Alias)
+	}
+	return nil, fmt.Errorf("line %d: unsupported value", node.Line)
+}
+
+func renderFileValue(node *yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			entries, err := renderFileEntries(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, entries...)
+		}
+		return strings.Join(items, ","), nil
+	case yaml.AliasNode:
+		return renderFileValue(node.Alias)
+	default:
+		entries, err := renderFileEntries(node)
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(entries, ","), nil
+	}
+}
+
+// get returns the value for the env var, and marks the key as used.
+func (cf *configFile) get(envKey string) (string, bool, error) {
+	node, path := cf.lookup(envKey)
+	if node == nil {
+		return "", false, nil
+	}
+	cf.used[node] = struct{}{}
+	value, err := renderFileValue(node)
+	if err != nil {
+		return "", true, fmt.Errorf("%s (%s): %w", path, cf.path, err)
+	}
+	return value, true, nil
+}
+
+// describe returns where the key is set in the file, if it is.
+func (cf *configFile) describe(envKey string) string {
+	node, path := cf.lookup(envKey)
+	if node == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s:%d)", path, cf.path, node.Line)
+}
+
+func (cf *configFile) collectUnusedKeys(node *yaml.Node, path string, keys *[]string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k, v := node.Content[i], node.Content[i+1]
+		if _, used := cf.used[v]; used {
+			continue
+		}
+		if v.Kind == yaml.MappingNode && len(v.Content) > 0 {
+			cf.collectUnusedKeys(v, path+k.Value+".", keys)
+			continue
+		}
+		*keys = append(*keys, fmt.Sprintf("%s%s (%s:%d)", path, k.Value, cf.path, k.Line))
+	}
+}
+
+// unknownKeys returns the keys in the file that were never read.
+func (cf *configFile) unknownKeys() []string {
+	keys := []string{}
+	if cf.root != nil {
+		cf.collectUnusedKeys(cf.root, "", &keys)
+	}
+	return keys
+}
+
+// keyError prefixes the error with where the key is set, i.e. the path in the
+// config file, or the env var.
+func (cf *configFile) keyError(key string, err error) error {
+	if value, exists := os.LookupEnv(key); !exists || len(value) == 0 {
+		if source := cf.describe(key); source != "" {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+	}
+	return fmt.Errorf("%s: %w", key, err)
+}
+
+var errConfigFileMissing = errors.New("config file does not exist")
+
+var file = func() *configFile {
+	path := os.Getenv("STREMTHRU_CONFIG_FILE")
+	cf, err := loadConfigFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			err = fmt.Errorf("%w: %s", errConfigFileMissing, path)
+		}
+		addProblem(err)
+	}
+	return cf
+}()
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigFileTestSuite struct {
+	suite.Suite
+}
+
+func (s *ConfigFileTestSuite) load(content string) *configFile {
+	path := filepath.Join(s.T().TempDir(), "stremthru.yaml")
+	s.Require().NoError(os.WriteFile(path, []byte(content), 0644))
+	cf, err := loadConfigFile(path)
+	s.Require().NoError(err)
+	return cf
+}
+
+func (s *ConfigFileTestSuite) get(cf *configFile, key string) string {
+	value, found, err := cf.get(key)
+	s.Require().NoError(err)
+	s.Require().True(found, key)
+	return value
+}
+
+func (s *ConfigFileTestSuite) TestValues() {
+	cf := s.load(`
+port: 8080
+log-level: warn
+proxy_auth:
+  - alice:pass
+  - bob:pass
+store:
+  auth:
+    alice:
+      realdebrid: tok1
+    "*":
+      torbox: tok2
+  content_cached_stale_time:
+    realdebrid: [48h, 16h]
+  tunnel:
+    "*": api
+feature: [-stremio_list, +anime]
+`)
+
+	s.Equal("8080", s.get(cf, "STREMTHRU_PORT"))
+	s.Equal("warn", s.get(cf, "STREMTHRU_LOG_LEVEL"))
+	s.Equal("alice:pass,bob:pass", s.get(cf, "STREMTHRU_PROXY_AUTH"))
+	s.Equal("alice:realdebrid:tok1,*:torbox:tok2", s.get(cf, "STREMTHRU_STORE_AUTH"))
+	s.Equal("realdebrid:48h:16h", s.get(cf, "STREMTHRU_STORE_CONTENT_CACHED_STALE_TIME"))
+	s.Equal("*:api", s.get(cf, "STREMTHRU_STORE_TUNNEL"))
+	s.Equal("-stremio_list,+anime", s.get(cf, "STREMTHRU_FEATURE"))
+
+	_, found, err := cf.get("STREMTHRU_STORE_RATE_LIMIT")
+	s.NoError(err)
+	s.False(found)
+
+	s.Empty(cf.unknownKeys())
+}
+
+func (s *ConfigFileTestSuite) TestUnknownKeys() {
+	cf := s.load(`
+port: 8080
+prot: 8081
+store:
+  auth: alice:realdebrid:tok
+  auht: alice:realdebrid:tok
+`)
+
+	s.Equal("8080", s.get(cf, "STREMTHRU_PORT"))
+	s.Equal("alice:realdebrid:tok", s.get(cf, "STREMTHRU_STORE_AUTH"))
+	s.Equal([]string{
+		"prot (" + cf.path + ":3)",
+		"store.auht (" + cf.path + ":6)",
+	}, cf.unknownKeys())
+}
+
+func (s *ConfigFileTestSuite) TestKeyError() {
+	cf := s.load(`
+worker_queue: disk
+`)
+
+	s.EqualError(cf.keyError("STREMTHRU_WORKER_QUEUE", os.ErrInvalid), "worker_queue ("+cf.path+":2): invalid argument")
+	s.EqualError(cf.keyError("STREMTHRU_PORT", os.ErrInvalid), "STREMTHRU_PORT: invalid argument")
+}
+
+func (s *ConfigFileTestSuite) TestInvalid() {
+	path := filepath.Join(s.T().TempDir(), "stremthru.yaml")
+	s.Require().NoError(os.WriteFile(path, []byte("- port\n"), 0644))
+	_, err := loadConfigFile(path)
+	s.ErrorContains(err, "expected a map")
+
+	cf := s.load(`
+store:
+  auth:
+    alice: [{realdebrid: tok}]
+`)
+	_, _, err = cf.get("STREMTHRU_STORE_AUTH")
+	s.ErrorContains(err, "store.auth")
+}
+
+func TestConfigFile(t *testing.T) {
+	suite.Run(t, new(ConfigFileTestSuite))
+}
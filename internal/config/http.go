@@ -2,8 +2,10 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"log"
+	"maps"
 	"net/http"
 	"net/url"
 	"os"
@@ -23,6 +25,9 @@ const (
 type TunnelMap map[string]url.URL
 
 func (tm TunnelMap) hasProxy() bool {
+	reloadable.RLock()
+	defer reloadable.RUnlock()
+
 	for _, proxyUrl := range tm {
 		if proxyUrl.Host != "" {
 			return true
@@ -32,6 +37,9 @@ func (tm TunnelMap) hasProxy() bool {
 }
 
 func (tm TunnelMap) GetDefaultProxyHost() string {
+	reloadable.RLock()
+	defer reloadable.RUnlock()
+
 	if proxy := tm.getProxy("*"); proxy != nil && proxy.Host != "" {
 		return proxy.Host
 	}
@@ -42,9 +50,6 @@ func (tm TunnelMap) getProxy(hostname string) *url.URL {
 	hn := hostname
 	for {
 		if proxy, ok := tm[hn]; ok {
-			if hn != hostname {
-				tm[hostname] = proxy
-			}
 			return &proxy
 		}
 
@@ -59,6 +64,9 @@ func (tm TunnelMap) getProxy(hostname string) *url.URL {
 // If tunnel is configured for `hostname` use that.
 // Otherwise fallback to environment proxy, i.e. `HTTP_PROXY`, `HTTPS_PROXY`, `NO_PROXY`
 func (tm TunnelMap) autoProxy(r *http.Request) (*url.URL, error) {
+	reloadable.RLock()
+	defer reloadable.RUnlock()
+
 	proxy := tm.getProxy(r.URL.Hostname())
 	if proxy == nil {
 		return http.ProxyFromEnvironment(r)
@@ -71,6 +79,9 @@ func (tm TunnelMap) autoProxy(r *http.Request) (*url.URL, error) {
 
 // Use the default tunnel, ignore `NO_PROXY`
 func (tm TunnelMap) forcedProxy(r *http.Request) (*url.URL, error) {
+	reloadable.RLock()
+	defer reloadable.RUnlock()
+
 	if proxy := tm.getProxy("*"); proxy != nil && proxy.Host != "" {
 		return proxy, nil
 	}
@@ -90,34 +101,42 @@ func (tm TunnelMap) GetProxy(tunnelType TunnelType) func(req *http.Request) (*ur
 	}
 }
 
-func parseTunnel(httpProxy, httpsProxy, tunnel string) TunnelMap {
-	tunnelMap := make(TunnelMap)
-
+func parseDefaultProxy(httpProxy, httpsProxy string) *url.URL {
 	defaultProxy := &url.URL{}
 
 	if value := httpProxy; len(value) > 0 {
-		if err := os.Setenv("HTTP_PROXY", value); err != nil {
-			log.Fatal("failed to set http_proxy")
-		}
-		if err := os.Setenv("HTTPS_PROXY", value); err != nil {
-			log.Fatal("failed to set https_proxy")
-		}
 		if u, err := url.Parse(value); err == nil {
 			defaultProxy = u
 		}
 	}
 
 	// deprecated
-	if value := httpsProxy; len(value) > 0 {
-		if err := os.Setenv("HTTPS_PROXY", value); err != nil {
-			log.Fatal("failed to set https_proxy")
+	if value := httpsProxy; len(value) > 0 && defaultProxy.Host == "" {
+		if u, err := url.Parse(value); err == nil {
+			defaultProxy = u
 		}
-		if defaultProxy.Host == "" {
-			if u, err := url.Parse(value); err == nil {
-				defaultProxy = u
-			}
+	}
+
+	return defaultProxy
+}
+
+// parseTunnelDefault returns the value for `*`, i.e. `true`, `false` or
+// empty.
+func parseTunnelDefault(tunnel string) string {
+	for _, tunnel := range strings.FieldsFunc(tunnel, func(c rune) bool {
+		return c == ','
+	}) {
+		if hostname, proxy, ok := strings.Cut(tunnel, ":"); ok && hostname == "*" {
+			return proxy
 		}
 	}
+	return ""
+}
+
+func parseTunnel(httpProxy, httpsProxy, tunnel string) (TunnelMap, error) {
+	tunnelMap := make(TunnelMap)
+
+	defaultProxy := parseDefaultProxy(httpProxy, httpsProxy)
 
 	tunnelMap["*"] = *defaultProxy
 
@@ -127,16 +146,8 @@ func parseTunnel(httpProxy, httpsProxy, tunnel string) TunnelMap {
 
 	for _, tunnel := range tunnelList {
 		if hostname, proxy, ok := strings.Cut(tunnel, ":"); ok {
+			// handled by setProxyEnv
 			if hostname == "*" {
-				if proxy == "false" {
-					if err := os.Setenv("NO_PROXY", "*"); err != nil {
-						log.Fatal("failed to set no_proxy")
-					}
-				} else if proxy == "true" {
-					if err := os.Unsetenv("NO_PROXY"); err != nil {
-						log.Fatal("failed to unset no_proxy")
-					}
-				}
 				continue
 			}
 
@@ -146,14 +157,47 @@ func parseTunnel(httpProxy, httpsProxy, tunnel string) TunnelMap {
 			case "true":
 				tunnelMap[hostname] = *defaultProxy
 			default:
-				if u, err := url.Parse(proxy); err == nil {
-					tunnelMap[hostname] = *u
+				u, err := url.Parse(proxy)
+				if err != nil || u.Scheme == "" || u.Host == "" {
+					return nil, fmt.Errorf("invalid proxy for %s", hostname)
 				}
+				tunnelMap[hostname] = *u
 			}
 		}
 	}
 
-	return tunnelMap
+	return tunnelMap, nil
+}
+
+// setProxyEnv sets the env vars used by http.ProxyFromEnvironment. It reads
+// them only once, so it is called only at startup.
+func setProxyEnv(httpProxy, httpsProxy, tunnel string) {
+	if value := httpProxy; len(value) > 0 {
+		if err := os.Setenv("HTTP_PROXY", value); err != nil {
+			log.Fatal("failed to set http_proxy")
+		}
+		if err := os.Setenv("HTTPS_PROXY", value); err != nil {
+			log.Fatal("failed to set https_proxy")
+		}
+	}
+
+	// deprecated
+	if value := httpsProxy; len(value) > 0 {
+		if err := os.Setenv("HTTPS_PROXY", value); err != nil {
+			log.Fatal("failed to set https_proxy")
+		}
+	}
+
+	switch parseTunnelDefault(tunnel) {
+	case "false":
+		if err := os.Setenv("NO_PROXY", "*"); err != nil {
+			log.Fatal("failed to set no_proxy")
+		}
+	case "true":
+		if err := os.Unsetenv("NO_PROXY"); err != nil {
+			log.Fatal("failed to unset no_proxy")
+		}
+	}
 }
 
 var Tunnel = func() TunnelMap {
@@ -164,7 +208,13 @@ var Tunnel = func() TunnelMap {
 		httpsProxy = httpProxy
 	}
 	tunnel := getEnv("STREMTHRU_TUNNEL")
-	return parseTunnel(httpProxy, httpsProxy, tunnel)
+	setProxyEnv(httpProxy, httpsProxy, tunnel)
+	tunnelMap, err := parseTunnel(httpProxy, httpsProxy, tunnel)
+	if err != nil {
+		addProblem(keyError("STREMTHRU_TUNNEL", err))
+		tunnelMap = TunnelMap{"*": *parseDefaultProxy(httpProxy, httpsProxy)}
+	}
+	return tunnelMap
 }()
 
 type StoreTunnelConfig struct {
@@ -185,6 +235,9 @@ func (stc StoreTunnelConfigMap) isEnabledForAPI(name string) bool {
 }
 
 func (stc StoreTunnelConfigMap) GetTypeForAPI(name string) TunnelType {
+	reloadable.RLock()
+	defer reloadable.RUnlock()
+
 	enabled := stc.isEnabledForAPI(name)
 	if enabled {
 		return TUNNEL_TYPE_FORCED
@@ -203,6 +256,9 @@ func (stc StoreTunnelConfigMap) isEnabledForStream(name string) bool {
 }
 
 func (stc StoreTunnelConfigMap) GetTypeForStream(name string) TunnelType {
+	reloadable.RLock()
+	defer reloadable.RUnlock()
+
 	enabled := stc.isEnabledForStream(name)
 	if enabled {
 		return TUNNEL_TYPE_FORCED
@@ -210,6 +266,17 @@ func (stc StoreTunnelConfigMap) GetTypeForStream(name string) TunnelType {
 	return TUNNEL_TYPE_NONE
 }
 
+func (stc StoreTunnelConfigMap) ListStores() []string {
+	reloadable.RLock()
+	defer reloadable.RUnlock()
+
+	stores := make([]string, 0, len(stc))
+	for name := range stc {
+		stores = append(stores, name)
+	}
+	return stores
+}
+
 func parseStoreTunnel(storeTunnel string, tunnelMap TunnelMap) StoreTunnelConfigMap {
 	storeTunnelList := strings.FieldsFunc(storeTunnel, func(c rune) bool {
 		return c == ','
@@ -377,7 +444,11 @@ func (ipr *IPResolver) resolveTunnelIPMap() error {
 	proxyIpByHostname := map[string]string{}
 	errs := []error{}
 
-	for hostname, u := range Tunnel {
+	reloadable.RLock()
+	tunnel := maps.Clone(Tunnel)
+	reloadable.RUnlock()
+
+	for hostname, u := range tunnel {
 		if ip, ok := proxyIpByProxyHost[u.Host]; ok {
 			proxyIpByHostname[hostname] = ip
 			continue
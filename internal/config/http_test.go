@@ -15,16 +15,19 @@ type TunnelTestSuite struct {
 
 func (s *TunnelTestSuite) TestDefaultOff() {
 	httpProxy := "http://127.0.0.1:1080"
-	tunnel := parseTunnel(httpProxy, httpProxy, "*:false,x.y:true")
+	tunnel, err := parseTunnel(httpProxy, httpProxy, "*:false,x.y:true")
+	s.Require().NoError(err)
 
 	proxy := tunnel.getProxy("*")
 	s.NotNil(proxy)
 	s.Equal(proxy.String(), httpProxy)
 
+	s.T().Setenv("NO_PROXY", "")
+	setProxyEnv(httpProxy, httpProxy, "*:false,x.y:true")
 	s.Equal(os.Getenv("NO_PROXY"), "*")
 
 	s.Nil(tunnel.getProxy("abc.xyz"))
-	proxy, err := tunnel.forcedProxy(&http.Request{
+	proxy, err = tunnel.forcedProxy(&http.Request{
 		URL: &url.URL{Host: "abc.xyz", Scheme: "https"},
 	})
 	s.Nil(err)
@@ -46,16 +49,19 @@ func (s *TunnelTestSuite) TestDefaultOff() {
 
 func (s *TunnelTestSuite) TestDefaultOn() {
 	httpProxy := "http://127.0.0.1:1080"
-	tunnel := parseTunnel(httpProxy, httpProxy, "*:true,x.y:false")
+	tunnel, err := parseTunnel(httpProxy, httpProxy, "*:true,x.y:false")
+	s.Require().NoError(err)
 
 	proxy := tunnel.getProxy("*")
 	s.NotNil(proxy)
 	s.Equal(proxy.String(), httpProxy)
 
+	s.T().Setenv("NO_PROXY", "*")
+	setProxyEnv(httpProxy, httpProxy, "*:true,x.y:false")
 	s.Equal(os.Getenv("NO_PROXY"), "")
 
 	s.Nil(tunnel.getProxy("abc.xyz"))
-	proxy, err := tunnel.autoProxy(&http.Request{
+	proxy, err = tunnel.autoProxy(&http.Request{
 		URL: &url.URL{Host: "abc.xyz", Scheme: "https"},
 	})
 	s.Nil(err)
@@ -74,6 +80,15 @@ func (s *TunnelTestSuite) TestDefaultOn() {
 	s.Equal(tunnel.getProxy("a.x.y"), &url.URL{})
 }
 
+func (s *TunnelTestSuite) TestInvalidProxy() {
+	httpProxy := "http://127.0.0.1:1080"
+	_, err := parseTunnel(httpProxy, httpProxy, "x.y:127.0.0.1")
+	s.EqualError(err, "invalid proxy for x.y")
+
+	_, err = parseTunnel(httpProxy, httpProxy, "x.y:http://%zz")
+	s.EqualError(err, "invalid proxy for x.y")
+}
+
 func TestTunnel(t *testing.T) {
 	suite.Run(t, new(TunnelTestSuite))
 }
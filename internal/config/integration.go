@@ -42,13 +42,21 @@ func (c integrationConfigLettterboxd) IsEnabled() bool {
 }
 
 func (c integrationConfigLettterboxd) IsPiggybacked() bool {
-	return !c.IsEnabled() && HasPeer
+	return !c.IsEnabled() && HasPeerRole(PeerRoleLetterboxd, false)
+}
+
+type integrationConfigJellyfin struct {
+	ListStaleTime time.Duration
 }
 
 type integrationConfigMDBList struct {
 	ListStaleTime time.Duration
 }
 
+type integrationConfigPlex struct {
+	ListStaleTime time.Duration
+}
+
 type integrationConfigTrakt struct {
 	ClientId      string
 	ClientSecret  string
@@ -102,8 +110,10 @@ type IntegrationConfig struct {
 	AniList    integrationConfigAniList
 	Bitmagnet  integrationConfigBitmagnet
 	GitHub     integrationConfigGitHub
+	Jellyfin   integrationConfigJellyfin
 	Letterboxd integrationConfigLettterboxd
 	MDBList    integrationConfigMDBList
+	Plex       integrationConfigPlex
 	Trakt      integrationConfigTrakt
 	Kitsu      integrationConfigKitsu
 	TMDB       integrationConfigTMDB
@@ -129,25 +139,31 @@ func parseIntegration() IntegrationConfig {
 	if letterboxd.IsPiggybacked() {
 		minLetterboxdListStaleTime = 24 * time.Hour
 	}
-	letterboxd.ListStaleTime = mustParseDuration("letterboxd list stale time", getEnv("STREMTHRU_INTEGRATION_LETTERBOXD_LIST_STALE_TIME"), minLetterboxdListStaleTime)
+	letterboxd.ListStaleTime = mustParseDuration("STREMTHRU_INTEGRATION_LETTERBOXD_LIST_STALE_TIME", "letterboxd list stale time", minLetterboxdListStaleTime)
 
 	integration := IntegrationConfig{
 		AniList: integrationConfigAniList{
-			ListStaleTime: mustParseDuration("anilist list stale time", getEnv("STREMTHRU_INTEGRATION_ANILIST_LIST_STALE_TIME"), 15*time.Minute),
+			ListStaleTime: mustParseDuration("STREMTHRU_INTEGRATION_ANILIST_LIST_STALE_TIME", "anilist list stale time", 15*time.Minute),
 		},
 		Bitmagnet: bitmagnet,
 		GitHub: integrationConfigGitHub{
 			User:  getEnv("STREMTHRU_INTEGRATION_GITHUB_USER"),
 			Token: getEnv("STREMTHRU_INTEGRATION_GITHUB_TOKEN"),
 		},
+		Jellyfin: integrationConfigJellyfin{
+			ListStaleTime: mustParseDuration("STREMTHRU_INTEGRATION_JELLYFIN_LIST_STALE_TIME", "jellyfin list stale time", 15*time.Minute),
+		},
 		Letterboxd: letterboxd,
 		MDBList: integrationConfigMDBList{
-			ListStaleTime: mustParseDuration("mdblist list stale time", getEnv("STREMTHRU_INTEGRATION_MDBLIST_LIST_STALE_TIME"), 15*time.Minute),
+			ListStaleTime: mustParseDuration("STREMTHRU_INTEGRATION_MDBLIST_LIST_STALE_TIME", "mdblist list stale time", 15*time.Minute),
+		},
+		Plex: integrationConfigPlex{
+			ListStaleTime: mustParseDuration("STREMTHRU_INTEGRATION_PLEX_LIST_STALE_TIME", "plex list stale time", 15*time.Minute),
 		},
 		Trakt: integrationConfigTrakt{
 			ClientId:      getEnv("STREMTHRU_INTEGRATION_TRAKT_CLIENT_ID"),
 			ClientSecret:  getEnv("STREMTHRU_INTEGRATION_TRAKT_CLIENT_SECRET"),
-			ListStaleTime: mustParseDuration("trakt list stale time", getEnv("STREMTHRU_INTEGRATION_TRAKT_LIST_STALE_TIME"), 15*time.Minute),
+			ListStaleTime: mustParseDuration("STREMTHRU_INTEGRATION_TRAKT_LIST_STALE_TIME", "trakt list stale time", 15*time.Minute),
 		},
 		Kitsu: integrationConfigKitsu{
 			ClientId:     getEnv("STREMTHRU_INTEGRATION_KITSU_CLIENT_ID"),
@@ -157,11 +173,11 @@ func parseIntegration() IntegrationConfig {
 		},
 		TMDB: integrationConfigTMDB{
 			AccessToken:   getEnv("STREMTHRU_INTEGRATION_TMDB_ACCESS_TOKEN"),
-			ListStaleTime: mustParseDuration("tmdb list stale time", getEnv("STREMTHRU_INTEGRATION_TMDB_LIST_STALE_TIME"), 15*time.Minute),
+			ListStaleTime: mustParseDuration("STREMTHRU_INTEGRATION_TMDB_LIST_STALE_TIME", "tmdb list stale time", 15*time.Minute),
 		},
 		TVDB: integrationConfigTVDB{
 			APIKey:             getEnv("STREMTHRU_INTEGRATION_TVDB_API_KEY"),
-			ListStaleTime:      mustParseDuration("tvdb list stale time", getEnv("STREMTHRU_INTEGRATION_TVDB_LIST_STALE_TIME"), 15*time.Minute),
+			ListStaleTime:      mustParseDuration("STREMTHRU_INTEGRATION_TVDB_LIST_STALE_TIME", "tvdb list stale time", 15*time.Minute),
 			SystemOAuthTokenId: "system:tvdb",
 		},
 	}
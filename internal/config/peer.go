@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type PeerRole string
+
+const (
+	PeerRoleCheckMagnet  PeerRole = "check_magnet"
+	PeerRoleListTorrents PeerRole = "list_torrents"
+	PeerRolePushTorrents PeerRole = "push_torrents"
+	PeerRoleLetterboxd   PeerRole = "letterboxd"
+)
+
+var peerRoles = []PeerRole{
+	PeerRoleCheckMagnet,
+	PeerRoleListTorrents,
+	PeerRolePushTorrents,
+	PeerRoleLetterboxd,
+}
+
+// check_magnet sends the store token to the peer, so it is only given to
+// trusted peers, explicitly.
+var defaultPeerRoles = []PeerRole{
+	PeerRoleListTorrents,
+	PeerRolePushTorrents,
+	PeerRoleLetterboxd,
+}
+
+type PeerConfig struct {
+	URL       string
+	AuthToken string
+	Weight    int
+	Roles     []PeerRole
+	// the store tokens are sent to the peer
+	Trusted bool
+}
+
+func (p PeerConfig) HasRole(role PeerRole) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+func (p PeerConfig) String() string {
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return p.URL
+	}
+	if p.AuthToken != "" {
+		u.User = url.UserPassword("", p.AuthToken)
+	}
+	roles := make([]string, len(p.Roles))
+	for i, role := range p.Roles {
+		roles[i] = string(role)
+	}
+	str := u.Redacted() + " (weight=" + strconv.Itoa(p.Weight) + ", roles=" + strings.Join(roles, ",")
+	if p.Trusted {
+		str += ", trusted"
+	}
+	return str + ")"
+}
+
+// parsePeerURI parses `https://:<pass>@<host>[:<port>][?weight=<n>&role=<role>...&trusted=<bool>]`.
+// The query is used for the options, and is not sent to the peer.
+func parsePeerURI(uri string) (PeerConfig, error) {
+	peer := PeerConfig{Weight: 1}
+
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		return peer, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return peer, fmt.Errorf("invalid peer uri: %s", uri)
+	}
+
+	query := u.Query()
+	for key := range query {
+		switch key {
+		case "weight":
+			peer.Weight, err = strconv.Atoi(query.Get(key))
+			if err == nil && peer.Weight < 1 {
+				err = fmt.Errorf("weight (%d) must be at least 1", peer.Weight)
+			}
+			if err != nil {
+				return peer, fmt.Errorf("invalid peer weight: %v", err)
+			}
+		case "role":
+			for _, role := range query[key] {
+				if !slices.Contains(peerRoles, PeerRole(role)) {
+					return peer, fmt.Errorf("invalid peer role: %s", role)
+				}
+				if !peer.HasRole(PeerRole(role)) {
+					peer.Roles = append(peer.Roles, PeerRole(role))
+				}
+			}
+		case "trusted":
+			peer.Trusted, err = strconv.ParseBool(query.Get(key))
+			if err != nil {
+				return peer, fmt.Errorf("invalid peer trusted: %v", err)
+			}
+		default:
+			return peer, fmt.Errorf("unknown peer option: %s", key)
+		}
+	}
+	if len(peer.Roles) == 0 {
+		peer.Roles = slices.Clone(defaultPeerRoles)
+		// check_magnet was a default role before trusted peers
+		log.Printf("WARNING: peer %s does not have the %s role, it needs trusted=true and role=%s\n", u.Host, PeerRoleCheckMagnet, PeerRoleCheckMagnet)
+	}
+	if peer.HasRole(PeerRoleCheckMagnet) && !peer.Trusted {
+		return peer, fmt.Errorf("peer role %s requires trusted peer", PeerRoleCheckMagnet)
+	}
+
+	if password, ok := u.User.Password(); ok {
+		peer.AuthToken = password
+	} else {
+		peer.AuthToken = u.User.Username()
+	}
+	u.User = nil
+	u.RawQuery = ""
+	peer.URL = strings.TrimSpace(u.String())
+
+	return peer, nil
+}
+
+func parsePeerURIs(uris string) ([]PeerConfig, error) {
+	peers := []PeerConfig{}
+	for _, uri := range strings.FieldsFunc(uris, func(c rune) bool {
+		return c == ','
+	}) {
+		peer, err := parsePeerURI(uri)
+		if err != nil {
+			return nil, err
+		}
+		if slices.ContainsFunc(peers, func(p PeerConfig) bool {
+			return p.URL == peer.URL
+		}) {
+			return nil, fmt.Errorf("duplicate peer uri: %s", peer.URL)
+		}
+		peers = append(peers, peer)
+	}
+	return peers, nil
+}
+
+// HasPeerRole reports if any peer is configured for the role.
+func HasPeerRole(role PeerRole, withToken bool) bool {
+	return slices.ContainsFunc(Peers, func(p PeerConfig) bool {
+		return p.HasRole(role) && (!withToken || p.AuthToken != "")
+	})
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+// problems found while parsing the config, reported together at startup
+var problems []error
+
+func addProblem(err error) {
+	problems = append(problems, err)
+}
+
+func init() {
+	for _, key := range file.unknownKeys() {
+		addProblem(fmt.Errorf("unknown key: %s", key))
+	}
+	if len(problems) == 0 {
+		return
+	}
+	for _, err := range problems {
+		log.Printf("invalid config: %v", err)
+	}
+	log.Printf("found %d problem(s) in config", len(problems))
+	os.Exit(1)
+}
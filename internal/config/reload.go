@@ -0,0 +1,208 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// guards the config that can be changed by Reload
+var reloadable sync.RWMutex
+
+// serializes the reloads
+var reloadMutex sync.Mutex
+
+var reloadableKeys = []string{
+	"STREMTHRU_PROXY_AUTH",
+	"STREMTHRU_AUTH_ADMIN",
+	"STREMTHRU_STORE_AUTH",
+	"STREMTHRU_TUNNEL",
+	"STREMTHRU_STORE_TUNNEL",
+	"STREMTHRU_STORE_CONTENT_PROXY",
+	"STREMTHRU_CONTENT_PROXY_CONNECTION_LIMIT",
+	"STREMTHRU_FEATURE",
+}
+
+// features enabled at startup, the endpoints and workers for the others are
+// not set up.
+var startupFeature = config.Feature
+
+type ReloadResult struct {
+	// keys with changed value, that are applied
+	Applied []string `json:"applied"`
+	// keys with changed value, that need a restart
+	RestartRequired []string `json:"restart_required"`
+}
+
+func replaceMap[M ~map[K]V, K comparable, V any](dst, src M) {
+	clear(dst)
+	maps.Copy(dst, src)
+}
+
+// Reload reads the config file and the env vars again, and applies the
+// reloadable keys. Nothing is applied if there is any problem.
+func Reload() (*ReloadResult, error) {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+
+	cf, err := loadConfigFile(file.path)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := []error{}
+
+	values := map[string]string{}
+	for key := range loadedEnv {
+		value, err := lookupEnv(cf, key)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		values[key] = value
+	}
+	for _, key := range cf.unknownKeys() {
+		errs = append(errs, fmt.Errorf("unknown key: %s", key))
+	}
+
+	changed := map[string]bool{}
+	for key, value := range values {
+		if value != loadedEnv[key] {
+			changed[key] = true
+		}
+	}
+
+	result := &ReloadResult{Applied: []string{}, RestartRequired: []string{}}
+	for key := range changed {
+		if !slices.Contains(reloadableKeys, key) {
+			result.RestartRequired = append(result.RestartRequired, key)
+		}
+	}
+
+	apply := []func(){}
+	applied := func(keys ...string) {
+		for _, key := range keys {
+			if changed[key] {
+				result.Applied = append(result.Applied, key)
+			}
+		}
+	}
+	restartRequired := func(key string) {
+		result.RestartRequired = append(result.RestartRequired, key)
+		delete(changed, key)
+	}
+
+	proxyAuthPasswordMap := ProxyAuthPassword
+	if changed["STREMTHRU_PROXY_AUTH"] {
+		proxyAuthPasswordMap = parseProxyAuth(values["STREMTHRU_PROXY_AUTH"])
+		// public instance can not be switched without restart
+		if (len(proxyAuthPasswordMap) == 0) != IsPublicInstance {
+			proxyAuthPasswordMap = ProxyAuthPassword
+			restartRequired("STREMTHRU_PROXY_AUTH")
+		}
+	}
+	if changed["STREMTHRU_PROXY_AUTH"] || changed["STREMTHRU_AUTH_ADMIN"] {
+		authAdminMap, adminPasswordMap := parseAuthAdmin(values["STREMTHRU_AUTH_ADMIN"], proxyAuthPasswordMap)
+		apply = append(apply, func() {
+			replaceMap(ProxyAuthPassword, proxyAuthPasswordMap)
+			replaceMap(AuthAdmin, authAdminMap)
+			replaceMap(AdminPassword, adminPasswordMap)
+		})
+		applied("STREMTHRU_PROXY_AUTH", "STREMTHRU_AUTH_ADMIN")
+	}
+
+	if changed["STREMTHRU_STORE_AUTH"] {
+		storeAuthTokenMap, err := parseStoreAuth(values["STREMTHRU_STORE_AUTH"])
+		if err != nil {
+			errs = append(errs, cf.keyError("STREMTHRU_STORE_AUTH", err))
+		}
+		apply = append(apply, func() {
+			replaceMap(StoreAuthToken, storeAuthTokenMap)
+		})
+		applied("STREMTHRU_STORE_AUTH")
+	}
+
+	// the default tunnel is set in the env vars, it needs a restart
+	if changed["STREMTHRU_TUNNEL"] && parseTunnelDefault(values["STREMTHRU_TUNNEL"]) != parseTunnelDefault(loadedEnv["STREMTHRU_TUNNEL"]) {
+		restartRequired("STREMTHRU_TUNNEL")
+	}
+	if changed["STREMTHRU_TUNNEL"] || changed["STREMTHRU_STORE_TUNNEL"] {
+		// the http proxy is set in the env vars, it needs a restart
+		httpProxy := loadedEnv["STREMTHRU_HTTP_PROXY"]
+		httpsProxy := loadedEnv["STREMTHRU_HTTPS_PROXY"]
+		if httpsProxy == "" {
+			httpsProxy = httpProxy
+		}
+		tunnel := loadedEnv["STREMTHRU_TUNNEL"]
+		if changed["STREMTHRU_TUNNEL"] {
+			tunnel = values["STREMTHRU_TUNNEL"]
+		}
+		tunnelMap, err := parseTunnel(httpProxy, httpsProxy, tunnel)
+		if err != nil {
+			errs = append(errs, cf.keyError("STREMTHRU_TUNNEL", err))
+		} else {
+			storeTunnelMap := parseStoreTunnel(values["STREMTHRU_STORE_TUNNEL"], tunnelMap)
+			apply = append(apply, func() {
+				replaceMap(Tunnel, tunnelMap)
+				replaceMap(StoreTunnel, storeTunnelMap)
+			})
+			applied("STREMTHRU_TUNNEL", "STREMTHRU_STORE_TUNNEL")
+		}
+	}
+
+	if changed["STREMTHRU_STORE_CONTENT_PROXY"] {
+		storeContentProxyMap := parseStoreContentProxy(values["STREMTHRU_STORE_CONTENT_PROXY"])
+		apply = append(apply, func() {
+			replaceMap(StoreContentProxy, storeContentProxyMap)
+		})
+		applied("STREMTHRU_STORE_CONTENT_PROXY")
+	}
+
+	if changed["STREMTHRU_CONTENT_PROXY_CONNECTION_LIMIT"] {
+		contentProxyConnectionMap, err := parseContentProxyConnectionLimit(values["STREMTHRU_CONTENT_PROXY_CONNECTION_LIMIT"])
+		if err != nil {
+			errs = append(errs, cf.keyError("STREMTHRU_CONTENT_PROXY_CONNECTION_LIMIT", err))
+		}
+		apply = append(apply, func() {
+			replaceMap(ContentProxyConnectionLimit, contentProxyConnectionMap)
+		})
+		applied("STREMTHRU_CONTENT_PROXY_CONNECTION_LIMIT")
+	}
+
+	if changed["STREMTHRU_FEATURE"] {
+		feature, err := parseFeature(values["STREMTHRU_FEATURE"])
+		if err != nil {
+			errs = append(errs, cf.keyError("STREMTHRU_FEATURE", err))
+		}
+		if slices.ContainsFunc(features, func(name string) bool {
+			return feature.isEnabled(name) && !startupFeature.isEnabled(name)
+		}) {
+			restartRequired("STREMTHRU_FEATURE")
+		} else {
+			apply = append(apply, func() {
+				Feature = feature
+			})
+			applied("STREMTHRU_FEATURE")
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	reloadable.Lock()
+	for _, fn := range apply {
+		fn()
+	}
+	reloadable.Unlock()
+
+	for _, key := range result.Applied {
+		loadedEnv[key] = values[key]
+	}
+	file = cf
+
+	slices.Sort(result.Applied)
+	slices.Sort(result.RestartRequired)
+	return result, nil
+}
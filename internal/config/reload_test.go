@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/yaml.v3"
+)
+
+type ReloadTestSuite struct {
+	suite.Suite
+	file *configFile
+	path string
+}
+
+func (s *ReloadTestSuite) SetupTest() {
+	s.file = file
+	s.path = filepath.Join(s.T().TempDir(), "stremthru.yaml")
+	s.write("")
+	file = &configFile{path: s.path, used: map[*yaml.Node]struct{}{}}
+}
+
+func (s *ReloadTestSuite) TearDownTest() {
+	s.write("")
+	_, err := Reload()
+	s.NoError(err)
+	file = s.file
+}
+
+func (s *ReloadTestSuite) write(content string) {
+	s.Require().NoError(os.WriteFile(s.path, []byte(content), 0644))
+}
+
+func (s *ReloadTestSuite) TestReload() {
+	s.write(`
+port: 9090
+proxy_auth: alice:pass
+store_auth: alice:realdebrid:token
+content_proxy_connection_limit: "*:3"
+`)
+	result, err := Reload()
+	s.Require().NoError(err)
+	s.Equal([]string{
+		"STREMTHRU_CONTENT_PROXY_CONNECTION_LIMIT",
+		"STREMTHRU_STORE_AUTH",
+	}, result.Applied)
+	s.Equal([]string{
+		"STREMTHRU_PORT",
+		"STREMTHRU_PROXY_AUTH",
+	}, result.RestartRequired)
+	s.Equal("token", StoreAuthToken.GetToken("alice", "realdebrid"))
+	s.Equal(3, ContentProxyConnectionLimit.Get("alice"))
+	s.Empty(ProxyAuthPassword)
+}
+
+func (s *ReloadTestSuite) TestReloadInvalid() {
+	s.write(`
+store_auth: alice:unknown:token
+content_proxy_connection_limit: "*:3"
+unknown: true
+`)
+	_, err := Reload()
+	s.ErrorContains(err, "store_auth ("+s.path+":2): invalid store name: unknown")
+	s.ErrorContains(err, "unknown key: unknown")
+	s.Equal(0, ContentProxyConnectionLimit.Get("alice"))
+}
+
+func (s *ReloadTestSuite) TestReloadTunnel() {
+	s.write(`
+tunnel: "x.y:socks5://127.0.0.1:1080"
+`)
+	result, err := Reload()
+	s.Require().NoError(err)
+	s.Equal([]string{"STREMTHRU_TUNNEL"}, result.Applied)
+	s.Equal("socks5://127.0.0.1:1080", Tunnel.getProxy("x.y").String())
+
+	s.write(`
+tunnel: "*:false,x.y:socks5://127.0.0.1:1080"
+`)
+	result, err = Reload()
+	s.Require().NoError(err)
+	s.Empty(result.Applied)
+	s.Equal([]string{"STREMTHRU_TUNNEL"}, result.RestartRequired)
+
+	s.write(`
+tunnel: "x.y:127.0.0.1:1080"
+`)
+	_, err = Reload()
+	s.ErrorContains(err, "tunnel ("+s.path+":2): invalid proxy for x.y")
+	s.Equal("socks5://127.0.0.1:1080", Tunnel.getProxy("x.y").String())
+}
+
+func TestReload(t *testing.T) {
+	suite.Run(t, new(ReloadTestSuite))
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/store"
+)
+
+type StoreHousekeepingRule struct {
+	MaxAge       time.Duration // remove magnets added longer than this ago
+	UnplayedFor  time.Duration // remove magnets not touched for this long
+	RemoveFailed bool          // remove failed / invalid magnets
+	StalledFor   time.Duration // remove magnets stuck in queued / downloading for this long
+	KeepRecent   int           // never remove the N most recently added magnets
+	DryRun       bool          // only log, do not remove
+}
+
+func (r StoreHousekeepingRule) String() string {
+	parts := []string{}
+	if r.MaxAge > 0 {
+		parts = append(parts, "max_age="+r.MaxAge.String())
+	}
+	if r.UnplayedFor > 0 {
+		parts = append(parts, "unplayed="+r.UnplayedFor.String())
+	}
+	if r.RemoveFailed {
+		parts = append(parts, "failed=true")
+	}
+	if r.StalledFor > 0 {
+		parts = append(parts, "stalled="+r.StalledFor.String())
+	}
+	if r.KeepRecent > 0 {
+		parts = append(parts, "keep="+strconv.Itoa(r.KeepRecent))
+	}
+	if r.DryRun {
+		parts = append(parts, "dry_run=true")
+	}
+	return strings.Join(parts, "&")
+}
+
+type StoreHousekeepingEntry struct {
+	User  string
+	Store string
+	Rule  StoreHousekeepingRule
+}
+
+type storeHousekeepingMap map[string]map[string]StoreHousekeepingRule
+
+func (shm storeHousekeepingMap) Get(user, storeName string) (StoreHousekeepingRule, bool) {
+	if rules, ok := shm[user]; ok {
+		if rule, ok := rules[storeName]; ok {
+			return rule, true
+		}
+	}
+	if user != "*" {
+		return shm.Get("*", storeName)
+	}
+	return StoreHousekeepingRule{}, false
+}
+
+// List returns the housekeeping entries for every user with a store token,
+// with `*` expanded to the matching users.
+func (shm storeHousekeepingMap) List() []StoreHousekeepingEntry {
+	entries := []StoreHousekeepingEntry{}
+	for user := range ProxyAuthPassword {
+		for _, storeName := range StoreAuthToken.ListStores(user) {
+			if rule, ok := shm.Get(user, storeName); ok {
+				entries = append(entries, StoreHousekeepingEntry{
+					User:  user,
+					Store: storeName,
+					Rule:  rule,
+				})
+			}
+		}
+	}
+	return entries
+}
+
+func (shm storeHousekeepingMap) IsEnabled() bool {
+	return len(shm) > 0
+}
+
+func parseStoreHousekeepingRule(value string) (StoreHousekeepingRule, error) {
+	rule := StoreHousekeepingRule{}
+
+	query, err := url.ParseQuery(value)
+	if err != nil {
+		return rule, err
+	}
+
+	for key := range query {
+		v := query.Get(key)
+		switch key {
+		case "max_age":
+			rule.MaxAge, err = parseDuration(key, v, 1*time.Hour)
+		case "unplayed":
+			rule.UnplayedFor, err = parseDuration(key, v, 1*time.Hour)
+		case "failed":
+			rule.RemoveFailed, err = strconv.ParseBool(v)
+		case "stalled":
+			rule.StalledFor, err = parseDuration(key, v, 1*time.Hour)
+		case "keep":
+			rule.KeepRecent, err = strconv.Atoi(v)
+			if err == nil && rule.KeepRecent < 0 {
+				err = fmt.Errorf("keep (%d) must not be negative", rule.KeepRecent)
+			}
+		case "dry_run":
+			rule.DryRun, err = strconv.ParseBool(v)
+		default:
+			err = fmt.Errorf("unknown rule: %s", key)
+		}
+		if err != nil {
+			return rule, err
+		}
+	}
+
+	if rule.MaxAge == 0 && rule.UnplayedFor == 0 && !rule.RemoveFailed && rule.StalledFor == 0 {
+		return rule, fmt.Errorf("missing rule, expected one of: max_age, unplayed, failed, stalled")
+	}
+
+	return rule, nil
+}
+
+func parseStoreHousekeeping(housekeepingConfig string) (storeHousekeepingMap, error) {
+	shm := storeHousekeepingMap{}
+	for _, item := range strings.FieldsFunc(housekeepingConfig, func(c rune) bool {
+		return c == ','
+	}) {
+		parts := strings.SplitN(item, ":", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid store housekeeping config: %s", item)
+		}
+		user, storeName, rules := parts[0], parts[1], parts[2]
+		if !store.StoreName(storeName).IsValid() {
+			return nil, fmt.Errorf("invalid store name: %s", storeName)
+		}
+		rule, err := parseStoreHousekeepingRule(rules)
+		if err != nil {
+			return nil, fmt.Errorf("invalid store housekeeping rules (%s): %v", item, err)
+		}
+		if _, ok := shm[user]; !ok {
+			shm[user] = map[string]StoreHousekeepingRule{}
+		}
+		shm[user][storeName] = rule
+	}
+	return shm, nil
+}
+
+var StoreHousekeeping = func() storeHousekeepingMap {
+	shm, err := parseStoreHousekeeping(getEnv("STREMTHRU_STORE_HOUSEKEEPING"))
+	if err != nil {
+		addProblem(keyError("STREMTHRU_STORE_HOUSEKEEPING", err))
+	}
+	return shm
+}()
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// storeRateLimitMap is the requests per minute, per API key, by store name.
+type storeRateLimitMap map[string]int
+
+func (srl storeRateLimitMap) Get(storeName string) int {
+	if limit, ok := srl[storeName]; ok {
+		return limit
+	}
+	if storeName != "*" {
+		return srl.Get("*")
+	}
+	return 0
+}
+
+func parseStoreRateLimit(rateLimitConfig string) (storeRateLimitMap, error) {
+	srl := storeRateLimitMap{}
+	for _, item := range strings.FieldsFunc(rateLimitConfig, func(c rune) bool {
+		return c == ','
+	}) {
+		storeName, limitStr, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid store rate limit: %s", item)
+		}
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid store rate limit: %s", item)
+		}
+		srl[storeName] = limit
+	}
+	return srl, nil
+}
+
+var StoreRateLimit = func() storeRateLimitMap {
+	srl, err := parseStoreRateLimit(getEnv("STREMTHRU_STORE_RATE_LIMIT"))
+	if err != nil {
+		addProblem(keyError("STREMTHRU_STORE_RATE_LIMIT", err))
+	}
+	return srl
+}()
@@ -3,12 +3,11 @@ package config
 import (
 	"strings"
 	"time"
-
-	"github.com/MunifTanjim/stremthru/internal/util"
 )
 
 type stremioConfigList struct {
 	PublicMaxListCount int
+	FeedStaleTime      time.Duration
 }
 
 type stremioConfigStore struct {
@@ -36,19 +35,20 @@ type StremioConfig struct {
 func parseStremio() StremioConfig {
 	stremio := StremioConfig{
 		List: stremioConfigList{
-			PublicMaxListCount: util.MustParseInt(getEnv("STREMTHRU_STREMIO_LIST_PUBLIC_MAX_LIST_COUNT")),
+			PublicMaxListCount: getEnvInt("STREMTHRU_STREMIO_LIST_PUBLIC_MAX_LIST_COUNT"),
+			FeedStaleTime:      mustParseDuration("STREMTHRU_STREMIO_LIST_FEED_STALE_TIME", "list feed stale time", 15*time.Minute),
 		},
 		Store: stremioConfigStore{
-			CatalogItemLimit: util.MustParseInt(getEnv("STREMTHRU_STREMIO_STORE_CATALOG_ITEM_LIMIT")),
-			CatalogCacheTime: mustParseDuration("store catalog cache time", getEnv("STREMTHRU_STREMIO_STORE_CATALOG_CACHE_TIME"), 1*time.Minute),
+			CatalogItemLimit: getEnvInt("STREMTHRU_STREMIO_STORE_CATALOG_ITEM_LIMIT"),
+			CatalogCacheTime: mustParseDuration("STREMTHRU_STREMIO_STORE_CATALOG_CACHE_TIME", "store catalog cache time", 1*time.Minute),
 		},
 		Torz: stremioConfigTorz{
 			LazyPull:            strings.ToLower(getEnv("STREMTHRU_STREMIO_TORZ_LAZY_PULL")) == "true",
-			PublicMaxStoreCount: util.MustParseInt(getEnv("STREMTHRU_STREMIO_TORZ_PUBLIC_MAX_STORE_COUNT")),
+			PublicMaxStoreCount: getEnvInt("STREMTHRU_STREMIO_TORZ_PUBLIC_MAX_STORE_COUNT"),
 		},
 		Wrap: stremioConfigWrap{
-			PublicMaxUpstreamCount: util.MustParseInt(getEnv("STREMTHRU_STREMIO_WRAP_PUBLIC_MAX_UPSTREAM_COUNT")),
-			PublicMaxStoreCount:    util.MustParseInt(getEnv("STREMTHRU_STREMIO_WRAP_PUBLIC_MAX_STORE_COUNT")),
+			PublicMaxUpstreamCount: getEnvInt("STREMTHRU_STREMIO_WRAP_PUBLIC_MAX_UPSTREAM_COUNT"),
+			PublicMaxStoreCount:    getEnvInt("STREMTHRU_STREMIO_WRAP_PUBLIC_MAX_STORE_COUNT"),
 		},
 	}
 	return stremio
@@ -0,0 +1,276 @@
+package content_proxy
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/kv"
+	"github.com/MunifTanjim/stremthru/internal/logger"
+)
+
+var log = logger.Scoped("content_proxy")
+
+// The connections are tracked with leases, renewed by heartbeat while the
+// content is streamed. The lease of a crashed instance is not renewed, and
+// expires after leaseTTL.
+const (
+	leaseTTL          = 1 * time.Minute
+	heartbeatInterval = 20 * time.Second
+)
+
+var ErrLimitReached = errors.New("content proxy connection limit reached")
+
+type Connection struct {
+	Id         string    `json:"id"`
+	User       string    `json:"user"`
+	IP         string    `json:"ip"`
+	Link       string    `json:"link"`
+	InstanceId string    `json:"instance_id"`
+	StartedAt  time.Time `json:"started_at"`
+}
+
+type ConnectionFilter struct {
+	User string
+	IP   string
+	// matches if the link contains it
+	Link string
+}
+
+func (f ConnectionFilter) IsEmpty() bool {
+	return f.User == "" && f.IP == "" && f.Link == ""
+}
+
+func (f ConnectionFilter) match(conn *Connection) bool {
+	return (f.User == "" || conn.User == f.User) &&
+		(f.IP == "" || conn.IP == f.IP) &&
+		(f.Link == "" || strings.Contains(conn.Link, f.Link))
+}
+
+type ActiveConnection struct {
+	Connection
+	HeartbeatAt time.Time `json:"heartbeat_at"`
+}
+
+// leases scoped by user, to check the limit
+var leaseStore = kv.NewKVStore[Connection](&kv.KVStoreConfig{
+	Type:      "cproxylease",
+	ExpiresIn: leaseTTL,
+	Backend:   kv.KVStoreBackendRedis,
+})
+
+// every lease, to list and kill the connections
+var leaseIndexStore = kv.NewKVStore[Connection](&kv.KVStoreConfig{
+	Type:      "cproxyleaseidx",
+	ExpiresIn: leaseTTL,
+	Backend:   kv.KVStoreBackendRedis,
+})
+
+// killed connections, checked by the owner instance on heartbeat
+var killStore = kv.NewKVStore[bool](&kv.KVStoreConfig{
+	Type:      "cproxykill",
+	ExpiresIn: 2 * leaseTTL,
+	Backend:   kv.KVStoreBackendRedis,
+})
+
+// leases held by this instance, by connection id
+var localLeases sync.Map
+
+type Lease struct {
+	conn    Connection
+	cancel  context.CancelFunc
+	done    chan struct{}
+	stopped chan struct{} // closed when the heartbeat exits
+	once    sync.Once
+}
+
+func setLease(conn *Connection) error {
+	if err := leaseStore.WithScope(conn.User).Set(conn.Id, *conn); err != nil {
+		return err
+	}
+	return leaseIndexStore.Set(conn.Id, *conn)
+}
+
+func delLease(conn *Connection) error {
+	return errors.Join(
+		leaseStore.WithScope(conn.User).Del(conn.Id),
+		leaseIndexStore.Del(conn.Id),
+	)
+}
+
+// isAdmitted checks if the connection is within the limit, the older
+// connections are admitted first. Concurrent acquires on different instances
+// agree on the same order.
+func isAdmitted(conns []Connection, id string, limit int) bool {
+	slices.SortFunc(conns, func(a, b Connection) int {
+		if c := a.StartedAt.Compare(b.StartedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+	idx := slices.IndexFunc(conns, func(conn Connection) bool {
+		return conn.Id == id
+	})
+	return idx != -1 && idx < limit
+}
+
+func listConnections() ([]ActiveConnection, error) {
+	items, err := leaseIndexStore.List()
+	if err != nil {
+		return nil, err
+	}
+	conns := make([]ActiveConnection, len(items))
+	for i := range items {
+		conns[i] = ActiveConnection{
+			Connection:  items[i].Value,
+			HeartbeatAt: items[i].UpdatedAt,
+		}
+	}
+	return conns, nil
+}
+
+// Acquire records the connection, and returns ErrLimitReached if the user is
+// over the connection limit. The cancel func is called if the connection is
+// killed. The lease must be released when the connection is closed.
+func Acquire(id, user, ip, link string, cancel context.CancelFunc) (*Lease, error) {
+	conn := Connection{
+		Id:         id,
+		User:       user,
+		IP:         ip,
+		Link:       link,
+		InstanceId: config.InstanceId,
+		StartedAt:  time.Now(),
+	}
+	if err := setLease(&conn); err != nil {
+		delLease(&conn)
+		return nil, err
+	}
+
+	if limit := config.ContentProxyConnectionLimit.Get(user); limit > 0 {
+		items, err := leaseStore.WithScope(user).List()
+		if err != nil {
+			delLease(&conn)
+			return nil, err
+		}
+		// the scope is case-insensitive
+		userConns := []Connection{}
+		for i := range items {
+			if items[i].Value.User == user {
+				userConns = append(userConns, items[i].Value)
+			}
+		}
+		if !isAdmitted(userConns, id, limit) {
+			delLease(&conn)
+			return nil, ErrLimitReached
+		}
+	}
+
+	lease := &Lease{
+		conn:    conn,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	localLeases.Store(id, lease)
+	go lease.heartbeat()
+	return lease, nil
+}
+
+func (l *Lease) heartbeat() {
+	defer close(l.stopped)
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+			killed := false
+			if err := killStore.GetValue(l.conn.Id, &killed); err != nil {
+				log.Error("failed to check killed connection", "id", l.conn.Id, "error", err)
+			} else if killed {
+				log.Info("connection killed", "id", l.conn.Id, "user", l.conn.User)
+				l.cancel()
+				return
+			}
+			if err := setLease(&l.conn); err != nil {
+				log.Error("failed to renew lease", "id", l.conn.Id, "error", err)
+			}
+		}
+	}
+}
+
+// Release stops the heartbeat and removes the connection.
+func (l *Lease) Release() {
+	l.once.Do(func() {
+		close(l.done)
+		// wait for the renewal in progress, so that it does not restore the
+		// lease after it is removed
+		<-l.stopped
+		localLeases.Delete(l.conn.Id)
+		if err := delLease(&l.conn); err != nil {
+			log.Error("failed to release lease", "id", l.conn.Id, "error", err)
+		}
+	})
+}
+
+func List(filter ConnectionFilter) ([]ActiveConnection, error) {
+	conns, err := listConnections()
+	if err != nil {
+		return nil, err
+	}
+	conns = slices.DeleteFunc(conns, func(conn ActiveConnection) bool {
+		return !filter.match(&conn.Connection)
+	})
+	slices.SortFunc(conns, func(a, b ActiveConnection) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return conns, nil
+}
+
+func kill(conn *Connection) error {
+	if err := killStore.Set(conn.Id, true); err != nil {
+		return err
+	}
+	if err := delLease(conn); err != nil {
+		return err
+	}
+	// the connection on other instances are closed on next heartbeat
+	if lease, ok := localLeases.Load(conn.Id); ok {
+		lease.(*Lease).cancel()
+	}
+	return nil
+}
+
+// Kill closes the connection, returns false if it is not active.
+func Kill(id string) (bool, error) {
+	conn := Connection{}
+	if err := leaseIndexStore.GetValue(id, &conn); err != nil {
+		return false, err
+	}
+	if conn.Id == "" {
+		return false, nil
+	}
+	return true, kill(&conn)
+}
+
+// KillMatching closes the connections matching the filter, and returns them.
+func KillMatching(filter ConnectionFilter) ([]ActiveConnection, error) {
+	conns, err := List(filter)
+	if err != nil {
+		return nil, err
+	}
+	errs := []error{}
+	for i := range conns {
+		if err := kill(&conns[i].Connection); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return conns, errors.Join(errs...)
+}
@@ -0,0 +1,217 @@
+package content_proxy
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/db"
+	"github.com/MunifTanjim/stremthru/internal/kv"
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsAdmitted(t *testing.T) {
+	now := time.Now()
+	conns := func() []Connection {
+		return []Connection{
+			{Id: "c", StartedAt: now.Add(2 * time.Second)},
+			{Id: "b", StartedAt: now},
+			{Id: "a", StartedAt: now},
+		}
+	}
+
+	for _, tc := range []struct {
+		id       string
+		limit    int
+		expected bool
+	}{
+		{"a", 1, true},
+		{"b", 1, false},
+		{"b", 2, true},
+		{"c", 2, false},
+		{"c", 3, true},
+		{"d", 3, false},
+	} {
+		assert.Equal(t, tc.expected, isAdmitted(conns(), tc.id, tc.limit), "id=%s limit=%d", tc.id, tc.limit)
+	}
+}
+
+func TestConnectionFilter(t *testing.T) {
+	conn := &Connection{User: "alice", IP: "1.2.3.4", Link: "https://example.com/file/movie.mkv"}
+
+	for _, tc := range []struct {
+		filter   ConnectionFilter
+		expected bool
+	}{
+		{ConnectionFilter{}, true},
+		{ConnectionFilter{User: "alice"}, true},
+		{ConnectionFilter{User: "bob"}, false},
+		{ConnectionFilter{User: "alice", IP: "1.2.3.4"}, true},
+		{ConnectionFilter{User: "alice", IP: "4.3.2.1"}, false},
+		{ConnectionFilter{Link: "movie.mkv"}, true},
+		{ConnectionFilter{Link: "show.mkv"}, false},
+	} {
+		assert.Equal(t, tc.expected, tc.filter.match(conn), "%+v", tc.filter)
+	}
+}
+
+var setupTestDBOnce sync.Once
+
+func setupTestDB(t *testing.T) {
+	t.Helper()
+
+	if db.Dialect != db.DBDialectSQLite {
+		t.Skip("requires sqlite")
+	}
+	setupTestDBOnce.Do(func() {
+		db.Open()
+		for _, name := range []string{
+			"20250101000000_init.sql",
+			"20250708120053_add_col_eat_kv.sql",
+		} {
+			migration, err := os.ReadFile("../../migrations/sqlite/" + name)
+			if err != nil {
+				panic(err)
+			}
+			up, _, _ := strings.Cut(string(migration), "-- +goose Down")
+			if _, err := db.Exec(up); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+				panic(err)
+			}
+		}
+	})
+}
+
+// setupTestStores replaces the stores with ones unique to the test, the
+// leases expire after ttl.
+func setupTestStores(t *testing.T, ttl time.Duration) {
+	setupTestDB(t)
+
+	prefix := "test:" + xid.New().String() + ":"
+	prevLeaseStore, prevLeaseIndexStore, prevKillStore := leaseStore, leaseIndexStore, killStore
+	leaseStore = kv.NewKVStore[Connection](&kv.KVStoreConfig{
+		Type:      prefix + "cproxylease",
+		ExpiresIn: ttl,
+	})
+	leaseIndexStore = kv.NewKVStore[Connection](&kv.KVStoreConfig{
+		Type:      prefix + "cproxyleaseidx",
+		ExpiresIn: ttl,
+	})
+	killStore = kv.NewKVStore[bool](&kv.KVStoreConfig{
+		Type:      prefix + "cproxykill",
+		ExpiresIn: 2 * ttl,
+	})
+	t.Cleanup(func() {
+		leaseStore, leaseIndexStore, killStore = prevLeaseStore, prevLeaseIndexStore, prevKillStore
+		db.Exec("DELETE FROM "+kv.TableName+" WHERE t LIKE ?", prefix+"%")
+	})
+}
+
+func setTestConnectionLimit(t *testing.T, user string, limit int) {
+	config.ContentProxyConnectionLimit[user] = limit
+	t.Cleanup(func() {
+		delete(config.ContentProxyConnectionLimit, user)
+	})
+}
+
+func listTestConnectionIds(t *testing.T, filter ConnectionFilter) []string {
+	t.Helper()
+
+	conns, err := List(filter)
+	require.NoError(t, err)
+	ids := make([]string, len(conns))
+	for i := range conns {
+		ids[i] = conns[i].Id
+	}
+	return ids
+}
+
+func TestAcquire(t *testing.T) {
+	setupTestStores(t, leaseTTL)
+	setTestConnectionLimit(t, "alice", 2)
+
+	leases := []*Lease{}
+	for _, id := range []string{"a1", "a2"} {
+		lease, err := Acquire(id, "alice", "1.2.3.4", "https://example.com/"+id, func() {})
+		require.NoError(t, err)
+		leases = append(leases, lease)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	_, err := Acquire("a3", "alice", "1.2.3.4", "https://example.com/a3", func() {})
+	assert.ErrorIs(t, err, ErrLimitReached)
+
+	// the limit is per user
+	lease, err := Acquire("b1", "bob", "4.3.2.1", "https://example.com/b1", func() {})
+	require.NoError(t, err)
+	defer lease.Release()
+
+	assert.Equal(t, []string{"a1", "a2", "b1"}, listTestConnectionIds(t, ConnectionFilter{}))
+	assert.Equal(t, []string{"a1", "a2"}, listTestConnectionIds(t, ConnectionFilter{User: "alice"}))
+
+	leases[0].Release()
+	leases[0].Release()
+	assert.Equal(t, []string{"a2"}, listTestConnectionIds(t, ConnectionFilter{User: "alice"}))
+
+	lease, err = Acquire("a3", "alice", "1.2.3.4", "https://example.com/a3", func() {})
+	require.NoError(t, err)
+	defer lease.Release()
+	leases[1].Release()
+}
+
+func TestAcquireAfterMissedHeartbeat(t *testing.T) {
+	setupTestStores(t, 1*time.Second)
+	setTestConnectionLimit(t, "alice", 1)
+
+	lease, err := Acquire("a1", "alice", "1.2.3.4", "https://example.com/a1", func() {})
+	require.NoError(t, err)
+
+	// crashed, the lease is not renewed nor released
+	close(lease.done)
+	<-lease.stopped
+	localLeases.Delete(lease.conn.Id)
+
+	_, err = Acquire("a2", "alice", "1.2.3.4", "https://example.com/a2", func() {})
+	assert.ErrorIs(t, err, ErrLimitReached)
+
+	time.Sleep(2 * time.Second)
+	assert.Empty(t, listTestConnectionIds(t, ConnectionFilter{}))
+
+	lease, err = Acquire("a2", "alice", "1.2.3.4", "https://example.com/a2", func() {})
+	require.NoError(t, err)
+	lease.Release()
+}
+
+func TestKill(t *testing.T) {
+	setupTestStores(t, leaseTTL)
+	setTestConnectionLimit(t, "alice", 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lease, err := Acquire("a1", "alice", "1.2.3.4", "https://example.com/a1", cancel)
+	require.NoError(t, err)
+	defer lease.Release()
+
+	found, err := Kill("a1")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Error(t, ctx.Err(), "local connection is cancelled")
+	assert.Empty(t, listTestConnectionIds(t, ConnectionFilter{}))
+
+	killed := false
+	require.NoError(t, killStore.GetValue("a1", &killed))
+	assert.True(t, killed, "remote connection is cancelled on heartbeat")
+
+	found, err = Kill("a1")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// the slot is freed
+	lease, err = Acquire("a2", "alice", "1.2.3.4", "https://example.com/a2", func() {})
+	require.NoError(t, err)
+	lease.Release()
+}
@@ -0,0 +1,30 @@
+package endpoint
+
+import (
+	"net/http"
+
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/server"
+	"github.com/MunifTanjim/stremthru/internal/shared"
+)
+
+func handleConfigReload(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	result, err := config.Reload()
+	if err != nil {
+		shared.ErrorBadRequest(r, err.Error()).Send(w, r)
+		return
+	}
+	server.GetReqCtx(r).Log.Info("reloaded config", "applied", result.Applied, "restart_required", result.RestartRequired)
+	SendResponse(w, r, 200, result, nil)
+}
+
+func AddConfigEndpoints(mux *http.ServeMux) {
+	withAdminAuth := shared.Middleware(AdminAuthed)
+
+	mux.HandleFunc("/v0/admin/config/reload", withAdminAuth(handleConfigReload))
+}
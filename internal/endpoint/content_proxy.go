@@ -0,0 +1,72 @@
+package endpoint
+
+import (
+	"net/http"
+
+	"github.com/MunifTanjim/stremthru/internal/content_proxy"
+	"github.com/MunifTanjim/stremthru/internal/server"
+	"github.com/MunifTanjim/stremthru/internal/shared"
+)
+
+type ListContentProxyConnectionsData struct {
+	Items []content_proxy.ActiveConnection `json:"items"`
+}
+
+func getContentProxyConnectionFilter(r *http.Request) content_proxy.ConnectionFilter {
+	query := r.URL.Query()
+	return content_proxy.ConnectionFilter{
+		User: query.Get("user"),
+		IP:   query.Get("ip"),
+		Link: query.Get("link"),
+	}
+}
+
+func handleContentProxyConnections(w http.ResponseWriter, r *http.Request) {
+	filter := getContentProxyConnectionFilter(r)
+
+	if shared.IsMethod(r, http.MethodGet) {
+		conns, err := content_proxy.List(filter)
+		SendResponse(w, r, 200, &ListContentProxyConnectionsData{Items: conns}, err)
+		return
+	}
+
+	if shared.IsMethod(r, http.MethodDelete) {
+		if filter.IsEmpty() {
+			shared.ErrorBadRequest(r, "missing filter: user, ip or link").Send(w, r)
+			return
+		}
+		conns, err := content_proxy.KillMatching(filter)
+		if err == nil {
+			server.GetReqCtx(r).Log.Info("killed content proxy connections", "count", len(conns))
+		}
+		SendResponse(w, r, 200, &ListContentProxyConnectionsData{Items: conns}, err)
+		return
+	}
+
+	shared.ErrorMethodNotAllowed(r).Send(w, r)
+}
+
+func handleContentProxyConnection(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodDelete) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	found, err := content_proxy.Kill(r.PathValue("id"))
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	if !found {
+		shared.ErrorNotFound(r).Send(w, r)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func AddContentProxyEndpoints(mux *http.ServeMux) {
+	withAdminAuth := shared.Middleware(AdminAuthed)
+
+	mux.HandleFunc("/v0/admin/proxy/connections", withAdminAuth(handleContentProxyConnections))
+	mux.HandleFunc("/v0/admin/proxy/connections/{id}", withAdminAuth(handleContentProxyConnection))
+}
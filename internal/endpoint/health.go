@@ -97,7 +97,7 @@ func handleHealthDebug(w http.ResponseWriter, r *http.Request) {
 			reqCtx.Log.Warn("Failed to get tunnel ip map", "error", ipMapErr)
 		}
 
-		for storeName := range config.StoreTunnel {
+		for _, storeName := range config.StoreTunnel.ListStores() {
 			switch config.StoreTunnel.GetTypeForAPI(storeName) {
 			case config.TUNNEL_TYPE_FORCED:
 				exposed[":"+storeName+":api:"] = exposed["*"]
@@ -1,6 +1,7 @@
 package endpoint
 
 import (
+	"crypto/subtle"
 	"net/http"
 	"strings"
 
@@ -134,14 +135,26 @@ func StoreRequired(next http.HandlerFunc) http.HandlerFunc {
 	})
 }
 
+func isAdminAuthorized(r *http.Request) bool {
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Basic "))
+	if token == "" {
+		return false
+	}
+	auth, err := core.ParseBasicAuth(token)
+	if err != nil || auth.Username == "" {
+		return false
+	}
+	password := config.AdminPassword.GetPassword(auth.Username)
+	if password == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(password), []byte(auth.Password)) == 1
+}
+
 func AdminAuthed(next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Basic "))
-		if token == "" {
-			shared.ErrorUnauthorized(r).Send(w, r)
-			return
-		}
-		if auth, err := core.ParseBasicAuth(token); err != nil || config.AdminPassword.GetPassword(auth.Username) != auth.Password {
+		if !isAdminAuthorized(r) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
 			shared.ErrorUnauthorized(r).Send(w, r)
 			return
 		}
@@ -0,0 +1,27 @@
+package endpoint
+
+import (
+	"net/http"
+
+	"github.com/MunifTanjim/stremthru/internal/peer"
+	"github.com/MunifTanjim/stremthru/internal/shared"
+)
+
+type ListPeersData struct {
+	Items []peer.PeerStatus `json:"items"`
+}
+
+func handlePeers(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	SendResponse(w, r, 200, &ListPeersData{Items: peer.Upstreams.Status()}, nil)
+}
+
+func AddPeerEndpoints(mux *http.ServeMux) {
+	withAdminAuth := shared.Middleware(AdminAuthed)
+
+	mux.HandleFunc("/v0/admin/peers", withAdminAuth(handlePeers))
+}
@@ -0,0 +1,203 @@
+package endpoint
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/peer_token"
+	"github.com/MunifTanjim/stremthru/internal/server"
+	"github.com/MunifTanjim/stremthru/internal/shared"
+)
+
+// usePeerToken records the request made with the peer token, and sends the
+// error if the request can not proceed, i.e. the quota is exceeded. An
+// invalid token is rejected only if it is required, otherwise the request
+// proceeds without the peer token. isValid is true only if the usage is
+// recorded.
+func usePeerToken(w http.ResponseWriter, r *http.Request, token string, required bool, torrentsPushed int) (isValid bool, ok bool) {
+	err := peer_token.Use(token, r.Header.Get(server.HEADER_INSTANCE_ID), torrentsPushed)
+	switch {
+	case err == nil:
+		return true, true
+	case errors.Is(err, peer_token.ErrInvalidToken):
+		if !required {
+			return false, true
+		}
+		shared.ErrorUnauthorized(r).Send(w, r)
+	case errors.Is(err, peer_token.ErrQuotaExceeded):
+		shared.ErrorTooManyRequests(r, err.Error()).Send(w, r)
+	default:
+		server.GetReqCtx(r).Log.Error("failed to record peer token usage", "error", err)
+		SendError(w, r, err)
+	}
+	return false, false
+}
+
+type PeerTokenUsageData struct {
+	Requests       int       `json:"requests"`
+	TorrentsPushed int       `json:"torrents_pushed"`
+	WindowAt       time.Time `json:"window_at"`
+	WindowRequests int       `json:"window_requests"`
+	InstanceId     string    `json:"instance_id"`
+	LastSeenAt     time.Time `json:"last_seen_at"`
+}
+
+type PeerTokenData struct {
+	Id        string              `json:"id"`
+	Name      string              `json:"name"`
+	Quota     int                 `json:"quota"`
+	CreatedAt time.Time           `json:"created_at"`
+	Usage     *PeerTokenUsageData `json:"usage"`
+}
+
+func toPeerTokenData(t *peer_token.PeerToken, u *peer_token.Usage) *PeerTokenData {
+	data := &PeerTokenData{
+		Id:        t.Id,
+		Name:      t.Name,
+		Quota:     t.Quota,
+		CreatedAt: t.CreatedAt.Time,
+	}
+	if u != nil {
+		data.Usage = &PeerTokenUsageData{
+			Requests:       u.Requests,
+			TorrentsPushed: u.TorrentsPushed,
+			WindowAt:       u.WindowAt.Time,
+			WindowRequests: u.WindowRequests,
+			InstanceId:     u.InstanceId,
+			LastSeenAt:     u.LastSeenAt.Time,
+		}
+	}
+	return data
+}
+
+type ListPeerTokensData struct {
+	Items []PeerTokenData `json:"items"`
+}
+
+type CreatePeerTokenPayload struct {
+	Name  string `json:"name"`
+	Quota int    `json:"quota"`
+}
+
+func handlePeerTokens(w http.ResponseWriter, r *http.Request) {
+	if shared.IsMethod(r, http.MethodGet) {
+		tokens, err := peer_token.GetAll()
+		if err != nil {
+			SendError(w, r, err)
+			return
+		}
+		usageById, err := peer_token.GetAllUsage()
+		if err != nil {
+			SendError(w, r, err)
+			return
+		}
+		data := &ListPeerTokensData{Items: make([]PeerTokenData, len(tokens))}
+		for i := range tokens {
+			t := &tokens[i]
+			var usage *peer_token.Usage
+			if u, ok := usageById[t.Id]; ok {
+				usage = &u
+			}
+			data.Items[i] = *toPeerTokenData(t, usage)
+		}
+		SendResponse(w, r, 200, data, nil)
+		return
+	}
+
+	if shared.IsMethod(r, http.MethodPost) {
+		payload := &CreatePeerTokenPayload{}
+		if err := shared.ReadRequestBodyJSON(r, payload); err != nil {
+			SendError(w, r, err)
+			return
+		}
+		if payload.Name == "" {
+			shared.ErrorBadRequest(r, "missing name").Send(w, r)
+			return
+		}
+		if payload.Quota < 0 {
+			shared.ErrorBadRequest(r, "invalid quota").Send(w, r)
+			return
+		}
+		t, err := peer_token.Create(payload.Name, payload.Quota)
+		if err != nil {
+			SendError(w, r, err)
+			return
+		}
+		SendResponse(w, r, 201, toPeerTokenData(t, nil), nil)
+		return
+	}
+
+	shared.ErrorMethodNotAllowed(r).Send(w, r)
+}
+
+type UpdatePeerTokenPayload struct {
+	Name  *string `json:"name"`
+	Quota *int    `json:"quota"`
+}
+
+func handlePeerToken(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) && !shared.IsMethod(r, http.MethodPatch) && !shared.IsMethod(r, http.MethodDelete) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	t, err := peer_token.GetById(r.PathValue("id"))
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	if t == nil {
+		shared.ErrorNotFound(r).Send(w, r)
+		return
+	}
+
+	if shared.IsMethod(r, http.MethodDelete) {
+		if err := peer_token.Revoke(t.Id); err != nil {
+			SendError(w, r, err)
+			return
+		}
+		w.WriteHeader(204)
+		return
+	}
+
+	if shared.IsMethod(r, http.MethodPatch) {
+		payload := &UpdatePeerTokenPayload{}
+		if err := shared.ReadRequestBodyJSON(r, payload); err != nil {
+			SendError(w, r, err)
+			return
+		}
+		if payload.Name != nil {
+			if *payload.Name == "" {
+				shared.ErrorBadRequest(r, "missing name").Send(w, r)
+				return
+			}
+			t.Name = *payload.Name
+		}
+		if payload.Quota != nil {
+			if *payload.Quota < 0 {
+				shared.ErrorBadRequest(r, "invalid quota").Send(w, r)
+				return
+			}
+			t.Quota = *payload.Quota
+		}
+		if err := peer_token.Update(t); err != nil {
+			SendError(w, r, err)
+			return
+		}
+	}
+
+	usage, err := peer_token.GetUsage(t.Id)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	SendResponse(w, r, 200, toPeerTokenData(t, usage), nil)
+}
+
+func AddPeerTokenEndpoints(mux *http.ServeMux) {
+	withAdminAuth := shared.Middleware(AdminAuthed)
+
+	mux.HandleFunc("/v0/admin/peer-tokens", withAdminAuth(handlePeerTokens))
+	mux.HandleFunc("/v0/admin/peer-tokens/{id}", withAdminAuth(handlePeerToken))
+}
@@ -1,6 +1,8 @@
 package endpoint
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -8,6 +10,7 @@ import (
 
 	"github.com/MunifTanjim/stremthru/core"
 	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/content_proxy"
 	"github.com/MunifTanjim/stremthru/internal/server"
 	"github.com/MunifTanjim/stremthru/internal/shared"
 	store_video "github.com/MunifTanjim/stremthru/internal/store/video"
@@ -43,22 +46,18 @@ func handleProxyLinkAccess(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if isGetReq && user != "" {
-		cpStore := contentProxyConnectionStore.WithScope(user)
-
-		if limit := config.ContentProxyConnectionLimit.Get(user); limit > 0 {
-			activeConnectionCount, err := cpStore.Count()
-			if err != nil {
-				ctx.Log.Error("[proxy] failed to count connections", "error", err)
-			} else if activeConnectionCount >= limit {
-				store_video.Redirect(store_video.StoreVideoNameContentProxyLimitReached, w, r)
-				return
-			}
-		}
+		proxyCtx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		r = r.WithContext(proxyCtx)
 
-		if err := cpStore.Set(ctx.RequestId, contentProxyConnection{IP: core.GetRequestIP(r), Link: link}); err != nil {
-			ctx.Log.Error("[proxy] failed to record connection", "error", err)
+		lease, err := content_proxy.Acquire(ctx.RequestId, user, core.GetRequestIP(r), link, cancel)
+		if errors.Is(err, content_proxy.ErrLimitReached) {
+			store_video.Redirect(store_video.StoreVideoNameContentProxyLimitReached, w, r)
+			return
+		} else if err != nil {
+			ctx.Log.Error("[proxy] failed to acquire connection lease", "error", err)
 		} else {
-			defer cpStore.Del(ctx.RequestId)
+			defer lease.Release()
 		}
 	}
 	bytesWritten, err := shared.ProxyResponse(w, r, link, tunnelType)
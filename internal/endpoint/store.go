@@ -6,11 +6,13 @@ import (
 	"strings"
 
 	"github.com/MunifTanjim/stremthru/internal/buddy"
+	"github.com/MunifTanjim/stremthru/internal/config"
 	"github.com/MunifTanjim/stremthru/internal/context"
-	"github.com/MunifTanjim/stremthru/internal/kv"
-	"github.com/MunifTanjim/stremthru/internal/peer_token"
 	"github.com/MunifTanjim/stremthru/internal/server"
 	"github.com/MunifTanjim/stremthru/internal/shared"
+	store_file "github.com/MunifTanjim/stremthru/internal/store/file"
+	store_housekeeping "github.com/MunifTanjim/stremthru/internal/store/housekeeping"
+	store_migration "github.com/MunifTanjim/stremthru/internal/store/migration"
 	store_util "github.com/MunifTanjim/stremthru/internal/store/util"
 	store_video "github.com/MunifTanjim/stremthru/internal/store/video"
 	"github.com/MunifTanjim/stremthru/internal/torrent_info"
@@ -35,10 +37,11 @@ func handleStoreUser(w http.ResponseWriter, r *http.Request) {
 }
 
 type AddMagnetPayload struct {
-	Magnet string `json:"magnet"`
+	Magnet string                     `json:"magnet"`
+	Files  *store.MagnetFileSelection `json:"files,omitempty"`
 }
 
-func checkMagnet(ctx *context.StoreContext, magnets []string, sid string, localOnly bool) (*store.CheckMagnetData, error) {
+func checkMagnet(ctx *context.StoreContext, magnets []string, sid string, localOnly bool, isTrustedRequest bool) (*store.CheckMagnetData, error) {
 	params := &store.CheckMagnetParams{}
 	params.APIKey = ctx.StoreAuthToken
 	params.Magnets = magnets
@@ -47,7 +50,7 @@ func checkMagnet(ctx *context.StoreContext, magnets []string, sid string, localO
 	if ctx.ClientIP != "" {
 		params.ClientIP = ctx.ClientIP
 	}
-	params.IsTrustedRequest, _ = peer_token.IsValid(ctx.PeerToken)
+	params.IsTrustedRequest = isTrustedRequest
 	data, err := ctx.Store.CheckMagnet(params)
 	if err == nil && data.Items == nil {
 		data.Items = []store.CheckMagnetDataItem{}
@@ -72,16 +75,7 @@ func hadleStoreMagnetsTrack(w http.ResponseWriter, r *http.Request) {
 
 	ctx := context.GetStoreContext(r)
 
-	log := server.GetReqCtx(r).Log
-
-	isValidToken, err := peer_token.IsValid(ctx.PeerToken)
-	if err != nil {
-		log.Error("failed to validate peer token", "error", err)
-		SendError(w, r, err)
-		return
-	}
-	if !isValidToken {
-		shared.ErrorUnauthorized(r).Send(w, r)
+	if _, ok := usePeerToken(w, r, ctx.PeerToken, true, 0); !ok {
 		return
 	}
 
@@ -137,7 +131,15 @@ func handleStoreMagnetsCheck(w http.ResponseWriter, r *http.Request) {
 	sid := queryParams.Get("sid")
 
 	ctx := context.GetStoreContext(r)
-	data, err := checkMagnet(ctx, magnets, sid, queryParams.Get("local_only") != "")
+	isTrustedRequest := false
+	if ctx.PeerToken != "" {
+		isValid, ok := usePeerToken(w, r, ctx.PeerToken, false, 0)
+		if !ok {
+			return
+		}
+		isTrustedRequest = isValid
+	}
+	data, err := checkMagnet(ctx, magnets, sid, queryParams.Get("local_only") != "", isTrustedRequest)
 	if err == nil && data != nil {
 		for _, item := range data.Items {
 			item.Hash = strings.ToLower(item.Hash)
@@ -146,6 +148,15 @@ func handleStoreMagnetsCheck(w http.ResponseWriter, r *http.Request) {
 	SendResponse(w, r, 200, data, err)
 }
 
+func isValidMagnetStatus(status store.MagnetStatus) bool {
+	switch status {
+	case store.MagnetStatusCached, store.MagnetStatusQueued, store.MagnetStatusDownloading, store.MagnetStatusProcessing, store.MagnetStatusDownloaded, store.MagnetStatusUploading, store.MagnetStatusFailed, store.MagnetStatusInvalid, store.MagnetStatusUnknown:
+		return true
+	default:
+		return false
+	}
+}
+
 func listMagnets(ctx *context.StoreContext, r *http.Request) (*store.ListMagnetsData, error) {
 	queryParams := r.URL.Query()
 	limit, err := GetQueryInt(queryParams, "limit", 100)
@@ -164,9 +175,32 @@ func listMagnets(ctx *context.StoreContext, r *http.Request) (*store.ListMagnets
 		Limit:    limit,
 		Offset:   offset,
 		ClientIP: ctx.ClientIP,
+		Query:    strings.TrimSpace(queryParams.Get("q")),
+	}
+	if status := queryParams.Get("status"); status != "" {
+		for _, s := range strings.Split(status, ",") {
+			magnetStatus := store.MagnetStatus(strings.TrimSpace(s))
+			if !isValidMagnetStatus(magnetStatus) {
+				return nil, shared.ErrorBadRequest(r, "invalid status: "+s)
+			}
+			params.Status = append(params.Status, magnetStatus)
+		}
+	}
+	if params.AddedAfter, err = GetQueryTime(queryParams, "added_after"); err != nil {
+		return nil, shared.ErrorBadRequest(r, err.Error())
+	}
+	if params.AddedBefore, err = GetQueryTime(queryParams, "added_before"); err != nil {
+		return nil, shared.ErrorBadRequest(r, err.Error())
+	}
+	if sort := queryParams.Get("sort"); sort != "" {
+		params.SortDesc = strings.HasPrefix(sort, "-")
+		params.SortBy = store.ListMagnetsSortBy(strings.TrimPrefix(sort, "-"))
+		if !params.SortBy.IsValid() {
+			return nil, shared.ErrorBadRequest(r, "invalid sort: "+sort)
+		}
 	}
 	params.APIKey = ctx.StoreAuthToken
-	data, err := ctx.Store.ListMagnets(params)
+	data, err := shared.ListMagnets(ctx.Store, params)
 
 	if err == nil {
 		if data.Items == nil {
@@ -194,14 +228,21 @@ func handleStoreMagnetsList(w http.ResponseWriter, r *http.Request) {
 	SendResponse(w, r, 200, data, err)
 }
 
-func addMagnet(ctx *context.StoreContext, magnet string) (*store.AddMagnetData, error) {
+func addMagnet(ctx *context.StoreContext, magnet string, files *store.MagnetFileSelection) (*store.AddMagnetData, error) {
+	if files != nil {
+		if fs, ok := ctx.Store.(store.MagnetFileSelector); !ok || !fs.CanSelectMagnetFiles() {
+			return nil, store.ErrorFileSelectionNotSupported(string(ctx.Store.GetName()))
+		}
+	}
+
 	params := &store.AddMagnetParams{}
 	params.APIKey = ctx.StoreAuthToken
 	params.Magnet = magnet
+	params.Files = files
 	if ctx.ClientIP != "" {
 		params.ClientIP = ctx.ClientIP
 	}
-	data, err := ctx.Store.AddMagnet(params)
+	data, err := shared.AddMagnet(ctx.Store, params)
 	if err == nil {
 		buddy.TrackMagnet(ctx.Store, data.Hash, data.Name, data.Size, data.Files, "", data.Status != store.MagnetStatusDownloaded, ctx.StoreAuthToken)
 	}
@@ -221,8 +262,15 @@ func handleStoreMagnetAdd(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if payload.Files != nil {
+		if err := payload.Files.Validate(); err != nil {
+			shared.ErrorBadRequest(r, err.Error()).Send(w, r)
+			return
+		}
+	}
+
 	ctx := context.GetStoreContext(r)
-	data, err := addMagnet(ctx, payload.Magnet)
+	data, err := addMagnet(ctx, payload.Magnet, payload.Files)
 	if err == nil && data != nil {
 		data.Hash = strings.ToLower(data.Hash)
 	}
@@ -281,7 +329,7 @@ func removeMagnet(ctx *context.StoreContext, magnetId string) (*store.RemoveMagn
 	params := &store.RemoveMagnetParams{}
 	params.APIKey = ctx.StoreAuthToken
 	params.Id = magnetId
-	return ctx.Store.RemoveMagnet(params)
+	return shared.RemoveMagnet(ctx.Store, params)
 }
 
 func handleStoreMagnetRemove(w http.ResponseWriter, r *http.Request) {
@@ -337,14 +385,194 @@ func handleStoreLinkGenerate(w http.ResponseWriter, r *http.Request) {
 	SendResponse(w, r, 200, link, err)
 }
 
-type contentProxyConnection struct {
-	IP   string `json:"ip"`
-	Link string `json:"link"`
+type UnrestrictLinkPayload struct {
+	Link     string `json:"link"`
+	Password string `json:"password,omitempty"`
+}
+
+func handleStoreLinkUnrestrict(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	payload := &UnrestrictLinkPayload{}
+	err := shared.ReadRequestBodyJSON(r, payload)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	if payload.Link == "" {
+		shared.ErrorBadRequest(r, "missing link").Send(w, r)
+		return
+	}
+
+	ctx := context.GetStoreContext(r)
+	link, err := shared.UnrestrictStremThruLink(r, ctx, payload.Link, payload.Password)
+	SendResponse(w, r, 200, link, err)
+}
+
+func handleStoreLinkHosts(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	ctx := context.GetStoreContext(r)
+	hosts, err := shared.ListStoreHosts(ctx.Store, ctx.StoreAuthToken)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	SendResponse(w, r, 200, &store.ListHostsData{Items: hosts}, nil)
+}
+
+type StoreHousekeepingLogsData struct {
+	Items []store_housekeeping.Log `json:"items"`
+}
+
+func handleStoreHousekeepingLogs(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	ctx := context.GetStoreContext(r)
+	if !ctx.IsProxyAuthorized {
+		shared.ErrorUnauthorized(r).Send(w, r)
+		return
+	}
+
+	limit, err := GetQueryInt(r.URL.Query(), "limit", 100)
+	if err != nil {
+		shared.ErrorBadRequest(r, err.Error()).Send(w, r)
+		return
+	}
+	limit = min(max(limit, 1), 500)
+
+	logs, err := store_housekeeping.GetLogs(ctx.ProxyAuthUser, string(ctx.Store.GetName()), limit)
+	SendResponse(w, r, 200, &StoreHousekeepingLogsData{Items: logs}, err)
+}
+
+type CreateStoreMigrationPayload struct {
+	DestinationStore string `json:"destination_store"`
+}
+
+type StoreMigrationsData struct {
+	Items []store_migration.Job `json:"items"`
+}
+
+type StoreMigrationData struct {
+	store_migration.Job
+	FailedItems []store_migration.Item `json:"failed_items"`
 }
 
-var contentProxyConnectionStore = kv.NewKVStore[contentProxyConnection](&kv.KVStoreConfig{
-	Type: "cproxyconn",
-})
+func handleStoreMigrationsCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := context.GetStoreContext(r)
+
+	payload := &CreateStoreMigrationPayload{}
+	if err := shared.ReadRequestBodyJSON(r, payload); err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	srcStore := string(ctx.Store.GetName())
+	dstStore := payload.DestinationStore
+	if !store.StoreName(dstStore).IsValid() {
+		shared.ErrorBadRequest(r, "invalid destination_store").Send(w, r)
+		return
+	}
+	if dstStore == srcStore {
+		shared.ErrorBadRequest(r, "destination_store must be different from source store").Send(w, r)
+		return
+	}
+	if config.StoreAuthToken.GetToken(ctx.ProxyAuthUser, srcStore) == "" {
+		shared.ErrorBadRequest(r, "missing configured token for source store").Send(w, r)
+		return
+	}
+	if config.StoreAuthToken.GetToken(ctx.ProxyAuthUser, dstStore) == "" {
+		shared.ErrorBadRequest(r, "missing configured token for destination_store").Send(w, r)
+		return
+	}
+
+	hasActiveJob, err := store_migration.HasActiveJob(ctx.ProxyAuthUser, srcStore, dstStore)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	if hasActiveJob {
+		shared.ErrorBadRequest(r, "migration already in progress").Send(w, r)
+		return
+	}
+
+	job, err := store_migration.CreateJob(ctx.ProxyAuthUser, srcStore, dstStore)
+	SendResponse(w, r, 201, job, err)
+}
+
+func handleStoreMigrationsList(w http.ResponseWriter, r *http.Request) {
+	ctx := context.GetStoreContext(r)
+
+	limit, err := GetQueryInt(r.URL.Query(), "limit", 20)
+	if err != nil {
+		shared.ErrorBadRequest(r, err.Error()).Send(w, r)
+		return
+	}
+	limit = min(max(limit, 1), 100)
+
+	jobs, err := store_migration.GetJobsByUser(ctx.ProxyAuthUser, limit)
+	SendResponse(w, r, 200, &StoreMigrationsData{Items: jobs}, err)
+}
+
+func handleStoreMigrations(w http.ResponseWriter, r *http.Request) {
+	ctx := context.GetStoreContext(r)
+	if config.IsPublicInstance {
+		shared.ErrorForbidden(r).Send(w, r)
+		return
+	}
+	if !ctx.IsProxyAuthorized {
+		shared.ErrorUnauthorized(r).Send(w, r)
+		return
+	}
+
+	if shared.IsMethod(r, http.MethodGet) {
+		handleStoreMigrationsList(w, r)
+		return
+	}
+
+	if shared.IsMethod(r, http.MethodPost) {
+		handleStoreMigrationsCreate(w, r)
+		return
+	}
+
+	shared.ErrorMethodNotAllowed(r).Send(w, r)
+}
+
+func handleStoreMigration(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	ctx := context.GetStoreContext(r)
+	if !ctx.IsProxyAuthorized {
+		shared.ErrorUnauthorized(r).Send(w, r)
+		return
+	}
+
+	job, err := store_migration.GetJob(r.PathValue("jobId"))
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	if job == nil || job.User != ctx.ProxyAuthUser {
+		shared.ErrorNotFound(r).Send(w, r)
+		return
+	}
+
+	failedItems, err := store_migration.GetItems(job.Id, store_migration.ItemStatusFailed)
+	SendResponse(w, r, 200, &StoreMigrationData{Job: *job, FailedItems: failedItems}, err)
+}
 
 func handleStatic(w http.ResponseWriter, r *http.Request) {
 	if !shared.IsMethod(r, http.MethodGet) && !shared.IsMethod(r, http.MethodHead) {
@@ -359,6 +587,20 @@ func handleStatic(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func handleStoreFile(w http.ResponseWriter, r *http.Request) {
+	ctx := server.GetReqCtx(r)
+	ctx.RedactURLPathValues(r, "token")
+
+	if !shared.IsMethod(r, http.MethodGet) && !shared.IsMethod(r, http.MethodHead) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	if err := store_file.Serve(r.PathValue("token"), w, r); err != nil {
+		SendError(w, r, err)
+	}
+}
+
 func AddStoreEndpoints(mux *http.ServeMux) {
 	withCors := shared.Middleware(shared.EnableCORS)
 	withStore := StoreMiddleware(ProxyAuthContext, StoreContext, StoreRequired)
@@ -368,6 +610,12 @@ func AddStoreEndpoints(mux *http.ServeMux) {
 	mux.HandleFunc("/v0/store/magnets/check", withStore(handleStoreMagnetsCheck))
 	mux.HandleFunc("/v0/store/magnets/{magnetId}", withStore(handleStoreMagnet))
 	mux.HandleFunc("/v0/store/link/generate", withStore(handleStoreLinkGenerate))
+	mux.HandleFunc("/v0/store/link/unrestrict", withStore(handleStoreLinkUnrestrict))
+	mux.HandleFunc("/v0/store/link/hosts", withStore(handleStoreLinkHosts))
+	mux.HandleFunc("/v0/store/housekeeping/logs", withStore(handleStoreHousekeepingLogs))
+	mux.HandleFunc("/v0/store/migrations", withStore(handleStoreMigrations))
+	mux.HandleFunc("/v0/store/migrations/{jobId}", withStore(handleStoreMigration))
 
 	mux.HandleFunc("/v0/store/_/static/{video}", withCors(handleStatic))
+	mux.HandleFunc("/v0/store/_/file/{token}/{filename}", withCors(handleStoreFile))
 }
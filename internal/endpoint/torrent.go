@@ -8,7 +8,6 @@ import (
 
 	"github.com/MunifTanjim/stremthru/internal/buddy"
 	"github.com/MunifTanjim/stremthru/internal/config"
-	"github.com/MunifTanjim/stremthru/internal/peer_token"
 	"github.com/MunifTanjim/stremthru/internal/server"
 	"github.com/MunifTanjim/stremthru/internal/shared"
 	"github.com/MunifTanjim/stremthru/internal/torrent_info"
@@ -21,12 +20,7 @@ type RecordTorrentsPayload struct {
 
 func handleRecordTorrents(w http.ResponseWriter, r *http.Request) {
 	peerToken := r.Header.Get("X-StremThru-Peer-Token")
-	isValidToken, err := peer_token.IsValid(peerToken)
-	if err != nil {
-		SendError(w, r, err)
-		return
-	}
-	if !isValidToken {
+	if peerToken == "" {
 		shared.ErrorUnauthorized(r).Send(w, r)
 		return
 	}
@@ -37,6 +31,10 @@ func handleRecordTorrents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, ok := usePeerToken(w, r, peerToken, true, len(payload.Items)); !ok {
+		return
+	}
+
 	go torrent_info.Upsert(payload.Items, "", false)
 	w.WriteHeader(204)
 }
@@ -53,6 +51,12 @@ func handleListTorrents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if peerToken := r.Header.Get("X-StremThru-Peer-Token"); peerToken != "" {
+		if _, ok := usePeerToken(w, r, peerToken, false, 0); !ok {
+			return
+		}
+	}
+
 	originInstanceId := r.Header.Get(server.HEADER_ORIGIN_INSTANCE_ID)
 	if originInstanceId == "" {
 		w.Header().Set(server.HEADER_ORIGIN_INSTANCE_ID, originInstanceId)
@@ -60,9 +64,14 @@ func handleListTorrents(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set(server.HEADER_ORIGIN_INSTANCE_ID, config.InstanceId)
 	}
 
+	via := []string{}
+	if v := r.Header.Get(server.HEADER_PEER_VIA); v != "" {
+		via = strings.Split(v, ",")
+	}
+
 	localOnly := query.Get("local_only") != ""
 	noMissingSize := query.Get("no_missing_size") != ""
-	data, err := buddy.ListTorrentsByStremId(sid, localOnly, originInstanceId, noMissingSize)
+	data, err := buddy.ListTorrentsByStremId(sid, localOnly, originInstanceId, via, noMissingSize)
 
 	w.Header().Set("Cache-Control", "public, max-age=7200")
 	SendResponse(w, r, 200, data, err)
@@ -0,0 +1,92 @@
+package endpoint
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/server"
+	"github.com/MunifTanjim/stremthru/internal/shared"
+	"github.com/MunifTanjim/stremthru/internal/torrent_dump"
+	ti "github.com/MunifTanjim/stremthru/internal/torrent_info"
+)
+
+func getTorrentDumpFormat(r *http.Request) (torrent_dump.Format, bool) {
+	format := torrent_dump.Format(r.URL.Query().Get("format"))
+	if format == "" {
+		format = torrent_dump.FormatNDJSON
+	}
+	return format, format.IsValid()
+}
+
+func handleTorrentsExport(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	format, ok := getTorrentDumpFormat(r)
+	if !ok {
+		shared.ErrorBadRequest(r, "invalid format").Send(w, r)
+		return
+	}
+
+	since := time.Time{}
+	if value := r.URL.Query().Get("since"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			shared.ErrorBadRequest(r, "invalid since").Send(w, r)
+			return
+		}
+		since = t
+	}
+
+	filename := "torrents.ndjson.gz"
+	contentType := "application/gzip"
+	if format == torrent_dump.FormatDMM {
+		filename = "torrents.html"
+		contentType = "text/html; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Add("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(200)
+
+	ctx := server.GetReqCtx(r)
+	count, err := torrent_dump.Export(w, format, since)
+	if err != nil {
+		ctx.Log.Error("failed to export torrents", "error", err, "format", format, "count", count)
+		return
+	}
+	ctx.Log.Info("exported torrents", "format", format, "count", count)
+}
+
+func handleTorrentsImport(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	format, ok := getTorrentDumpFormat(r)
+	if !ok {
+		shared.ErrorBadRequest(r, "invalid format").Send(w, r)
+		return
+	}
+	source := ti.TorrentInfoSource(r.URL.Query().Get("source"))
+	if source != ti.TorrentInfoSourceUnknown && !source.IsValid() {
+		shared.ErrorBadRequest(r, "invalid source").Send(w, r)
+		return
+	}
+
+	result, err := torrent_dump.Import(r.Body, format, source)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	SendResponse(w, r, 200, result, nil)
+}
+
+func AddTorrentDumpEndpoints(mux *http.ServeMux) {
+	withAdminAuth := shared.Middleware(AdminAuthed)
+
+	mux.HandleFunc("/v0/admin/torrents/export", withAdminAuth(handleTorrentsExport))
+	mux.HandleFunc("/v0/admin/torrents/import", withAdminAuth(handleTorrentsImport))
+}
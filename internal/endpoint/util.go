@@ -5,6 +5,7 @@ import (
 )
 
 var GetQueryInt = shared.GetQueryInt
+var GetQueryTime = shared.GetQueryTime
 var SendError = shared.SendError
 var SendHTML = shared.SendHTML
 var SendResponse = shared.SendResponse
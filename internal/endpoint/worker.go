@@ -0,0 +1,169 @@
+package endpoint
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/shared"
+	"github.com/MunifTanjim/stremthru/internal/worker"
+)
+
+func sendWorkerError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, worker.ErrWorkerNotFound):
+		shared.ErrorNotFound(r).Send(w, r)
+	case errors.Is(err, worker.ErrWorkerDisabled),
+		errors.Is(err, worker.ErrWorkerPaused),
+		errors.Is(err, worker.ErrWorkerRunning),
+		errors.Is(err, worker.ErrWorkerNotRunning),
+		errors.Is(err, worker.ErrWorkerNotCancellable):
+		shared.ErrorBadRequest(r, err.Error()).Send(w, r)
+	default:
+		SendError(w, r, err)
+	}
+}
+
+type ListWorkersData struct {
+	Items []worker.WorkerStatus `json:"items"`
+}
+
+func handleWorkers(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	items, err := worker.ListWorkers()
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	SendResponse(w, r, 200, &ListWorkersData{Items: items}, nil)
+}
+
+func handleWorker(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	status, err := worker.GetWorkerStatus(r.PathValue("name"))
+	if err != nil {
+		sendWorkerError(w, r, err)
+		return
+	}
+	SendResponse(w, r, 200, status, nil)
+}
+
+func handleWorkerAction(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	name := r.PathValue("name")
+
+	var err error
+	switch r.PathValue("action") {
+	case "run":
+		err = worker.RunWorker(name)
+	case "pause":
+		err = worker.PauseWorker(name)
+	case "resume":
+		err = worker.ResumeWorker(name)
+	case "cancel":
+		err = worker.CancelWorker(name)
+	default:
+		shared.ErrorNotFound(r).Send(w, r)
+		return
+	}
+	if err != nil {
+		sendWorkerError(w, r, err)
+		return
+	}
+
+	status, err := worker.GetWorkerStatus(name)
+	if err != nil {
+		sendWorkerError(w, r, err)
+		return
+	}
+	SendResponse(w, r, 200, status, nil)
+}
+
+//go:embed worker.html
+var workerTemplateBlob string
+
+type workerTemplateData struct {
+	Title   string
+	Version string
+	Now     time.Time
+	Workers []worker.WorkerStatus
+}
+
+var executeWorkerTemplate = func() func(data *workerTemplateData) (bytes.Buffer, error) {
+	tmpl := template.Must(template.New("worker.html").Funcs(template.FuncMap{
+		"since": func(t *time.Time) string {
+			if t == nil {
+				return ""
+			}
+			return time.Since(*t).Truncate(time.Second).String()
+		},
+		"until": func(t *time.Time) string {
+			if t == nil {
+				return ""
+			}
+			return time.Until(*t).Truncate(time.Second).String()
+		},
+		"time": func(t time.Time) string {
+			return t.UTC().Format(time.DateTime)
+		},
+		"duration": func(d time.Duration) string {
+			return d.Truncate(time.Second).String()
+		},
+	}).Parse(workerTemplateBlob))
+	return func(data *workerTemplateData) (bytes.Buffer, error) {
+		var buf bytes.Buffer
+		err := tmpl.Execute(&buf, data)
+		return buf, err
+	}
+}()
+
+func handleWorkersPage(w http.ResponseWriter, r *http.Request) {
+	if !shared.IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	items, err := worker.ListWorkers()
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	buf, err := executeWorkerTemplate(&workerTemplateData{
+		Title:   "StremThru - Workers",
+		Version: config.Version,
+		Now:     time.Now(),
+		Workers: items,
+	})
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	SendHTML(w, 200, buf)
+}
+
+func AddWorkerEndpoints(mux *http.ServeMux) {
+	withAdminAuth := shared.Middleware(AdminAuthed)
+
+	mux.HandleFunc("/v0/admin/workers", withAdminAuth(handleWorkers))
+	mux.HandleFunc("/v0/admin/workers/{name}", withAdminAuth(handleWorker))
+	mux.HandleFunc("/v0/admin/workers/{name}/{action}", withAdminAuth(handleWorkerAction))
+
+	mux.HandleFunc("/admin/workers", withAdminAuth(handleWorkersPage))
+}
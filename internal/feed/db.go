@@ -0,0 +1,271 @@
+package feed
+
+import (
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/db"
+	"github.com/MunifTanjim/stremthru/internal/util"
+)
+
+const ListTableName = "feed_list"
+
+type FeedList struct {
+	Id        string
+	URL       string
+	Name      string
+	UpdatedAt db.Timestamp
+
+	Items []FeedItem `json:"-"`
+}
+
+// NewListId derives a stable id from the list url, including the mapping.
+func NewListId(listUrl string) string {
+	hash := sha1.Sum([]byte(listUrl))
+	return hex.EncodeToString(hash[:])
+}
+
+func (l *FeedList) GetURL() string {
+	return l.URL
+}
+
+func (l *FeedList) IsStale() bool {
+	return time.Now().After(l.UpdatedAt.Add(config.Stremio.List.FeedStaleTime + util.GetRandomDuration(5*time.Second, 5*time.Minute)))
+}
+
+var ListColumn = struct {
+	Id        string
+	URL       string
+	Name      string
+	UpdatedAt string
+}{
+	Id:        "id",
+	URL:       "url",
+	Name:      "name",
+	UpdatedAt: "uat",
+}
+
+var ListColumns = []string{
+	ListColumn.Id,
+	ListColumn.URL,
+	ListColumn.Name,
+	ListColumn.UpdatedAt,
+}
+
+const ListItemTableName = "feed_list_item"
+
+type FeedItemType string
+
+const (
+	ItemTypeMovie FeedItemType = "movie"
+	ItemTypeShow  FeedItemType = "show"
+)
+
+type FeedItem struct {
+	IMDBId string
+	Type   FeedItemType
+	Title  string
+	Year   int
+	Order  int
+}
+
+var ListItemColumn = struct {
+	ListId string
+	IMDBId string
+	Type   string
+	Title  string
+	Year   string
+	Order  string
+}{
+	ListId: "list_id",
+	IMDBId: "imdb_id",
+	Type:   "type",
+	Title:  "title",
+	Year:   "year",
+	Order:  "order",
+}
+
+var ListItemColumns = []string{
+	ListItemColumn.ListId,
+	ListItemColumn.IMDBId,
+	ListItemColumn.Type,
+	ListItemColumn.Title,
+	ListItemColumn.Year,
+	ListItemColumn.Order,
+}
+
+var query_get_list_by_id = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ?`,
+	db.JoinColumnNames(ListColumns...),
+	ListTableName,
+	ListColumn.Id,
+)
+
+func GetListById(id string) (*FeedList, error) {
+	row := db.QueryRow(query_get_list_by_id, id)
+	list := &FeedList{}
+	if err := row.Scan(
+		&list.Id,
+		&list.URL,
+		&list.Name,
+		&list.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	items, err := GetListItems(id)
+	if err != nil {
+		return nil, err
+	}
+	list.Items = items
+	return list, nil
+}
+
+var query_get_list_items = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ? ORDER BY "%s" ASC`,
+	db.JoinColumnNames(ListItemColumns[1:]...),
+	ListItemTableName,
+	ListItemColumn.ListId,
+	ListItemColumn.Order,
+)
+
+func GetListItems(listId string) ([]FeedItem, error) {
+	var items []FeedItem
+	rows, err := db.Query(query_get_list_items, listId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item FeedItem
+		if err := rows.Scan(
+			&item.IMDBId,
+			&item.Type,
+			&item.Title,
+			&item.Year,
+			&item.Order,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+var query_upsert_list = fmt.Sprintf(
+	`INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s`,
+	ListTableName,
+	strings.Join(ListColumns[:len(ListColumns)-1], ", "),
+	util.RepeatJoin("?", len(ListColumns)-1, ", "),
+	ListColumn.Id,
+	strings.Join([]string{
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ListColumn.URL, ListColumn.URL),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ListColumn.Name, ListColumn.Name),
+		fmt.Sprintf(`%s = %s`, ListColumn.UpdatedAt, db.CurrentTimestamp),
+	}, ", "),
+)
+
+func UpsertList(list *FeedList) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			err = tx.Commit()
+			return
+		}
+		tErr := tx.Rollback()
+		err = errors.Join(tErr, err)
+	}()
+
+	_, err = tx.Exec(
+		query_upsert_list,
+		list.Id,
+		list.URL,
+		list.Name,
+	)
+	if err != nil {
+		return err
+	}
+
+	list.UpdatedAt = db.Timestamp{Time: time.Now()}
+
+	err = setListItems(tx, list.Id, list.Items)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var query_set_list_item_before_values = fmt.Sprintf(
+	`INSERT INTO %s (%s) VALUES `,
+	ListItemTableName,
+	db.JoinColumnNames(ListItemColumns...),
+)
+var query_set_list_item_values_placeholder = "(" + util.RepeatJoin("?", len(ListItemColumns), ",") + ")"
+var query_set_list_item_after_values = fmt.Sprintf(
+	` ON CONFLICT (%s,%s) DO UPDATE SET %s = EXCLUDED.%s, %s = EXCLUDED.%s, %s = EXCLUDED.%s, "%s" = EXCLUDED."%s"`,
+	ListItemColumn.ListId,
+	ListItemColumn.IMDBId,
+	ListItemColumn.Type,
+	ListItemColumn.Type,
+	ListItemColumn.Title,
+	ListItemColumn.Title,
+	ListItemColumn.Year,
+	ListItemColumn.Year,
+	ListItemColumn.Order,
+	ListItemColumn.Order,
+)
+var query_cleanup_list_item = fmt.Sprintf(
+	`DELETE FROM %s WHERE %s = ?`,
+	ListItemTableName,
+	ListItemColumn.ListId,
+)
+
+func setListItems(tx db.Executor, listId string, items []FeedItem) error {
+	if _, err := tx.Exec(query_cleanup_list_item, listId); err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	columnCount := len(ListItemColumns)
+	for cItems := range slices.Chunk(items, 500) {
+		count := len(cItems)
+		query := query_set_list_item_before_values +
+			util.RepeatJoin(query_set_list_item_values_placeholder, count, ",") +
+			query_set_list_item_after_values
+		args := make([]any, count*columnCount)
+		for i, item := range cItems {
+			args[i*columnCount+0] = listId
+			args[i*columnCount+1] = item.IMDBId
+			args[i*columnCount+2] = item.Type
+			args[i*columnCount+3] = item.Title
+			args[i*columnCount+4] = item.Year
+			args[i*columnCount+5] = item.Order
+		}
+
+		if _, err := tx.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
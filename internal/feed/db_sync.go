@@ -0,0 +1,345 @@
+package feed
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/cache"
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/imdb_title"
+	"github.com/MunifTanjim/stremthru/internal/tvdb"
+)
+
+const maxBodySize = 5 * 1024 * 1024
+const maxEntryCount = 1000
+
+var listCache = cache.NewCache[FeedList](&cache.CacheConfig{
+	Lifetime:      6 * time.Hour,
+	Name:          "feed:list",
+	LocalCapacity: 1024,
+})
+
+func getListCacheKey(l *FeedList) string {
+	return l.Id
+}
+
+// ParseListURL validates the list url and returns the url to fetch and the
+// json field mapping. The mapping is passed in the url fragment, e.g.
+// `#items=data.results&imdb=ids.imdb&title=name&year=year&type=kind`.
+// GitHub Gist urls are resolved to their raw content.
+func ParseListURL(listUrl *url.URL) (sourceUrl string, mapping *Mapping, err error) {
+	if listUrl.Scheme != "http" && listUrl.Scheme != "https" {
+		return "", nil, errors.New("unsupported url scheme")
+	}
+
+	u := *listUrl
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	if u.Hostname() == "gist.github.com" {
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return "", nil, errors.New("invalid gist url")
+		}
+		u = url.URL{
+			Scheme: "https",
+			Host:   "gist.githubusercontent.com",
+			Path:   "/" + parts[0] + "/" + parts[1] + "/raw",
+		}
+	}
+
+	mapping = &Mapping{}
+	if listUrl.Fragment != "" {
+		q, err := url.ParseQuery(listUrl.Fragment)
+		if err != nil {
+			return "", nil, errors.New("invalid mapping: " + err.Error())
+		}
+		mapping.Items = q.Get("items")
+		mapping.IMDBId = q.Get("imdb")
+		mapping.TMDBId = q.Get("tmdb")
+		mapping.TVDBId = q.Get("tvdb")
+		mapping.Title = q.Get("title")
+		mapping.Year = q.Get("year")
+		mapping.Type = q.Get("type")
+	}
+
+	return u.String(), mapping, nil
+}
+
+var httpClient = func() *http.Client {
+	c := *config.DefaultHTTPClient
+	c.Timeout = 30 * time.Second
+	return &c
+}()
+
+func fetchDocument(listUrl string) (*Document, error) {
+	u, err := url.Parse(listUrl)
+	if err != nil {
+		return nil, err
+	}
+	sourceUrl, mapping, err := ParseListURL(u)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, sourceUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/json, text/plain;q=0.9, */*;q=0.8")
+	req.Header.Set("User-Agent", "StremThru/"+config.Version)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		return nil, errors.New("failed to fetch feed: " + res.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxBodySize))
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(res.Header.Get("Content-Type"), body, mapping)
+}
+
+func toItemType(titleType imdb_title.IMDBTitleType, fallback EntryType) FeedItemType {
+	if titleType != "" {
+		if titleType.IsShow() {
+			return ItemTypeShow
+		}
+		return ItemTypeMovie
+	}
+	if fallback == EntryTypeShow {
+		return ItemTypeShow
+	}
+	return ItemTypeMovie
+}
+
+func resolveEntries(entries []Entry) ([]FeedItem, error) {
+	if len(entries) > maxEntryCount {
+		entries = entries[:maxEntryCount]
+	}
+
+	var tmdbMovieIds, tmdbShowIds, tvdbMovieIds, tvdbShowIds []string
+	for i := range entries {
+		entry := &entries[i]
+		if entry.IMDBId != "" {
+			continue
+		}
+		if entry.TMDBId != "" {
+			if entry.Type != EntryTypeShow {
+				tmdbMovieIds = append(tmdbMovieIds, entry.TMDBId)
+			}
+			if entry.Type != EntryTypeMovie {
+				tmdbShowIds = append(tmdbShowIds, entry.TMDBId)
+			}
+		} else if entry.TVDBId != "" {
+			if entry.Type != EntryTypeShow {
+				tvdbMovieIds = append(tvdbMovieIds, entry.TVDBId)
+			}
+			if entry.Type != EntryTypeMovie {
+				tvdbShowIds = append(tvdbShowIds, entry.TVDBId)
+			}
+		}
+	}
+
+	movieImdbIdByTmdbId, showImdbIdByTmdbId, err := imdb_title.GetIMDBIdByTMDBId(tmdbMovieIds, tmdbShowIds)
+	if err != nil {
+		return nil, err
+	}
+	getIMDBIdByTVDBId := imdb_title.GetIMDBIdByTVDBId
+	if config.Integration.TVDB.IsEnabled() {
+		getIMDBIdByTVDBId = tvdb.GetIMDBIdsForTVDBIds
+	}
+	movieImdbIdByTvdbId, showImdbIdByTvdbId, err := getIMDBIdByTVDBId(tvdbMovieIds, tvdbShowIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		if entry.IMDBId != "" {
+			continue
+		}
+		if entry.TMDBId != "" {
+			if id, ok := movieImdbIdByTmdbId[entry.TMDBId]; ok && entry.Type != EntryTypeShow {
+				entry.IMDBId = id
+			} else if id, ok := showImdbIdByTmdbId[entry.TMDBId]; ok && entry.Type != EntryTypeMovie {
+				entry.IMDBId = id
+			}
+		} else if entry.TVDBId != "" {
+			if id, ok := movieImdbIdByTvdbId[entry.TVDBId]; ok && entry.Type != EntryTypeShow {
+				entry.IMDBId = id
+			} else if id, ok := showImdbIdByTvdbId[entry.TVDBId]; ok && entry.Type != EntryTypeMovie {
+				entry.IMDBId = id
+			}
+		}
+		if entry.IMDBId == "" && entry.Title != "" {
+			titleType := imdb_title.SearchTitleTypeUnknown
+			switch entry.Type {
+			case EntryTypeMovie:
+				titleType = imdb_title.SearchTitleTypeMovie
+			case EntryTypeShow:
+				titleType = imdb_title.SearchTitleTypeShow
+			}
+			title, err := imdb_title.SearchOne(entry.Title, titleType, entry.Year, false)
+			if err != nil {
+				log.Warn("failed to search imdb title", "title", entry.Title, "year", entry.Year, "error", err)
+				continue
+			}
+			if title != nil {
+				entry.IMDBId = title.TId
+			}
+		}
+	}
+
+	imdbIds := make([]string, 0, len(entries))
+	for i := range entries {
+		if id := entries[i].IMDBId; id != "" {
+			imdbIds = append(imdbIds, id)
+		}
+	}
+	titles, err := imdb_title.ListByIds(imdbIds)
+	if err != nil {
+		return nil, err
+	}
+	titleById := make(map[string]*imdb_title.IMDBTitle, len(titles))
+	for i := range titles {
+		titleById[titles[i].TId] = &titles[i]
+	}
+
+	items := make([]FeedItem, 0, len(imdbIds))
+	seen := make(map[string]struct{}, len(imdbIds))
+	for i := range entries {
+		entry := &entries[i]
+		if entry.IMDBId == "" {
+			continue
+		}
+		if _, ok := seen[entry.IMDBId]; ok {
+			continue
+		}
+		seen[entry.IMDBId] = struct{}{}
+		item := FeedItem{
+			IMDBId: entry.IMDBId,
+			Type:   toItemType("", entry.Type),
+			Title:  entry.Title,
+			Year:   entry.Year,
+			Order:  len(items),
+		}
+		if title, ok := titleById[entry.IMDBId]; ok {
+			item.Type = toItemType(imdb_title.IMDBTitleType(title.Type), entry.Type)
+			item.Title = title.Title
+			if title.Year > 0 {
+				item.Year = title.Year
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+var syncListMutex sync.Mutex
+
+func syncList(l *FeedList) error {
+	if l.URL == "" {
+		return errors.New("url must be provided")
+	}
+	l.Id = NewListId(l.URL)
+
+	log.Debug("fetching feed", "id", l.Id)
+	doc, err := fetchDocument(l.URL)
+	if err != nil {
+		return err
+	}
+
+	items, err := resolveEntries(doc.Entries)
+	if err != nil {
+		return err
+	}
+	log.Debug("resolved feed items", "id", l.Id, "format", doc.Format, "entry_count", len(doc.Entries), "item_count", len(items))
+
+	// the titles are resolved without the lock, a large feed does not block
+	// the other lists
+	syncListMutex.Lock()
+	defer syncListMutex.Unlock()
+
+	l.Name = doc.Title
+	if l.Name == "" {
+		if u, err := url.Parse(l.URL); err == nil {
+			l.Name = u.Hostname() + strings.TrimSuffix(u.Path, "/")
+		} else {
+			l.Name = "Feed (" + strconv.Itoa(len(items)) + ")"
+		}
+	}
+	l.Items = items
+
+	if err := UpsertList(l); err != nil {
+		return err
+	}
+
+	if err := listCache.Add(getListCacheKey(l), *l); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Fetch loads the list by Id, or by URL for a new list.
+func (l *FeedList) Fetch() error {
+	if l.Id == "" {
+		if l.URL == "" {
+			return errors.New("id or url must be provided")
+		}
+		l.Id = NewListId(l.URL)
+	}
+
+	isMissing := false
+
+	listCacheKey := getListCacheKey(l)
+	var cachedL FeedList
+	if !listCache.Get(listCacheKey, &cachedL) {
+		if list, err := GetListById(l.Id); err != nil {
+			return err
+		} else if list == nil {
+			isMissing = true
+		} else {
+			*l = *list
+			log.Debug("found list by id", "id", l.Id, "is_stale", l.IsStale())
+			listCache.Add(listCacheKey, *l)
+		}
+	} else {
+		*l = cachedL
+	}
+
+	if !isMissing {
+		if l.IsStale() {
+			staleList := *l
+			go func() {
+				if err := syncList(&staleList); err != nil {
+					log.Error("failed to sync stale list", "id", l.Id, "error", err)
+				}
+			}()
+		}
+		return nil
+	}
+
+	if l.URL == "" {
+		return errors.New("list not found")
+	}
+
+	if err := syncList(l); err != nil {
+		return err
+	}
+
+	return nil
+}
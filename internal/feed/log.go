@@ -0,0 +1,7 @@
+package feed
+
+import (
+	"github.com/MunifTanjim/stremthru/internal/logger"
+)
+
+var log = logger.Scoped("feed")
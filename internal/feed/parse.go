@@ -0,0 +1,409 @@
+package feed
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/MunifTanjim/stremthru/internal/util"
+)
+
+type EntryType string
+
+const (
+	EntryTypeMovie   EntryType = "movie"
+	EntryTypeShow    EntryType = "show"
+	EntryTypeUnknown EntryType = ""
+)
+
+func toEntryType(value string) EntryType {
+	switch strings.ToLower(value) {
+	case "movie", "movies", "film":
+		return EntryTypeMovie
+	case "show", "shows", "tv", "series", "tvshow", "tv_show":
+		return EntryTypeShow
+	default:
+		return EntryTypeUnknown
+	}
+}
+
+// Entry is a single item extracted from a feed, before resolution.
+type Entry struct {
+	Type   EntryType
+	IMDBId string
+	TMDBId string
+	TVDBId string
+	Title  string
+	Year   int
+}
+
+func (e *Entry) IsEmpty() bool {
+	return e.IMDBId == "" && e.TMDBId == "" && e.TVDBId == "" && e.Title == ""
+}
+
+type Format string
+
+const (
+	FormatRSS  Format = "rss"
+	FormatAtom Format = "atom"
+	FormatJSON Format = "json"
+	FormatText Format = "text"
+)
+
+// Mapping describes where to find the fields in a JSON document, using
+// dot separated paths, e.g. `data.results` or `ids.imdb`.
+type Mapping struct {
+	Items  string `json:"items,omitempty"`
+	IMDBId string `json:"imdb,omitempty"`
+	TMDBId string `json:"tmdb,omitempty"`
+	TVDBId string `json:"tvdb,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Year   string `json:"year,omitempty"`
+	Type   string `json:"type,omitempty"`
+}
+
+type Document struct {
+	Format  Format
+	Title   string
+	Entries []Entry
+}
+
+var (
+	imdbIdRegex     = regexp.MustCompile(`\btt\d{7,}\b`)
+	tmdbUrlRegex    = regexp.MustCompile(`themoviedb\.org/(movie|tv)/(\d+)`)
+	tvdbUrlRegex    = regexp.MustCompile(`thetvdb\.com/(movies|series)/([a-z0-9-]+)`)
+	titleYearRegex  = regexp.MustCompile(`^(.+?)(?:\s*\(((?:19|20)\d{2})\)|\s*\[((?:19|20)\d{2})\]|,\s*((?:19|20)\d{2}))$`)
+	prefixedIdRegex = regexp.MustCompile(`^(imdb|tmdb|tvdb)(?::(movie|tv|show|series))?:(\w+)$`)
+)
+
+func detectFormat(contentType string, body []byte) Format {
+	switch {
+	case strings.Contains(contentType, "json"):
+		return FormatJSON
+	case strings.Contains(contentType, "atom"):
+		return FormatAtom
+	case strings.Contains(contentType, "rss"):
+		return FormatRSS
+	}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return FormatText
+	}
+	switch trimmed[0] {
+	case '{', '[':
+		return FormatJSON
+	case '<':
+		if bytes.Contains(trimmed[:min(len(trimmed), 1024)], []byte("<feed")) {
+			return FormatAtom
+		}
+		return FormatRSS
+	}
+	return FormatText
+}
+
+func Parse(contentType string, body []byte, mapping *Mapping) (*Document, error) {
+	format := detectFormat(contentType, body)
+	switch format {
+	case FormatRSS:
+		return parseRSS(body)
+	case FormatAtom:
+		return parseAtom(body)
+	case FormatJSON:
+		return parseJSON(body, mapping)
+	default:
+		return parseText(body)
+	}
+}
+
+// entryFromText extracts ids from free form text, e.g. item link/description.
+func entryFromText(entry *Entry, texts ...string) {
+	for _, text := range texts {
+		if entry.IMDBId == "" {
+			entry.IMDBId = imdbIdRegex.FindString(text)
+		}
+		if entry.TMDBId == "" {
+			if m := tmdbUrlRegex.FindStringSubmatch(text); m != nil {
+				entry.TMDBId = m[2]
+				entry.Type = toEntryType(m[1])
+			}
+		}
+		if entry.TVDBId == "" {
+			if m := tvdbUrlRegex.FindStringSubmatch(text); m != nil && util.IsNumericString(m[2]) {
+				entry.TVDBId = m[2]
+				entry.Type = toEntryType(m[1])
+			}
+		}
+	}
+}
+
+func parseTitleYear(text string) (string, int) {
+	text = strings.TrimSpace(text)
+	if m := titleYearRegex.FindStringSubmatch(text); m != nil {
+		year, _ := strconv.Atoi(m[2] + m[3] + m[4])
+		return strings.TrimSpace(m[1]), year
+	}
+	return text, 0
+}
+
+type xmlExtra struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string     `xml:"title"`
+	Link        string     `xml:"link"`
+	Guid        string     `xml:"guid"`
+	Description string     `xml:"description"`
+	Extra       []xmlExtra `xml:",any"`
+}
+
+type rssFeed struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+// applyXMLExtra picks up well known namespaced elements, e.g. letterboxd's
+// `tmdb:movieId`, `letterboxd:filmTitle` and `letterboxd:filmYear`.
+func applyXMLExtra(entry *Entry, extras []xmlExtra) {
+	for _, extra := range extras {
+		value := strings.TrimSpace(extra.Value)
+		if value == "" {
+			continue
+		}
+		switch strings.ToLower(extra.XMLName.Local) {
+		case "movieid":
+			entry.TMDBId, entry.Type = value, EntryTypeMovie
+		case "tvid":
+			entry.TMDBId, entry.Type = value, EntryTypeShow
+		case "imdbid", "imdb_id":
+			entry.IMDBId = value
+		case "filmtitle":
+			entry.Title = value
+		case "filmyear":
+			entry.Year, _ = strconv.Atoi(value)
+		}
+	}
+}
+
+func parseRSS(body []byte) (*Document, error) {
+	feed := rssFeed{}
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, err
+	}
+	doc := &Document{Format: FormatRSS, Title: strings.TrimSpace(feed.Channel.Title)}
+	for i := range feed.Channel.Items {
+		item := &feed.Channel.Items[i]
+		entry := Entry{}
+		applyXMLExtra(&entry, item.Extra)
+		entryFromText(&entry, item.Link, item.Guid, item.Description)
+		if entry.Title == "" {
+			entry.Title, entry.Year = parseTitleYear(item.Title)
+		}
+		if !entry.IsEmpty() {
+			doc.Entries = append(doc.Entries, entry)
+		}
+	}
+	return doc, nil
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title   string     `xml:"title"`
+	Id      string     `xml:"id"`
+	Links   []atomLink `xml:"link"`
+	Summary string     `xml:"summary"`
+	Content string     `xml:"content"`
+	Extra   []xmlExtra `xml:",any"`
+}
+
+type atomFeed struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+func parseAtom(body []byte) (*Document, error) {
+	feed := atomFeed{}
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, err
+	}
+	doc := &Document{Format: FormatAtom, Title: strings.TrimSpace(feed.Title)}
+	for i := range feed.Entries {
+		item := &feed.Entries[i]
+		entry := Entry{}
+		applyXMLExtra(&entry, item.Extra)
+		texts := []string{item.Id}
+		for _, link := range item.Links {
+			texts = append(texts, link.Href)
+		}
+		texts = append(texts, item.Summary, item.Content)
+		entryFromText(&entry, texts...)
+		if entry.Title == "" {
+			entry.Title, entry.Year = parseTitleYear(item.Title)
+		}
+		if !entry.IsEmpty() {
+			doc.Entries = append(doc.Entries, entry)
+		}
+	}
+	return doc, nil
+}
+
+func getPath(value any, path string) any {
+	if path == "" {
+		return value
+	}
+	for key := range strings.SplitSeq(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			value = v[key]
+		case []any:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil
+			}
+			value = v[idx]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+func getPathString(value any, paths ...string) string {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		switch v := getPath(value, path).(type) {
+		case string:
+			if v = strings.TrimSpace(v); v != "" {
+				return v
+			}
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return ""
+}
+
+var defaultMapping = Mapping{
+	IMDBId: "imdb_id,imdbId,imdb,ids.imdb",
+	TMDBId: "tmdb_id,tmdbId,tmdb,ids.tmdb",
+	TVDBId: "tvdb_id,tvdbId,tvdb,ids.tvdb",
+	Title:  "title,name,original_title",
+	Year:   "year,release_year,release_date,first_air_date",
+	Type:   "type,media_type,mediatype",
+}
+
+func splitPaths(custom, fallback string) []string {
+	if custom != "" {
+		return strings.Split(custom, ",")
+	}
+	return strings.Split(fallback, ",")
+}
+
+func parseJSON(body []byte, mapping *Mapping) (*Document, error) {
+	var root any
+	if err := json.Unmarshal(body, &root); err != nil {
+		return nil, err
+	}
+	if mapping == nil {
+		mapping = &Mapping{}
+	}
+
+	doc := &Document{Format: FormatJSON}
+	if obj, ok := root.(map[string]any); ok {
+		doc.Title = getPathString(obj, "title", "name")
+	}
+
+	itemsValue := getPath(root, mapping.Items)
+	if mapping.Items == "" {
+		if obj, ok := root.(map[string]any); ok {
+			for _, key := range []string{"items", "results", "data", "list"} {
+				if _, ok := obj[key].([]any); ok {
+					itemsValue = obj[key]
+					break
+				}
+			}
+		}
+	}
+	items, ok := itemsValue.([]any)
+	if !ok {
+		return nil, errors.New("items not found in json")
+	}
+
+	for _, item := range items {
+		entry := Entry{}
+		if s, ok := item.(string); ok {
+			entry = parseTextLine(s)
+		} else {
+			entry.IMDBId = getPathString(item, splitPaths(mapping.IMDBId, defaultMapping.IMDBId)...)
+			entry.TMDBId = getPathString(item, splitPaths(mapping.TMDBId, defaultMapping.TMDBId)...)
+			entry.TVDBId = getPathString(item, splitPaths(mapping.TVDBId, defaultMapping.TVDBId)...)
+			entry.Title = getPathString(item, splitPaths(mapping.Title, defaultMapping.Title)...)
+			if year := getPathString(item, splitPaths(mapping.Year, defaultMapping.Year)...); len(year) >= 4 {
+				entry.Year, _ = strconv.Atoi(year[:4])
+			}
+			entry.Type = toEntryType(getPathString(item, splitPaths(mapping.Type, defaultMapping.Type)...))
+			if !imdbIdRegex.MatchString(entry.IMDBId) {
+				entry.IMDBId = ""
+			}
+		}
+		if !entry.IsEmpty() {
+			doc.Entries = append(doc.Entries, entry)
+		}
+	}
+	return doc, nil
+}
+
+// parseTextLine accepts `tt1234567`, `tmdb:movie:123`, `tvdb:series:123`,
+// urls containing those ids, or `Title (Year)`.
+func parseTextLine(line string) Entry {
+	entry := Entry{}
+	line = strings.TrimSpace(line)
+	if m := prefixedIdRegex.FindStringSubmatch(line); m != nil {
+		entry.Type = toEntryType(m[2])
+		switch m[1] {
+		case "imdb":
+			entry.IMDBId = m[3]
+		case "tmdb":
+			entry.TMDBId = m[3]
+		case "tvdb":
+			entry.TVDBId = m[3]
+		}
+		return entry
+	}
+	entryFromText(&entry, line)
+	if entry.IMDBId == "" && entry.TMDBId == "" && entry.TVDBId == "" && !strings.Contains(line, "://") {
+		entry.Title, entry.Year = parseTitleYear(line)
+	}
+	return entry
+}
+
+func parseText(body []byte) (*Document, error) {
+	doc := &Document{Format: FormatText}
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		if entry := parseTextLine(line); !entry.IsEmpty() {
+			doc.Entries = append(doc.Entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
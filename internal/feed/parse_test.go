@@ -0,0 +1,82 @@
+package feed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTextLine(t *testing.T) {
+	for _, row := range []struct {
+		line  string
+		entry Entry
+	}{
+		{"tt0111161", Entry{IMDBId: "tt0111161"}},
+		{"https://www.imdb.com/title/tt0111161/", Entry{IMDBId: "tt0111161"}},
+		{"tmdb:movie:278", Entry{Type: EntryTypeMovie, TMDBId: "278"}},
+		{"https://www.themoviedb.org/tv/1396-breaking-bad", Entry{Type: EntryTypeShow, TMDBId: "1396"}},
+		{"The Shawshank Redemption (1994)", Entry{Title: "The Shawshank Redemption", Year: 1994}},
+		{"Parasite, 2019", Entry{Title: "Parasite", Year: 2019}},
+		{"Blade Runner 2049", Entry{Title: "Blade Runner 2049"}},
+	} {
+		t.Run(row.line, func(t *testing.T) {
+			assert.Equal(t, row.entry, parseTextLine(row.line))
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, row := range []struct {
+		name        string
+		contentType string
+		body        string
+		mapping     *Mapping
+		doc         Document
+	}{
+		{
+			name: "rss",
+			body: `<?xml version="1.0"?>
+<rss version="2.0" xmlns:letterboxd="https://letterboxd.com" xmlns:tmdb="https://themoviedb.org">
+<channel>
+<title>Watched</title>
+<item><title>Parasite, 2019</title><link>https://letterboxd.com/film/parasite-2019/</link><tmdb:movieId>496243</tmdb:movieId><letterboxd:filmTitle>Parasite</letterboxd:filmTitle><letterboxd:filmYear>2019</letterboxd:filmYear></item>
+<item><title>Heat (1995)</title><link>https://www.imdb.com/title/tt0113277/</link></item>
+</channel>
+</rss>`,
+			doc: Document{Format: FormatRSS, Title: "Watched", Entries: []Entry{
+				{Type: EntryTypeMovie, TMDBId: "496243", Title: "Parasite", Year: 2019},
+				{IMDBId: "tt0113277", Title: "Heat", Year: 1995},
+			}},
+		},
+		{
+			name: "atom",
+			body: `<feed xmlns="http://www.w3.org/2005/Atom"><title>Picks</title><entry><title>Alien (1979)</title><link href="https://www.imdb.com/title/tt0078748/"/></entry></feed>`,
+			doc: Document{Format: FormatAtom, Title: "Picks", Entries: []Entry{
+				{IMDBId: "tt0078748", Title: "Alien", Year: 1979},
+			}},
+		},
+		{
+			name:        "json",
+			contentType: "application/json",
+			body:        `{"data":{"rows":[{"meta":{"imdb":"tt0133093"},"label":"The Matrix","kind":"movie"}]}}`,
+			mapping:     &Mapping{Items: "data.rows", IMDBId: "meta.imdb", Title: "label", Type: "kind"},
+			doc: Document{Format: FormatJSON, Entries: []Entry{
+				{Type: EntryTypeMovie, IMDBId: "tt0133093", Title: "The Matrix"},
+			}},
+		},
+		{
+			name: "text",
+			body: "# my list\ntt0133093\n\nHeat (1995)\n",
+			doc: Document{Format: FormatText, Entries: []Entry{
+				{IMDBId: "tt0133093"},
+				{Title: "Heat", Year: 1995},
+			}},
+		},
+	} {
+		t.Run(row.name, func(t *testing.T) {
+			doc, err := Parse(row.contentType, []byte(row.body), row.mapping)
+			assert.NoError(t, err)
+			assert.Equal(t, &row.doc, doc)
+		})
+	}
+}
@@ -83,3 +83,45 @@ func GetLastMappedIMDBId() (string, error) {
 	err := row.Scan(&lastIMDBId)
 	return lastIMDBId, err
 }
+
+var query_get_tids_by_hashes = fmt.Sprintf(
+	"SELECT %s, %s FROM %s WHERE %s != '' AND %s IN ",
+	Column.Hash,
+	Column.TId,
+	TableName,
+	Column.TId,
+	Column.Hash,
+)
+
+func GetTIdsByHashes(hashes []string) (map[string][]string, error) {
+	tidsByHash := map[string][]string{}
+	if len(hashes) == 0 {
+		return tidsByHash, nil
+	}
+
+	for cHashes := range slices.Chunk(hashes, 1000) {
+		args := make([]any, len(cHashes))
+		for i, hash := range cHashes {
+			args[i] = hash
+		}
+		query := query_get_tids_by_hashes + "(" + util.RepeatJoin("?", len(cHashes), ",") + ")"
+		rows, err := db.Query(query, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var hash, tid string
+			if err := rows.Scan(&hash, &tid); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			tidsByHash[hash] = append(tidsByHash[hash], tid)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tidsByHash, nil
+}
@@ -0,0 +1,467 @@
+package jellyfin
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/db"
+	"github.com/MunifTanjim/stremthru/internal/meta"
+	"github.com/MunifTanjim/stremthru/internal/util"
+)
+
+const ListTableName = "jellyfin_list"
+
+type JellyfinListKind string
+
+const (
+	ListKindCollection JellyfinListKind = "collection"
+	ListKindPlaylist   JellyfinListKind = "playlist"
+)
+
+type JellyfinList struct {
+	Id        string // {server_id}:{item_id}, stored as {scope}:{server_id}:{item_id}
+	ServerURL string
+	Kind      JellyfinListKind
+	Name      string
+	Overview  string
+	UpdatedAt db.Timestamp
+
+	Items []JellyfinItem `json:"-"`
+}
+
+func NewListId(serverId, itemId string) string {
+	return serverId + ":" + itemId
+}
+
+func (l *JellyfinList) GetServerId() string {
+	serverId, _, _ := strings.Cut(l.Id, ":")
+	return serverId
+}
+
+func (l *JellyfinList) GetItemId() string {
+	_, itemId, _ := strings.Cut(l.Id, ":")
+	return itemId
+}
+
+// getScopedId returns the id the list is stored with. The server id is
+// reported by the server itself, so the stored lists and items are scoped to
+// the credential.
+func getScopedId(scope, id string) string {
+	return scope + ":" + id
+}
+
+func (l *JellyfinList) GetURL() string {
+	return strings.TrimSuffix(l.ServerURL, "/") + "/web/#/details?id=" + l.GetItemId() + "&serverId=" + l.GetServerId()
+}
+
+func (l *JellyfinList) IsStale() bool {
+	return time.Now().After(l.UpdatedAt.Add(config.Integration.Jellyfin.ListStaleTime + util.GetRandomDuration(5*time.Second, 5*time.Minute)))
+}
+
+var ListColumn = struct {
+	Id        string
+	ServerURL string
+	Kind      string
+	Name      string
+	Overview  string
+	UpdatedAt string
+}{
+	Id:        "id",
+	ServerURL: "server_url",
+	Kind:      "kind",
+	Name:      "name",
+	Overview:  "overview",
+	UpdatedAt: "uat",
+}
+
+var ListColumns = []string{
+	ListColumn.Id,
+	ListColumn.ServerURL,
+	ListColumn.Kind,
+	ListColumn.Name,
+	ListColumn.Overview,
+	ListColumn.UpdatedAt,
+}
+
+const ItemTableName = "jellyfin_item"
+
+type JellyfinItemType string
+
+const (
+	ItemTypeMovie  JellyfinItemType = "movie"
+	ItemTypeSeries JellyfinItemType = "series"
+)
+
+type JellyfinItem struct {
+	Id         string // {scope}:{server_id}:{item_id}
+	Type       JellyfinItemType
+	Name       string
+	Overview   string
+	Year       int
+	Runtime    int
+	Poster     string
+	Background string
+	IMDBId     string
+	TMDBId     string
+	TVDBId     string
+	Genres     db.JSONStringList
+	UpdatedAt  db.Timestamp
+
+	Order int `json:"-"`
+}
+
+func (item *JellyfinItem) GenreNames() []string {
+	return item.Genres
+}
+
+func (item *JellyfinItem) GetIdMap() *meta.IdMap {
+	if item.IMDBId == "" {
+		return nil
+	}
+	idMap := &meta.IdMap{
+		IMDB: item.IMDBId,
+		TMDB: item.TMDBId,
+		TVDB: item.TVDBId,
+	}
+	switch item.Type {
+	case ItemTypeMovie:
+		idMap.Type = meta.IdTypeMovie
+	case ItemTypeSeries:
+		idMap.Type = meta.IdTypeShow
+	}
+	return idMap
+}
+
+var ItemColumn = struct {
+	Id         string
+	Type       string
+	Name       string
+	Overview   string
+	Year       string
+	Runtime    string
+	Poster     string
+	Background string
+	IMDBId     string
+	TMDBId     string
+	TVDBId     string
+	Genres     string
+	UpdatedAt  string
+}{
+	Id:         "id",
+	Type:       "type",
+	Name:       "name",
+	Overview:   "overview",
+	Year:       "year",
+	Runtime:    "runtime",
+	Poster:     "poster",
+	Background: "background",
+	IMDBId:     "imdb_id",
+	TMDBId:     "tmdb_id",
+	TVDBId:     "tvdb_id",
+	Genres:     "genres",
+	UpdatedAt:  "uat",
+}
+
+var ItemColumns = []string{
+	ItemColumn.Id,
+	ItemColumn.Type,
+	ItemColumn.Name,
+	ItemColumn.Overview,
+	ItemColumn.Year,
+	ItemColumn.Runtime,
+	ItemColumn.Poster,
+	ItemColumn.Background,
+	ItemColumn.IMDBId,
+	ItemColumn.TMDBId,
+	ItemColumn.TVDBId,
+	ItemColumn.Genres,
+	ItemColumn.UpdatedAt,
+}
+
+const ListItemTableName = "jellyfin_list_item"
+
+type JellyfinListItem struct {
+	ListId string
+	ItemId string
+	Order  int
+}
+
+var ListItemColumn = struct {
+	ListId string
+	ItemId string
+	Order  string
+}{
+	ListId: "list_id",
+	ItemId: "item_id",
+	Order:  "order",
+}
+
+var query_get_list_by_id = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ?`,
+	db.JoinColumnNames(ListColumns...),
+	ListTableName,
+	ListColumn.Id,
+)
+
+func GetListById(scope, id string) (*JellyfinList, error) {
+	scopedId := getScopedId(scope, id)
+	row := db.QueryRow(query_get_list_by_id, scopedId)
+	list := &JellyfinList{}
+	if err := row.Scan(
+		&list.Id,
+		&list.ServerURL,
+		&list.Kind,
+		&list.Name,
+		&list.Overview,
+		&list.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	list.Id = id
+	items, err := GetListItems(scopedId)
+	if err != nil {
+		return nil, err
+	}
+	list.Items = items
+	return list, nil
+}
+
+var query_get_list_items = fmt.Sprintf(
+	`SELECT %s, li."%s" FROM %s li JOIN %s i ON i.%s = li.%s WHERE li.%s = ? ORDER BY li."%s" ASC`,
+	db.JoinPrefixedColumnNames("i.", ItemColumns...),
+	ListItemColumn.Order,
+	ListItemTableName,
+	ItemTableName,
+	ItemColumn.Id,
+	ListItemColumn.ItemId,
+	ListItemColumn.ListId,
+	ListItemColumn.Order,
+)
+
+func GetListItems(listId string) ([]JellyfinItem, error) {
+	var items []JellyfinItem
+	rows, err := db.Query(query_get_list_items, listId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item JellyfinItem
+		if err := rows.Scan(
+			&item.Id,
+			&item.Type,
+			&item.Name,
+			&item.Overview,
+			&item.Year,
+			&item.Runtime,
+			&item.Poster,
+			&item.Background,
+			&item.IMDBId,
+			&item.TMDBId,
+			&item.TVDBId,
+			&item.Genres,
+			&item.UpdatedAt,
+			&item.Order,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+var query_upsert_list = fmt.Sprintf(
+	`INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s`,
+	ListTableName,
+	strings.Join(ListColumns[:len(ListColumns)-1], ", "),
+	util.RepeatJoin("?", len(ListColumns)-1, ", "),
+	ListColumn.Id,
+	strings.Join([]string{
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ListColumn.ServerURL, ListColumn.ServerURL),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ListColumn.Kind, ListColumn.Kind),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ListColumn.Name, ListColumn.Name),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ListColumn.Overview, ListColumn.Overview),
+		fmt.Sprintf(`%s = %s`, ListColumn.UpdatedAt, db.CurrentTimestamp),
+	}, ", "),
+)
+
+func UpsertList(scope string, list *JellyfinList) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			err = tx.Commit()
+			return
+		}
+		tErr := tx.Rollback()
+		err = errors.Join(tErr, err)
+	}()
+
+	scopedId := getScopedId(scope, list.Id)
+
+	_, err = tx.Exec(
+		query_upsert_list,
+		scopedId,
+		list.ServerURL,
+		list.Kind,
+		list.Name,
+		list.Overview,
+	)
+	if err != nil {
+		return err
+	}
+
+	list.UpdatedAt = db.Timestamp{Time: time.Now()}
+
+	err = UpsertItems(tx, list.Items)
+	if err != nil {
+		return err
+	}
+
+	err = setListItems(tx, scopedId, list.Items)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var query_upsert_items_before_values = fmt.Sprintf(
+	`INSERT INTO %s (%s) VALUES `,
+	ItemTableName,
+	strings.Join(ItemColumns[:len(ItemColumns)-1], ", "),
+)
+var query_upsert_items_values_placholder = fmt.Sprintf(
+	`(%s)`,
+	util.RepeatJoin("?", len(ItemColumns)-1, ","),
+)
+var query_upsert_items_after_values = fmt.Sprintf(
+	` ON CONFLICT (%s) DO UPDATE SET %s`,
+	ItemColumn.Id,
+	strings.Join([]string{
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ItemColumn.Type, ItemColumn.Type),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ItemColumn.Name, ItemColumn.Name),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ItemColumn.Overview, ItemColumn.Overview),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ItemColumn.Year, ItemColumn.Year),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ItemColumn.Runtime, ItemColumn.Runtime),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ItemColumn.Poster, ItemColumn.Poster),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ItemColumn.Background, ItemColumn.Background),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ItemColumn.IMDBId, ItemColumn.IMDBId),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ItemColumn.TMDBId, ItemColumn.TMDBId),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ItemColumn.TVDBId, ItemColumn.TVDBId),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ItemColumn.Genres, ItemColumn.Genres),
+		fmt.Sprintf(`%s = %s`, ItemColumn.UpdatedAt, db.CurrentTimestamp),
+	}, ", "),
+)
+
+func UpsertItems(tx db.Executor, items []JellyfinItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	for cItems := range slices.Chunk(items, 500) {
+		count := len(cItems)
+
+		query := query_upsert_items_before_values +
+			util.RepeatJoin(query_upsert_items_values_placholder, count, ",") +
+			query_upsert_items_after_values
+
+		columnCount := len(ItemColumns) - 1
+		args := make([]any, count*columnCount)
+		idMaps := make([]meta.IdMap, 0, count)
+		for i, item := range cItems {
+			args[i*columnCount+0] = item.Id
+			args[i*columnCount+1] = item.Type
+			args[i*columnCount+2] = item.Name
+			args[i*columnCount+3] = item.Overview
+			args[i*columnCount+4] = item.Year
+			args[i*columnCount+5] = item.Runtime
+			args[i*columnCount+6] = item.Poster
+			args[i*columnCount+7] = item.Background
+			args[i*columnCount+8] = item.IMDBId
+			args[i*columnCount+9] = item.TMDBId
+			args[i*columnCount+10] = item.TVDBId
+			args[i*columnCount+11] = item.Genres
+
+			if idMap := item.GetIdMap(); idMap != nil {
+				idMaps = append(idMaps, *idMap)
+			}
+		}
+
+		_, err := tx.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+
+		util.LogError(log, meta.SetIdMapsInTrx(tx, idMaps, meta.IdProviderIMDB), "failed to set id maps")
+	}
+
+	return nil
+}
+
+var query_set_list_item_before_values = fmt.Sprintf(
+	`INSERT INTO %s (%s) VALUES `,
+	ListItemTableName,
+	db.JoinColumnNames(
+		ListItemColumn.ListId,
+		ListItemColumn.ItemId,
+		ListItemColumn.Order,
+	),
+)
+var query_set_list_item_values_placeholder = `(?,?,?)`
+var query_set_list_item_after_values = fmt.Sprintf(
+	` ON CONFLICT (%s,%s) DO UPDATE SET "%s" = EXCLUDED."%s"`,
+	ListItemColumn.ListId,
+	ListItemColumn.ItemId,
+	ListItemColumn.Order,
+	ListItemColumn.Order,
+)
+var query_cleanup_list_item = fmt.Sprintf(
+	`DELETE FROM %s WHERE %s = ?`,
+	ListItemTableName,
+	ListItemColumn.ListId,
+)
+
+func setListItems(tx db.Executor, listId string, items []JellyfinItem) error {
+	if _, err := tx.Exec(query_cleanup_list_item, listId); err != nil {
+		return err
+	}
+
+	count := len(items)
+	if count == 0 {
+		return nil
+	}
+
+	for cItems := range slices.Chunk(items, 500) {
+		count := len(cItems)
+		query := query_set_list_item_before_values +
+			util.RepeatJoin(query_set_list_item_values_placeholder, count, ",") +
+			query_set_list_item_after_values
+		args := make([]any, count*3)
+		for i, item := range cItems {
+			args[i*3+0] = listId
+			args[i*3+1] = item.Id
+			args[i*3+2] = item.Order
+		}
+
+		if _, err := tx.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
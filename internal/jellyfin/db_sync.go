@@ -0,0 +1,252 @@
+package jellyfin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/cache"
+	"github.com/MunifTanjim/stremthru/internal/db"
+)
+
+var listCache = cache.NewCache[JellyfinList](&cache.CacheConfig{
+	Lifetime:      6 * time.Hour,
+	Name:          "jellyfin:list",
+	LocalCapacity: 1024,
+})
+
+var serverInfoCache = cache.NewCache[SystemInfo](&cache.CacheConfig{
+	Lifetime:      12 * time.Hour,
+	Name:          "jellyfin:server-info",
+	LocalCapacity: 256,
+})
+
+// getCredentialScope returns the scope for the lists fetched with the
+// credential, so that the lists are not shared across the users.
+func getCredentialScope(serverURL, apiKey string) string {
+	hash := sha256.Sum256([]byte(strings.TrimSuffix(serverURL, "/") + ":" + apiKey))
+	return hex.EncodeToString(hash[:16])
+}
+
+func getListCacheKey(scope string, l *JellyfinList) string {
+	return getScopedId(scope, l.Id)
+}
+
+func GetServerInfo(serverURL, apiKey string) (*SystemInfo, error) {
+	cacheKey := serverURL + ":" + apiKey
+	info := SystemInfo{}
+	if serverInfoCache.Get(cacheKey, &info) {
+		return &info, nil
+	}
+
+	client, err := NewAPIClient(&APIClientConfig{
+		BaseURL: serverURL,
+		APIKey:  apiKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.FetchSystemInfo(&FetchSystemInfoParams{})
+	if err != nil {
+		return nil, err
+	}
+	info = res.Data
+	if info.Id == "" {
+		return nil, errors.New("failed to resolve server id")
+	}
+	serverInfoCache.Add(cacheKey, info)
+	return &info, nil
+}
+
+func toItemType(kind ItemKind) JellyfinItemType {
+	switch kind {
+	case ItemKindMovie:
+		return ItemTypeMovie
+	case ItemKindSeries:
+		return ItemTypeSeries
+	default:
+		return ""
+	}
+}
+
+var syncListMutex sync.Mutex
+
+func syncList(l *JellyfinList, serverURL, apiKey string) error {
+	serverId, itemId := l.GetServerId(), l.GetItemId()
+	if serverId == "" || itemId == "" {
+		return errors.New("id must be provided")
+	}
+	if serverURL == "" {
+		return errors.New("server url must be provided")
+	}
+
+	info, err := GetServerInfo(serverURL, apiKey)
+	if err != nil {
+		return err
+	}
+	if info.Id != serverId {
+		return errors.New("server id mismatch")
+	}
+
+	syncListMutex.Lock()
+	defer syncListMutex.Unlock()
+
+	client, err := NewAPIClient(&APIClientConfig{
+		BaseURL: serverURL,
+		APIKey:  apiKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	scope := getCredentialScope(serverURL, apiKey)
+
+	log.Debug("fetching list by id", "id", l.Id)
+	res, err := client.FetchItems(&FetchItemsParams{
+		Ids: []string{itemId},
+	})
+	if err != nil {
+		return err
+	}
+	if len(res.Data.Items) == 0 {
+		return errors.New("list not found")
+	}
+	list := &res.Data.Items[0]
+	if list.ServerId != serverId {
+		return errors.New("server id mismatch")
+	}
+
+	switch list.Type {
+	case ItemKindBoxSet:
+		l.Kind = ListKindCollection
+	case ItemKindPlaylist:
+		l.Kind = ListKindPlaylist
+	default:
+		return errors.New("unsupported list type: " + string(list.Type))
+	}
+	l.ServerURL = serverURL
+	l.Name = list.Name
+	l.Overview = list.Overview
+	l.Items = nil
+
+	log.Debug("fetching list items", "id", l.Id)
+	limit := 500
+	startIndex := 0
+	hasMore := true
+	for hasMore {
+		var res ItemsResult
+		switch l.Kind {
+		case ListKindCollection:
+			r, err := client.FetchItems(&FetchItemsParams{
+				ParentId:   itemId,
+				StartIndex: startIndex,
+				Limit:      limit,
+			})
+			if err != nil {
+				return err
+			}
+			res = r.Data
+		case ListKindPlaylist:
+			r, err := client.FetchPlaylistItems(&FetchPlaylistItemsParams{
+				PlaylistId: itemId,
+				StartIndex: startIndex,
+				Limit:      limit,
+			})
+			if err != nil {
+				return err
+			}
+			res = r.Data
+		}
+
+		for i := range res.Items {
+			item := &res.Items[i]
+			itemType := toItemType(item.Type)
+			if itemType == "" {
+				continue
+			}
+			l.Items = append(l.Items, JellyfinItem{
+				Id:         getScopedId(scope, NewListId(serverId, item.Id)),
+				Type:       itemType,
+				Name:       item.Name,
+				Overview:   item.Overview,
+				Year:       item.ProductionYear,
+				Runtime:    item.GetRuntime(),
+				Poster:     client.GetImageURL(item.Id, "Primary", item.ImageTags["Primary"]),
+				Background: client.GetImageURL(item.Id, "Backdrop", firstOrEmpty(item.BackdropImageTags)),
+				IMDBId:     item.ProviderIds.IMDB,
+				TMDBId:     item.ProviderIds.TMDB,
+				TVDBId:     item.ProviderIds.TVDB,
+				Genres:     item.Genres,
+				UpdatedAt:  db.Timestamp{Time: time.Now()},
+
+				Order: startIndex + i,
+			})
+		}
+
+		startIndex += len(res.Items)
+		hasMore = len(res.Items) == limit && startIndex < res.TotalRecordCount
+	}
+
+	if err := UpsertList(scope, l); err != nil {
+		return err
+	}
+
+	if err := listCache.Add(getListCacheKey(scope, l), *l); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func firstOrEmpty(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (l *JellyfinList) Fetch(serverURL, apiKey string) error {
+	if l.GetServerId() == "" || l.GetItemId() == "" {
+		return errors.New("id must be provided")
+	}
+
+	isMissing := false
+
+	scope := getCredentialScope(serverURL, apiKey)
+	listCacheKey := getListCacheKey(scope, l)
+	var cachedL JellyfinList
+	if !listCache.Get(listCacheKey, &cachedL) {
+		if list, err := GetListById(scope, l.Id); err != nil {
+			return err
+		} else if list == nil {
+			isMissing = true
+		} else {
+			*l = *list
+			log.Debug("found list by id", "id", l.Id, "is_stale", l.IsStale())
+			listCache.Add(listCacheKey, *l)
+		}
+	} else {
+		*l = cachedL
+	}
+
+	if !isMissing {
+		if l.IsStale() {
+			staleList := *l
+			go func() {
+				if err := syncList(&staleList, serverURL, apiKey); err != nil {
+					log.Error("failed to sync stale list", "id", l.Id, "error", err)
+				}
+			}()
+		}
+		return nil
+	}
+
+	if err := syncList(l, serverURL, apiKey); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package jellyfin
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/MunifTanjim/stremthru/internal/request"
+)
+
+type ItemKind string
+
+const (
+	ItemKindBoxSet   ItemKind = "BoxSet"
+	ItemKindPlaylist ItemKind = "Playlist"
+	ItemKindMovie    ItemKind = "Movie"
+	ItemKindSeries   ItemKind = "Series"
+)
+
+type ProviderIds struct {
+	IMDB string `json:"Imdb,omitempty"`
+	TMDB string `json:"Tmdb,omitempty"`
+	TVDB string `json:"Tvdb,omitempty"`
+}
+
+type BaseItem struct {
+	Id                string            `json:"Id"`
+	ServerId          string            `json:"ServerId"`
+	Name              string            `json:"Name"`
+	Type              ItemKind          `json:"Type"`
+	Overview          string            `json:"Overview,omitempty"`
+	ProductionYear    int               `json:"ProductionYear,omitempty"`
+	RunTimeTicks      int64             `json:"RunTimeTicks,omitempty"`
+	Genres            []string          `json:"Genres,omitempty"`
+	ProviderIds       ProviderIds       `json:"ProviderIds"`
+	ImageTags         map[string]string `json:"ImageTags,omitempty"`
+	BackdropImageTags []string          `json:"BackdropImageTags,omitempty"`
+	ChildCount        int               `json:"ChildCount,omitempty"`
+}
+
+func (i *BaseItem) GetRuntime() int {
+	// 1 tick = 100 nanoseconds
+	return int(i.RunTimeTicks / 10_000_000 / 60)
+}
+
+type SystemInfo struct {
+	Id                     string `json:"Id"`
+	ServerName             string `json:"ServerName"`
+	Version                string `json:"Version"`
+	LocalAddress           string `json:"LocalAddress"`
+	ProductName            string `json:"ProductName"`
+	StartupWizardCompleted bool   `json:"StartupWizardCompleted"`
+}
+
+type fetchSystemInfoData struct {
+	ResponseError
+	SystemInfo
+}
+
+type FetchSystemInfoParams struct {
+	Ctx
+}
+
+func (c APIClient) FetchSystemInfo(params *FetchSystemInfoParams) (request.APIResponse[SystemInfo], error) {
+	response := fetchSystemInfoData{}
+	res, err := c.Request("GET", "/System/Info", params, &response)
+	return request.NewAPIResponse(res, response.SystemInfo), err
+}
+
+type ItemsResult struct {
+	Items            []BaseItem `json:"Items"`
+	TotalRecordCount int        `json:"TotalRecordCount"`
+	StartIndex       int        `json:"StartIndex"`
+}
+
+type fetchItemsData struct {
+	ResponseError
+	ItemsResult
+}
+
+var itemFields = strings.Join([]string{
+	"Overview",
+	"Genres",
+	"ProviderIds",
+	"ProductionYear",
+	"ChildCount",
+}, ",")
+
+type FetchItemsParams struct {
+	Ctx
+	Ids        []string
+	ParentId   string
+	StartIndex int
+	Limit      int
+}
+
+func (c APIClient) FetchItems(params *FetchItemsParams) (request.APIResponse[ItemsResult], error) {
+	query := url.Values{}
+	query.Set("fields", itemFields)
+	query.Set("enableImageTypes", "Primary,Backdrop")
+	if len(params.Ids) > 0 {
+		query.Set("ids", strings.Join(params.Ids, ","))
+	}
+	if params.ParentId != "" {
+		query.Set("parentId", params.ParentId)
+	}
+	if params.StartIndex > 0 {
+		query.Set("startIndex", strconv.Itoa(params.StartIndex))
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+	params.Query = &query
+
+	response := fetchItemsData{}
+	res, err := c.Request("GET", "/Items", params, &response)
+	return request.NewAPIResponse(res, response.ItemsResult), err
+}
+
+type FetchPlaylistItemsParams struct {
+	Ctx
+	PlaylistId string
+	StartIndex int
+	Limit      int
+}
+
+func (c APIClient) FetchPlaylistItems(params *FetchPlaylistItemsParams) (request.APIResponse[ItemsResult], error) {
+	query := url.Values{}
+	query.Set("fields", itemFields)
+	query.Set("enableImageTypes", "Primary,Backdrop")
+	if params.StartIndex > 0 {
+		query.Set("startIndex", strconv.Itoa(params.StartIndex))
+	}
+	if params.Limit > 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+	params.Query = &query
+
+	response := fetchItemsData{}
+	res, err := c.Request("GET", "/Playlists/"+params.PlaylistId+"/Items", params, &response)
+	return request.NewAPIResponse(res, response.ItemsResult), err
+}
+
+func (c APIClient) GetImageURL(itemId, imageType, tag string) string {
+	if tag == "" {
+		return ""
+	}
+	u := c.BaseURL.JoinPath("/Items/" + itemId + "/Images/" + imageType)
+	u.RawQuery = "tag=" + url.QueryEscape(tag)
+	return u.String()
+}
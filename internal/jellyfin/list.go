@@ -0,0 +1,39 @@
+package jellyfin
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// ParseListURL extracts item id and server id from a Jellyfin web UI URL,
+// e.g. `{server}/web/#/details?id={item_id}&serverId={server_id}`.
+func ParseListURL(listUrl *url.URL) (itemId, serverId string, err error) {
+	fragment := strings.TrimPrefix(listUrl.Fragment, "!")
+	if fragment == "" {
+		return "", "", errors.New("missing url fragment")
+	}
+	fragmentUrl, err := url.Parse(fragment)
+	if err != nil {
+		return "", "", err
+	}
+	if strings.Trim(fragmentUrl.Path, "/") != "details" {
+		return "", "", errors.New("unsupported url")
+	}
+	query := fragmentUrl.Query()
+	itemId = query.Get("id")
+	serverId = query.Get("serverId")
+	if itemId == "" {
+		return "", "", errors.New("missing item id")
+	}
+	return itemId, serverId, nil
+}
+
+// IsServerURL checks if listUrl belongs to the server at serverURL.
+func IsServerURL(listUrl *url.URL, serverURL string) bool {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, listUrl.Host)
+}
@@ -0,0 +1,7 @@
+package jellyfin
+
+import (
+	"github.com/MunifTanjim/stremthru/internal/logger"
+)
+
+var log = logger.Scoped("jellyfin")
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/MunifTanjim/stremthru/internal/cache"
 	"github.com/MunifTanjim/stremthru/internal/db"
 	"github.com/MunifTanjim/stremthru/internal/util"
 )
@@ -29,10 +30,19 @@ type ParsedKV[V any] struct {
 	UpdatedAt time.Time
 }
 
+type KVStoreBackend string
+
+const (
+	KVStoreBackendSQL KVStoreBackend = "sql"
+	// falls back to sql, if redis is not configured
+	KVStoreBackendRedis KVStoreBackend = "redis"
+)
+
 type KVStoreConfig struct {
 	Type      string
 	GetKey    func(key string) string
 	ExpiresIn time.Duration
+	Backend   KVStoreBackend // default: sql
 }
 
 type KVStore[V any] interface {
@@ -194,6 +204,11 @@ func NewKVStore[V any](config *KVStoreConfig) KVStore[V] {
 	if !strings.Contains(outputKey, inputKey) {
 		panic("GetKey output does not contain input")
 	}
+	if config.Backend == KVStoreBackendRedis {
+		if redis := cache.GetRedisClient(); redis != nil {
+			return newRedisKVStore[V](redis, config)
+		}
+	}
 	return &SQLKVStore[V]{
 		t:         strings.ToLower(config.Type),
 		getKey:    config.GetKey,
@@ -0,0 +1,235 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	r "github.com/redis/go-redis/v9"
+)
+
+// RedisKVStore keeps each entry in a hash, with native ttl. The keys of a
+// type are tracked in:
+//   - `kv:<type>:keys` set, for Count
+//   - `kv:<type>:cat` sorted set by created at, for GetLast
+//   - `kv:<type>:eat` sorted set by expires at, to prune the expired keys
+type RedisKVStore[V any] struct {
+	redis     *r.Client
+	t         string
+	getKey    func(key string) string
+	expiresIn time.Duration
+}
+
+func (kv *RedisKVStore[V]) keysKey() string {
+	return "kv:" + kv.t + ":keys"
+}
+
+func (kv *RedisKVStore[V]) createdAtKey() string {
+	return "kv:" + kv.t + ":cat"
+}
+
+func (kv *RedisKVStore[V]) expiresAtKey() string {
+	return "kv:" + kv.t + ":eat"
+}
+
+func (kv *RedisKVStore[V]) valueKey(storedKey string) string {
+	return "kv:" + kv.t + ":key:" + storedKey
+}
+
+// prune removes the expired keys from the sets, the values are already
+// expired by redis.
+func (kv *RedisKVStore[V]) prune(ctx context.Context) error {
+	expiredKeys, err := kv.redis.ZRangeByScore(ctx, kv.expiresAtKey(), &r.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(time.Now().UnixMilli(), 10),
+	}).Result()
+	if err != nil || len(expiredKeys) == 0 {
+		return err
+	}
+	return kv.untrack(ctx, expiredKeys...)
+}
+
+func (kv *RedisKVStore[V]) untrack(ctx context.Context, storedKeys ...string) error {
+	members := make([]any, len(storedKeys))
+	for i, key := range storedKeys {
+		members[i] = key
+	}
+	_, err := kv.redis.TxPipelined(ctx, func(pipe r.Pipeliner) error {
+		pipe.SRem(ctx, kv.keysKey(), members...)
+		pipe.ZRem(ctx, kv.createdAtKey(), members...)
+		pipe.ZRem(ctx, kv.expiresAtKey(), members...)
+		return nil
+	})
+	return err
+}
+
+func parseRedisKV[V any](storedKey string, fields map[string]string) (*ParsedKV[V], error) {
+	pkv := ParsedKV[V]{Key: storedKey}
+	if cat, err := strconv.ParseInt(fields["cat"], 10, 64); err == nil {
+		pkv.CreatedAt = time.UnixMilli(cat)
+	}
+	if uat, err := strconv.ParseInt(fields["uat"], 10, 64); err == nil {
+		pkv.UpdatedAt = time.UnixMilli(uat)
+	}
+	if err := json.Unmarshal([]byte(fields["v"]), &pkv.Value); err != nil {
+		return nil, err
+	}
+	return &pkv, nil
+}
+
+func (kv *RedisKVStore[V]) GetValue(key string, value *V) error {
+	val, err := kv.redis.HGet(context.Background(), kv.valueKey(kv.getKey(key)), "v").Result()
+	if err != nil {
+		if errors.Is(err, r.Nil) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal([]byte(val), &value)
+}
+
+func (kv *RedisKVStore[V]) GetLast() (*ParsedKV[V], error) {
+	ctx := context.Background()
+	if err := kv.prune(ctx); err != nil {
+		return nil, err
+	}
+	for {
+		keys, err := kv.redis.ZRevRange(ctx, kv.createdAtKey(), 0, 0).Result()
+		if err != nil || len(keys) == 0 {
+			return nil, err
+		}
+		fields, err := kv.redis.HGetAll(ctx, kv.valueKey(keys[0])).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			if err := kv.untrack(ctx, keys[0]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return parseRedisKV[V](keys[0], fields)
+	}
+}
+
+func (kv *RedisKVStore[V]) List() ([]ParsedKV[V], error) {
+	if kv.t == "" {
+		return nil, errors.New("missing kv type value")
+	}
+	ctx := context.Background()
+	if err := kv.prune(ctx); err != nil {
+		return nil, err
+	}
+	keys, err := kv.redis.SMembers(ctx, kv.keysKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	cmds := make([]*r.MapStringStringCmd, len(keys))
+	if _, err := kv.redis.Pipelined(ctx, func(pipe r.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.HGetAll(ctx, kv.valueKey(key))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	keysToUntrack := []string{}
+	vs := []ParsedKV[V]{}
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		if len(fields) == 0 {
+			keysToUntrack = append(keysToUntrack, keys[i])
+			continue
+		}
+		pkv, err := parseRedisKV[V](keys[i], fields)
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, *pkv)
+	}
+
+	if len(keysToUntrack) > 0 {
+		if err := kv.untrack(ctx, keysToUntrack...); err != nil {
+			return vs, err
+		}
+	}
+
+	return vs, nil
+}
+
+func (kv *RedisKVStore[V]) Count() (int, error) {
+	if kv.t == "" {
+		return -1, errors.New("missing kv type value")
+	}
+	ctx := context.Background()
+	if err := kv.prune(ctx); err != nil {
+		return -1, err
+	}
+	count, err := kv.redis.SCard(ctx, kv.keysKey()).Result()
+	if err != nil {
+		return -1, err
+	}
+	return int(count), nil
+}
+
+func (kv *RedisKVStore[V]) Set(key string, value V) error {
+	val, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	storedKey := kv.getKey(key)
+	valueKey := kv.valueKey(storedKey)
+	now := time.Now()
+	_, err = kv.redis.TxPipelined(ctx, func(pipe r.Pipeliner) error {
+		pipe.HSet(ctx, valueKey, "v", string(val), "uat", now.UnixMilli())
+		pipe.HSetNX(ctx, valueKey, "cat", now.UnixMilli())
+		pipe.SAdd(ctx, kv.keysKey(), storedKey)
+		pipe.ZAddNX(ctx, kv.createdAtKey(), r.Z{Score: float64(now.UnixMilli()), Member: storedKey})
+		if kv.expiresIn != 0 {
+			pipe.PExpire(ctx, valueKey, kv.expiresIn)
+			pipe.ZAdd(ctx, kv.expiresAtKey(), r.Z{Score: float64(now.Add(kv.expiresIn).UnixMilli()), Member: storedKey})
+		} else {
+			pipe.Persist(ctx, valueKey)
+			pipe.ZRem(ctx, kv.expiresAtKey(), storedKey)
+		}
+		return nil
+	})
+	return err
+}
+
+func (kv *RedisKVStore[V]) Del(key string) error {
+	ctx := context.Background()
+	storedKey := kv.getKey(key)
+	_, err := kv.redis.TxPipelined(ctx, func(pipe r.Pipeliner) error {
+		pipe.Del(ctx, kv.valueKey(storedKey))
+		pipe.SRem(ctx, kv.keysKey(), storedKey)
+		pipe.ZRem(ctx, kv.createdAtKey(), storedKey)
+		pipe.ZRem(ctx, kv.expiresAtKey(), storedKey)
+		return nil
+	})
+	return err
+}
+
+func (kv *RedisKVStore[V]) WithScope(scope string) KVStore[V] {
+	if scope == "" {
+		return kv
+	}
+	skv := *kv
+	skv.t = skv.t + ":" + strings.ToLower(scope)
+	return &skv
+}
+
+func newRedisKVStore[V any](redis *r.Client, config *KVStoreConfig) KVStore[V] {
+	return &RedisKVStore[V]{
+		redis:     redis,
+		t:         strings.ToLower(config.Type),
+		getKey:    config.GetKey,
+		expiresIn: config.ExpiresIn,
+	}
+}
@@ -0,0 +1,186 @@
+package kv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	r "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testValue struct {
+	N int `json:"n"`
+}
+
+func newTestRedisKVStore(t *testing.T, expiresIn time.Duration) (*miniredis.Miniredis, *RedisKVStore[testValue]) {
+	mr := miniredis.RunT(t)
+	redis := r.NewClient(&r.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		redis.Close()
+	})
+	kv := newRedisKVStore[testValue](redis, &KVStoreConfig{
+		Type:      "Test",
+		GetKey:    func(key string) string { return key },
+		ExpiresIn: expiresIn,
+	}).(*RedisKVStore[testValue])
+	return mr, kv
+}
+
+// expire moves the real and the redis clock past the ttl, the sets are
+// pruned by the real clock.
+func expire(mr *miniredis.Miniredis, d time.Duration) {
+	time.Sleep(d)
+	mr.FastForward(d)
+}
+
+func zMembers(t *testing.T, mr *miniredis.Miniredis, key string) []string {
+	if !mr.Exists(key) {
+		return nil
+	}
+	members, err := mr.ZMembers(key)
+	require.NoError(t, err)
+	return members
+}
+
+func sMembers(t *testing.T, mr *miniredis.Miniredis, key string) []string {
+	if !mr.Exists(key) {
+		return nil
+	}
+	members, err := mr.SMembers(key)
+	require.NoError(t, err)
+	return members
+}
+
+func TestRedisKVStoreSet(t *testing.T) {
+	mr, kv := newTestRedisKVStore(t, time.Minute)
+
+	require.NoError(t, kv.Set("a", testValue{N: 1}))
+	assert.Equal(t, time.Minute, mr.TTL("kv:test:key:a"))
+	assert.Equal(t, []string{"a"}, sMembers(t, mr, "kv:test:keys"))
+	assert.Equal(t, []string{"a"}, zMembers(t, mr, "kv:test:cat"))
+	assert.Equal(t, []string{"a"}, zMembers(t, mr, "kv:test:eat"))
+
+	cat := mr.HGet("kv:test:key:a", "cat")
+	mr.FastForward(30 * time.Second)
+	time.Sleep(2 * time.Millisecond)
+	require.NoError(t, kv.Set("a", testValue{N: 2}))
+	assert.Equal(t, time.Minute, mr.TTL("kv:test:key:a"))
+	assert.Equal(t, cat, mr.HGet("kv:test:key:a", "cat"))
+	assert.NotEqual(t, cat, mr.HGet("kv:test:key:a", "uat"))
+
+	value := testValue{}
+	require.NoError(t, kv.GetValue("a", &value))
+	assert.Equal(t, 2, value.N)
+
+	persistent := *kv
+	persistent.expiresIn = 0
+	require.NoError(t, persistent.Set("a", testValue{N: 3}))
+	assert.Equal(t, time.Duration(0), mr.TTL("kv:test:key:a"))
+	assert.Empty(t, zMembers(t, mr, "kv:test:eat"))
+
+	require.NoError(t, kv.Del("a"))
+	assert.False(t, mr.Exists("kv:test:key:a"))
+	assert.Empty(t, sMembers(t, mr, "kv:test:keys"))
+	assert.Empty(t, zMembers(t, mr, "kv:test:cat"))
+
+	value = testValue{}
+	require.NoError(t, kv.GetValue("a", &value))
+	assert.Equal(t, 0, value.N)
+}
+
+func TestRedisKVStorePrune(t *testing.T) {
+	mr, kv := newTestRedisKVStore(t, 50*time.Millisecond)
+	persistent := *kv
+	persistent.expiresIn = 0
+
+	require.NoError(t, kv.Set("a", testValue{N: 1}))
+	require.NoError(t, persistent.Set("b", testValue{N: 2}))
+	require.NoError(t, persistent.Set("c", testValue{N: 3}))
+
+	count, err := kv.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	// expired by ttl, pruned with eat
+	expire(mr, 100*time.Millisecond)
+	assert.False(t, mr.Exists("kv:test:key:a"))
+	count, err = kv.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.ElementsMatch(t, []string{"b", "c"}, sMembers(t, mr, "kv:test:keys"))
+	assert.ElementsMatch(t, []string{"b", "c"}, zMembers(t, mr, "kv:test:cat"))
+	assert.Empty(t, zMembers(t, mr, "kv:test:eat"))
+
+	// gone without eat, untracked on read
+	mr.Del("kv:test:key:c")
+	vs, err := kv.List()
+	require.NoError(t, err)
+	require.Len(t, vs, 1)
+	assert.Equal(t, "b", vs[0].Key)
+	assert.Equal(t, 2, vs[0].Value.N)
+	assert.Equal(t, []string{"b"}, sMembers(t, mr, "kv:test:keys"))
+	assert.Equal(t, []string{"b"}, zMembers(t, mr, "kv:test:cat"))
+}
+
+func TestRedisKVStoreGetLast(t *testing.T) {
+	mr, kv := newTestRedisKVStore(t, 50*time.Millisecond)
+	persistent := *kv
+	persistent.expiresIn = 0
+
+	last, err := kv.GetLast()
+	require.NoError(t, err)
+	assert.Nil(t, last)
+
+	require.NoError(t, persistent.Set("a", testValue{N: 1}))
+	time.Sleep(2 * time.Millisecond)
+	require.NoError(t, persistent.Set("b", testValue{N: 2}))
+	time.Sleep(2 * time.Millisecond)
+	require.NoError(t, kv.Set("c", testValue{N: 3}))
+
+	last, err = kv.GetLast()
+	require.NoError(t, err)
+	require.NotNil(t, last)
+	assert.Equal(t, "c", last.Key)
+	assert.Equal(t, 3, last.Value.N)
+
+	expire(mr, 100*time.Millisecond)
+	last, err = kv.GetLast()
+	require.NoError(t, err)
+	require.NotNil(t, last)
+	assert.Equal(t, "b", last.Key)
+
+	mr.Del("kv:test:key:b")
+	last, err = kv.GetLast()
+	require.NoError(t, err)
+	require.NotNil(t, last)
+	assert.Equal(t, "a", last.Key)
+	assert.Equal(t, []string{"a"}, zMembers(t, mr, "kv:test:cat"))
+}
+
+func TestRedisKVStoreWithScope(t *testing.T) {
+	mr, kv := newTestRedisKVStore(t, 0)
+
+	assert.Same(t, kv, kv.WithScope(""))
+
+	scoped := kv.WithScope("Alice")
+	require.NoError(t, kv.Set("a", testValue{N: 1}))
+	require.NoError(t, scoped.Set("a", testValue{N: 2}))
+	assert.True(t, mr.Exists("kv:test:alice:key:a"))
+	assert.Equal(t, []string{"a"}, sMembers(t, mr, "kv:test:alice:keys"))
+
+	value := testValue{}
+	require.NoError(t, scoped.GetValue("a", &value))
+	assert.Equal(t, 2, value.N)
+	require.NoError(t, kv.GetValue("a", &value))
+	assert.Equal(t, 1, value.N)
+
+	require.NoError(t, scoped.Del("a"))
+	count, err := scoped.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+	count, err = kv.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
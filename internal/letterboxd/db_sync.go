@@ -20,10 +20,6 @@ const MAX_LIST_ITEM_COUNT = 5000
 var LetterboxdEnabled = config.Integration.Letterboxd.IsEnabled()
 var LetterboxdPiggybacked = config.Integration.Letterboxd.IsPiggybacked()
 
-var Peer = peer.NewAPIClient(&peer.APIClientConfig{
-	BaseURL: config.PeerURL,
-})
-
 var listCache = cache.NewCache[LetterboxdList](&cache.CacheConfig{
 	Lifetime:      6 * time.Hour,
 	Name:          "letterboxd:list",
@@ -66,11 +62,11 @@ func syncList(l *LetterboxdList) error {
 		var res request.APIResponse[meta_type.List]
 		var err error
 		if isUserWatchlist {
-			res, err = Peer.FetchLetterboxdUserWatchlist(&peer.FetchLetterboxdUserWatchlistParams{
+			res, err = peer.Upstreams.FetchLetterboxdUserWatchlist(&peer.FetchLetterboxdUserWatchlistParams{
 				UserId: l.UserId,
 			})
 		} else {
-			res, err = Peer.FetchLetterboxdList(&peer.FetchLetterboxdListParams{
+			res, err = peer.Upstreams.FetchLetterboxdList(&peer.FetchLetterboxdListParams{
 				ListId: l.Id,
 			})
 		}
@@ -0,0 +1,213 @@
+package lzstring
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+//
+// Compress to uri encoded lz-string, port of `compressToEncodedURIComponent`
+// https://github.com/pieroxy/lz-string/
+//
+
+const keyStrUriSafeChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+-$"
+
+const bitsPerChar = 6
+
+type compressData struct {
+	out      *bufio.Writer
+	err      error
+	val      int
+	position int
+}
+
+func (data *compressData) writeChar(val int) {
+	if err := data.out.WriteByte(keyStrUriSafeChars[val]); err != nil && data.err == nil {
+		data.err = err
+	}
+}
+
+func (data *compressData) writeBit(bit int) {
+	data.val = (data.val << 1) | bit
+	if data.position == bitsPerChar-1 {
+		data.position = 0
+		data.writeChar(data.val)
+		data.val = 0
+	} else {
+		data.position++
+	}
+}
+
+// writeBits writes the lowest nb bits of value, least significant first.
+func (data *compressData) writeBits(nb int, value int) {
+	for range nb {
+		data.writeBit(value & 1)
+		value = value >> 1
+	}
+}
+
+type compressContext struct {
+	data               compressData
+	dictionary         map[string]int
+	dictionaryToCreate map[string]bool
+	dictSize           int
+	enlargeIn          float64
+	numBits            int
+}
+
+func (ctx *compressContext) decrementEnlargeIn() {
+	ctx.enlargeIn--
+	if ctx.enlargeIn == 0 {
+		ctx.enlargeIn = math.Pow(2, float64(ctx.numBits))
+		ctx.numBits++
+	}
+}
+
+// the keys are utf-16 code units, 2 bytes each
+func firstCodeUnit(w string) int {
+	return int(w[0])<<8 | int(w[1])
+}
+
+func (ctx *compressContext) writeW(w string) {
+	if ctx.dictionaryToCreate[w] {
+		value := firstCodeUnit(w)
+		if value < 256 {
+			ctx.data.writeBits(ctx.numBits, 0)
+			ctx.data.writeBits(8, value)
+		} else {
+			ctx.data.writeBits(ctx.numBits, 1)
+			ctx.data.writeBits(16, value)
+		}
+		ctx.decrementEnlargeIn()
+		delete(ctx.dictionaryToCreate, w)
+	} else {
+		ctx.data.writeBits(ctx.numBits, ctx.dictionary[w])
+	}
+	ctx.decrementEnlargeIn()
+}
+
+// Writer compresses the text written to it, and writes the uri encoded
+// lz-string to the underlying writer as it goes. The output is complete
+// only after Close.
+type Writer struct {
+	ctx     compressContext
+	w       string
+	pending []byte // incomplete utf-8 sequence
+	closed  bool
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		ctx: compressContext{
+			data:               compressData{out: bufio.NewWriter(w)},
+			dictionary:         map[string]int{},
+			dictionaryToCreate: map[string]bool{},
+			dictSize:           3,
+			enlargeIn:          2,
+			numBits:            2,
+		},
+	}
+}
+
+func (z *Writer) writeUnit(unit uint16) {
+	ctx := &z.ctx
+
+	c := string([]byte{byte(unit >> 8), byte(unit)})
+	if _, ok := ctx.dictionary[c]; !ok {
+		ctx.dictionary[c] = ctx.dictSize
+		ctx.dictSize++
+		ctx.dictionaryToCreate[c] = true
+	}
+
+	wc := z.w + c
+	if _, ok := ctx.dictionary[wc]; ok {
+		z.w = wc
+		return
+	}
+
+	ctx.writeW(z.w)
+	ctx.dictionary[wc] = ctx.dictSize
+	ctx.dictSize++
+	z.w = c
+}
+
+func (z *Writer) writeRune(r rune) {
+	for _, unit := range utf16.AppendRune(nil, r) {
+		z.writeUnit(unit)
+	}
+}
+
+func (z *Writer) Write(p []byte) (int, error) {
+	if z.closed {
+		return 0, errors.New("lzstring: write to closed writer")
+	}
+
+	buf := p
+	if len(z.pending) > 0 {
+		buf = append(z.pending, p...)
+		z.pending = nil
+	}
+	for len(buf) > 0 {
+		if !utf8.FullRune(buf) {
+			z.pending = append([]byte{}, buf...)
+			break
+		}
+		r, size := utf8.DecodeRune(buf)
+		z.writeRune(r)
+		buf = buf[size:]
+	}
+	if z.ctx.data.err != nil {
+		return 0, z.ctx.data.err
+	}
+	return len(p), nil
+}
+
+func (z *Writer) Close() error {
+	if z.closed {
+		return nil
+	}
+	z.closed = true
+
+	ctx := &z.ctx
+
+	for len(z.pending) > 0 {
+		_, size := utf8.DecodeRune(z.pending)
+		z.writeRune(utf8.RuneError)
+		z.pending = z.pending[size:]
+	}
+
+	if z.w != "" {
+		ctx.writeW(z.w)
+	}
+
+	// end of stream
+	ctx.data.writeBits(ctx.numBits, 2)
+
+	// flush the last char
+	for {
+		ctx.data.val = ctx.data.val << 1
+		if ctx.data.position == bitsPerChar-1 {
+			ctx.data.writeChar(ctx.data.val)
+			break
+		}
+		ctx.data.position++
+	}
+
+	if ctx.data.err != nil {
+		return ctx.data.err
+	}
+	return ctx.data.out.Flush()
+}
+
+func CompressToEncodedUriComponent(input string) string {
+	var out strings.Builder
+	z := NewWriter(&out)
+	z.Write([]byte(input))
+	z.Close()
+	return out.String()
+}
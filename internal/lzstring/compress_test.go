@@ -0,0 +1,47 @@
+package lzstring
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressToEncodedUriComponent(t *testing.T) {
+	for _, input := range []string{
+		"a",
+		"hello world",
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		`[{"filename":"Movie.2024.1080p.WEB-DL.mkv","hash":"0123456789abcdef0123456789abcdef01234567","bytes":1234567890}]`,
+		"Amélie · Ōkami · 千と千尋の神隠し",
+		strings.Repeat("The quick brown fox jumps over the lazy dog. ", 100),
+	} {
+		t.Run(input[:min(len(input), 20)], func(t *testing.T) {
+			compressed := CompressToEncodedUriComponent(input)
+			decompressed, err := DecompressFromEncodedUriComponent(compressed)
+			assert.NoError(t, err)
+			assert.Equal(t, input, decompressed)
+		})
+	}
+
+	// from lz-string (javascript)
+	assert.Equal(t, "BYUwNmD2Q", CompressToEncodedUriComponent("hello"))
+}
+
+func TestWriter(t *testing.T) {
+	input := strings.Repeat("Amélie · Ōkami · 千と千尋の神隠し · ", 10)
+
+	var out strings.Builder
+	z := NewWriter(&out)
+	for i := range len(input) {
+		n, err := z.Write([]byte{input[i]})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+	}
+	assert.NoError(t, z.Close())
+
+	assert.Equal(t, CompressToEncodedUriComponent(input), out.String())
+	decompressed, err := DecompressFromEncodedUriComponent(out.String())
+	assert.NoError(t, err)
+	assert.Equal(t, input, decompressed)
+}
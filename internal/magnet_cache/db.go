@@ -11,6 +11,7 @@ import (
 	"github.com/MunifTanjim/stremthru/internal/db"
 	"github.com/MunifTanjim/stremthru/internal/logger"
 	"github.com/MunifTanjim/stremthru/internal/torrent_stream"
+	"github.com/MunifTanjim/stremthru/internal/util"
 	"github.com/MunifTanjim/stremthru/store"
 )
 
@@ -187,3 +188,37 @@ func BulkTouch(storeCode store.StoreCode, filesByHash map[string]torrent_stream.
 		}
 	}
 }
+
+func GetModifiedAtByHashes(storeCode store.StoreCode, hashes []string) (map[string]time.Time, error) {
+	byHash := map[string]time.Time{}
+	if len(hashes) == 0 {
+		return byHash, nil
+	}
+
+	args := make([]any, len(hashes)+1)
+	args[0] = storeCode
+	for i, hash := range hashes {
+		args[i+1] = hash
+	}
+
+	query := "SELECT hash, modified_at FROM " + TableName + " WHERE store = ? AND hash IN (" + util.RepeatJoin("?", len(hashes), ",") + ")"
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		hash := ""
+		var modifiedAt db.Timestamp
+		if err := rows.Scan(&hash, &modifiedAt); err != nil {
+			return nil, err
+		}
+		byHash[hash] = modifiedAt.Time
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return byHash, nil
+}
@@ -0,0 +1,399 @@
+package peer
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/core"
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/logger"
+	meta_type "github.com/MunifTanjim/stremthru/internal/meta/type"
+	"github.com/MunifTanjim/stremthru/internal/request"
+	"github.com/MunifTanjim/stremthru/store"
+)
+
+var log = logger.Scoped("peer")
+
+var ErrNoPeer = errors.New("no available peer")
+
+const (
+	peerFailureThreshold = 3
+	peerRetryBaseDelay   = 30 * time.Second
+	peerRetryMaxDelay    = 10 * time.Minute
+)
+
+type peerHealth struct {
+	mu           sync.Mutex
+	failures     int
+	lastErr      string
+	lastFailedAt time.Time
+	retryAfter   time.Time
+}
+
+type Peer struct {
+	*APIClient
+	URL      string
+	Weight   int
+	Roles    []config.PeerRole
+	HasToken bool
+	Trusted  bool
+	health   peerHealth
+}
+
+// IsHealthy is false after consecutive failures, until the retry delay has
+// passed. Then the next request decides.
+func (p *Peer) IsHealthy() bool {
+	p.health.mu.Lock()
+	defer p.health.mu.Unlock()
+
+	return p.health.failures < peerFailureThreshold || time.Now().After(p.health.retryAfter)
+}
+
+// isFailure ignores the errors the peer responded with on purpose, e.g.
+// not found.
+func isFailure(err error) bool {
+	var rerr *ResponseError
+	if errors.As(err, &rerr) {
+		return rerr.StatusCode == 0 || rerr.StatusCode == 429 || rerr.StatusCode >= 500
+	}
+	var cerr *core.Error
+	if errors.As(err, &cerr) {
+		return cerr.StatusCode == 0 || cerr.StatusCode == 429 || cerr.StatusCode >= 500
+	}
+	return true
+}
+
+func (p *Peer) report(err error) {
+	p.health.mu.Lock()
+	defer p.health.mu.Unlock()
+
+	if err == nil || !isFailure(err) {
+		p.health.failures = 0
+		return
+	}
+
+	p.health.failures++
+	p.health.lastErr = err.Error()
+	p.health.lastFailedAt = time.Now()
+	if p.health.failures >= peerFailureThreshold {
+		delay := float64(peerRetryBaseDelay) * math.Pow(2, float64(p.health.failures-peerFailureThreshold))
+		p.health.retryAfter = time.Now().Add(time.Duration(min(delay, float64(peerRetryMaxDelay))))
+		if p.health.failures == peerFailureThreshold {
+			log.Warn("peer is unhealthy", "url", p.URL, "error", err)
+		}
+	}
+}
+
+type PeerStatus struct {
+	URL          string            `json:"url"`
+	Weight       int               `json:"weight"`
+	Roles        []config.PeerRole `json:"roles"`
+	Healthy      bool              `json:"healthy"`
+	Failures     int               `json:"failures"`
+	LastError    string            `json:"last_error,omitempty"`
+	LastFailedAt *time.Time        `json:"last_failed_at,omitempty"`
+	RetryAfter   *time.Time        `json:"retry_after,omitempty"`
+}
+
+func (p *Peer) Status() PeerStatus {
+	status := PeerStatus{
+		URL:     p.URL,
+		Weight:  p.Weight,
+		Roles:   p.Roles,
+		Healthy: p.IsHealthy(),
+	}
+
+	p.health.mu.Lock()
+	defer p.health.mu.Unlock()
+
+	status.Failures = p.health.failures
+	status.LastError = p.health.lastErr
+	if !p.health.lastFailedAt.IsZero() {
+		lastFailedAt := p.health.lastFailedAt
+		status.LastFailedAt = &lastFailedAt
+	}
+	if !status.Healthy {
+		retryAfter := p.health.retryAfter
+		status.RetryAfter = &retryAfter
+	}
+	return status
+}
+
+// Federation spreads the requests across the peers, by role:
+//   - check_magnet: fan out, first positive answer per magnet wins
+//   - list_torrents: fan out, union of the results
+//   - push_torrents: fan out
+//   - letterboxd: fail over, in weighted random order
+//
+// When merging, the answer from the peer with higher weight wins.
+type Federation struct {
+	peers []*Peer
+}
+
+func NewFederation(confs []config.PeerConfig) *Federation {
+	f := &Federation{peers: make([]*Peer, 0, len(confs))}
+	for _, conf := range confs {
+		f.peers = append(f.peers, &Peer{
+			APIClient: NewAPIClient(&APIClientConfig{
+				BaseURL: conf.URL,
+				APIKey:  conf.AuthToken,
+			}),
+			URL:      conf.URL,
+			Weight:   conf.Weight,
+			Roles:    conf.Roles,
+			HasToken: conf.AuthToken != "",
+			Trusted:  conf.Trusted,
+		})
+	}
+	// stable, so that the order in config breaks the tie
+	slices.SortStableFunc(f.peers, func(a, b *Peer) int {
+		return b.Weight - a.Weight
+	})
+	return f
+}
+
+var Upstreams = NewFederation(config.Peers)
+
+func (f *Federation) filter(role config.PeerRole, withToken bool) []*Peer {
+	peers := []*Peer{}
+	for _, p := range f.peers {
+		if slices.Contains(p.Roles, role) && (!withToken || p.HasToken) {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+// HasRole reports if any peer is configured for the role, healthy or not.
+func (f *Federation) HasRole(role config.PeerRole, withToken bool) bool {
+	return len(f.filter(role, withToken)) > 0
+}
+
+func (f *Federation) healthy(role config.PeerRole, withToken bool) []*Peer {
+	peers := []*Peer{}
+	for _, p := range f.filter(role, withToken) {
+		if p.IsHealthy() {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+// weightedOrder shuffles the peers, a peer with weight w is w times as
+// likely to come first as a peer with weight 1.
+func weightedOrder(peers []*Peer) []*Peer {
+	keys := make(map[*Peer]float64, len(peers))
+	for _, p := range peers {
+		keys[p] = math.Pow(rand.Float64(), 1/float64(p.Weight))
+	}
+	ordered := slices.Clone(peers)
+	slices.SortFunc(ordered, func(a, b *Peer) int {
+		if keys[a] > keys[b] {
+			return -1
+		}
+		if keys[a] < keys[b] {
+			return 1
+		}
+		return 0
+	})
+	return ordered
+}
+
+func (f *Federation) Status() []PeerStatus {
+	statuses := make([]PeerStatus, len(f.peers))
+	for i, p := range f.peers {
+		statuses[i] = p.Status()
+	}
+	return statuses
+}
+
+// fanOut calls fn for each peer concurrently, and returns the results in
+// the order of the peers. It fails only if every peer fails.
+func fanOut[T any](peers []*Peer, fn func(p *Peer) (T, error)) ([]*T, error) {
+	results := make([]*T, len(peers))
+	errs := make([]error, len(peers))
+	var wg sync.WaitGroup
+	for i, p := range peers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := fn(p)
+			p.report(err)
+			if err != nil {
+				log.Error("peer request failed", "url", p.URL, "error", core.PackError(err))
+				errs[i] = err
+				return
+			}
+			results[i] = &result
+		}()
+	}
+	wg.Wait()
+	if !slices.ContainsFunc(results, func(r *T) bool { return r != nil }) {
+		return nil, errors.Join(errs...)
+	}
+	return results, nil
+}
+
+// trustedCheckMagnet returns the healthy check_magnet peers that can be sent
+// the store token.
+func (f *Federation) trustedCheckMagnet(withToken bool) []*Peer {
+	return slices.DeleteFunc(f.healthy(config.PeerRoleCheckMagnet, withToken), func(p *Peer) bool {
+		return !p.Trusted
+	})
+}
+
+// IsHaltedCheckMagnet is true if every check_magnet peer is halted or
+// unhealthy.
+func (f *Federation) IsHaltedCheckMagnet() bool {
+	for _, p := range f.trustedCheckMagnet(false) {
+		if !p.IsHaltedCheckMagnet() {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *Federation) CheckMagnet(params *CheckMagnetParams) (*store.CheckMagnetData, error) {
+	peers := slices.DeleteFunc(f.trustedCheckMagnet(false), func(p *Peer) bool {
+		return p.IsHaltedCheckMagnet()
+	})
+	if len(peers) == 0 {
+		return nil, ErrNoPeer
+	}
+
+	results, err := fanOut(peers, func(p *Peer) (store.CheckMagnetData, error) {
+		pParams := *params
+		start := time.Now()
+		res, err := p.CheckMagnet(&pParams)
+		if time.Since(start).Seconds() > 10 {
+			p.HaltCheckMagnet()
+		}
+		return res.Data, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := &store.CheckMagnetData{Items: []store.CheckMagnetDataItem{}}
+	idxByHash := map[string]int{}
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		for _, item := range result.Items {
+			if idx, seen := idxByHash[item.Hash]; !seen {
+				idxByHash[item.Hash] = len(data.Items)
+				data.Items = append(data.Items, item)
+			} else if data.Items[idx].Status != store.MagnetStatusCached && item.Status == store.MagnetStatusCached {
+				data.Items[idx] = item
+			}
+		}
+	}
+	return data, nil
+}
+
+func (f *Federation) TrackMagnet(params *TrackMagnetParams) error {
+	peers := f.trustedCheckMagnet(true)
+	if len(peers) == 0 {
+		return ErrNoPeer
+	}
+	errs := []error{}
+	for _, p := range peers {
+		pParams := *params
+		_, err := p.TrackMagnet(&pParams)
+		p.report(err)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f *Federation) ListTorrents(params *ListTorrentsByStremIdParams) (*ListTorrentsByStremIdData, error) {
+	peers := f.healthy(config.PeerRoleListTorrents, false)
+	if len(peers) == 0 {
+		return nil, ErrNoPeer
+	}
+
+	results, err := fanOut(peers, func(p *Peer) (ListTorrentsByStremIdData, error) {
+		pParams := *params
+		res, err := p.ListTorrents(&pParams)
+		return res.Data, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := &ListTorrentsByStremIdData{}
+	seen := map[string]struct{}{}
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		for _, item := range result.Items {
+			if _, ok := seen[item.Hash]; ok {
+				continue
+			}
+			seen[item.Hash] = struct{}{}
+			data.Items = append(data.Items, item)
+		}
+	}
+	data.TotalItems = len(data.Items)
+	return data, nil
+}
+
+func (f *Federation) PushTorrents(params *PushTorrentsParams) error {
+	peers := f.healthy(config.PeerRolePushTorrents, true)
+	if len(peers) == 0 {
+		return ErrNoPeer
+	}
+	errs := []error{}
+	for _, p := range peers {
+		pParams := *params
+		_, err := p.PushTorrents(&pParams)
+		p.report(err)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// failOver tries the peers in weighted random order, until one succeeds or
+// fails on purpose.
+func failOver[T any](peers []*Peer, fn func(p *Peer) (T, error)) (T, error) {
+	var result T
+	if len(peers) == 0 {
+		return result, ErrNoPeer
+	}
+	errs := []error{}
+	for _, p := range weightedOrder(peers) {
+		var err error
+		result, err = fn(p)
+		p.report(err)
+		if err == nil || !isFailure(err) {
+			return result, err
+		}
+		log.Warn("peer request failed, failing over", "url", p.URL, "error", core.PackError(err))
+		errs = append(errs, err)
+	}
+	return result, errors.Join(errs...)
+}
+
+func (f *Federation) FetchLetterboxdList(params *FetchLetterboxdListParams) (request.APIResponse[meta_type.List], error) {
+	return failOver(f.healthy(config.PeerRoleLetterboxd, false), func(p *Peer) (request.APIResponse[meta_type.List], error) {
+		pParams := *params
+		return p.FetchLetterboxdList(&pParams)
+	})
+}
+
+func (f *Federation) FetchLetterboxdUserWatchlist(params *FetchLetterboxdUserWatchlistParams) (request.APIResponse[meta_type.List], error) {
+	return failOver(f.healthy(config.PeerRoleLetterboxd, false), func(p *Peer) (request.APIResponse[meta_type.List], error) {
+		pParams := *params
+		return p.FetchLetterboxdUserWatchlist(&pParams)
+	})
+}
@@ -0,0 +1,93 @@
+package peer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/torrent_info"
+	"github.com/MunifTanjim/stremthru/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPeer(t *testing.T, weight int, status int, data any) config.PeerConfig {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status >= 400 {
+			json.NewEncoder(w).Encode(map[string]any{
+				"error": map[string]any{"message": "failed", "status_code": status},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	t.Cleanup(server.Close)
+	return config.PeerConfig{
+		URL:     server.URL,
+		Weight:  weight,
+		Roles:   []config.PeerRole{config.PeerRoleCheckMagnet, config.PeerRoleListTorrents},
+		Trusted: true,
+	}
+}
+
+func TestFederationCheckMagnet(t *testing.T) {
+	f := NewFederation([]config.PeerConfig{
+		newTestPeer(t, 1, 200, store.CheckMagnetData{Items: []store.CheckMagnetDataItem{
+			{Hash: "a", Status: store.MagnetStatusCached},
+			{Hash: "b", Status: store.MagnetStatusUnknown},
+		}}),
+		newTestPeer(t, 2, 200, store.CheckMagnetData{Items: []store.CheckMagnetDataItem{
+			{Hash: "a", Status: store.MagnetStatusUnknown},
+			{Hash: "b", Status: store.MagnetStatusCached},
+		}}),
+		newTestPeer(t, 3, 500, nil),
+	})
+
+	data, err := f.CheckMagnet(&CheckMagnetParams{})
+	assert.NoError(t, err)
+	assert.Equal(t, []store.CheckMagnetDataItem{
+		{Hash: "a", Status: store.MagnetStatusCached},
+		{Hash: "b", Status: store.MagnetStatusCached},
+	}, data.Items)
+}
+
+func TestFederationListTorrents(t *testing.T) {
+	f := NewFederation([]config.PeerConfig{
+		newTestPeer(t, 1, 200, torrent_info.ListTorrentsData{Items: []torrent_info.TorrentItem{
+			{Hash: "a", TorrentTitle: "low"},
+			{Hash: "b", TorrentTitle: "low"},
+		}}),
+		newTestPeer(t, 2, 200, torrent_info.ListTorrentsData{Items: []torrent_info.TorrentItem{
+			{Hash: "a", TorrentTitle: "high"},
+		}}),
+	})
+
+	data, err := f.ListTorrents(&ListTorrentsByStremIdParams{SId: "tt0000001"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, data.TotalItems)
+	assert.Equal(t, "a", data.Items[0].Hash)
+	assert.Equal(t, "high", data.Items[0].TorrentTitle)
+	assert.Equal(t, "b", data.Items[1].Hash)
+}
+
+func TestFederationHealth(t *testing.T) {
+	f := NewFederation([]config.PeerConfig{
+		newTestPeer(t, 1, 502, nil),
+	})
+
+	for range peerFailureThreshold {
+		_, err := f.ListTorrents(&ListTorrentsByStremIdParams{SId: "tt0000001"})
+		assert.Error(t, err)
+	}
+
+	status := f.Status()[0]
+	assert.False(t, status.Healthy)
+	assert.Equal(t, peerFailureThreshold, status.Failures)
+	assert.NotNil(t, status.RetryAfter)
+
+	_, err := f.ListTorrents(&ListTorrentsByStremIdParams{SId: "tt0000001"})
+	assert.ErrorIs(t, err, ErrNoPeer)
+}
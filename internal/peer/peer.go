@@ -5,6 +5,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/MunifTanjim/stremthru/core"
@@ -233,6 +235,8 @@ type ListTorrentsByStremIdParams struct {
 	SId              string
 	LocalOnly        bool
 	OriginInstanceId string
+	// instance ids the request has already passed through
+	Via []string
 }
 
 type ListTorrentsByStremIdData = torrent_info.ListTorrentsData
@@ -248,6 +252,7 @@ func (c APIClient) ListTorrents(params *ListTorrentsByStremIdParams) (request.AP
 	} else {
 		params.Headers.Set(server.HEADER_ORIGIN_INSTANCE_ID, config.InstanceId)
 	}
+	params.Headers.Set(server.HEADER_PEER_VIA, strings.Join(append(slices.Clone(params.Via), config.InstanceId), ","))
 
 	response := &Response[ListTorrentsByStremIdData]{}
 	res, err := c.Request("GET", "/v0/torrents", params, response)
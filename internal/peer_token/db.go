@@ -1,7 +1,10 @@
 package peer_token
 
 import (
+	"crypto/rand"
 	"database/sql"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/MunifTanjim/stremthru/internal/cache"
@@ -11,37 +14,193 @@ import (
 const TableName = "peer_token"
 
 type PeerToken struct {
+	Id   string
+	Name string
+	// max requests per hour, 0 means unlimited
+	Quota     int
+	CreatedAt db.Timestamp
+}
+
+var Column = struct {
 	Id        string
 	Name      string
-	CreatedAt db.Timestamp
+	Quota     string
+	CreatedAt string
+}{
+	Id:        "id",
+	Name:      "name",
+	Quota:     "quota",
+	CreatedAt: "created_at",
+}
+
+var Columns = []string{
+	Column.Id,
+	Column.Name,
+	Column.Quota,
+	Column.CreatedAt,
 }
 
-var peerTokenCache = cache.NewLRUCache[bool](&cache.CacheConfig{
+var peerTokenCache = cache.NewLRUCache[PeerToken](&cache.CacheConfig{
 	Lifetime:      15 * time.Minute,
-	Name:          "peer_token_is_valid",
+	Name:          "peer_token",
 	LocalCapacity: 512,
 })
 
-func IsValid(token string) (isValid bool, err error) {
+var query_get_by_id = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ?`,
+	db.JoinColumnNames(Columns...),
+	TableName,
+	Column.Id,
+)
+
+func GetById(id string) (*PeerToken, error) {
+	t := &PeerToken{}
+	row := db.QueryRow(query_get_by_id, id)
+	if err := row.Scan(&t.Id, &t.Name, &t.Quota, &t.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+// get is the cached GetById, a missing token is cached with empty id.
+func get(token string) (*PeerToken, error) {
 	if token == "" {
-		return false, nil
+		return nil, nil
+	}
+
+	t := &PeerToken{}
+	if !peerTokenCache.Get(token, t) {
+		pt, err := GetById(token)
+		if err != nil {
+			return nil, err
+		}
+		if pt != nil {
+			t = pt
+		}
+		if err := peerTokenCache.Add(token, *t); err != nil {
+			return nil, err
+		}
 	}
 
-	if peerTokenCache.Get(token, &isValid) {
-		return isValid, nil
+	if t.Id != token {
+		return nil, nil
 	}
+	return t, nil
+}
 
-	id := ""
-	row := db.QueryRow("SELECT id FROM "+TableName+" WHERE id = ?", token)
-	if err := row.Scan(&id); err != nil && err != sql.ErrNoRows {
+func IsValid(token string) (isValid bool, err error) {
+	t, err := get(token)
+	if err != nil {
 		return false, err
 	}
+	return t != nil, nil
+}
 
-	isValid = id == token
+var query_get_all = fmt.Sprintf(
+	`SELECT %s FROM %s ORDER BY %s ASC`,
+	db.JoinColumnNames(Columns...),
+	TableName,
+	Column.CreatedAt,
+)
 
-	if err := peerTokenCache.Add(token, isValid); err != nil {
-		return false, err
+func GetAll() ([]PeerToken, error) {
+	rows, err := db.Query(query_get_all)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return isValid, nil
+	tokens := []PeerToken{}
+	for rows.Next() {
+		t := PeerToken{}
+		if err := rows.Scan(&t.Id, &t.Name, &t.Quota, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+var query_insert = fmt.Sprintf(
+	`INSERT INTO %s (%s) VALUES (?, ?, ?)`,
+	TableName,
+	db.JoinColumnNames(Column.Id, Column.Name, Column.Quota),
+)
+
+func Create(name string, quota int) (*PeerToken, error) {
+	if name == "" {
+		return nil, errors.New("name must be provided")
+	}
+	if quota < 0 {
+		return nil, errors.New("quota must not be negative")
+	}
+	t := &PeerToken{
+		Id:        rand.Text(),
+		Name:      name,
+		Quota:     quota,
+		CreatedAt: db.Timestamp{Time: time.Now()},
+	}
+	if _, err := db.Exec(query_insert, t.Id, t.Name, t.Quota); err != nil {
+		return nil, err
+	}
+	peerTokenCache.Remove(t.Id)
+	return t, nil
+}
+
+var query_update = fmt.Sprintf(
+	`UPDATE %s SET %s = ?, %s = ? WHERE %s = ?`,
+	TableName,
+	Column.Name,
+	Column.Quota,
+	Column.Id,
+)
+
+func Update(t *PeerToken) error {
+	if t.Name == "" {
+		return errors.New("name must be provided")
+	}
+	if t.Quota < 0 {
+		return errors.New("quota must not be negative")
+	}
+	if _, err := db.Exec(query_update, t.Name, t.Quota, t.Id); err != nil {
+		return err
+	}
+	peerTokenCache.Remove(t.Id)
+	return nil
+}
+
+var query_delete = fmt.Sprintf(
+	`DELETE FROM %s WHERE %s = ?`,
+	TableName,
+	Column.Id,
+)
+
+// Revoke deletes the token with its usage. Other instances may accept the
+// token until their cache expires.
+func Revoke(id string) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			err = tx.Commit()
+			peerTokenCache.Remove(id)
+			return
+		}
+		tErr := tx.Rollback()
+		err = errors.Join(tErr, err)
+	}()
+
+	if _, err = tx.Exec(query_delete_usage, id); err != nil {
+		return err
+	}
+	_, err = tx.Exec(query_delete, id)
+	return err
 }
@@ -0,0 +1,174 @@
+package peer_token
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/db"
+)
+
+const UsageTableName = "peer_token_usage"
+
+const quotaWindow = time.Hour
+
+var ErrInvalidToken = errors.New("invalid peer token")
+var ErrQuotaExceeded = errors.New("peer token quota exceeded")
+
+type Usage struct {
+	Id             string
+	Requests       int
+	TorrentsPushed int
+	WindowAt       db.Timestamp
+	WindowRequests int
+	InstanceId     string
+	LastSeenAt     db.Timestamp
+}
+
+var UsageColumn = struct {
+	Id             string
+	Requests       string
+	TorrentsPushed string
+	WindowAt       string
+	WindowRequests string
+	InstanceId     string
+	LastSeenAt     string
+}{
+	Id:             "id",
+	Requests:       "requests",
+	TorrentsPushed: "torrents_pushed",
+	WindowAt:       "window_at",
+	WindowRequests: "window_requests",
+	InstanceId:     "instance_id",
+	LastSeenAt:     "last_seen_at",
+}
+
+var UsageColumns = []string{
+	UsageColumn.Id,
+	UsageColumn.Requests,
+	UsageColumn.TorrentsPushed,
+	UsageColumn.WindowAt,
+	UsageColumn.WindowRequests,
+	UsageColumn.InstanceId,
+	UsageColumn.LastSeenAt,
+}
+
+func scanUsage(row interface{ Scan(dest ...any) error }) (*Usage, error) {
+	u := &Usage{}
+	if err := row.Scan(
+		&u.Id,
+		&u.Requests,
+		&u.TorrentsPushed,
+		&u.WindowAt,
+		&u.WindowRequests,
+		&u.InstanceId,
+		&u.LastSeenAt,
+	); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+var query_get_usage = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ?`,
+	db.JoinColumnNames(UsageColumns...),
+	UsageTableName,
+	UsageColumn.Id,
+)
+
+func GetUsage(id string) (*Usage, error) {
+	u, err := scanUsage(db.QueryRow(query_get_usage, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+var query_get_all_usage = fmt.Sprintf(
+	`SELECT %s FROM %s`,
+	db.JoinColumnNames(UsageColumns...),
+	UsageTableName,
+)
+
+func GetAllUsage() (map[string]Usage, error) {
+	rows, err := db.Query(query_get_all_usage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usageById := map[string]Usage{}
+	for rows.Next() {
+		u, err := scanUsage(rows)
+		if err != nil {
+			return nil, err
+		}
+		usageById[u.Id] = *u
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return usageById, nil
+}
+
+var query_record_usage = fmt.Sprintf(
+	`INSERT INTO %s AS ptu (%s) VALUES (?, 1, ?, ?, 1, ?, %s) ON CONFLICT (%s) DO UPDATE SET %s = ptu.%s + 1, %s = ptu.%s + EXCLUDED.%s, %s = CASE WHEN ptu.%s = EXCLUDED.%s THEN ptu.%s + 1 ELSE 1 END, %s = EXCLUDED.%s, %s = CASE WHEN EXCLUDED.%s = '' THEN ptu.%s ELSE EXCLUDED.%s END, %s = EXCLUDED.%s`,
+	UsageTableName,
+	db.JoinColumnNames(UsageColumns...),
+	db.CurrentTimestamp,
+	UsageColumn.Id,
+	UsageColumn.Requests, UsageColumn.Requests,
+	UsageColumn.TorrentsPushed, UsageColumn.TorrentsPushed, UsageColumn.TorrentsPushed,
+	UsageColumn.WindowRequests, UsageColumn.WindowAt, UsageColumn.WindowAt, UsageColumn.WindowRequests,
+	UsageColumn.WindowAt, UsageColumn.WindowAt,
+	UsageColumn.InstanceId, UsageColumn.InstanceId, UsageColumn.InstanceId, UsageColumn.InstanceId,
+	UsageColumn.LastSeenAt, UsageColumn.LastSeenAt,
+)
+
+var query_get_window_requests = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ?`,
+	UsageColumn.WindowRequests,
+	UsageTableName,
+	UsageColumn.Id,
+)
+
+// Use records a request made with the token, and checks the quota. The
+// rejected requests are counted too, so a peer hammering past its quota
+// stays blocked until the window ends.
+func Use(token string, instanceId string, torrentsPushed int) error {
+	t, err := get(token)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return ErrInvalidToken
+	}
+
+	windowAt := db.Timestamp{Time: time.Now().Truncate(quotaWindow)}
+	if _, err := db.Exec(query_record_usage, t.Id, torrentsPushed, windowAt, instanceId); err != nil {
+		return err
+	}
+
+	if t.Quota == 0 {
+		return nil
+	}
+
+	windowRequests := 0
+	if err := db.QueryRow(query_get_window_requests, t.Id).Scan(&windowRequests); err != nil {
+		return err
+	}
+	if windowRequests > t.Quota {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+var query_delete_usage = fmt.Sprintf(
+	`DELETE FROM %s WHERE %s = ?`,
+	UsageTableName,
+	UsageColumn.Id,
+)
@@ -0,0 +1,212 @@
+package plex
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/MunifTanjim/stremthru/core"
+	"github.com/MunifTanjim/stremthru/internal/request"
+)
+
+type User struct {
+	Id       int    `json:"id"`
+	UUID     string `json:"uuid"`
+	Username string `json:"username"`
+	Title    string `json:"title"`
+}
+
+type fetchUserData struct {
+	ResponseError
+	User
+}
+
+type FetchUserParams struct {
+	Ctx
+}
+
+func (c APIClient) FetchUser(params *FetchUserParams) (request.APIResponse[User], error) {
+	response := fetchUserData{}
+	res, err := c.Request("GET", "/api/v2/user", params, &response)
+	return request.NewAPIResponse(res, response.User), err
+}
+
+type ResourceConnection struct {
+	Protocol string `json:"protocol"`
+	Address  string `json:"address"`
+	Port     int    `json:"port"`
+	URI      string `json:"uri"`
+	Local    bool   `json:"local"`
+	Relay    bool   `json:"relay"`
+}
+
+type Resource struct {
+	Name             string               `json:"name"`
+	ClientIdentifier string               `json:"clientIdentifier"`
+	Provides         string               `json:"provides"`
+	AccessToken      string               `json:"accessToken"`
+	Connections      []ResourceConnection `json:"connections"`
+}
+
+func (r *Resource) IsServer() bool {
+	return strings.Contains(r.Provides, "server")
+}
+
+// GetConnectionURI prefers remote direct connections, then relay, then local.
+func (r *Resource) GetConnectionURI() string {
+	rank := func(conn *ResourceConnection) int {
+		switch {
+		case !conn.Local && !conn.Relay:
+			return 0
+		case conn.Relay:
+			return 1
+		default:
+			return 2
+		}
+	}
+	uri, best := "", 3
+	for i := range r.Connections {
+		conn := &r.Connections[i]
+		if score := rank(conn); score < best {
+			uri, best = conn.URI, score
+		}
+	}
+	return uri
+}
+
+type fetchResourcesData struct {
+	ResponseError
+	Resources []Resource
+}
+
+func (d *fetchResourcesData) Unmarshal(res *http.Response, body []byte, v any) error {
+	d.StatusCode = res.StatusCode
+	if res.StatusCode >= 400 {
+		return d.ResponseError.Unmarshal(res, body, v)
+	}
+	return core.UnmarshalJSON(res.StatusCode, body, &d.Resources)
+}
+
+type FetchResourcesParams struct {
+	Ctx
+}
+
+func (c APIClient) FetchResources(params *FetchResourcesParams) (request.APIResponse[[]Resource], error) {
+	query := url.Values{}
+	query.Set("includeHttps", "1")
+	query.Set("includeRelay", "1")
+	params.Query = &query
+
+	response := fetchResourcesData{}
+	res, err := c.Request("GET", "/api/v2/resources", params, &response)
+	return request.NewAPIResponse(res, response.Resources), err
+}
+
+type MetadataType string
+
+const (
+	MetadataTypeCollection MetadataType = "collection"
+	MetadataTypePlaylist   MetadataType = "playlist"
+	MetadataTypeMovie      MetadataType = "movie"
+	MetadataTypeShow       MetadataType = "show"
+)
+
+type Guid struct {
+	Id string `json:"id"`
+}
+
+type Tag struct {
+	Tag string `json:"tag"`
+}
+
+type Metadata struct {
+	RatingKey string       `json:"ratingKey"`
+	Key       string       `json:"key"`
+	Guid      string       `json:"guid"`
+	Type      MetadataType `json:"type"`
+	Title     string       `json:"title"`
+	Summary   string       `json:"summary,omitempty"`
+	Year      int          `json:"year,omitempty"`
+	Duration  int          `json:"duration,omitempty"` // in milliseconds
+	Thumb     string       `json:"thumb,omitempty"`
+	Art       string       `json:"art,omitempty"`
+	Guids     []Guid       `json:"Guid,omitempty"`
+	Genres    []Tag        `json:"Genre,omitempty"`
+}
+
+func (m *Metadata) GetRuntime() int {
+	return m.Duration / 1000 / 60
+}
+
+// GetExternalIds extracts imdb, tmdb and tvdb ids from agent guids.
+func (m *Metadata) GetExternalIds() (imdbId, tmdbId, tvdbId string) {
+	guids := make([]string, 0, len(m.Guids)+1)
+	for i := range m.Guids {
+		guids = append(guids, m.Guids[i].Id)
+	}
+	guids = append(guids, m.Guid)
+	for _, guid := range guids {
+		agent, id, ok := strings.Cut(guid, "://")
+		if !ok {
+			continue
+		}
+		id, _, _ = strings.Cut(id, "?")
+		switch agent {
+		case "imdb", "com.plexapp.agents.imdb":
+			if imdbId == "" && strings.HasPrefix(id, "tt") {
+				imdbId = id
+			}
+		case "tmdb", "com.plexapp.agents.themoviedb":
+			if tmdbId == "" {
+				tmdbId = id
+			}
+		case "tvdb", "com.plexapp.agents.thetvdb":
+			if tvdbId == "" {
+				tvdbId, _, _ = strings.Cut(id, "/")
+			}
+		}
+	}
+	return imdbId, tmdbId, tvdbId
+}
+
+func (m *Metadata) GenreNames() []string {
+	genres := make([]string, len(m.Genres))
+	for i := range m.Genres {
+		genres[i] = m.Genres[i].Tag
+	}
+	return genres
+}
+
+type MediaContainer struct {
+	Size      int        `json:"size"`
+	TotalSize int        `json:"totalSize"`
+	Offset    int        `json:"offset"`
+	Metadata  []Metadata `json:"Metadata"`
+}
+
+type fetchMediaContainerData struct {
+	ResponseError
+	MediaContainer MediaContainer `json:"MediaContainer"`
+}
+
+type FetchMetadataParams struct {
+	Ctx
+	Path  string
+	Start int
+	Size  int
+}
+
+func (c APIClient) FetchMetadata(params *FetchMetadataParams) (request.APIResponse[MediaContainer], error) {
+	query := url.Values{}
+	query.Set("includeGuids", "1")
+	if params.Size > 0 {
+		query.Set("X-Plex-Container-Start", strconv.Itoa(params.Start))
+		query.Set("X-Plex-Container-Size", strconv.Itoa(params.Size))
+	}
+	params.Query = &query
+
+	response := fetchMediaContainerData{}
+	res, err := c.Request("GET", params.Path, params, &response)
+	return request.NewAPIResponse(res, response.MediaContainer), err
+}
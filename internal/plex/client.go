@@ -0,0 +1,134 @@
+package plex
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/MunifTanjim/stremthru/core"
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/request"
+)
+
+const (
+	PlexTVBaseURL   = "https://plex.tv"
+	ClientsBaseURL  = "https://clients.plex.tv"
+	DiscoverBaseURL = "https://discover.provider.plex.tv"
+)
+
+type APIClientConfig struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+type APIClient struct {
+	BaseURL    *url.URL
+	httpClient *http.Client
+	token      string
+
+	reqQuery  func(query *url.Values, params request.Context)
+	reqHeader func(query *http.Header, params request.Context)
+}
+
+func NewAPIClient(conf *APIClientConfig) (*APIClient, error) {
+	if conf.HTTPClient == nil {
+		conf.HTTPClient = config.DefaultHTTPClient
+	}
+	if conf.BaseURL == "" {
+		conf.BaseURL = PlexTVBaseURL
+	}
+
+	baseUrl, err := url.Parse(strings.TrimSuffix(conf.BaseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if baseUrl.Scheme != "http" && baseUrl.Scheme != "https" {
+		return nil, errors.New("invalid base url: " + conf.BaseURL)
+	}
+
+	c := &APIClient{}
+
+	c.BaseURL = baseUrl
+	c.httpClient = conf.HTTPClient
+	c.token = conf.Token
+
+	c.reqQuery = func(query *url.Values, params request.Context) {
+	}
+
+	c.reqHeader = func(header *http.Header, params request.Context) {
+		header.Set("Accept", "application/json")
+		header.Set("X-Plex-Client-Identifier", "stremthru")
+		header.Set("X-Plex-Product", "StremThru")
+		header.Set("X-Plex-Version", config.Version)
+		header.Set("X-Plex-Token", params.GetAPIKey(c.token))
+	}
+
+	return c, nil
+}
+
+type Ctx = request.Ctx
+
+type ResponseError struct {
+	StatusCode int    `json:"-"`
+	Message    string `json:"message,omitempty"`
+}
+
+func (e *ResponseError) Error() string {
+	ret, _ := json.Marshal(e)
+	return string(ret)
+}
+
+func (r *ResponseError) GetError(res *http.Response) error {
+	if r == nil || r.StatusCode < 400 {
+		return nil
+	}
+	if r.Message == "" {
+		r.Message = http.StatusText(r.StatusCode)
+	}
+	return r
+}
+
+func (r *ResponseError) Unmarshal(res *http.Response, body []byte, v any) error {
+	r.StatusCode = res.StatusCode
+	if res.StatusCode >= 400 {
+		r.Message = strings.TrimSpace(string(body))
+		return nil
+	}
+	contentType := res.Header.Get("Content-Type")
+	switch {
+	case strings.Contains(contentType, "application/json"):
+		return core.UnmarshalJSON(res.StatusCode, body, v)
+	default:
+		return errors.New("unexpected content type: " + contentType)
+	}
+}
+
+func (c APIClient) Request(method, path string, params request.Context, v request.ResponseContainer) (*http.Response, error) {
+	if params == nil {
+		params = &Ctx{}
+	}
+	req, err := params.NewRequest(c.BaseURL, method, path, c.reqHeader, c.reqQuery)
+	if err != nil {
+		error := core.NewAPIError("failed to create request")
+		error.Cause = err
+		return nil, error
+	}
+	res, err := c.httpClient.Do(req)
+	err = request.ProcessResponseBody(res, err, v)
+	if err != nil {
+		error := core.NewUpstreamError("")
+		if rerr, ok := err.(*ResponseError); ok {
+			error.Msg = rerr.Message
+			error.StatusCode = rerr.StatusCode
+			error.UpstreamCause = rerr
+		} else {
+			error.Cause = err
+		}
+		error.InjectReq(req)
+		return res, error
+	}
+	return res, nil
+}
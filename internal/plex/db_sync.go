@@ -0,0 +1,332 @@
+package plex
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/cache"
+	"github.com/MunifTanjim/stremthru/internal/db"
+)
+
+var listCache = cache.NewCache[PlexList](&cache.CacheConfig{
+	Lifetime:      6 * time.Hour,
+	Name:          "plex:list",
+	LocalCapacity: 1024,
+})
+
+var userCache = cache.NewCache[User](&cache.CacheConfig{
+	Lifetime:      12 * time.Hour,
+	Name:          "plex:user",
+	LocalCapacity: 256,
+})
+
+var serverCache = cache.NewCache[Resource](&cache.CacheConfig{
+	Lifetime:      1 * time.Hour,
+	Name:          "plex:server",
+	LocalCapacity: 256,
+})
+
+func getListCacheKey(l *PlexList) string {
+	return l.Id
+}
+
+func GetUser(token string) (*User, error) {
+	user := User{}
+	if userCache.Get(token, &user) {
+		return &user, nil
+	}
+
+	client, err := NewAPIClient(&APIClientConfig{
+		BaseURL: PlexTVBaseURL,
+		Token:   token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.FetchUser(&FetchUserParams{})
+	if err != nil {
+		return nil, err
+	}
+	user = res.Data
+	if user.UUID == "" {
+		return nil, errors.New("failed to resolve user")
+	}
+	userCache.Add(token, user)
+	return &user, nil
+}
+
+func getServer(token, machineId string) (*Resource, error) {
+	cacheKey := token + ":" + machineId
+	server := Resource{}
+	if serverCache.Get(cacheKey, &server) {
+		return &server, nil
+	}
+
+	client, err := NewAPIClient(&APIClientConfig{
+		BaseURL: ClientsBaseURL,
+		Token:   token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.FetchResources(&FetchResourcesParams{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range res.Data {
+		r := &res.Data[i]
+		if r.IsServer() && r.ClientIdentifier == machineId {
+			if r.GetConnectionURI() == "" {
+				return nil, errors.New("server is not reachable")
+			}
+			serverCache.Add(cacheKey, *r)
+			return r, nil
+		}
+	}
+	return nil, errors.New("server not found")
+}
+
+func getServerClient(token, machineId string) (*APIClient, error) {
+	server, err := getServer(token, machineId)
+	if err != nil {
+		return nil, err
+	}
+	accessToken := server.AccessToken
+	if accessToken == "" {
+		accessToken = token
+	}
+	return NewAPIClient(&APIClientConfig{
+		BaseURL: server.GetConnectionURI(),
+		Token:   accessToken,
+	})
+}
+
+func toItemType(t MetadataType) PlexItemType {
+	switch t {
+	case MetadataTypeMovie:
+		return ItemTypeMovie
+	case MetadataTypeShow:
+		return ItemTypeSeries
+	default:
+		return ""
+	}
+}
+
+// toImageURL drops server relative paths, those require the user's token.
+func toImageURL(path string) string {
+	if strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://") {
+		return path
+	}
+	return ""
+}
+
+var syncListMutex sync.Mutex
+
+func syncList(l *PlexList, token string) error {
+	if l.Id == "" {
+		return errors.New("id must be provided")
+	}
+	if token == "" {
+		return errors.New("token must be provided")
+	}
+
+	syncListMutex.Lock()
+	defer syncListMutex.Unlock()
+
+	var client *APIClient
+	var itemsPath string
+
+	l.Kind = l.GetKind()
+	switch l.Kind {
+	case ListKindCollection, ListKindPlaylist:
+		c, err := getServerClient(token, l.GetMachineId())
+		if err != nil {
+			return err
+		}
+		client = c
+
+		listPath := "/library/metadata/" + l.GetRatingKey()
+		itemsPath = "/library/collections/" + l.GetRatingKey() + "/children"
+		if l.Kind == ListKindPlaylist {
+			listPath = "/playlists/" + l.GetRatingKey()
+			itemsPath = listPath + "/items"
+		}
+
+		log.Debug("fetching list by id", "id", l.Id)
+		res, err := client.FetchMetadata(&FetchMetadataParams{Path: listPath})
+		if err != nil {
+			return err
+		}
+		if len(res.Data.Metadata) == 0 {
+			return errors.New("list not found")
+		}
+		list := &res.Data.Metadata[0]
+		if string(list.Type) != string(l.Kind) {
+			return errors.New("unsupported list type: " + string(list.Type))
+		}
+		l.Name = list.Title
+		l.Overview = list.Summary
+	case ListKindWatchlist:
+		user, err := GetUser(token)
+		if err != nil {
+			return err
+		}
+		if user.UUID != l.GetUserUUID() {
+			return errors.New("user mismatch")
+		}
+		c, err := NewAPIClient(&APIClientConfig{
+			BaseURL: DiscoverBaseURL,
+			Token:   token,
+		})
+		if err != nil {
+			return err
+		}
+		client = c
+		itemsPath = "/library/sections/watchlist/all"
+		l.Name = "Watchlist"
+		if user.Username != "" {
+			l.Name = user.Username + "'s Watchlist"
+		}
+		l.Overview = ""
+	default:
+		return errors.New("unsupported list kind: " + string(l.Kind))
+	}
+
+	l.Items = nil
+
+	log.Debug("fetching list items", "id", l.Id)
+	limit := 300
+	start := 0
+	hasMore := true
+	for hasMore {
+		res, err := client.FetchMetadata(&FetchMetadataParams{
+			Path:  itemsPath,
+			Start: start,
+			Size:  limit,
+		})
+		if err != nil {
+			return err
+		}
+
+		for i := range res.Data.Metadata {
+			item := &res.Data.Metadata[i]
+			itemType := toItemType(item.Type)
+			if itemType == "" || item.Guid == "" {
+				continue
+			}
+			if l.Kind == ListKindWatchlist && len(item.Guids) == 0 {
+				if r, err := client.FetchMetadata(&FetchMetadataParams{Path: "/library/metadata/" + item.RatingKey}); err != nil {
+					log.Warn("failed to fetch item metadata", "id", item.Guid, "error", err)
+				} else if len(r.Data.Metadata) > 0 {
+					item.Guids = r.Data.Metadata[0].Guids
+				}
+			}
+			imdbId, tmdbId, tvdbId := item.GetExternalIds()
+			l.Items = append(l.Items, PlexItem{
+				Id:         item.Guid,
+				Type:       itemType,
+				Name:       item.Title,
+				Overview:   item.Summary,
+				Year:       item.Year,
+				Runtime:    item.GetRuntime(),
+				Poster:     toImageURL(item.Thumb),
+				Background: toImageURL(item.Art),
+				IMDBId:     imdbId,
+				TMDBId:     tmdbId,
+				TVDBId:     tvdbId,
+				Genres:     item.GenreNames(),
+				UpdatedAt:  db.Timestamp{Time: time.Now()},
+
+				Order: start + i,
+			})
+		}
+
+		start += len(res.Data.Metadata)
+		hasMore = len(res.Data.Metadata) == limit && start < res.Data.TotalSize
+	}
+
+	if err := UpsertList(l); err != nil {
+		return err
+	}
+
+	if err := listCache.Add(getListCacheKey(l), *l); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// authorize checks if the token has access to the list, the lists are shared
+// by the users with access to the same server.
+func (l *PlexList) authorize(token string) error {
+	if token == "" {
+		return errors.New("token must be provided")
+	}
+	switch l.GetKind() {
+	case ListKindCollection, ListKindPlaylist:
+		if l.GetMachineId() == "" || l.GetRatingKey() == "" {
+			return errors.New("invalid list id")
+		}
+		_, err := getServer(token, l.GetMachineId())
+		return err
+	case ListKindWatchlist:
+		user, err := GetUser(token)
+		if err != nil {
+			return err
+		}
+		if user.UUID != l.GetUserUUID() {
+			return errors.New("user mismatch")
+		}
+		return nil
+	default:
+		return errors.New("unsupported list kind: " + string(l.GetKind()))
+	}
+}
+
+func (l *PlexList) Fetch(token string) error {
+	if l.Id == "" {
+		return errors.New("id must be provided")
+	}
+
+	if err := l.authorize(token); err != nil {
+		return err
+	}
+
+	isMissing := false
+
+	listCacheKey := getListCacheKey(l)
+	var cachedL PlexList
+	if !listCache.Get(listCacheKey, &cachedL) {
+		if list, err := GetListById(l.Id); err != nil {
+			return err
+		} else if list == nil {
+			isMissing = true
+		} else {
+			*l = *list
+			log.Debug("found list by id", "id", l.Id, "is_stale", l.IsStale())
+			listCache.Add(listCacheKey, *l)
+		}
+	} else {
+		*l = cachedL
+	}
+
+	if !isMissing {
+		if l.IsStale() {
+			staleList := *l
+			go func() {
+				if err := syncList(&staleList, token); err != nil {
+					log.Error("failed to sync stale list", "id", l.Id, "error", err)
+				}
+			}()
+		}
+		return nil
+	}
+
+	if err := syncList(l, token); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package plex
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// ParseListURL extracts the list kind and its identifiers from a Plex web URL:
+//   - `https://app.plex.tv/desktop/#!/server/{machine_id}/details?key=/library/collections/{rating_key}`
+//   - `https://app.plex.tv/desktop/#!/server/{machine_id}/playlist?key=/playlists/{rating_key}`
+//   - `https://app.plex.tv/desktop/#!/watchlist`
+//   - `https://watch.plex.tv/watchlist`
+func ParseListURL(listUrl *url.URL) (kind PlexListKind, machineId, ratingKey string, err error) {
+	if listUrl.Hostname() == "watch.plex.tv" {
+		if strings.Trim(listUrl.Path, "/") == "watchlist" {
+			return ListKindWatchlist, "", "", nil
+		}
+		return "", "", "", errors.New("unsupported url")
+	}
+
+	fragment := strings.TrimPrefix(listUrl.Fragment, "!")
+	if fragment == "" {
+		return "", "", "", errors.New("missing url fragment")
+	}
+	fragmentUrl, err := url.Parse(fragment)
+	if err != nil {
+		return "", "", "", err
+	}
+	path := strings.Trim(fragmentUrl.Path, "/")
+	if path == "watchlist" {
+		return ListKindWatchlist, "", "", nil
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) != 3 || parts[0] != "server" {
+		return "", "", "", errors.New("unsupported url")
+	}
+	machineId = parts[1]
+	key := strings.TrimSuffix(fragmentUrl.Query().Get("key"), "/")
+	switch {
+	case parts[2] == "details" && strings.HasPrefix(key, "/library/collections/"):
+		kind = ListKindCollection
+		ratingKey = strings.TrimPrefix(key, "/library/collections/")
+	case parts[2] == "details" && strings.HasPrefix(key, "/library/metadata/"):
+		kind = ListKindCollection
+		ratingKey = strings.TrimPrefix(key, "/library/metadata/")
+	case parts[2] == "playlist" && strings.HasPrefix(key, "/playlists/"):
+		kind = ListKindPlaylist
+		ratingKey = strings.TrimPrefix(key, "/playlists/")
+	default:
+		return "", "", "", errors.New("unsupported url")
+	}
+	ratingKey, _, _ = strings.Cut(ratingKey, "/")
+	if machineId == "" || ratingKey == "" {
+		return "", "", "", errors.New("invalid url")
+	}
+	return kind, machineId, ratingKey, nil
+}
@@ -0,0 +1,7 @@
+package plex
+
+import (
+	"github.com/MunifTanjim/stremthru/internal/logger"
+)
+
+var log = logger.Scoped("plex")
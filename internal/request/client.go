@@ -0,0 +1,304 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-freelru"
+	"github.com/zeebo/xxh3"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultMaxRetry = 2
+	defaultMaxWait  = 30 * time.Second
+	backoffBase     = 500 * time.Millisecond
+	backoffMax      = 8 * time.Second
+
+	limiterCapacity = 4096
+	limiterLifetime = 15 * time.Minute
+
+	// for the coalesced request, it is not cancelled with the callers
+	coalescedRequestTimeout = 2 * time.Minute
+)
+
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return "rate limited, retry after " + e.RetryAfter.Round(time.Second).String()
+}
+
+type ClientConfig struct {
+	RequestsPerMinute int           // per API key, 0: unlimited
+	MaxRetry          int           // for idempotent requests, default: 2
+	MaxWait           time.Duration // for rate limit and Retry-After, default: 30s
+}
+
+// Client does the requests to an upstream API. It applies the rate limit per
+// API key, honors Retry-After, retries idempotent requests with jittered
+// exponential backoff and coalesces identical concurrent idempotent requests.
+type Client struct {
+	interval  time.Duration
+	tolerance time.Duration
+	maxRetry  int
+	maxWait   time.Duration
+
+	limiterByKey *freelru.LRU[string, *limiter]
+	limiterMutex sync.Mutex
+	sf           singleflight.Group
+}
+
+func NewClient(conf *ClientConfig) *Client {
+	if conf.MaxRetry == 0 {
+		conf.MaxRetry = defaultMaxRetry
+	}
+	if conf.MaxWait == 0 {
+		conf.MaxWait = defaultMaxWait
+	}
+
+	limiterByKey, err := freelru.New[string, *limiter](limiterCapacity, func(key string) uint32 {
+		return uint32(xxh3.HashString(key))
+	})
+	if err != nil {
+		panic("failed to create request client limiter cache")
+	}
+
+	c := &Client{
+		maxRetry:     max(conf.MaxRetry, 0),
+		maxWait:      conf.MaxWait,
+		limiterByKey: limiterByKey,
+	}
+	if conf.RequestsPerMinute > 0 {
+		c.interval = time.Minute / time.Duration(conf.RequestsPerMinute)
+		// allow bursts of upto 10 seconds worth of requests
+		burst := max(conf.RequestsPerMinute/6, 1)
+		c.tolerance = c.interval * time.Duration(burst-1)
+	}
+	return c
+}
+
+// limiter is a GCRA (generic cell rate algorithm) rate limiter.
+type limiter struct {
+	mutex        sync.Mutex
+	tat          time.Time // theoretical arrival time
+	blockedUntil time.Time
+}
+
+// getLimiter returns the limiter for the key. The limiters are evicted when
+// the cache is full or after a while of not being used, long enough for the
+// limiter to be back to its initial state unless blocked by Retry-After.
+func (c *Client) getLimiter(key string) *limiter {
+	c.limiterMutex.Lock()
+	defer c.limiterMutex.Unlock()
+
+	l, ok := c.limiterByKey.Get(key)
+	if !ok {
+		l = &limiter{}
+	}
+	lifetime := limiterLifetime
+	if blockedFor := time.Until(l.getBlockedUntil()); blockedFor > 0 {
+		lifetime += blockedFor
+	}
+	c.limiterByKey.AddWithLifetime(key, l, lifetime)
+	return l
+}
+
+// reserve returns the duration to wait before the request can be sent. If
+// it is more than maxWait, nothing is reserved.
+func (c *Client) reserve(l *limiter, now time.Time) (time.Duration, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	tat := l.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	if tat.Before(l.blockedUntil) {
+		tat = l.blockedUntil
+	}
+	allowAt := tat.Add(-c.tolerance)
+	if allowAt.Before(l.blockedUntil) {
+		allowAt = l.blockedUntil
+	}
+
+	wait := max(allowAt.Sub(now), 0)
+	if wait > c.maxWait {
+		return wait, false
+	}
+	l.tat = tat.Add(c.interval)
+	return wait, true
+}
+
+func (l *limiter) getBlockedUntil() time.Time {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.blockedUntil
+}
+
+func (c *Client) block(l *limiter, until time.Time) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (c *Client) wait(ctx context.Context, l *limiter) error {
+	wait, ok := c.reserve(l, time.Now())
+	if !ok {
+		return &RateLimitedError{RetryAfter: wait}
+	}
+	return sleep(ctx, wait)
+}
+
+// parseRetryAfter parses the Retry-After header, in seconds or HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+func backoff(attempt int) time.Duration {
+	d := min(backoffBase<<attempt, backoffMax)
+	return d/2 + rand.N(d/2+1)
+}
+
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func isIdempotent(req *http.Request) bool {
+	return (req.Method == http.MethodGet || req.Method == http.MethodHead) && (req.Body == nil || req.Body == http.NoBody)
+}
+
+func (c *Client) do(httpClient *http.Client, req *http.Request, l *limiter, retryable bool) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		if err := c.wait(ctx, l); err != nil {
+			return nil, err
+		}
+
+		res, err := httpClient.Do(req)
+		if err != nil {
+			if !retryable || attempt >= c.maxRetry || ctx.Err() != nil {
+				return res, err
+			}
+			if err := sleep(ctx, backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		retryAfter, hasRetryAfter := time.Duration(0), false
+		if res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusServiceUnavailable {
+			if retryAfter, hasRetryAfter = parseRetryAfter(res.Header.Get("Retry-After"), time.Now()); hasRetryAfter {
+				c.block(l, time.Now().Add(retryAfter))
+			}
+		}
+
+		if !retryable || attempt >= c.maxRetry || !isRetryableStatus(res.StatusCode) {
+			return res, nil
+		}
+
+		delay := max(backoff(attempt), retryAfter)
+		if delay > c.maxWait {
+			return res, nil
+		}
+
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+
+		if !hasRetryAfter {
+			if err := sleep(ctx, delay); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+type sharedResponse struct {
+	res  *http.Response
+	body []byte
+}
+
+func (sr *sharedResponse) newResponse(req *http.Request) *http.Response {
+	res := *sr.res
+	res.Header = sr.res.Header.Clone()
+	res.Body = io.NopCloser(bytes.NewReader(sr.body))
+	res.ContentLength = int64(len(sr.body))
+	res.Request = req
+	return &res
+}
+
+// Do sends the request, key is used for rate limiting and coalescing, e.g.
+// the API key.
+func (c *Client) Do(httpClient *http.Client, req *http.Request, key string) (*http.Response, error) {
+	l := c.getLimiter(key)
+
+	if !isIdempotent(req) {
+		return c.do(httpClient, req, l, false)
+	}
+
+	ch := c.sf.DoChan(key+":"+req.Method+":"+req.URL.String(), func() (any, error) {
+		// shared by the callers, so the first caller cancelling it does
+		// not fail the others
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(req.Context()), coalescedRequestTimeout)
+		defer cancel()
+
+		res, err := c.do(httpClient, req.WithContext(ctx), l, true)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			return nil, err
+		}
+		return &sharedResponse{res: res, body: body}, nil
+	})
+	select {
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*sharedResponse).newResponse(req), nil
+	}
+}
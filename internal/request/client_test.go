@@ -0,0 +1,178 @@
+package request
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		value    string
+		duration time.Duration
+		ok       bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"-1", 0, true},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second, true},
+		{"soon", 0, false},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			duration, ok := parseRetryAfter(tc.value, now)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.duration, duration)
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := range 10 {
+		d := min(backoffBase<<attempt, backoffMax)
+		for range 10 {
+			b := backoff(attempt)
+			assert.GreaterOrEqual(t, b, d/2)
+			assert.LessOrEqual(t, b, d)
+		}
+	}
+}
+
+func TestClientReserve(t *testing.T) {
+	c := NewClient(&ClientConfig{RequestsPerMinute: 60})
+	l := c.getLimiter("key")
+	now := time.Now()
+
+	for range 10 {
+		wait, ok := c.reserve(l, now)
+		assert.True(t, ok)
+		assert.Equal(t, time.Duration(0), wait, "burst")
+	}
+	wait, ok := c.reserve(l, now)
+	assert.True(t, ok)
+	assert.Equal(t, 1*time.Second, wait)
+
+	c.block(l, now.Add(time.Minute))
+	wait, ok = c.reserve(l, now)
+	assert.False(t, ok)
+	assert.Equal(t, time.Minute, wait)
+}
+
+func TestClientGetLimiter(t *testing.T) {
+	c := NewClient(&ClientConfig{RequestsPerMinute: 60})
+	l := c.getLimiter("key")
+	assert.Same(t, l, c.getLimiter("key"))
+	assert.NotSame(t, l, c.getLimiter("other-key"))
+
+	for i := range limiterCapacity * 2 {
+		c.getLimiter(strconv.Itoa(i))
+	}
+	assert.LessOrEqual(t, c.limiterByKey.Len(), limiterCapacity)
+}
+
+func TestClientDo(t *testing.T) {
+	t.Run("retry", func(t *testing.T) {
+		var count atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if count.Add(1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		c := NewClient(&ClientConfig{})
+
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		res, err := c.Do(server.Client(), req, "key")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		body, _ := io.ReadAll(res.Body)
+		assert.Equal(t, "ok", string(body))
+		assert.Equal(t, int32(2), count.Load())
+
+		count.Store(0)
+		req, _ = http.NewRequest(http.MethodPost, server.URL, nil)
+		res, err = c.Do(server.Client(), req, "key")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, res.StatusCode, "not idempotent")
+		assert.Equal(t, int32(1), count.Load())
+	})
+
+	t.Run("coalesce", func(t *testing.T) {
+		var count atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			count.Add(1)
+			time.Sleep(200 * time.Millisecond)
+			w.Write([]byte(r.URL.Path))
+		}))
+		defer server.Close()
+
+		c := NewClient(&ClientConfig{})
+
+		var wg sync.WaitGroup
+		for _, path := range []string{"/a", "/a", "/a", "/b"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+				res, err := c.Do(server.Client(), req, "key")
+				assert.NoError(t, err)
+				body, _ := io.ReadAll(res.Body)
+				assert.Equal(t, path, string(body))
+				assert.Equal(t, req, res.Request)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(2), count.Load())
+	})
+
+	t.Run("coalesce with cancel", func(t *testing.T) {
+		var count atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			count.Add(1)
+			time.Sleep(200 * time.Millisecond)
+			w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		c := NewClient(&ClientConfig{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.Do(server.Client(), req, "key")
+			assert.ErrorIs(t, err, context.Canceled)
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			res, err := c.Do(server.Client(), req, "key")
+			if assert.NoError(t, err) {
+				body, _ := io.ReadAll(res.Body)
+				assert.Equal(t, "ok", string(body))
+			}
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		cancel()
+		wg.Wait()
+		assert.Equal(t, int32(1), count.Load())
+	})
+}
@@ -4,6 +4,7 @@ const (
 	HEADER_REQUEST_ID              = "Request-ID"
 	HEADER_INSTANCE_ID             = "X-StremThru-Instance-ID"
 	HEADER_ORIGIN_INSTANCE_ID      = "X-StremThru-Origin-Instance-ID"
+	HEADER_PEER_VIA                = "X-StremThru-Peer-Via"
 	HEADER_PROXY_AUTHORIZATION     = "Proxy-Authorization"
 	HEADER_STREMTHRU_AUTHORIZATION = "X-StremThru-Authorization"
 	HEADER_STREMTHRU_AUTHENTICATE  = "X-StremThru-Authenticate"
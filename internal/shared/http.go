@@ -11,6 +11,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/MunifTanjim/stremthru/core"
 	"github.com/MunifTanjim/stremthru/internal/config"
@@ -38,6 +39,20 @@ func GetQueryInt(queryParams url.Values, name string, defaultValue int) (int, er
 	return defaultValue, nil
 }
 
+func GetQueryTime(queryParams url.Values, name string) (time.Time, error) {
+	v := queryParams.Get(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, nil
+	}
+	return time.Time{}, errors.New("invalid " + name)
+}
+
 func ReadRequestBodyJSON[T any](r *http.Request, payload T) error {
 	contentType := r.Header.Get("Content-Type")
 	if !strings.Contains(contentType, "application/json") {
@@ -157,7 +172,7 @@ var proxyHttpClientByTunnelType = map[config.TunnelType]*http.Client{
 }
 
 func ProxyResponse(w http.ResponseWriter, r *http.Request, url string, tunnelType config.TunnelType) (bytesWritten int64, err error) {
-	request, err := http.NewRequest(r.Method, url, nil)
+	request, err := http.NewRequestWithContext(r.Context(), r.Method, url, nil)
 	if err != nil {
 		e := ErrorInternalServerError(r, "failed to create request")
 		e.Cause = err
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/url"
 	"path/filepath"
 	"strings"
 	"time"
@@ -17,11 +18,14 @@ import (
 	"github.com/MunifTanjim/stremthru/store/debrider"
 	"github.com/MunifTanjim/stremthru/store/debridlink"
 	"github.com/MunifTanjim/stremthru/store/easydebrid"
+	"github.com/MunifTanjim/stremthru/store/local"
 	"github.com/MunifTanjim/stremthru/store/offcloud"
 	"github.com/MunifTanjim/stremthru/store/pikpak"
 	"github.com/MunifTanjim/stremthru/store/premiumize"
+	"github.com/MunifTanjim/stremthru/store/qbittorrent"
 	"github.com/MunifTanjim/stremthru/store/realdebrid"
 	"github.com/MunifTanjim/stremthru/store/torbox"
+	"github.com/MunifTanjim/stremthru/store/transmission"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -41,6 +45,7 @@ var edStore = easydebrid.NewStoreClient(&easydebrid.StoreClientConfig{
 	HTTPClient: config.GetHTTPClient(config.StoreTunnel.GetTypeForAPI("easydebrid")),
 	UserAgent:  config.StoreClientUserAgent,
 })
+var lcStore = local.NewStoreClient(&local.StoreClientConfig{})
 var pmStore = premiumize.NewStoreClient(&premiumize.StoreClientConfig{
 	HTTPClient: config.GetHTTPClient(config.StoreTunnel.GetTypeForAPI("premiumize")),
 	UserAgent:  config.StoreClientUserAgent,
@@ -53,6 +58,10 @@ var ocStore = offcloud.NewStoreClient(&offcloud.StoreClientConfig{
 	HTTPClient: config.GetHTTPClient(config.StoreTunnel.GetTypeForAPI("offcloud")),
 	UserAgent:  config.StoreClientUserAgent,
 })
+var qbStore = qbittorrent.NewStoreClient(&qbittorrent.StoreClientConfig{
+	HTTPClient: config.GetHTTPClient(config.StoreTunnel.GetTypeForAPI("qbittorrent")),
+	UserAgent:  config.StoreClientUserAgent,
+})
 var rdStore = realdebrid.NewStoreClient(&realdebrid.StoreClientConfig{
 	HTTPClient: config.GetHTTPClient(config.StoreTunnel.GetTypeForAPI("realdebrid")),
 	UserAgent:  "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
@@ -61,6 +70,10 @@ var tbStore = torbox.NewStoreClient(&torbox.StoreClientConfig{
 	HTTPClient: config.GetHTTPClient(config.StoreTunnel.GetTypeForAPI("torbox")),
 	UserAgent:  config.StoreClientUserAgent,
 })
+var trStore = transmission.NewStoreClient(&transmission.StoreClientConfig{
+	HTTPClient: config.GetHTTPClient(config.StoreTunnel.GetTypeForAPI("transmission")),
+	UserAgent:  config.StoreClientUserAgent,
+})
 
 func GetStore(name string) store.Store {
 	switch store.StoreName(name) {
@@ -72,16 +85,22 @@ func GetStore(name string) store.Store {
 		return dlStore
 	case store.StoreNameEasyDebrid:
 		return edStore
+	case store.StoreNameLocal:
+		return lcStore
 	case store.StoreNameOffcloud:
 		return ocStore
 	case store.StoreNamePikPak:
 		return ppStore
 	case store.StoreNamePremiumize:
 		return pmStore
+	case store.StoreNameQBittorrent:
+		return qbStore
 	case store.StoreNameRealDebrid:
 		return rdStore
 	case store.StoreNameTorBox:
 		return tbStore
+	case store.StoreNameTransmission:
+		return trStore
 	default:
 		return nil
 	}
@@ -97,16 +116,22 @@ func GetStoreByCode(code string) store.Store {
 		return dlStore
 	case store.StoreCodeEasyDebrid:
 		return edStore
+	case store.StoreCodeLocal:
+		return lcStore
 	case store.StoreCodeOffcloud:
 		return ocStore
 	case store.StoreCodePikPak:
 		return ppStore
 	case store.StoreCodePremiumize:
 		return pmStore
+	case store.StoreCodeQBittorrent:
+		return qbStore
 	case store.StoreCodeRealDebrid:
 		return rdStore
 	case store.StoreCodeTorBox:
 		return tbStore
+	case store.StoreCodeTransmission:
+		return trStore
 	default:
 		return nil
 	}
@@ -208,18 +233,215 @@ func GenerateStremThruLink(r *http.Request, ctx *context.StoreContext, link stri
 		return nil, err
 	}
 
+	link, err = wrapStoreLinkWithProxy(r, ctx, data.Link)
+	if err != nil {
+		return nil, err
+	}
+	data.Link = link
+
+	return data, nil
+}
+
+func wrapStoreLinkWithProxy(r *http.Request, ctx *context.StoreContext, link string) (string, error) {
 	storeName := string(ctx.Store.GetName())
-	if config.StoreContentProxy.IsEnabled(storeName) && ctx.StoreAuthToken == config.StoreAuthToken.GetToken(ctx.ProxyAuthUser, storeName) {
+	if !ctx.Store.GetName().IsSelfHosted() && config.StoreContentProxy.IsEnabled(storeName) && ctx.StoreAuthToken == config.StoreAuthToken.GetToken(ctx.ProxyAuthUser, storeName) {
 		if ctx.IsProxyAuthorized {
 			tunnelType := config.StoreTunnel.GetTypeForStream(string(ctx.Store.GetName()))
-			proxyLink, err := CreateProxyLink(r, data.Link, nil, tunnelType, 12*time.Hour, ctx.ProxyAuthUser, ctx.ProxyAuthPassword, true, "")
+			proxyLink, err := CreateProxyLink(r, link, nil, tunnelType, 12*time.Hour, ctx.ProxyAuthUser, ctx.ProxyAuthPassword, true, "")
+			if err != nil {
+				return "", err
+			}
+
+			return proxyLink, nil
+		}
+	}
+	return link, nil
+}
+
+type listMagnetsQueryCacheValue struct {
+	Items       []store.ListMagnetsDataItem
+	IsTruncated bool
+}
+
+var listMagnetsQueryCache = cache.NewCache[listMagnetsQueryCacheValue](&cache.CacheConfig{
+	Name:     "store:list-magnets-query",
+	Lifetime: 1 * time.Minute,
+})
+
+const maxListMagnetsQueryItems = 10000
+
+func getListMagnetsQueryCacheKey(s store.Store, apiKey string) string {
+	return string(s.GetName()) + ":" + apiKey
+}
+
+// AddMagnet adds the magnet to the store, and invalidates the magnets
+// listed by ListMagnets.
+func AddMagnet(s store.Store, params *store.AddMagnetParams) (*store.AddMagnetData, error) {
+	data, err := s.AddMagnet(params)
+	listMagnetsQueryCache.Remove(getListMagnetsQueryCacheKey(s, params.GetAPIKey("")))
+	return data, err
+}
+
+// RemoveMagnet removes the magnet from the store, and invalidates the
+// magnets listed by ListMagnets.
+func RemoveMagnet(s store.Store, params *store.RemoveMagnetParams) (*store.RemoveMagnetData, error) {
+	data, err := s.RemoveMagnet(params)
+	listMagnetsQueryCache.Remove(getListMagnetsQueryCacheKey(s, params.GetAPIKey("")))
+	return data, err
+}
+
+// ListMagnets lists the magnets from the store. If the params have search,
+// filter or sort that the store can not handle, all the magnets (up to
+// maxListMagnetsQueryItems) are listed and queried in memory. If there are
+// more magnets, the result is marked as truncated.
+func ListMagnets(s store.Store, params *store.ListMagnetsParams) (*store.ListMagnetsData, error) {
+	if !params.HasQuery() {
+		return s.ListMagnets(params)
+	}
+	if q, ok := s.(store.ListMagnetsQuerier); ok && q.CanQueryListMagnets() {
+		return s.ListMagnets(params)
+	}
+
+	cacheKey := getListMagnetsQueryCacheKey(s, params.GetAPIKey(""))
+	cached := listMagnetsQueryCacheValue{}
+	if !listMagnetsQueryCache.Get(cacheKey, &cached) {
+		items := []store.ListMagnetsDataItem{}
+		isTruncated := false
+		for {
+			lmParams := &store.ListMagnetsParams{
+				Ctx:      params.Ctx,
+				Limit:    500,
+				Offset:   len(items),
+				ClientIP: params.ClientIP,
+			}
+			res, err := s.ListMagnets(lmParams)
 			if err != nil {
 				return nil, err
 			}
+			items = append(items, res.Items...)
+			if len(res.Items) == 0 || len(items) >= res.TotalItems {
+				break
+			}
+			if len(items) >= maxListMagnetsQueryItems {
+				isTruncated = true
+				break
+			}
+			time.Sleep(500 * time.Millisecond)
+		}
+		cached = listMagnetsQueryCacheValue{Items: items, IsTruncated: isTruncated}
+		listMagnetsQueryCache.Add(cacheKey, cached)
+	}
+
+	items := store.QueryListMagnetsItems(params, cached.Items)
+
+	totalItems := len(items)
+	limit := params.Limit
+	if limit == 0 {
+		limit = 100
+	}
+	start := min(params.Offset, totalItems)
+	end := min(start+limit, totalItems)
+
+	data := &store.ListMagnetsData{
+		Items:       items[start:end],
+		TotalItems:  totalItems,
+		IsTruncated: cached.IsTruncated,
+	}
+	return data, nil
+}
+
+var storeHostsCache = cache.NewCache[[]string](&cache.CacheConfig{
+	Name:     "store:hosts",
+	Lifetime: 6 * time.Hour,
+})
+
+func getLinkUnrestrictor(s store.Store) (store.LinkUnrestrictor, error) {
+	if lu, ok := s.(store.LinkUnrestrictor); ok {
+		return lu, nil
+	}
+	error := core.NewAPIError("store does not support link unrestriction")
+	error.StoreName = string(s.GetName())
+	error.StatusCode = http.StatusNotImplemented
+	error.Code = core.ErrorCodeNotImplemented
+	return nil, error
+}
+
+// ListStoreHosts returns the file hoster domains supported by the store.
+// The list is same for every user of a store, so it is cached by store name.
+func ListStoreHosts(s store.Store, storeToken string) ([]string, error) {
+	lu, err := getLinkUnrestrictor(s)
+	if err != nil {
+		return nil, err
+	}
 
-			data.Link = proxyLink
+	cacheKey := string(s.GetName())
+	hosts := []string{}
+	if storeHostsCache.Get(cacheKey, &hosts) {
+		return hosts, nil
+	}
+
+	params := &store.ListHostsParams{}
+	params.APIKey = storeToken
+	data, err := lu.ListHosts(params)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts = make([]string, 0, len(data.Items))
+	for _, host := range data.Items {
+		if host = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(host)), "www."); host != "" {
+			hosts = append(hosts, host)
 		}
 	}
+	storeHostsCache.Add(cacheKey, hosts)
+	return hosts, nil
+}
+
+// IsHostLink reports whether the link points to one of the file hoster
+// domains in hosts, including their subdomains.
+func IsHostLink(link string, hosts []string) bool {
+	if len(hosts) == 0 {
+		return false
+	}
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	hostname := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if hostname == "" {
+		return false
+	}
+	for _, host := range hosts {
+		if hostname == host || strings.HasSuffix(hostname, "."+host) {
+			return true
+		}
+	}
+	return false
+}
+
+func UnrestrictStremThruLink(r *http.Request, ctx *context.StoreContext, link, password string) (*store.UnrestrictLinkData, error) {
+	lu, err := getLinkUnrestrictor(ctx.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	params := &store.UnrestrictLinkParams{}
+	params.APIKey = ctx.StoreAuthToken
+	params.Link = link
+	params.Password = password
+	if ctx.ClientIP != "" {
+		params.ClientIP = ctx.ClientIP
+	}
+
+	data, err := lu.UnrestrictLink(params)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Link, err = wrapStoreLinkWithProxy(r, ctx, data.Link)
+	if err != nil {
+		return nil, err
+	}
 
 	return data, nil
 }
@@ -0,0 +1,81 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/MunifTanjim/stremthru/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsHostLink(t *testing.T) {
+	hosts := []string{"1fichier.com", "rapidgator.net"}
+
+	for _, tc := range []struct {
+		name   string
+		link   string
+		hosts  []string
+		result bool
+	}{
+		{"exact", "https://1fichier.com/?abc123", hosts, true},
+		{"www", "https://www.1fichier.com/?abc123", hosts, true},
+		{"subdomain", "https://dl.rapidgator.net/file/abc", hosts, true},
+		{"uppercase", "https://RAPIDGATOR.NET/file/abc", hosts, true},
+		{"suffix only", "https://notrapidgator.net/file/abc", hosts, false},
+		{"unknown", "https://example.com/file.mkv", hosts, false},
+		{"non-http", "ftp://1fichier.com/abc", hosts, false},
+		{"no hosts", "https://1fichier.com/?abc123", nil, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.result, IsHostLink(tc.link, tc.hosts))
+		})
+	}
+}
+
+type listMagnetsTestStore struct {
+	store.Store
+	items     []store.ListMagnetsDataItem
+	listCount int
+}
+
+func (s *listMagnetsTestStore) GetName() store.StoreName {
+	return store.StoreNameRealDebrid
+}
+
+func (s *listMagnetsTestStore) ListMagnets(params *store.ListMagnetsParams) (*store.ListMagnetsData, error) {
+	s.listCount++
+	start := min(params.Offset, len(s.items))
+	end := min(start+params.Limit, len(s.items))
+	return &store.ListMagnetsData{Items: s.items[start:end], TotalItems: len(s.items)}, nil
+}
+
+func (s *listMagnetsTestStore) AddMagnet(params *store.AddMagnetParams) (*store.AddMagnetData, error) {
+	s.items = append(s.items, store.ListMagnetsDataItem{Hash: params.Magnet, Name: params.Magnet})
+	return &store.AddMagnetData{Hash: params.Magnet}, nil
+}
+
+func TestListMagnetsInvalidation(t *testing.T) {
+	s := &listMagnetsTestStore{
+		items: []store.ListMagnetsDataItem{{Hash: "a", Name: "Movie A"}},
+	}
+	params := &store.ListMagnetsParams{Query: "movie"}
+	params.APIKey = "list-magnets-invalidation"
+
+	data, err := ListMagnets(s, params)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, data.TotalItems)
+	assert.False(t, data.IsTruncated)
+
+	_, err = ListMagnets(s, params)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, s.listCount, "cached")
+
+	amParams := &store.AddMagnetParams{Magnet: "movie b"}
+	amParams.APIKey = params.APIKey
+	_, err = AddMagnet(s, amParams)
+	assert.NoError(t, err)
+
+	data, err = ListMagnets(s, params)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, s.listCount, "invalidated")
+	assert.Equal(t, 2, data.TotalItems)
+}
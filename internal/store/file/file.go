@@ -0,0 +1,184 @@
+package store_file
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/core"
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/kv"
+	"github.com/MunifTanjim/stremthru/internal/util"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const LinkExpiresIn = 12 * time.Hour
+
+var secretStore = kv.NewKVStore[string](&kv.KVStoreConfig{
+	Type: "store:file",
+})
+
+var secret struct {
+	sync.Mutex
+	value string
+}
+
+// getSecret returns the key used for signing file links. It is generated
+// once and persisted, so that the links survive restarts.
+func getSecret() (string, error) {
+	secret.Lock()
+	defer secret.Unlock()
+
+	if secret.value != "" {
+		return secret.value, nil
+	}
+
+	value := ""
+	if err := secretStore.GetValue("secret", &value); err != nil {
+		return "", err
+	}
+	if value == "" {
+		bytes := make([]byte, 32)
+		if _, err := rand.Read(bytes); err != nil {
+			return "", err
+		}
+		value = hex.EncodeToString(bytes)
+		if err := secretStore.Set("secret", value); err != nil {
+			return "", err
+		}
+	}
+	secret.value = value
+	return value, nil
+}
+
+type linkTokenData struct {
+	EncPath string `json:"enc_path"`
+}
+
+// isAllowed checks if the local path is inside `STREMTHRU_STORE_FILE_PATH_MAP`
+// or `STREMTHRU_STORE_LOCAL_DIR`.
+func isAllowed(localPath string) bool {
+	if config.StoreFilePathMap.IsAllowed(localPath) {
+		return true
+	}
+	localPath = filepath.Clean(localPath)
+	for _, dir := range config.StoreLocalDirs {
+		if util.IsPathWithin(dir, localPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolvePath maps the path reported by a self-hosted store to the local
+// path, based on `STREMTHRU_STORE_FILE_PATH_MAP`.
+func ResolvePath(remotePath string) (string, error) {
+	localPath, ok := config.StoreFilePathMap.Resolve(remotePath)
+	if !ok {
+		err := core.NewAPIError("file path not allowed")
+		err.StatusCode = http.StatusForbidden
+		return "", err
+	}
+	return localPath, nil
+}
+
+// CreateLink returns a signed link for serving the local file.
+func CreateLink(localPath string) (string, error) {
+	if !isAllowed(localPath) {
+		err := core.NewAPIError("file path not allowed")
+		err.StatusCode = http.StatusForbidden
+		return "", err
+	}
+
+	key, err := getSecret()
+	if err != nil {
+		return "", err
+	}
+
+	encPath, err := core.Encrypt(key, localPath)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := core.CreateJWT(key, core.JWTClaims[linkTokenData]{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "stremthru",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(LinkExpiresIn)),
+		},
+		Data: &linkTokenData{
+			EncPath: encPath,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return config.BaseURL.JoinPath("/v0/store/_/file", token, filepath.Base(localPath)).String(), nil
+}
+
+func parseLinkToken(token string) (string, error) {
+	key, err := getSecret()
+	if err != nil {
+		return "", err
+	}
+
+	claims := &core.JWTClaims[linkTokenData]{}
+	if _, err := core.ParseJWT(func(t *jwt.Token) (any, error) {
+		return []byte(key), nil
+	}, token, claims); err != nil {
+		rerr := core.NewAPIError("invalid token")
+		rerr.StatusCode = http.StatusUnauthorized
+		rerr.Cause = err
+		return "", rerr
+	}
+	if claims.Data == nil {
+		err := core.NewAPIError("invalid token")
+		err.StatusCode = http.StatusUnauthorized
+		return "", err
+	}
+
+	return core.Decrypt(key, claims.Data.EncPath)
+}
+
+// Serve serves the file for the signed link token, with support for range
+// requests.
+func Serve(token string, w http.ResponseWriter, r *http.Request) error {
+	localPath, err := parseLinkToken(token)
+	if err != nil {
+		return err
+	}
+	if !isAllowed(localPath) {
+		err := core.NewAPIError("file path not allowed")
+		err.StatusCode = http.StatusForbidden
+		return err
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			err := core.NewAPIError("file not found")
+			err.StatusCode = http.StatusNotFound
+			return err
+		}
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if stat.IsDir() {
+		err := core.NewAPIError("file not found")
+		err.StatusCode = http.StatusNotFound
+		return err
+	}
+
+	http.ServeContent(w, r, stat.Name(), stat.ModTime(), file)
+	return nil
+}
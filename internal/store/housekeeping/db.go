@@ -0,0 +1,145 @@
+package store_housekeeping
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/db"
+	"github.com/MunifTanjim/stremthru/internal/util"
+	"github.com/rs/xid"
+)
+
+const LogTableName = "store_housekeeping_log"
+
+type Log struct {
+	Id        string       `json:"id"`
+	User      string       `json:"-"`
+	Store     string       `json:"store"`
+	Hash      string       `json:"hash"`
+	MagnetId  string       `json:"magnet_id"`
+	Name      string       `json:"name"`
+	Reason    Reason       `json:"reason"`
+	DryRun    bool         `json:"dry_run"`
+	Error     string       `json:"error,omitempty"`
+	CreatedAt db.Timestamp `json:"created_at"`
+}
+
+var LogColumn = struct {
+	Id        string
+	User      string
+	Store     string
+	Hash      string
+	MagnetId  string
+	Name      string
+	Reason    string
+	DryRun    string
+	Error     string
+	CreatedAt string
+}{
+	Id:        "id",
+	User:      "username",
+	Store:     "store",
+	Hash:      "hash",
+	MagnetId:  "magnet_id",
+	Name:      "name",
+	Reason:    "reason",
+	DryRun:    "dry_run",
+	Error:     "err",
+	CreatedAt: "cat",
+}
+
+var LogColumns = []string{
+	LogColumn.Id,
+	LogColumn.User,
+	LogColumn.Store,
+	LogColumn.Hash,
+	LogColumn.MagnetId,
+	LogColumn.Name,
+	LogColumn.Reason,
+	LogColumn.DryRun,
+	LogColumn.Error,
+	LogColumn.CreatedAt,
+}
+
+var query_insert_log = fmt.Sprintf(
+	`INSERT INTO %s (%s) VALUES (%s)`,
+	LogTableName,
+	db.JoinColumnNames(LogColumns[:9]...),
+	util.RepeatJoin("?", 9, ", "),
+)
+
+func insertLog(l *Log) error {
+	l.Id = xid.New().String()
+	l.CreatedAt = db.Timestamp{Time: time.Now()}
+	_, err := db.Exec(
+		query_insert_log,
+		l.Id,
+		l.User,
+		l.Store,
+		l.Hash,
+		l.MagnetId,
+		l.Name,
+		l.Reason,
+		l.DryRun,
+		l.Error,
+	)
+	return err
+}
+
+var query_get_logs = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ? AND %s = ? ORDER BY %s DESC, %s DESC LIMIT ?`,
+	db.JoinColumnNames(LogColumns...),
+	LogTableName,
+	LogColumn.User,
+	LogColumn.Store,
+	LogColumn.CreatedAt,
+	LogColumn.Id,
+)
+
+// GetLogs lists the housekeeping logs for the user's store, newest first.
+func GetLogs(user, storeName string, limit int) ([]Log, error) {
+	rows, err := db.Query(query_get_logs, user, storeName, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := []Log{}
+	for rows.Next() {
+		l := Log{}
+		if err := rows.Scan(
+			&l.Id,
+			&l.User,
+			&l.Store,
+			&l.Hash,
+			&l.MagnetId,
+			&l.Name,
+			&l.Reason,
+			&l.DryRun,
+			&l.Error,
+			&l.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+var query_prune_logs = fmt.Sprintf(
+	`DELETE FROM %s WHERE %s < ?`,
+	LogTableName,
+	LogColumn.CreatedAt,
+)
+
+// pruneLogs removes logs older than the retention period.
+func pruneLogs(retention time.Duration) (int64, error) {
+	res, err := db.Exec(query_prune_logs, db.Timestamp{Time: time.Now().Add(-retention)})
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,144 @@
+package store_housekeeping
+
+import (
+	"slices"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/magnet_cache"
+	"github.com/MunifTanjim/stremthru/internal/shared"
+	"github.com/MunifTanjim/stremthru/internal/torrent_stream"
+	"github.com/MunifTanjim/stremthru/store"
+)
+
+const logRetention = 30 * 24 * time.Hour
+
+type Result struct {
+	Checked int
+	Matched int
+	Removed int
+	Failed  int
+}
+
+func listAllMagnets(s store.Store, token string) ([]store.ListMagnetsDataItem, error) {
+	items := []store.ListMagnetsDataItem{}
+	for {
+		params := &store.ListMagnetsParams{
+			Limit:  500,
+			Offset: len(items),
+		}
+		params.APIKey = token
+		res, err := s.ListMagnets(params)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, res.Items...)
+		if len(res.Items) == 0 || len(items) >= res.TotalItems {
+			break
+		}
+		time.Sleep(1 * time.Second)
+	}
+	return items, nil
+}
+
+// getTouchedAtByHash returns the last activity on this instance, it is not
+// tracked by user. On a store account shared by users, a magnet checked or
+// played by any of them is touched.
+func getTouchedAtByHash(storeCode store.StoreCode, items []store.ListMagnetsDataItem) (map[string]time.Time, error) {
+	touchedAtByHash := map[string]time.Time{}
+	for cItems := range slices.Chunk(items, 500) {
+		hashes := make([]string, len(cItems))
+		for i := range cItems {
+			hashes[i] = cItems[i].Hash
+		}
+
+		mcTouchedAt, err := magnet_cache.GetModifiedAtByHashes(storeCode, hashes)
+		if err != nil {
+			return nil, err
+		}
+		tsTouchedAt, err := torrent_stream.GetUpdatedAtByHashes(hashes)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, hash := range hashes {
+			touchedAt := mcTouchedAt[hash]
+			if t, ok := tsTouchedAt[hash]; ok && t.After(touchedAt) {
+				touchedAt = t
+			}
+			if !touchedAt.IsZero() {
+				touchedAtByHash[hash] = touchedAt
+			}
+		}
+	}
+	return touchedAtByHash, nil
+}
+
+// Run prunes the user's store account using the rule. In dry-run mode, the
+// matched magnets are only logged.
+func Run(user string, storeName string, rule config.StoreHousekeepingRule) (*Result, error) {
+	s := shared.GetStore(storeName)
+	token := config.StoreAuthToken.GetToken(user, storeName)
+	if s == nil || token == "" {
+		return &Result{}, nil
+	}
+
+	items, err := listAllMagnets(s, token)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Checked: len(items)}
+
+	touchedAtByHash := map[string]time.Time{}
+	if rule.UnplayedFor > 0 {
+		touchedAtByHash, err = getTouchedAtByHash(s.GetName().Code(), items)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	candidates := Evaluate(rule, items, touchedAtByHash, time.Now())
+	result.Matched = len(candidates)
+
+	for i := range candidates {
+		c := &candidates[i]
+		l := &Log{
+			User:     user,
+			Store:    storeName,
+			Hash:     c.Item.Hash,
+			MagnetId: c.Item.Id,
+			Name:     c.Item.Name,
+			Reason:   c.Reason,
+			DryRun:   rule.DryRun,
+		}
+
+		if !rule.DryRun {
+			params := &store.RemoveMagnetParams{
+				Id: c.Item.Id,
+			}
+			params.APIKey = token
+			if _, err := shared.RemoveMagnet(s, params); err != nil {
+				log.Warn("failed to remove magnet", "error", err, "user", user, "store", storeName, "hash", c.Item.Hash, "reason", c.Reason)
+				l.Error = err.Error()
+				result.Failed++
+			} else {
+				log.Info("removed magnet", "user", user, "store", storeName, "hash", c.Item.Hash, "reason", c.Reason)
+				result.Removed++
+			}
+			time.Sleep(500 * time.Millisecond)
+		} else {
+			log.Info("would remove magnet", "user", user, "store", storeName, "hash", c.Item.Hash, "reason", c.Reason)
+		}
+
+		if err := insertLog(l); err != nil {
+			log.Error("failed to insert log", "error", err, "user", user, "store", storeName, "hash", c.Item.Hash)
+		}
+	}
+
+	if _, err := pruneLogs(logRetention); err != nil {
+		log.Warn("failed to prune logs", "error", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,83 @@
+package store_housekeeping
+
+import (
+	"slices"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/store"
+)
+
+type Reason string
+
+const (
+	ReasonFailed   Reason = "failed"
+	ReasonStalled  Reason = "stalled"
+	ReasonAge      Reason = "age"
+	ReasonUnplayed Reason = "unplayed"
+)
+
+type Candidate struct {
+	Item   store.ListMagnetsDataItem
+	Reason Reason
+}
+
+func isFailedStatus(status store.MagnetStatus) bool {
+	return status == store.MagnetStatusFailed || status == store.MagnetStatusInvalid
+}
+
+func isStalledStatus(status store.MagnetStatus) bool {
+	return status == store.MagnetStatusQueued || status == store.MagnetStatusDownloading
+}
+
+// hasKnownTime is false for zero and unix epoch, used by some stores when
+// the time is not available.
+func hasKnownTime(t time.Time) bool {
+	return t.Unix() > 0
+}
+
+// Evaluate returns the magnets that should be removed by the rule, with the
+// first matching reason. The `KeepRecent` most recently added magnets are
+// never removed. `touchedAtByHash` holds the last time a magnet was touched,
+// i.e. checked or played, falling back to the time it was added. Magnets
+// with unknown added time, i.e. zero or unix epoch, are only removed if failed.
+func Evaluate(rule config.StoreHousekeepingRule, items []store.ListMagnetsDataItem, touchedAtByHash map[string]time.Time, now time.Time) []Candidate {
+	items = slices.Clone(items)
+	slices.SortStableFunc(items, func(a, b store.ListMagnetsDataItem) int {
+		return b.AddedAt.Compare(a.AddedAt)
+	})
+
+	candidates := []Candidate{}
+	for i := range items {
+		if i < rule.KeepRecent {
+			continue
+		}
+
+		item := items[i]
+		hasAddedAt := hasKnownTime(item.AddedAt)
+
+		reason := Reason("")
+		switch {
+		case rule.RemoveFailed && isFailedStatus(item.Status):
+			reason = ReasonFailed
+		case !hasAddedAt:
+		case rule.StalledFor > 0 && isStalledStatus(item.Status) && now.Sub(item.AddedAt) > rule.StalledFor:
+			reason = ReasonStalled
+		case rule.MaxAge > 0 && now.Sub(item.AddedAt) > rule.MaxAge:
+			reason = ReasonAge
+		case rule.UnplayedFor > 0:
+			touchedAt := item.AddedAt
+			if t, ok := touchedAtByHash[item.Hash]; ok && t.After(touchedAt) {
+				touchedAt = t
+			}
+			if now.Sub(touchedAt) > rule.UnplayedFor {
+				reason = ReasonUnplayed
+			}
+		}
+
+		if reason != "" {
+			candidates = append(candidates, Candidate{Item: item, Reason: reason})
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,89 @@
+package store_housekeeping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	items := []store.ListMagnetsDataItem{
+		{Id: "1", Hash: "h1", Status: store.MagnetStatusDownloaded, AddedAt: now.Add(-1 * day)},
+		{Id: "2", Hash: "h2", Status: store.MagnetStatusFailed, AddedAt: now.Add(-2 * day)},
+		{Id: "3", Hash: "h3", Status: store.MagnetStatusDownloading, AddedAt: now.Add(-3 * day)},
+		{Id: "4", Hash: "h4", Status: store.MagnetStatusDownloaded, AddedAt: now.Add(-40 * day)},
+		{Id: "5", Hash: "h5", Status: store.MagnetStatusDownloaded, AddedAt: now.Add(-20 * day)},
+		{Id: "6", Hash: "h6", Status: store.MagnetStatusDownloaded, AddedAt: now.Add(-20 * day)},
+		{Id: "7", Hash: "h7", Status: store.MagnetStatusDownloaded, AddedAt: time.Unix(0, 0).UTC()},
+		{Id: "8", Hash: "h8", Status: store.MagnetStatusDownloading},
+		{Id: "9", Hash: "h9", Status: store.MagnetStatusFailed, AddedAt: time.Unix(0, 0).UTC()},
+	}
+	touchedAtByHash := map[string]time.Time{
+		"h6": now.Add(-1 * day),
+	}
+
+	getIds := func(candidates []Candidate) map[string]Reason {
+		ids := map[string]Reason{}
+		for _, c := range candidates {
+			ids[c.Item.Id] = c.Reason
+		}
+		return ids
+	}
+
+	for _, tc := range []struct {
+		name   string
+		rule   config.StoreHousekeepingRule
+		result map[string]Reason
+	}{
+		{
+			"failed",
+			config.StoreHousekeepingRule{RemoveFailed: true},
+			map[string]Reason{"2": ReasonFailed, "9": ReasonFailed},
+		},
+		{
+			"stalled",
+			config.StoreHousekeepingRule{StalledFor: 2 * day},
+			map[string]Reason{"3": ReasonStalled},
+		},
+		{
+			"age",
+			config.StoreHousekeepingRule{MaxAge: 30 * day},
+			map[string]Reason{"4": ReasonAge},
+		},
+		{
+			"unplayed",
+			config.StoreHousekeepingRule{UnplayedFor: 14 * day},
+			map[string]Reason{"4": ReasonUnplayed, "5": ReasonUnplayed},
+		},
+		{
+			"combined",
+			config.StoreHousekeepingRule{RemoveFailed: true, MaxAge: 30 * day, UnplayedFor: 14 * day},
+			map[string]Reason{"2": ReasonFailed, "4": ReasonAge, "5": ReasonUnplayed, "9": ReasonFailed},
+		},
+		{
+			"keep recent",
+			config.StoreHousekeepingRule{RemoveFailed: true, MaxAge: 30 * day, KeepRecent: 2},
+			map[string]Reason{"4": ReasonAge, "9": ReasonFailed},
+		},
+		{
+			"unknown added at",
+			config.StoreHousekeepingRule{StalledFor: 1 * time.Hour, MaxAge: 1 * time.Hour, UnplayedFor: 1 * time.Hour, KeepRecent: 6},
+			map[string]Reason{},
+		},
+		{
+			"keep all",
+			config.StoreHousekeepingRule{MaxAge: 1 * time.Hour, KeepRecent: 10},
+			map[string]Reason{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.result, getIds(Evaluate(tc.rule, items, touchedAtByHash, now)))
+		})
+	}
+}
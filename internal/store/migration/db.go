@@ -0,0 +1,461 @@
+package store_migration
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/db"
+	"github.com/MunifTanjim/stremthru/internal/util"
+	"github.com/rs/xid"
+)
+
+type JobStatus string
+
+const (
+	JobStatusQueued  JobStatus = "queued"
+	JobStatusRunning JobStatus = "running"
+	JobStatusDone    JobStatus = "done"
+	JobStatusFailed  JobStatus = "failed"
+)
+
+type ItemStatus string
+
+const (
+	ItemStatusPending ItemStatus = "pending"
+	ItemStatusAdded   ItemStatus = "added"   // added to destination store
+	ItemStatusExists  ItemStatus = "exists"  // already in destination store
+	ItemStatusSkipped ItemStatus = "skipped" // failed / invalid in source store
+	ItemStatusFailed  ItemStatus = "failed"
+)
+
+const JobTableName = "store_migration_job"
+
+type Job struct {
+	Id        string       `json:"id"`
+	User      string       `json:"-"`
+	SrcStore  string       `json:"source_store"`
+	DstStore  string       `json:"destination_store"`
+	Status    JobStatus    `json:"status"`
+	Total     int          `json:"total"`
+	Added     int          `json:"added"`
+	Skipped   int          `json:"skipped"`
+	Failed    int          `json:"failed"`
+	Error     string       `json:"error,omitempty"`
+	Checked   bool         `json:"-"` // pending items are checked for cached
+	CreatedAt db.Timestamp `json:"created_at"`
+	UpdatedAt db.Timestamp `json:"updated_at"`
+}
+
+var JobColumn = struct {
+	Id        string
+	User      string
+	SrcStore  string
+	DstStore  string
+	Status    string
+	Total     string
+	Added     string
+	Skipped   string
+	Failed    string
+	Error     string
+	Checked   string
+	CreatedAt string
+	UpdatedAt string
+}{
+	Id:        "id",
+	User:      "username",
+	SrcStore:  "src_store",
+	DstStore:  "dst_store",
+	Status:    "status",
+	Total:     "total",
+	Added:     "added",
+	Skipped:   "skipped",
+	Failed:    "failed",
+	Error:     "err",
+	Checked:   "checked",
+	CreatedAt: "cat",
+	UpdatedAt: "uat",
+}
+
+var JobColumns = []string{
+	JobColumn.Id,
+	JobColumn.User,
+	JobColumn.SrcStore,
+	JobColumn.DstStore,
+	JobColumn.Status,
+	JobColumn.Total,
+	JobColumn.Added,
+	JobColumn.Skipped,
+	JobColumn.Failed,
+	JobColumn.Error,
+	JobColumn.Checked,
+	JobColumn.CreatedAt,
+	JobColumn.UpdatedAt,
+}
+
+const ItemTableName = "store_migration_item"
+
+type Item struct {
+	JobId     string       `json:"-"`
+	Hash      string       `json:"hash"`
+	Name      string       `json:"name"`
+	Cached    bool         `json:"cached"`
+	Status    ItemStatus   `json:"status"`
+	Error     string       `json:"error,omitempty"`
+	UpdatedAt db.Timestamp `json:"updated_at"`
+}
+
+var ItemColumn = struct {
+	JobId     string
+	Hash      string
+	Name      string
+	Cached    string
+	Status    string
+	Error     string
+	UpdatedAt string
+}{
+	JobId:     "job_id",
+	Hash:      "hash",
+	Name:      "name",
+	Cached:    "cached",
+	Status:    "status",
+	Error:     "err",
+	UpdatedAt: "uat",
+}
+
+var ItemColumns = []string{
+	ItemColumn.JobId,
+	ItemColumn.Hash,
+	ItemColumn.Name,
+	ItemColumn.Cached,
+	ItemColumn.Status,
+	ItemColumn.Error,
+	ItemColumn.UpdatedAt,
+}
+
+func scanJob(row interface{ Scan(dest ...any) error }) (*Job, error) {
+	j := Job{}
+	if err := row.Scan(
+		&j.Id,
+		&j.User,
+		&j.SrcStore,
+		&j.DstStore,
+		&j.Status,
+		&j.Total,
+		&j.Added,
+		&j.Skipped,
+		&j.Failed,
+		&j.Error,
+		&j.Checked,
+		&j.CreatedAt,
+		&j.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+var query_create_job = fmt.Sprintf(
+	`INSERT INTO %s (%s) VALUES (%s)`,
+	JobTableName,
+	db.JoinColumnNames(JobColumn.Id, JobColumn.User, JobColumn.SrcStore, JobColumn.DstStore, JobColumn.Status),
+	util.RepeatJoin("?", 5, ", "),
+)
+
+func CreateJob(user, srcStore, dstStore string) (*Job, error) {
+	now := db.Timestamp{Time: time.Now()}
+	j := &Job{
+		Id:        xid.New().String(),
+		User:      user,
+		SrcStore:  srcStore,
+		DstStore:  dstStore,
+		Status:    JobStatusQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	_, err := db.Exec(query_create_job, j.Id, j.User, j.SrcStore, j.DstStore, j.Status)
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+var query_get_job = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ?`,
+	db.JoinColumnNames(JobColumns...),
+	JobTableName,
+	JobColumn.Id,
+)
+
+func GetJob(id string) (*Job, error) {
+	j, err := scanJob(db.QueryRow(query_get_job, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return j, nil
+}
+
+func queryJobs(query string, args ...any) ([]Job, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []Job{}
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+var query_get_jobs_by_user = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ? ORDER BY %s DESC, %s DESC LIMIT ?`,
+	db.JoinColumnNames(JobColumns...),
+	JobTableName,
+	JobColumn.User,
+	JobColumn.CreatedAt,
+	JobColumn.Id,
+)
+
+// GetJobsByUser lists the user's migration jobs, newest first.
+func GetJobsByUser(user string, limit int) ([]Job, error) {
+	return queryJobs(query_get_jobs_by_user, user, limit)
+}
+
+var query_get_pending_jobs = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s IN (?, ?) ORDER BY %s ASC, %s ASC`,
+	db.JoinColumnNames(JobColumns...),
+	JobTableName,
+	JobColumn.Status,
+	JobColumn.CreatedAt,
+	JobColumn.Id,
+)
+
+// GetPendingJobs lists the jobs that are queued or running, oldest first.
+// The jobs need to be claimed with `ClaimJob` before running.
+func GetPendingJobs() ([]Job, error) {
+	return queryJobs(query_get_pending_jobs, JobStatusQueued, JobStatusRunning)
+}
+
+// jobStaleTime is the time after which a running job, that is not updated,
+// is considered interrupted, i.e. the instance running it has crashed.
+const jobStaleTime = 15 * time.Minute
+
+var query_claim_job = fmt.Sprintf(
+	`UPDATE %s SET %s = ?, %s = '', %s = %s WHERE %s = ? AND (%s = ? OR (%s = ? AND %s < ?))`,
+	JobTableName,
+	JobColumn.Status,
+	JobColumn.Error,
+	JobColumn.UpdatedAt,
+	db.CurrentTimestamp,
+	JobColumn.Id,
+	JobColumn.Status,
+	JobColumn.Status,
+	JobColumn.UpdatedAt,
+)
+
+// ClaimJob marks the job as running, if it is queued or was interrupted.
+// It returns false if the job is already claimed, e.g. by another instance.
+func ClaimJob(j *Job) (bool, error) {
+	staleBefore := db.Timestamp{Time: time.Now().Add(-jobStaleTime)}
+	res, err := db.Exec(query_claim_job, JobStatusRunning, j.Id, JobStatusQueued, JobStatusRunning, staleBefore)
+	if err != nil {
+		return false, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if count == 0 {
+		return false, nil
+	}
+	j.Status = JobStatusRunning
+	j.Error = ""
+	j.UpdatedAt = db.Timestamp{Time: time.Now()}
+	return true, nil
+}
+
+var query_has_active_job = fmt.Sprintf(
+	`SELECT 1 FROM %s WHERE %s = ? AND %s = ? AND %s = ? AND %s IN (?, ?) LIMIT 1`,
+	JobTableName,
+	JobColumn.User,
+	JobColumn.SrcStore,
+	JobColumn.DstStore,
+	JobColumn.Status,
+)
+
+func HasActiveJob(user, srcStore, dstStore string) (bool, error) {
+	var one int
+	err := db.QueryRow(query_has_active_job, user, srcStore, dstStore, JobStatusQueued, JobStatusRunning).Scan(&one)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+var query_update_job = fmt.Sprintf(
+	`UPDATE %s SET %s = ?, %s = ?, %s = ?, %s = ?, %s = ?, %s = ?, %s = ?, %s = %s WHERE %s = ?`,
+	JobTableName,
+	JobColumn.Status,
+	JobColumn.Total,
+	JobColumn.Added,
+	JobColumn.Skipped,
+	JobColumn.Failed,
+	JobColumn.Error,
+	JobColumn.Checked,
+	JobColumn.UpdatedAt,
+	db.CurrentTimestamp,
+	JobColumn.Id,
+)
+
+func updateJob(j *Job) error {
+	_, err := db.Exec(query_update_job, j.Status, j.Total, j.Added, j.Skipped, j.Failed, j.Error, j.Checked, j.Id)
+	if err != nil {
+		return err
+	}
+	j.UpdatedAt = db.Timestamp{Time: time.Now()}
+	return nil
+}
+
+var query_insert_items_before_values = fmt.Sprintf(
+	`INSERT INTO %s (%s) VALUES `,
+	ItemTableName,
+	db.JoinColumnNames(ItemColumns[:5]...),
+)
+var query_insert_items_placeholder = "(" + util.RepeatJoin("?", 5, ",") + ")"
+var query_insert_items_after_values = fmt.Sprintf(
+	` ON CONFLICT (%s, %s) DO NOTHING`,
+	ItemColumn.JobId,
+	ItemColumn.Hash,
+)
+
+func insertItems(items []Item) error {
+	for cItems := range slices.Chunk(items, 200) {
+		args := make([]any, 0, len(cItems)*5)
+		for i := range cItems {
+			item := &cItems[i]
+			args = append(args, item.JobId, item.Hash, item.Name, item.Cached, item.Status)
+		}
+		query := query_insert_items_before_values +
+			util.RepeatJoin(query_insert_items_placeholder, len(cItems), ",") +
+			query_insert_items_after_values
+		if _, err := db.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var query_get_items = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ?`,
+	db.JoinColumnNames(ItemColumns...),
+	ItemTableName,
+	ItemColumn.JobId,
+)
+
+// GetItems lists the job's items, optionally filtered by status.
+func GetItems(jobId string, statuses ...ItemStatus) ([]Item, error) {
+	query := query_get_items
+	args := []any{jobId}
+	if len(statuses) > 0 {
+		query += fmt.Sprintf(" AND %s IN (%s)", ItemColumn.Status, util.RepeatJoin("?", len(statuses), ","))
+		for _, status := range statuses {
+			args = append(args, status)
+		}
+	}
+	query += fmt.Sprintf(" ORDER BY %s DESC, %s ASC", ItemColumn.Cached, ItemColumn.Hash)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []Item{}
+	for rows.Next() {
+		item := Item{}
+		if err := rows.Scan(
+			&item.JobId,
+			&item.Hash,
+			&item.Name,
+			&item.Cached,
+			&item.Status,
+			&item.Error,
+			&item.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+var query_update_item = fmt.Sprintf(
+	`UPDATE %s SET %s = ?, %s = ?, %s = ?, %s = %s WHERE %s = ? AND %s = ?`,
+	ItemTableName,
+	ItemColumn.Cached,
+	ItemColumn.Status,
+	ItemColumn.Error,
+	ItemColumn.UpdatedAt,
+	db.CurrentTimestamp,
+	ItemColumn.JobId,
+	ItemColumn.Hash,
+)
+
+func updateItem(item *Item) error {
+	_, err := db.Exec(query_update_item, item.Cached, item.Status, item.Error, item.JobId, item.Hash)
+	if err != nil {
+		return err
+	}
+	item.UpdatedAt = db.Timestamp{Time: time.Now()}
+	return nil
+}
+
+var query_count_items_by_status = fmt.Sprintf(
+	`SELECT %s, COUNT(*) FROM %s WHERE %s = ? GROUP BY %s`,
+	ItemColumn.Status,
+	ItemTableName,
+	ItemColumn.JobId,
+	ItemColumn.Status,
+)
+
+func countItemsByStatus(jobId string) (map[ItemStatus]int, error) {
+	rows, err := db.Query(query_count_items_by_status, jobId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	countByStatus := map[ItemStatus]int{}
+	for rows.Next() {
+		var status ItemStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		countByStatus[status] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return countByStatus, nil
+}
@@ -0,0 +1,7 @@
+package store_migration
+
+import (
+	"github.com/MunifTanjim/stremthru/internal/logger"
+)
+
+var log = logger.Scoped("store/migration")
@@ -0,0 +1,213 @@
+package store_migration
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/shared"
+	"github.com/MunifTanjim/stremthru/store"
+)
+
+func listAllMagnets(s store.Store, token string) ([]store.ListMagnetsDataItem, error) {
+	items := []store.ListMagnetsDataItem{}
+	for {
+		params := &store.ListMagnetsParams{
+			Limit:  500,
+			Offset: len(items),
+		}
+		params.APIKey = token
+		res, err := s.ListMagnets(params)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, res.Items...)
+		if len(res.Items) == 0 || len(items) >= res.TotalItems {
+			break
+		}
+		time.Sleep(2 * time.Second)
+	}
+	return items, nil
+}
+
+// markCached checks the pending items against the destination store and
+// flags the cached ones, so that they can be added first.
+func markCached(j *Job, s store.Store, token string, items []Item) error {
+	for cItems := range slices.Chunk(items, 100) {
+		// keeps the claim on the job
+		if err := updateJob(j); err != nil {
+			return err
+		}
+		hashes := make([]string, len(cItems))
+		for i := range cItems {
+			hashes[i] = cItems[i].Hash
+		}
+		params := &store.CheckMagnetParams{
+			Magnets: hashes,
+		}
+		params.APIKey = token
+		res, err := s.CheckMagnet(params)
+		if err != nil {
+			return err
+		}
+		isCachedByHash := map[string]bool{}
+		for _, item := range res.Items {
+			isCachedByHash[strings.ToLower(item.Hash)] = item.Status == store.MagnetStatusCached
+		}
+		for i := range cItems {
+			item := &cItems[i]
+			if isCachedByHash[item.Hash] && !item.Cached {
+				item.Cached = true
+				if err := updateItem(item); err != nil {
+					return err
+				}
+			}
+		}
+		time.Sleep(1 * time.Second)
+	}
+	return nil
+}
+
+func syncJobProgress(j *Job) error {
+	countByStatus, err := countItemsByStatus(j.Id)
+	if err != nil {
+		return err
+	}
+	j.Total = 0
+	for _, count := range countByStatus {
+		j.Total += count
+	}
+	j.Added = countByStatus[ItemStatusAdded]
+	j.Skipped = countByStatus[ItemStatusExists] + countByStatus[ItemStatusSkipped]
+	j.Failed = countByStatus[ItemStatusFailed]
+	return updateJob(j)
+}
+
+func run(ctx context.Context, j *Job) error {
+	srcStore := shared.GetStore(j.SrcStore)
+	dstStore := shared.GetStore(j.DstStore)
+	if srcStore == nil || dstStore == nil {
+		return errors.New("invalid store")
+	}
+	srcToken := config.StoreAuthToken.GetToken(j.User, j.SrcStore)
+	dstToken := config.StoreAuthToken.GetToken(j.User, j.DstStore)
+	if srcToken == "" || dstToken == "" {
+		return errors.New("missing store token")
+	}
+
+	items, err := GetItems(j.Id)
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		srcItems, err := listAllMagnets(srcStore, srcToken)
+		if err != nil {
+			return err
+		}
+		dstItems, err := listAllMagnets(dstStore, dstToken)
+		if err != nil {
+			return err
+		}
+		dstHashes := make(map[string]struct{}, len(dstItems))
+		for i := range dstItems {
+			dstHashes[strings.ToLower(dstItems[i].Hash)] = struct{}{}
+		}
+
+		items = Plan(j.Id, srcItems, dstHashes)
+		if err := insertItems(items); err != nil {
+			return err
+		}
+		if err := syncJobProgress(j); err != nil {
+			return err
+		}
+	}
+
+	// an interrupted job is checked again, the cached items are added first
+	if !j.Checked {
+		pendingItems := []Item{}
+		for i := range items {
+			if items[i].Status == ItemStatusPending {
+				pendingItems = append(pendingItems, items[i])
+			}
+		}
+		if err := markCached(j, dstStore, dstToken, pendingItems); err != nil {
+			return err
+		}
+		j.Checked = true
+		if err := updateJob(j); err != nil {
+			return err
+		}
+	}
+
+	pendingItems, err := GetItems(j.Id, ItemStatusPending)
+	if err != nil {
+		return err
+	}
+
+	for i := range pendingItems {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		item := &pendingItems[i]
+
+		params := &store.AddMagnetParams{
+			Magnet: item.Hash,
+		}
+		params.APIKey = dstToken
+		if _, err := shared.AddMagnet(dstStore, params); err != nil {
+			log.Warn("failed to add magnet", "error", err, "job_id", j.Id, "store", j.DstStore, "hash", item.Hash)
+			item.Status = ItemStatusFailed
+			item.Error = err.Error()
+		} else {
+			log.Debug("added magnet", "job_id", j.Id, "store", j.DstStore, "hash", item.Hash, "cached", item.Cached)
+			item.Status = ItemStatusAdded
+		}
+		if err := updateItem(item); err != nil {
+			return err
+		}
+		if (i+1)%10 == 0 {
+			if err := syncJobProgress(j); err != nil {
+				return err
+			}
+		}
+
+		// uncached magnets start a download on the destination store
+		if item.Cached {
+			time.Sleep(1 * time.Second)
+		} else {
+			time.Sleep(3 * time.Second)
+		}
+	}
+
+	return syncJobProgress(j)
+}
+
+// Run moves the magnets from the job's source store to its destination
+// store. Cached magnets are added first. The job needs to be claimed with
+// `ClaimJob` first. An interrupted job resumes from the pending items, and
+// a cancelled job is queued again.
+func Run(ctx context.Context, j *Job) error {
+	if err := run(ctx, j); err != nil {
+		if ctx.Err() != nil {
+			j.Status = JobStatusQueued
+			if uErr := updateJob(j); uErr != nil {
+				return errors.Join(err, uErr)
+			}
+			return err
+		}
+		j.Status = JobStatusFailed
+		j.Error = err.Error()
+		if uErr := updateJob(j); uErr != nil {
+			return errors.Join(err, uErr)
+		}
+		return err
+	}
+
+	j.Status = JobStatusDone
+	return updateJob(j)
+}
@@ -0,0 +1,40 @@
+package store_migration
+
+import (
+	"strings"
+
+	"github.com/MunifTanjim/stremthru/store"
+)
+
+// Plan builds the migration items for the job from the magnets in the source
+// store. Magnets already present in the destination store are marked as
+// exists, failed / invalid ones as skipped.
+func Plan(jobId string, srcItems []store.ListMagnetsDataItem, dstHashes map[string]struct{}) []Item {
+	items := []Item{}
+	seenHash := map[string]struct{}{}
+	for i := range srcItems {
+		src := &srcItems[i]
+		hash := strings.ToLower(src.Hash)
+		if hash == "" {
+			continue
+		}
+		if _, seen := seenHash[hash]; seen {
+			continue
+		}
+		seenHash[hash] = struct{}{}
+
+		item := Item{
+			JobId:  jobId,
+			Hash:   hash,
+			Name:   src.Name,
+			Status: ItemStatusPending,
+		}
+		if _, exists := dstHashes[hash]; exists {
+			item.Status = ItemStatusExists
+		} else if src.Status == store.MagnetStatusFailed || src.Status == store.MagnetStatusInvalid {
+			item.Status = ItemStatusSkipped
+		}
+		items = append(items, item)
+	}
+	return items
+}
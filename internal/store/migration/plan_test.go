@@ -0,0 +1,39 @@
+package store_migration
+
+import (
+	"testing"
+
+	"github.com/MunifTanjim/stremthru/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlan(t *testing.T) {
+	srcItems := []store.ListMagnetsDataItem{
+		{Hash: "AAA", Name: "a", Status: store.MagnetStatusDownloaded},
+		{Hash: "bbb", Name: "b", Status: store.MagnetStatusDownloaded},
+		{Hash: "ccc", Name: "c", Status: store.MagnetStatusFailed},
+		{Hash: "ddd", Name: "d", Status: store.MagnetStatusInvalid},
+		{Hash: "aaa", Name: "a (dup)", Status: store.MagnetStatusDownloaded},
+		{Hash: "", Name: "empty", Status: store.MagnetStatusDownloaded},
+		{Hash: "eee", Name: "e", Status: store.MagnetStatusQueued},
+	}
+	dstHashes := map[string]struct{}{
+		"bbb": {},
+	}
+
+	items := Plan("job", srcItems, dstHashes)
+
+	statusByHash := map[string]ItemStatus{}
+	for _, item := range items {
+		assert.Equal(t, "job", item.JobId)
+		statusByHash[item.Hash] = item.Status
+	}
+	assert.Equal(t, map[string]ItemStatus{
+		"aaa": ItemStatusPending,
+		"bbb": ItemStatusExists,
+		"ccc": ItemStatusSkipped,
+		"ddd": ItemStatusSkipped,
+		"eee": ItemStatusPending,
+	}, statusByHash)
+	assert.Equal(t, "a", items[0].Name)
+}
@@ -0,0 +1,191 @@
+package stremio_backup
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/db"
+	stremio_api "github.com/MunifTanjim/stremthru/internal/stremio/api"
+)
+
+const (
+	MinInterval  = 6 * time.Hour
+	MaxRetention = 30
+)
+
+var client = stremio_api.NewClient(&stremio_api.ClientConfig{})
+
+func hashData(data []byte) string {
+	hash := sha1.Sum(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func takeSnapshot(accountId, authKey string, kind SnapshotKind, retention int) (*Snapshot, error) {
+	var data any
+	itemCount := 0
+	switch kind {
+	case SnapshotKindAddons:
+		params := &stremio_api.GetAddonsParams{}
+		params.APIKey = authKey
+		res, err := client.GetAddons(params)
+		if err != nil {
+			return nil, err
+		}
+		data = res.Data.Addons
+		itemCount = len(res.Data.Addons)
+	case SnapshotKindLibrary:
+		params := &stremio_api.GetAllLibraryItemsParams{}
+		params.APIKey = authKey
+		res, err := client.GetAllLibraryItems(params)
+		if err != nil {
+			return nil, err
+		}
+		items := make([]stremio_api.LibraryItem, 0, len(res.Data))
+		for i := range res.Data {
+			if !res.Data[i].Removed {
+				items = append(items, res.Data[i])
+			}
+		}
+		data = items
+		itemCount = len(items)
+	default:
+		return nil, errors.New("invalid snapshot kind")
+	}
+
+	blob, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := hashData(blob)
+	lastHash, err := getLastSnapshotHash(accountId, kind)
+	if err != nil {
+		return nil, err
+	}
+	if hash == lastHash {
+		return nil, nil
+	}
+
+	snapshot := &Snapshot{
+		AccountId: accountId,
+		Kind:      kind,
+		Hash:      hash,
+		ItemCount: itemCount,
+		Data:      string(blob),
+	}
+	if err := insertSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+
+	if count, err := pruneSnapshots(accountId, kind, retention); err != nil {
+		log.Error("failed to prune snapshots", "error", err, "account_id", accountId, "kind", kind)
+	} else if count > 0 {
+		log.Debug("pruned snapshots", "account_id", accountId, "kind", kind, "count", count)
+	}
+
+	return snapshot, nil
+}
+
+// Run takes a snapshot of the addons and library for the schedule, skipping
+// the ones that did not change since the last snapshot. The schedule is
+// disabled if the stored auth key is no longer valid.
+func Run(s *Schedule) error {
+	var errs []error
+	for _, kind := range []SnapshotKind{SnapshotKindAddons, SnapshotKindLibrary} {
+		snapshot, err := takeSnapshot(s.AccountId, s.AuthKey, kind, s.Retention)
+		if err != nil {
+			var rerr *stremio_api.ResponseError
+			if errors.As(err, &rerr) && rerr.Code == stremio_api.ErrorCodeSessionNotFound {
+				s.Enabled = false
+				errs = []error{errors.New("session expired, login again to re-enable scheduled backups")}
+				break
+			}
+			errs = append(errs, err)
+			continue
+		}
+		if snapshot != nil {
+			log.Debug("created snapshot", "account_id", s.AccountId, "kind", kind, "id", snapshot.Id, "item_count", snapshot.ItemCount)
+		}
+	}
+
+	err := errors.Join(errs...)
+	s.LastRunAt = db.Timestamp{Time: time.Now()}
+	s.LastError = ""
+	if err != nil {
+		s.LastError = err.Error()
+	}
+	if serr := setScheduleResult(s); serr != nil {
+		return errors.Join(err, serr)
+	}
+	return err
+}
+
+// Rollback replaces the current addons or library of the account with the
+// ones in the snapshot. For library, items added after the snapshot are
+// marked as removed.
+func Rollback(snapshot *Snapshot, authKey string) error {
+	switch snapshot.Kind {
+	case SnapshotKindAddons:
+		addons, err := snapshot.GetAddons()
+		if err != nil {
+			return err
+		}
+		params := &stremio_api.SetAddonsParams{Addons: addons}
+		params.APIKey = authKey
+		res, err := client.SetAddons(params)
+		if err != nil {
+			return err
+		}
+		if !res.Data.Success {
+			return errors.New("failed to set addons")
+		}
+		return nil
+	case SnapshotKindLibrary:
+		items, err := snapshot.GetLibraryItems()
+		if err != nil {
+			return err
+		}
+
+		getParams := &stremio_api.GetAllLibraryItemsParams{}
+		getParams.APIKey = authKey
+		current, err := client.GetAllLibraryItems(getParams)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		itemIds := make(map[string]struct{}, len(items))
+		changes := make([]stremio_api.LibraryItem, 0, len(items))
+		for i := range items {
+			item := items[i]
+			itemIds[item.Id] = struct{}{}
+			item.MTime = now
+			changes = append(changes, item)
+		}
+		for i := range current.Data {
+			item := current.Data[i]
+			if _, ok := itemIds[item.Id]; ok || item.Removed {
+				continue
+			}
+			item.Removed = true
+			item.MTime = now
+			changes = append(changes, item)
+		}
+
+		params := &stremio_api.UpdateLibraryItemsParams{Changes: changes}
+		params.APIKey = authKey
+		res, err := client.UpdateLibraryItems(params)
+		if err != nil {
+			return err
+		}
+		if !res.Data.Success {
+			return errors.New("failed to update library items")
+		}
+		return nil
+	default:
+		return errors.New("invalid snapshot kind")
+	}
+}
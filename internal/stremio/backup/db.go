@@ -0,0 +1,407 @@
+package stremio_backup
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/db"
+	"github.com/MunifTanjim/stremthru/internal/util"
+	"github.com/rs/xid"
+)
+
+const ScheduleTableName = "stremio_backup_schedule"
+
+type Schedule struct {
+	AccountId string
+	Email     string
+	AuthKey   string
+	Interval  time.Duration
+	Retention int
+	Enabled   bool
+	LastRunAt db.Timestamp
+	LastError string
+	CreatedAt db.Timestamp
+	UpdatedAt db.Timestamp
+}
+
+func (s *Schedule) IsDue() bool {
+	if !s.Enabled {
+		return false
+	}
+	return s.LastRunAt.IsZero() || time.Now().After(s.LastRunAt.Add(s.Interval))
+}
+
+var ScheduleColumn = struct {
+	AccountId string
+	Email     string
+	AuthKey   string
+	Interval  string
+	Retention string
+	Enabled   string
+	LastRunAt string
+	LastError string
+	CreatedAt string
+	UpdatedAt string
+}{
+	AccountId: "account_id",
+	Email:     "email",
+	AuthKey:   "auth_key",
+	Interval:  "interval_seconds",
+	Retention: "retention",
+	Enabled:   "enabled",
+	LastRunAt: "lrat",
+	LastError: "lerr",
+	CreatedAt: "cat",
+	UpdatedAt: "uat",
+}
+
+var ScheduleColumns = []string{
+	ScheduleColumn.AccountId,
+	ScheduleColumn.Email,
+	ScheduleColumn.AuthKey,
+	ScheduleColumn.Interval,
+	ScheduleColumn.Retention,
+	ScheduleColumn.Enabled,
+	ScheduleColumn.LastRunAt,
+	ScheduleColumn.LastError,
+	ScheduleColumn.CreatedAt,
+	ScheduleColumn.UpdatedAt,
+}
+
+func scanSchedule(row interface{ Scan(dest ...any) error }) (*Schedule, error) {
+	s := &Schedule{}
+	var interval int64
+	if err := row.Scan(
+		&s.AccountId,
+		&s.Email,
+		&s.AuthKey,
+		&interval,
+		&s.Retention,
+		&s.Enabled,
+		&s.LastRunAt,
+		&s.LastError,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	s.Interval = time.Duration(interval) * time.Second
+	return s, nil
+}
+
+var query_get_schedule = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ?`,
+	db.JoinColumnNames(ScheduleColumns...),
+	ScheduleTableName,
+	ScheduleColumn.AccountId,
+)
+
+func GetSchedule(accountId string) (*Schedule, error) {
+	s, err := scanSchedule(db.QueryRow(query_get_schedule, accountId))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+var query_get_enabled_schedules = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = %s`,
+	db.JoinColumnNames(ScheduleColumns...),
+	ScheduleTableName,
+	ScheduleColumn.Enabled,
+	db.BooleanTrue,
+)
+
+func GetEnabledSchedules() ([]Schedule, error) {
+	rows, err := db.Query(query_get_enabled_schedules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []Schedule{}
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+var query_upsert_schedule = fmt.Sprintf(
+	`INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s`,
+	ScheduleTableName,
+	db.JoinColumnNames(ScheduleColumns[:6]...),
+	util.RepeatJoin("?", 6, ", "),
+	ScheduleColumn.AccountId,
+	strings.Join([]string{
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ScheduleColumn.Email, ScheduleColumn.Email),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ScheduleColumn.AuthKey, ScheduleColumn.AuthKey),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ScheduleColumn.Interval, ScheduleColumn.Interval),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ScheduleColumn.Retention, ScheduleColumn.Retention),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ScheduleColumn.Enabled, ScheduleColumn.Enabled),
+		fmt.Sprintf(`%s = ''`, ScheduleColumn.LastError),
+		fmt.Sprintf(`%s = %s`, ScheduleColumn.UpdatedAt, db.CurrentTimestamp),
+	}, ", "),
+)
+
+func UpsertSchedule(s *Schedule) error {
+	_, err := db.Exec(
+		query_upsert_schedule,
+		s.AccountId,
+		s.Email,
+		s.AuthKey,
+		int64(s.Interval.Seconds()),
+		s.Retention,
+		s.Enabled,
+	)
+	return err
+}
+
+var query_set_schedule_auth_key = fmt.Sprintf(
+	`UPDATE %s SET %s = ?, %s = ?, %s = %s WHERE %s = ?`,
+	ScheduleTableName,
+	ScheduleColumn.Email,
+	ScheduleColumn.AuthKey,
+	ScheduleColumn.UpdatedAt,
+	db.CurrentTimestamp,
+	ScheduleColumn.AccountId,
+)
+
+// SetScheduleAuthKey refreshes the stored auth key, if a schedule exists
+// for the account.
+func SetScheduleAuthKey(accountId, email, authKey string) error {
+	_, err := db.Exec(query_set_schedule_auth_key, email, authKey, accountId)
+	return err
+}
+
+var query_set_schedule_result = fmt.Sprintf(
+	`UPDATE %s SET %s = ?, %s = ?, %s = ?, %s = %s WHERE %s = ?`,
+	ScheduleTableName,
+	ScheduleColumn.Enabled,
+	ScheduleColumn.LastRunAt,
+	ScheduleColumn.LastError,
+	ScheduleColumn.UpdatedAt,
+	db.CurrentTimestamp,
+	ScheduleColumn.AccountId,
+)
+
+func setScheduleResult(s *Schedule) error {
+	_, err := db.Exec(query_set_schedule_result, s.Enabled, s.LastRunAt, s.LastError, s.AccountId)
+	return err
+}
+
+const SnapshotTableName = "stremio_backup_snapshot"
+
+type SnapshotKind string
+
+const (
+	SnapshotKindAddons  SnapshotKind = "addons"
+	SnapshotKindLibrary SnapshotKind = "library"
+)
+
+func (k SnapshotKind) IsValid() bool {
+	return k == SnapshotKindAddons || k == SnapshotKindLibrary
+}
+
+type Snapshot struct {
+	Id        string
+	AccountId string
+	Kind      SnapshotKind
+	Hash      string
+	ItemCount int
+	Data      string `json:"-"`
+	CreatedAt db.Timestamp
+}
+
+var SnapshotColumn = struct {
+	Id        string
+	AccountId string
+	Kind      string
+	Hash      string
+	ItemCount string
+	Data      string
+	CreatedAt string
+}{
+	Id:        "id",
+	AccountId: "account_id",
+	Kind:      "kind",
+	Hash:      "hash",
+	ItemCount: "item_count",
+	Data:      "data",
+	CreatedAt: "cat",
+}
+
+var SnapshotColumns = []string{
+	SnapshotColumn.Id,
+	SnapshotColumn.AccountId,
+	SnapshotColumn.Kind,
+	SnapshotColumn.Hash,
+	SnapshotColumn.ItemCount,
+	SnapshotColumn.Data,
+	SnapshotColumn.CreatedAt,
+}
+
+var query_get_snapshot = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ? AND %s = ?`,
+	db.JoinColumnNames(SnapshotColumns...),
+	SnapshotTableName,
+	SnapshotColumn.AccountId,
+	SnapshotColumn.Id,
+)
+
+func GetSnapshot(accountId, id string) (*Snapshot, error) {
+	row := db.QueryRow(query_get_snapshot, accountId, id)
+	s := &Snapshot{}
+	if err := row.Scan(
+		&s.Id,
+		&s.AccountId,
+		&s.Kind,
+		&s.Hash,
+		&s.ItemCount,
+		&s.Data,
+		&s.CreatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+var query_get_snapshots = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ? ORDER BY %s DESC, %s DESC`,
+	db.JoinColumnNames(SnapshotColumns[:5]...)+", "+SnapshotColumn.CreatedAt,
+	SnapshotTableName,
+	SnapshotColumn.AccountId,
+	SnapshotColumn.CreatedAt,
+	SnapshotColumn.Id,
+)
+
+// GetSnapshots lists the snapshots for the account, newest first, without
+// their data.
+func GetSnapshots(accountId string) ([]Snapshot, error) {
+	rows, err := db.Query(query_get_snapshots, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := []Snapshot{}
+	for rows.Next() {
+		s := Snapshot{}
+		if err := rows.Scan(
+			&s.Id,
+			&s.AccountId,
+			&s.Kind,
+			&s.Hash,
+			&s.ItemCount,
+			&s.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+var query_get_last_snapshot_hash = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ? AND %s = ? ORDER BY %s DESC, %s DESC LIMIT 1`,
+	SnapshotColumn.Hash,
+	SnapshotTableName,
+	SnapshotColumn.AccountId,
+	SnapshotColumn.Kind,
+	SnapshotColumn.CreatedAt,
+	SnapshotColumn.Id,
+)
+
+func getLastSnapshotHash(accountId string, kind SnapshotKind) (string, error) {
+	var hash string
+	if err := db.QueryRow(query_get_last_snapshot_hash, accountId, kind).Scan(&hash); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+	return hash, nil
+}
+
+var query_insert_snapshot = fmt.Sprintf(
+	`INSERT INTO %s (%s) VALUES (%s)`,
+	SnapshotTableName,
+	db.JoinColumnNames(SnapshotColumns[:6]...),
+	util.RepeatJoin("?", 6, ", "),
+)
+
+func insertSnapshot(s *Snapshot) error {
+	s.Id = xid.New().String()
+	s.CreatedAt = db.Timestamp{Time: time.Now()}
+	_, err := db.Exec(
+		query_insert_snapshot,
+		s.Id,
+		s.AccountId,
+		s.Kind,
+		s.Hash,
+		s.ItemCount,
+		s.Data,
+	)
+	return err
+}
+
+var query_prune_snapshots = fmt.Sprintf(
+	`DELETE FROM %s WHERE %s = ? AND %s = ? AND %s NOT IN (SELECT %s FROM %s WHERE %s = ? AND %s = ? ORDER BY %s DESC, %s DESC LIMIT ?)`,
+	SnapshotTableName,
+	SnapshotColumn.AccountId,
+	SnapshotColumn.Kind,
+	SnapshotColumn.Id,
+	SnapshotColumn.Id,
+	SnapshotTableName,
+	SnapshotColumn.AccountId,
+	SnapshotColumn.Kind,
+	SnapshotColumn.CreatedAt,
+	SnapshotColumn.Id,
+)
+
+// pruneSnapshots keeps the latest `retention` snapshots of the kind.
+func pruneSnapshots(accountId string, kind SnapshotKind, retention int) (int64, error) {
+	res, err := db.Exec(query_prune_snapshots, accountId, kind, accountId, kind, retention)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+var query_delete_snapshots = fmt.Sprintf(
+	`DELETE FROM %s WHERE %s = ?`,
+	SnapshotTableName,
+	SnapshotColumn.AccountId,
+)
+
+var query_delete_schedule = fmt.Sprintf(
+	`DELETE FROM %s WHERE %s = ?`,
+	ScheduleTableName,
+	ScheduleColumn.AccountId,
+)
+
+// DeleteAll removes the schedule and every snapshot of the account.
+func DeleteAll(accountId string) error {
+	if _, err := db.Exec(query_delete_snapshots, accountId); err != nil {
+		return err
+	}
+	_, err := db.Exec(query_delete_schedule, accountId)
+	return err
+}
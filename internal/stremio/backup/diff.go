@@ -0,0 +1,208 @@
+package stremio_backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	stremio_api "github.com/MunifTanjim/stremthru/internal/stremio/api"
+	"github.com/MunifTanjim/stremthru/stremio"
+)
+
+func (s *Snapshot) GetAddons() ([]stremio.Addon, error) {
+	if s.Kind != SnapshotKindAddons {
+		return nil, errors.New("not an addons snapshot")
+	}
+	addons := []stremio.Addon{}
+	if err := json.Unmarshal([]byte(s.Data), &addons); err != nil {
+		return nil, err
+	}
+	return addons, nil
+}
+
+func (s *Snapshot) GetLibraryItems() ([]stremio_api.LibraryItem, error) {
+	if s.Kind != SnapshotKindLibrary {
+		return nil, errors.New("not a library snapshot")
+	}
+	items := []stremio_api.LibraryItem{}
+	if err := json.Unmarshal([]byte(s.Data), &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+type DiffEntry struct {
+	Id     string
+	Name   string
+	Detail string
+}
+
+type Diff struct {
+	Kind      SnapshotKind
+	Added     []DiffEntry
+	Removed   []DiffEntry
+	Changed   []DiffEntry
+	Reordered bool
+}
+
+func (d *Diff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 && !d.Reordered
+}
+
+// GetDiff compares snapshot `from` against snapshot `to`, i.e. the changes
+// needed to go from the former to the latter.
+func GetDiff(from, to *Snapshot) (*Diff, error) {
+	if from.Kind != to.Kind {
+		return nil, errors.New("snapshot kind mismatch")
+	}
+
+	switch from.Kind {
+	case SnapshotKindAddons:
+		fromAddons, err := from.GetAddons()
+		if err != nil {
+			return nil, err
+		}
+		toAddons, err := to.GetAddons()
+		if err != nil {
+			return nil, err
+		}
+		return diffAddons(fromAddons, toAddons), nil
+	case SnapshotKindLibrary:
+		fromItems, err := from.GetLibraryItems()
+		if err != nil {
+			return nil, err
+		}
+		toItems, err := to.GetLibraryItems()
+		if err != nil {
+			return nil, err
+		}
+		return diffLibraryItems(fromItems, toItems), nil
+	default:
+		return nil, errors.New("invalid snapshot kind")
+	}
+}
+
+// isAddonModified checks for the other changes, e.g. catalogs, flags or
+// behavior hints modified by sidekick.
+func isAddonModified(a, b *stremio.Addon) bool {
+	aBlob, aErr := json.Marshal(a)
+	bBlob, bErr := json.Marshal(b)
+	return aErr != nil || bErr != nil || !bytes.Equal(aBlob, bBlob)
+}
+
+func diffAddons(from, to []stremio.Addon) *Diff {
+	diff := &Diff{Kind: SnapshotKindAddons}
+
+	fromByUrl := make(map[string]*stremio.Addon, len(from))
+	for i := range from {
+		fromByUrl[from[i].TransportUrl] = &from[i]
+	}
+	toByUrl := make(map[string]*stremio.Addon, len(to))
+	for i := range to {
+		toByUrl[to[i].TransportUrl] = &to[i]
+	}
+
+	commonFrom := []string{}
+	for i := range from {
+		addon := &from[i]
+		if _, ok := toByUrl[addon.TransportUrl]; !ok {
+			diff.Removed = append(diff.Removed, DiffEntry{Id: addon.TransportUrl, Name: addon.Manifest.Name})
+		} else {
+			commonFrom = append(commonFrom, addon.TransportUrl)
+		}
+	}
+
+	commonTo := []string{}
+	for i := range to {
+		addon := &to[i]
+		prev, ok := fromByUrl[addon.TransportUrl]
+		if !ok {
+			diff.Added = append(diff.Added, DiffEntry{Id: addon.TransportUrl, Name: addon.Manifest.Name})
+			continue
+		}
+		commonTo = append(commonTo, addon.TransportUrl)
+		if prev.Manifest.Version != addon.Manifest.Version {
+			diff.Changed = append(diff.Changed, DiffEntry{
+				Id:     addon.TransportUrl,
+				Name:   addon.Manifest.Name,
+				Detail: "v" + prev.Manifest.Version + " → v" + addon.Manifest.Version,
+			})
+		} else if prev.Manifest.Name != addon.Manifest.Name {
+			diff.Changed = append(diff.Changed, DiffEntry{
+				Id:     addon.TransportUrl,
+				Name:   addon.Manifest.Name,
+				Detail: "renamed from " + prev.Manifest.Name,
+			})
+		} else if isAddonModified(prev, addon) {
+			diff.Changed = append(diff.Changed, DiffEntry{
+				Id:     addon.TransportUrl,
+				Name:   addon.Manifest.Name,
+				Detail: "modified",
+			})
+		}
+	}
+
+	for i := range commonFrom {
+		if commonFrom[i] != commonTo[i] {
+			diff.Reordered = true
+			break
+		}
+	}
+
+	return diff
+}
+
+func isLibraryItemWatchStateChanged(a, b *stremio_api.LibraryItem) bool {
+	return a.State.TimesWatched != b.State.TimesWatched ||
+		a.State.FlaggedWatched != b.State.FlaggedWatched ||
+		a.State.VideoId != b.State.VideoId ||
+		a.State.TimeOffset != b.State.TimeOffset
+}
+
+func diffLibraryItems(from, to []stremio_api.LibraryItem) *Diff {
+	diff := &Diff{Kind: SnapshotKindLibrary}
+
+	fromById := make(map[string]*stremio_api.LibraryItem, len(from))
+	for i := range from {
+		if !from[i].Removed {
+			fromById[from[i].Id] = &from[i]
+		}
+	}
+	toById := make(map[string]*stremio_api.LibraryItem, len(to))
+	for i := range to {
+		if !to[i].Removed {
+			toById[to[i].Id] = &to[i]
+		}
+	}
+
+	for i := range from {
+		item := &from[i]
+		if item.Removed {
+			continue
+		}
+		if _, ok := toById[item.Id]; !ok {
+			diff.Removed = append(diff.Removed, DiffEntry{Id: item.Id, Name: item.Name})
+		}
+	}
+
+	for i := range to {
+		item := &to[i]
+		if item.Removed {
+			continue
+		}
+		prev, ok := fromById[item.Id]
+		if !ok {
+			diff.Added = append(diff.Added, DiffEntry{Id: item.Id, Name: item.Name})
+			continue
+		}
+		if isLibraryItemWatchStateChanged(prev, item) {
+			diff.Changed = append(diff.Changed, DiffEntry{
+				Id:     item.Id,
+				Name:   item.Name,
+				Detail: "watch state changed",
+			})
+		}
+	}
+
+	return diff
+}
@@ -0,0 +1,98 @@
+package stremio_backup
+
+import (
+	"testing"
+
+	stremio_api "github.com/MunifTanjim/stremthru/internal/stremio/api"
+	"github.com/MunifTanjim/stremthru/stremio"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAddon(url, name, version string) stremio.Addon {
+	return stremio.Addon{
+		TransportUrl: url,
+		Manifest: stremio.Manifest{
+			Name:    name,
+			Version: version,
+		},
+	}
+}
+
+func TestDiffAddons(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		from      []stremio.Addon
+		to        []stremio.Addon
+		added     []string
+		removed   []string
+		changed   []string
+		reordered bool
+	}{
+		{
+			name: "unchanged",
+			from: []stremio.Addon{newAddon("a", "A", "1.0.0"), newAddon("b", "B", "1.0.0")},
+			to:   []stremio.Addon{newAddon("a", "A", "1.0.0"), newAddon("b", "B", "1.0.0")},
+		},
+		{
+			name:    "added and removed",
+			from:    []stremio.Addon{newAddon("a", "A", "1.0.0"), newAddon("b", "B", "1.0.0")},
+			to:      []stremio.Addon{newAddon("a", "A", "1.0.0"), newAddon("c", "C", "1.0.0")},
+			added:   []string{"c"},
+			removed: []string{"b"},
+		},
+		{
+			name:    "version changed",
+			from:    []stremio.Addon{newAddon("a", "A", "1.0.0")},
+			to:      []stremio.Addon{newAddon("a", "A", "1.1.0")},
+			changed: []string{"a"},
+		},
+		{
+			name:    "modified",
+			from:    []stremio.Addon{newAddon("a", "A", "1.0.0")},
+			to:      []stremio.Addon{{TransportUrl: "a", Manifest: stremio.Manifest{Name: "A", Version: "1.0.0", Description: "Modified"}}},
+			changed: []string{"a"},
+		},
+		{
+			name:      "reordered",
+			from:      []stremio.Addon{newAddon("a", "A", "1.0.0"), newAddon("b", "B", "1.0.0"), newAddon("c", "C", "1.0.0")},
+			to:        []stremio.Addon{newAddon("b", "B", "1.0.0"), newAddon("a", "A", "1.0.0")},
+			removed:   []string{"c"},
+			reordered: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			diff := diffAddons(tc.from, tc.to)
+			ids := func(entries []DiffEntry) []string {
+				result := []string{}
+				for _, e := range entries {
+					result = append(result, e.Id)
+				}
+				return result
+			}
+			assert.ElementsMatch(t, tc.added, ids(diff.Added))
+			assert.ElementsMatch(t, tc.removed, ids(diff.Removed))
+			assert.ElementsMatch(t, tc.changed, ids(diff.Changed))
+			assert.Equal(t, tc.reordered, diff.Reordered)
+		})
+	}
+}
+
+func TestDiffLibraryItems(t *testing.T) {
+	from := []stremio_api.LibraryItem{
+		{Id: "tt1", Name: "One"},
+		{Id: "tt2", Name: "Two"},
+		{Id: "tt3", Name: "Three", Removed: true},
+	}
+	to := []stremio_api.LibraryItem{
+		{Id: "tt1", Name: "One", State: stremio_api.LibraryItemState{TimesWatched: 1}},
+		{Id: "tt3", Name: "Three"},
+	}
+
+	diff := diffLibraryItems(from, to)
+	assert.Len(t, diff.Added, 1)
+	assert.Equal(t, "tt3", diff.Added[0].Id)
+	assert.Len(t, diff.Removed, 1)
+	assert.Equal(t, "tt2", diff.Removed[0].Id)
+	assert.Len(t, diff.Changed, 1)
+	assert.Equal(t, "tt1", diff.Changed[0].Id)
+}
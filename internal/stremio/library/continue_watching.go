@@ -0,0 +1,41 @@
+package stremio_library
+
+import (
+	"time"
+
+	stremio_api "github.com/MunifTanjim/stremthru/internal/stremio/api"
+)
+
+// IsInContinueWatching mirrors the condition used by Stremio for the
+// "Continue Watching" board.
+func IsInContinueWatching(item *stremio_api.LibraryItem) bool {
+	return item.Type != "other" && (!item.Removed || item.Temp) && item.State.TimeOffset > 0
+}
+
+// GetStaleContinueWatching returns the items in "Continue Watching" that
+// were last watched before `olderThan`.
+func GetStaleContinueWatching(items []stremio_api.LibraryItem, olderThan time.Time) []stremio_api.LibraryItem {
+	stale := []stremio_api.LibraryItem{}
+	for i := range items {
+		item := &items[i]
+		if IsInContinueWatching(item) && item.State.LastWatched.Before(olderThan) {
+			stale = append(stale, *item)
+		}
+	}
+	return stale
+}
+
+// ClearContinueWatching removes the items from "Continue Watching" by
+// resetting the playback offset, the items are kept in the library.
+func ClearContinueWatching(authKey string, items []stremio_api.LibraryItem) (int, error) {
+	changes := make([]stremio_api.LibraryItem, len(items))
+	for i := range items {
+		item := items[i]
+		item.State.TimeOffset = 0
+		changes[i] = item
+	}
+	if err := UpdateItems(authKey, changes); err != nil {
+		return 0, err
+	}
+	return len(changes), nil
+}
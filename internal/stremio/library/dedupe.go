@@ -0,0 +1,163 @@
+package stremio_library
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/MunifTanjim/stremthru/internal/anime"
+	"github.com/MunifTanjim/stremthru/internal/imdb_title"
+	stremio_api "github.com/MunifTanjim/stremthru/internal/stremio/api"
+)
+
+type DuplicateGroup struct {
+	IMDBId string
+	// Items are sorted by last activity, the first one is kept.
+	Items []stremio_api.LibraryItem
+}
+
+func (g DuplicateGroup) Keep() *stremio_api.LibraryItem {
+	return &g.Items[0]
+}
+
+func (g DuplicateGroup) Extra() []stremio_api.LibraryItem {
+	return g.Items[1:]
+}
+
+// resolveDedupeKeys maps the library item ids in different schemes, e.g.
+// `tmdb:`, `kitsu:` and `mal:`, to the key used for deduping.
+//
+// `tt` and `tmdb:` ids are keyed by IMDB id. `kitsu:` and `mal:` ids refer to
+// a single season, while the IMDB id refers to the whole series, so those are
+// keyed by IMDB id and AniDB season, and are not deduped against the series.
+// Anime ids without a known season are left out.
+func resolveDedupeKeys(items []stremio_api.LibraryItem) (map[string]string, error) {
+	keyById := map[string]string{}
+	tmdbMovieIds, tmdbShowIds := []string{}, []string{}
+	kitsuIds, malIds := []string{}, []string{}
+	for i := range items {
+		item := &items[i]
+		id, _, _ := strings.Cut(item.Id, ":")
+		switch {
+		case strings.HasPrefix(item.Id, "tt"):
+			keyById[item.Id] = id
+		case strings.HasPrefix(item.Id, "tmdb:"):
+			tmdbId := strings.TrimPrefix(item.Id, "tmdb:")
+			if item.Type == "movie" {
+				tmdbMovieIds = append(tmdbMovieIds, tmdbId)
+			} else {
+				tmdbShowIds = append(tmdbShowIds, tmdbId)
+			}
+		case strings.HasPrefix(item.Id, "kitsu:"):
+			kitsuIds = append(kitsuIds, strings.TrimPrefix(item.Id, "kitsu:"))
+		case strings.HasPrefix(item.Id, "mal:"):
+			malIds = append(malIds, strings.TrimPrefix(item.Id, "mal:"))
+		}
+	}
+
+	if len(tmdbMovieIds)+len(tmdbShowIds) > 0 {
+		movieImdbIds, showImdbIds, err := imdb_title.GetIMDBIdByTMDBId(tmdbMovieIds, tmdbShowIds)
+		if err != nil {
+			return nil, err
+		}
+		for tmdbId, imdbId := range movieImdbIds {
+			keyById["tmdb:"+tmdbId] = imdbId
+		}
+		for tmdbId, imdbId := range showImdbIds {
+			keyById["tmdb:"+tmdbId] = imdbId
+		}
+	}
+
+	kitsuImdbIds, err := anime.GetIMDBIdByKitsuIds(kitsuIds)
+	if err != nil {
+		return nil, err
+	}
+	for kitsuId, imdbId := range kitsuImdbIds {
+		_, season, err := anime.GetAniDBIdByKitsuId(kitsuId)
+		if err != nil {
+			return nil, err
+		}
+		if season != "" {
+			keyById["kitsu:"+kitsuId] = getSeasonDedupeKey(imdbId, season)
+		}
+	}
+
+	malImdbIds, err := anime.GetIMDBIdByMALIds(malIds)
+	if err != nil {
+		return nil, err
+	}
+	for malId, imdbId := range malImdbIds {
+		_, season, err := anime.GetAniDBIdByMALId(malId)
+		if err != nil {
+			return nil, err
+		}
+		if season != "" {
+			keyById["mal:"+malId] = getSeasonDedupeKey(imdbId, season)
+		}
+	}
+
+	return keyById, nil
+}
+
+func getSeasonDedupeKey(imdbId, season string) string {
+	return imdbId + ":" + season
+}
+
+// FindDuplicates groups the library items that refer to the same title
+// across different id schemes. Removed items are ignored.
+func FindDuplicates(items []stremio_api.LibraryItem) ([]DuplicateGroup, error) {
+	activeItems := make([]stremio_api.LibraryItem, 0, len(items))
+	for i := range items {
+		if !items[i].Removed {
+			activeItems = append(activeItems, items[i])
+		}
+	}
+
+	keyById, err := resolveDedupeKeys(activeItems)
+	if err != nil {
+		return nil, err
+	}
+
+	itemsByKey := map[string][]stremio_api.LibraryItem{}
+	keys := []string{}
+	for i := range activeItems {
+		item := &activeItems[i]
+		key, ok := keyById[item.Id]
+		if !ok {
+			continue
+		}
+		if _, seen := itemsByKey[key]; !seen {
+			keys = append(keys, key)
+		}
+		itemsByKey[key] = append(itemsByKey[key], *item)
+	}
+
+	groups := []DuplicateGroup{}
+	for _, key := range keys {
+		groupItems := itemsByKey[key]
+		if len(groupItems) < 2 {
+			continue
+		}
+		slices.SortStableFunc(groupItems, func(a, b stremio_api.LibraryItem) int {
+			return getLastActivity(&b).Compare(getLastActivity(&a))
+		})
+		imdbId, _, _ := strings.Cut(key, ":")
+		groups = append(groups, DuplicateGroup{IMDBId: imdbId, Items: groupItems})
+	}
+	return groups, nil
+}
+
+// RemoveDuplicates marks all but the most recently active item in each
+// group as removed.
+func RemoveDuplicates(authKey string, groups []DuplicateGroup) (int, error) {
+	changes := []stremio_api.LibraryItem{}
+	for _, g := range groups {
+		for _, item := range g.Extra() {
+			item.Removed = true
+			changes = append(changes, item)
+		}
+	}
+	if err := UpdateItems(authKey, changes); err != nil {
+		return 0, err
+	}
+	return len(changes), nil
+}
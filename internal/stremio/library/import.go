@@ -0,0 +1,371 @@
+package stremio_library
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/imdb_title"
+	stremio_api "github.com/MunifTanjim/stremthru/internal/stremio/api"
+	stremio_shared "github.com/MunifTanjim/stremthru/internal/stremio/shared"
+)
+
+type ImportSource string
+
+const (
+	ImportSourceTrakt      ImportSource = "trakt"
+	ImportSourceLetterboxd ImportSource = "letterboxd"
+)
+
+type WatchedEntry struct {
+	IMDBId    string
+	TMDBId    string
+	Type      string // movie | series
+	Title     string
+	Year      int
+	Season    int
+	Episode   int
+	Plays     int
+	WatchedAt time.Time
+}
+
+type traktIds struct {
+	IMDB string `json:"imdb"`
+	TMDB int    `json:"tmdb"`
+}
+
+type traktMedia struct {
+	Title string   `json:"title"`
+	Year  int      `json:"year"`
+	Ids   traktIds `json:"ids"`
+}
+
+type traktEpisode struct {
+	Season        int       `json:"season"`
+	Number        int       `json:"number"`
+	Plays         int       `json:"plays"`
+	LastWatchedAt time.Time `json:"last_watched_at"`
+}
+
+type traktSeason struct {
+	Number   int            `json:"number"`
+	Episodes []traktEpisode `json:"episodes"`
+}
+
+// traktExportItem covers the items of `watched-movies.json`,
+// `watched-shows.json` and `history.json` in Trakt exports.
+type traktExportItem struct {
+	Type          string        `json:"type"`
+	WatchedAt     time.Time     `json:"watched_at"`
+	LastWatchedAt time.Time     `json:"last_watched_at"`
+	Plays         int           `json:"plays"`
+	Movie         *traktMedia   `json:"movie"`
+	Show          *traktMedia   `json:"show"`
+	Episode       *traktEpisode `json:"episode"`
+	Seasons       []traktSeason `json:"seasons"`
+}
+
+func newTraktWatchedEntry(media *traktMedia, sType string) WatchedEntry {
+	entry := WatchedEntry{
+		IMDBId: media.Ids.IMDB,
+		Type:   sType,
+		Title:  media.Title,
+		Year:   media.Year,
+		Plays:  1,
+	}
+	if media.Ids.TMDB != 0 {
+		entry.TMDBId = strconv.Itoa(media.Ids.TMDB)
+	}
+	return entry
+}
+
+func ParseTraktExport(blob []byte) ([]WatchedEntry, error) {
+	items := []traktExportItem{}
+	if err := json.Unmarshal(blob, &items); err != nil {
+		return nil, err
+	}
+
+	entries := []WatchedEntry{}
+	for i := range items {
+		item := &items[i]
+		watchedAt := item.WatchedAt
+		if watchedAt.IsZero() {
+			watchedAt = item.LastWatchedAt
+		}
+		switch {
+		case item.Movie != nil:
+			entry := newTraktWatchedEntry(item.Movie, "movie")
+			entry.WatchedAt = watchedAt
+			if item.Plays > 0 {
+				entry.Plays = item.Plays
+			}
+			entries = append(entries, entry)
+		case item.Show != nil && item.Episode != nil:
+			entry := newTraktWatchedEntry(item.Show, "series")
+			entry.Season = item.Episode.Season
+			entry.Episode = item.Episode.Number
+			entry.WatchedAt = watchedAt
+			entries = append(entries, entry)
+		case item.Show != nil:
+			for _, season := range item.Seasons {
+				for _, episode := range season.Episodes {
+					entry := newTraktWatchedEntry(item.Show, "series")
+					entry.Season = season.Number
+					entry.Episode = episode.Number
+					entry.WatchedAt = episode.LastWatchedAt
+					if episode.Plays > 0 {
+						entry.Plays = episode.Plays
+					}
+					entries = append(entries, entry)
+				}
+			}
+		}
+	}
+	return entries, nil
+}
+
+// ParseLetterboxdExport parses `diary.csv` or `watched.csv` from Letterboxd
+// exports.
+func ParseLetterboxdExport(blob []byte) ([]WatchedEntry, error) {
+	r := csv.NewReader(bytes.NewReader(blob))
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	colIdx := map[string]int{}
+	for i, name := range header {
+		colIdx[strings.TrimSpace(name)] = i
+	}
+	if _, ok := colIdx["Name"]; !ok {
+		return nil, errors.New("missing column: Name")
+	}
+	getValue := func(record []string, name string) string {
+		if idx, ok := colIdx[name]; ok && idx < len(record) {
+			return strings.TrimSpace(record[idx])
+		}
+		return ""
+	}
+
+	entries := []WatchedEntry{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		entry := WatchedEntry{
+			Type:  "movie",
+			Title: getValue(record, "Name"),
+			Plays: 1,
+		}
+		if entry.Title == "" {
+			continue
+		}
+		entry.Year, _ = strconv.Atoi(getValue(record, "Year"))
+		date := getValue(record, "Watched Date")
+		if date == "" {
+			date = getValue(record, "Date")
+		}
+		if date != "" {
+			entry.WatchedAt, _ = time.Parse(time.DateOnly, date)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func ParseExport(source ImportSource, blob []byte) ([]WatchedEntry, error) {
+	switch source {
+	case ImportSourceTrakt:
+		return ParseTraktExport(blob)
+	case ImportSourceLetterboxd:
+		return ParseLetterboxdExport(blob)
+	default:
+		return nil, errors.New("invalid import source")
+	}
+}
+
+// resolveEntries fills the missing IMDB ids, using the TMDB id mapping first
+// and falling back to title search.
+func resolveEntries(entries []WatchedEntry) error {
+	tmdbMovieIds, tmdbShowIds := []string{}, []string{}
+	for i := range entries {
+		entry := &entries[i]
+		if entry.IMDBId != "" || entry.TMDBId == "" {
+			continue
+		}
+		if entry.Type == "movie" {
+			tmdbMovieIds = append(tmdbMovieIds, entry.TMDBId)
+		} else {
+			tmdbShowIds = append(tmdbShowIds, entry.TMDBId)
+		}
+	}
+
+	movieImdbIds, showImdbIds := map[string]string{}, map[string]string{}
+	if len(tmdbMovieIds)+len(tmdbShowIds) > 0 {
+		var err error
+		movieImdbIds, showImdbIds, err = imdb_title.GetIMDBIdByTMDBId(tmdbMovieIds, tmdbShowIds)
+		if err != nil {
+			return err
+		}
+	}
+
+	searchCache := map[string]string{}
+	for i := range entries {
+		entry := &entries[i]
+		if entry.IMDBId != "" {
+			continue
+		}
+		if entry.TMDBId != "" {
+			if entry.Type == "movie" {
+				entry.IMDBId = movieImdbIds[entry.TMDBId]
+			} else {
+				entry.IMDBId = showImdbIds[entry.TMDBId]
+			}
+			if entry.IMDBId != "" {
+				continue
+			}
+		}
+		if entry.Title == "" {
+			continue
+		}
+
+		titleType := imdb_title.SearchTitleTypeMovie
+		if entry.Type == "series" {
+			titleType = imdb_title.SearchTitleTypeShow
+		}
+		cacheKey := string(titleType) + ":" + entry.Title + ":" + strconv.Itoa(entry.Year)
+		if imdbId, ok := searchCache[cacheKey]; ok {
+			entry.IMDBId = imdbId
+			continue
+		}
+		title, err := imdb_title.SearchOne(entry.Title, titleType, entry.Year, false)
+		if err != nil {
+			return err
+		}
+		if title != nil {
+			entry.IMDBId = title.TId
+		}
+		searchCache[cacheKey] = entry.IMDBId
+	}
+	return nil
+}
+
+type ImportResult struct {
+	Updated    int
+	Unresolved int
+}
+
+// ImportWatchHistory applies the watched entries to the library. Items that
+// are not in the library are added only to the watch history, unless
+// `addToLibrary` is true.
+func ImportWatchHistory(authKey string, entries []WatchedEntry, addToLibrary bool) (*ImportResult, error) {
+	if err := resolveEntries(entries); err != nil {
+		return nil, err
+	}
+
+	items, err := GetItems(authKey)
+	if err != nil {
+		return nil, err
+	}
+	itemById := make(map[string]*stremio_api.LibraryItem, len(items))
+	for i := range items {
+		itemById[items[i].Id] = &items[i]
+	}
+
+	result := &ImportResult{}
+	entriesByImdbId := map[string][]WatchedEntry{}
+	imdbIds := []string{}
+	for _, entry := range entries {
+		if entry.IMDBId == "" {
+			result.Unresolved++
+			continue
+		}
+		if _, seen := entriesByImdbId[entry.IMDBId]; !seen {
+			imdbIds = append(imdbIds, entry.IMDBId)
+		}
+		entriesByImdbId[entry.IMDBId] = append(entriesByImdbId[entry.IMDBId], entry)
+	}
+
+	now := time.Now().UTC()
+	changes := []stremio_api.LibraryItem{}
+	for _, imdbId := range imdbIds {
+		itemEntries := entriesByImdbId[imdbId]
+		first := &itemEntries[0]
+
+		item, ok := itemById[imdbId]
+		if !ok {
+			item = &stremio_api.LibraryItem{
+				Id:      imdbId,
+				Type:    first.Type,
+				Name:    first.Title,
+				Poster:  stremio_shared.GetCinemetaPosterURL(imdbId),
+				CTime:   now,
+				Removed: !addToLibrary,
+				Temp:    !addToLibrary,
+			}
+			if first.Year != 0 {
+				item.Year = strconv.Itoa(first.Year)
+			}
+		} else if addToLibrary && item.Removed {
+			item.Removed = false
+			item.Temp = false
+		}
+
+		for i := range itemEntries {
+			if itemEntries[i].WatchedAt.After(item.State.LastWatched) {
+				item.State.LastWatched = itemEntries[i].WatchedAt
+			}
+		}
+
+		if item.Type == "series" {
+			meta, err := fetchMeta(item.Type, imdbId)
+			if err != nil {
+				log.Warn("failed to fetch meta", "error", err, "id", imdbId)
+				result.Unresolved += len(itemEntries)
+				continue
+			}
+			if item.Name == "" {
+				item.Name = meta.Name
+			}
+			wbf, err := ParseWatchedBitField(item.State.Watched, GetSortedVideoIds(meta.Videos))
+			if err != nil {
+				return nil, err
+			}
+			for i := range itemEntries {
+				entry := &itemEntries[i]
+				videoId := imdbId + ":" + strconv.Itoa(entry.Season) + ":" + strconv.Itoa(entry.Episode)
+				if !wbf.Set(videoId, true) {
+					result.Unresolved++
+				}
+			}
+			if item.State.Watched, err = wbf.String(); err != nil {
+				return nil, err
+			}
+		} else {
+			plays := 0
+			for i := range itemEntries {
+				plays += itemEntries[i].Plays
+			}
+			item.State.FlaggedWatched = 1
+			item.State.TimesWatched = max(item.State.TimesWatched, plays)
+		}
+
+		changes = append(changes, *item)
+	}
+
+	if err := UpdateItems(authKey, changes); err != nil {
+		return nil, err
+	}
+	result.Updated = len(changes)
+	return result, nil
+}
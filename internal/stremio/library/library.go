@@ -0,0 +1,74 @@
+package stremio_library
+
+import (
+	"errors"
+	"net/url"
+	"time"
+
+	stremio_addon "github.com/MunifTanjim/stremthru/internal/stremio/addon"
+	stremio_api "github.com/MunifTanjim/stremthru/internal/stremio/api"
+	"github.com/MunifTanjim/stremthru/stremio"
+)
+
+var client = stremio_api.NewClient(&stremio_api.ClientConfig{})
+
+var addonClient = stremio_addon.NewClient(&stremio_addon.ClientConfig{})
+
+var cinemetaBaseUrl = func() *url.URL {
+	url, err := url.Parse("https://v3-cinemeta.strem.io/")
+	if err != nil {
+		panic(err)
+	}
+	return url
+}()
+
+func fetchMeta(sType, imdbId string) (*stremio.Meta, error) {
+	res, err := addonClient.FetchMeta(&stremio_addon.FetchMetaParams{
+		BaseURL: cinemetaBaseUrl,
+		Type:    sType,
+		Id:      imdbId + ".json",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res.Data.Meta, nil
+}
+
+// GetItems returns all the library items of the account, including the
+// removed ones.
+func GetItems(authKey string) ([]stremio_api.LibraryItem, error) {
+	params := &stremio_api.GetAllLibraryItemsParams{}
+	params.APIKey = authKey
+	res, err := client.GetAllLibraryItems(params)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+func UpdateItems(authKey string, changes []stremio_api.LibraryItem) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	for i := range changes {
+		changes[i].MTime = now
+	}
+	params := &stremio_api.UpdateLibraryItemsParams{Changes: changes}
+	params.APIKey = authKey
+	res, err := client.UpdateLibraryItems(params)
+	if err != nil {
+		return err
+	}
+	if !res.Data.Success {
+		return errors.New("failed to update library items")
+	}
+	return nil
+}
+
+func getLastActivity(item *stremio_api.LibraryItem) time.Time {
+	if item.State.LastWatched.After(item.MTime) {
+		return item.State.LastWatched
+	}
+	return item.MTime
+}
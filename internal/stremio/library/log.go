@@ -0,0 +1,7 @@
+package stremio_library
+
+import (
+	"github.com/MunifTanjim/stremthru/internal/logger"
+)
+
+var log = logger.Scoped("stremio/library")
@@ -0,0 +1,72 @@
+package stremio_library
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	stremio_api "github.com/MunifTanjim/stremthru/internal/stremio/api"
+)
+
+// SetSeasonWatched marks all the episodes of the season of a series as
+// watched or unwatched. Only IMDB ids are supported, since the episode list
+// is fetched from Cinemeta.
+func SetSeasonWatched(authKey, itemId string, season int, watched bool) (int, error) {
+	if !strings.HasPrefix(itemId, "tt") {
+		return 0, errors.New("only imdb ids are supported")
+	}
+
+	items, err := GetItems(authKey)
+	if err != nil {
+		return 0, err
+	}
+	var item *stremio_api.LibraryItem
+	for i := range items {
+		if items[i].Id == itemId {
+			item = &items[i]
+			break
+		}
+	}
+	if item == nil {
+		return 0, errors.New("library item not found")
+	}
+	if item.Type != "series" {
+		return 0, errors.New("library item is not a series")
+	}
+
+	meta, err := fetchMeta(item.Type, item.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	wbf, err := ParseWatchedBitField(item.State.Watched, GetSortedVideoIds(meta.Videos))
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range meta.Videos {
+		video := &meta.Videos[i]
+		if int(video.Season) != season {
+			continue
+		}
+		if wbf.Set(video.Id, watched) {
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, errors.New("no episode found for season")
+	}
+
+	if item.State.Watched, err = wbf.String(); err != nil {
+		return 0, err
+	}
+	if watched {
+		item.State.LastWatched = time.Now().UTC()
+	}
+
+	if err := UpdateItems(authKey, []stremio_api.LibraryItem{*item}); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
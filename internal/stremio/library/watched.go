@@ -0,0 +1,158 @@
+package stremio_library
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/MunifTanjim/stremthru/stremio"
+)
+
+// WatchedBitField is the watched episodes of a series, as stored by Stremio
+// in the library item state: `{anchor_video_id}:{anchor_length}:{bitfield}`,
+// where bitfield is zlib compressed and base64 encoded, with a bit for each
+// video in the sorted video list.
+type WatchedBitField struct {
+	videoIds []string
+	values   []byte
+}
+
+func NewWatchedBitField(videoIds []string) *WatchedBitField {
+	return &WatchedBitField{
+		videoIds: videoIds,
+		values:   make([]byte, (len(videoIds)+7)/8),
+	}
+}
+
+func decodeBitField(packed string) ([]byte, error) {
+	compressed, err := base64.StdEncoding.DecodeString(packed)
+	if err != nil {
+		return nil, err
+	}
+	r, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func encodeBitField(values []byte) (string, error) {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(values); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func getBit(values []byte, idx int) bool {
+	if idx < 0 || idx/8 >= len(values) {
+		return false
+	}
+	return values[idx/8]&(1<<(idx%8)) != 0
+}
+
+// ParseWatchedBitField restores the watched state for videoIds, shifting it
+// if videos were added or removed since the bitfield was serialized.
+func ParseWatchedBitField(serialized string, videoIds []string) (*WatchedBitField, error) {
+	wbf := NewWatchedBitField(videoIds)
+	if serialized == "" {
+		return wbf, nil
+	}
+
+	parts := strings.Split(serialized, ":")
+	if len(parts) < 3 {
+		return nil, errors.New("invalid watched bitfield")
+	}
+	anchorVideoId := strings.Join(parts[:len(parts)-2], ":")
+	anchorLength, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil {
+		return nil, errors.New("invalid watched bitfield anchor length")
+	}
+	values, err := decodeBitField(parts[len(parts)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	anchorIdx := slices.Index(videoIds, anchorVideoId)
+	if anchorIdx == -1 {
+		return wbf, nil
+	}
+
+	offset := anchorLength - anchorIdx - 1
+	for i := range videoIds {
+		if getBit(values, i+offset) {
+			wbf.setIdx(i, true)
+		}
+	}
+	return wbf, nil
+}
+
+func (wbf *WatchedBitField) setIdx(idx int, watched bool) {
+	if watched {
+		wbf.values[idx/8] |= 1 << (idx % 8)
+	} else {
+		wbf.values[idx/8] &^= 1 << (idx % 8)
+	}
+}
+
+func (wbf *WatchedBitField) Get(videoId string) bool {
+	return getBit(wbf.values, slices.Index(wbf.videoIds, videoId))
+}
+
+func (wbf *WatchedBitField) Set(videoId string, watched bool) bool {
+	idx := slices.Index(wbf.videoIds, videoId)
+	if idx == -1 {
+		return false
+	}
+	wbf.setIdx(idx, watched)
+	return true
+}
+
+func (wbf *WatchedBitField) String() (string, error) {
+	lastIdx := 0
+	for i := len(wbf.videoIds) - 1; i >= 0; i-- {
+		if getBit(wbf.values, i) {
+			lastIdx = i
+			break
+		}
+	}
+	anchorVideoId := "undefined"
+	if lastIdx < len(wbf.videoIds) {
+		anchorVideoId = wbf.videoIds[lastIdx]
+	}
+	packed, err := encodeBitField(wbf.values)
+	if err != nil {
+		return "", err
+	}
+	return anchorVideoId + ":" + strconv.Itoa(lastIdx+1) + ":" + packed, nil
+}
+
+// GetSortedVideoIds returns the video ids in the order used by Stremio for
+// the watched bitfield.
+func GetSortedVideoIds(videos []stremio.MetaVideo) []string {
+	videos = slices.Clone(videos)
+	slices.SortStableFunc(videos, func(a, b stremio.MetaVideo) int {
+		if a.Season != b.Season {
+			return int(a.Season) - int(b.Season)
+		}
+		if a.Episode != b.Episode {
+			return int(a.Episode) - int(b.Episode)
+		}
+		return a.Released.Compare(b.Released)
+	})
+	ids := make([]string, len(videos))
+	for i := range videos {
+		ids[i] = videos[i].Id
+	}
+	return ids
+}
@@ -0,0 +1,45 @@
+package stremio_library
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchedBitField(t *testing.T) {
+	videoIds := []string{"tt1:1:1", "tt1:1:2", "tt1:1:3", "tt1:2:1", "tt1:2:2"}
+
+	wbf := NewWatchedBitField(videoIds)
+	assert.True(t, wbf.Set("tt1:1:1", true))
+	assert.True(t, wbf.Set("tt1:1:3", true))
+	assert.False(t, wbf.Set("tt1:3:1", true))
+
+	serialized, err := wbf.String()
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		videoIds []string
+		watched  []string
+	}{
+		{"same videos", videoIds, []string{"tt1:1:1", "tt1:1:3"}},
+		{"new videos appended", append(videoIds, "tt1:2:3"), []string{"tt1:1:1", "tt1:1:3"}},
+		{"new video prepended", append([]string{"tt1:0:1"}, videoIds...), []string{"tt1:1:1", "tt1:1:3"}},
+		{"anchor missing", videoIds[3:], []string{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := ParseWatchedBitField(serialized, tc.videoIds)
+			assert.NoError(t, err)
+			watched := []string{}
+			for _, id := range tc.videoIds {
+				if parsed.Get(id) {
+					watched = append(watched, id)
+				}
+			}
+			assert.Equal(t, tc.watched, watched)
+		})
+	}
+
+	_, err = ParseWatchedBitField("invalid", videoIds)
+	assert.Error(t, err)
+}
@@ -11,10 +11,13 @@ import (
 
 	"github.com/MunifTanjim/stremthru/internal/anilist"
 	"github.com/MunifTanjim/stremthru/internal/db"
+	"github.com/MunifTanjim/stremthru/internal/feed"
 	"github.com/MunifTanjim/stremthru/internal/imdb_title"
+	"github.com/MunifTanjim/stremthru/internal/jellyfin"
 	"github.com/MunifTanjim/stremthru/internal/letterboxd"
 	"github.com/MunifTanjim/stremthru/internal/mdblist"
 	"github.com/MunifTanjim/stremthru/internal/meta"
+	"github.com/MunifTanjim/stremthru/internal/plex"
 	"github.com/MunifTanjim/stremthru/internal/shared"
 	stremio_shared "github.com/MunifTanjim/stremthru/internal/stremio/shared"
 	"github.com/MunifTanjim/stremthru/internal/tmdb"
@@ -320,6 +323,83 @@ func getTMDBIdsForIMDBIds(tokenId string, imdbIds []string) (map[string]string,
 	return tmdbIdByImdbId, nil
 }
 
+type externalIds struct {
+	isMovie bool
+	imdbId  string
+	tmdbId  string
+	tvdbId  string
+}
+
+// resolveIMDBIds returns imdb id for each entry, falling back to known
+// tmdb/tvdb id maps when the source did not provide one.
+func resolveIMDBIds(ids []externalIds) ([]string, error) {
+	imdbIds := make([]string, len(ids))
+	var tmdbMovieIds, tmdbShowIds, tvdbMovieIds, tvdbShowIds []string
+	for i := range ids {
+		id := &ids[i]
+		if id.imdbId != "" {
+			imdbIds[i] = id.imdbId
+			continue
+		}
+		if id.tmdbId != "" {
+			if id.isMovie {
+				tmdbMovieIds = append(tmdbMovieIds, id.tmdbId)
+			} else {
+				tmdbShowIds = append(tmdbShowIds, id.tmdbId)
+			}
+		}
+		if id.tvdbId != "" {
+			if id.isMovie {
+				tvdbMovieIds = append(tvdbMovieIds, id.tvdbId)
+			} else {
+				tvdbShowIds = append(tvdbShowIds, id.tvdbId)
+			}
+		}
+	}
+
+	if len(tmdbMovieIds)+len(tmdbShowIds) > 0 {
+		movieImdbIdByTmdbId, showImdbIdByTmdbId, err := imdb_title.GetIMDBIdByTMDBId(tmdbMovieIds, tmdbShowIds)
+		if err != nil {
+			return nil, err
+		}
+		for i := range ids {
+			id := &ids[i]
+			if imdbIds[i] != "" || id.tmdbId == "" {
+				continue
+			}
+			if id.isMovie {
+				imdbIds[i] = movieImdbIdByTmdbId[id.tmdbId]
+			} else {
+				imdbIds[i] = showImdbIdByTmdbId[id.tmdbId]
+			}
+		}
+	}
+
+	if len(tvdbMovieIds)+len(tvdbShowIds) > 0 {
+		getIMDBIdByTVDBId := imdb_title.GetIMDBIdByTVDBId
+		if TVDBEnabled {
+			getIMDBIdByTVDBId = tvdb.GetIMDBIdsForTVDBIds
+		}
+		movieImdbIdByTvdbId, showImdbIdByTvdbId, err := getIMDBIdByTVDBId(tvdbMovieIds, tvdbShowIds)
+		if err != nil {
+			return nil, err
+		}
+		for i := range ids {
+			id := &ids[i]
+			if imdbIds[i] != "" || id.tvdbId == "" {
+				continue
+			}
+			if id.isMovie {
+				imdbIds[i] = movieImdbIdByTvdbId[id.tvdbId]
+			} else {
+				imdbIds[i] = showImdbIdByTvdbId[id.tvdbId]
+			}
+		}
+	}
+
+	return imdbIds, nil
+}
+
 type catalogItem struct {
 	stremio.MetaPreview
 	item any
@@ -375,6 +455,71 @@ func handleCatalog(w http.ResponseWriter, r *http.Request) {
 			catalogItems = append(catalogItems, catalogItem{meta, *media})
 		}
 
+	case "feed":
+		list := feed.FeedList{Id: id}
+		if err := ud.FetchFeedList(&list); err != nil {
+			SendError(w, r, err)
+			return
+		}
+
+		for i := range list.Items {
+			item := &list.Items[i]
+
+			poster := stremio_shared.GetCinemetaPosterURL(item.IMDBId)
+			if rpdbPosterBaseUrl != "" {
+				poster = rpdbPosterBaseUrl + item.IMDBId + ".jpg?fallback=true"
+			}
+
+			meta := stremio.MetaPreview{
+				Id:          item.IMDBId,
+				Name:        item.Title,
+				Poster:      poster,
+				PosterShape: stremio.MetaPosterShapePoster,
+				Background:  stremio_shared.GetCinemetaBackgroundURL(item.IMDBId),
+			}
+			if item.Year > 0 {
+				meta.ReleaseInfo = strconv.Itoa(item.Year)
+			}
+			switch item.Type {
+			case feed.ItemTypeMovie:
+				meta.Type = stremio.ContentTypeMovie
+			case feed.ItemTypeShow:
+				meta.Type = stremio.ContentTypeSeries
+			default:
+				continue
+			}
+			catalogItems = append(catalogItems, catalogItem{meta, item})
+		}
+
+	case "jellyfin":
+		list := jellyfin.JellyfinList{Id: id}
+		if err := ud.FetchJellyfinList(&list); err != nil {
+			SendError(w, r, err)
+			return
+		}
+
+		for i := range list.Items {
+			item := &list.Items[i]
+			meta := stremio.MetaPreview{
+				Name:        item.Name,
+				Description: item.Overview,
+				Poster:      item.Poster,
+				PosterShape: stremio.MetaPosterShapePoster,
+				Background:  item.Background,
+				Genres:      item.GenreNames(),
+				ReleaseInfo: strconv.Itoa(item.Year),
+			}
+			switch item.Type {
+			case jellyfin.ItemTypeMovie:
+				meta.Type = stremio.ContentTypeMovie
+			case jellyfin.ItemTypeSeries:
+				meta.Type = stremio.ContentTypeSeries
+			default:
+				continue
+			}
+			catalogItems = append(catalogItems, catalogItem{meta, item})
+		}
+
 	case "letterboxd":
 		list := letterboxd.LetterboxdList{Id: id}
 		if err := ud.FetchLetterboxdList(&list); err != nil {
@@ -423,6 +568,35 @@ func handleCatalog(w http.ResponseWriter, r *http.Request) {
 			catalogItems = append(catalogItems, catalogItem{meta, item})
 		}
 
+	case "plex":
+		list := plex.PlexList{Id: id}
+		if err := ud.FetchPlexList(&list); err != nil {
+			SendError(w, r, err)
+			return
+		}
+
+		for i := range list.Items {
+			item := &list.Items[i]
+			meta := stremio.MetaPreview{
+				Name:        item.Name,
+				Description: item.Overview,
+				Poster:      item.Poster,
+				PosterShape: stremio.MetaPosterShapePoster,
+				Background:  item.Background,
+				Genres:      item.GenreNames(),
+				ReleaseInfo: strconv.Itoa(item.Year),
+			}
+			switch item.Type {
+			case plex.ItemTypeMovie:
+				meta.Type = stremio.ContentTypeMovie
+			case plex.ItemTypeSeries:
+				meta.Type = stremio.ContentTypeSeries
+			default:
+				continue
+			}
+			catalogItems = append(catalogItems, catalogItem{meta, item})
+		}
+
 	case "tmdb":
 		list := tmdb.TMDBList{Id: id}
 		if err := ud.FetchTMDBList(&list); err != nil {
@@ -628,6 +802,49 @@ func handleCatalog(w http.ResponseWriter, r *http.Request) {
 			items = append(items, item.MetaPreview)
 		}
 
+	case "feed":
+		for i := range catalogItems {
+			items = append(items, catalogItems[i].MetaPreview)
+		}
+
+	case "jellyfin":
+		ids := make([]externalIds, len(catalogItems))
+		for i := range catalogItems {
+			item := catalogItems[i].item.(*jellyfin.JellyfinItem)
+			ids[i] = externalIds{
+				isMovie: item.Type == jellyfin.ItemTypeMovie,
+				imdbId:  item.IMDBId,
+				tmdbId:  item.TMDBId,
+				tvdbId:  item.TVDBId,
+			}
+		}
+
+		imdbIds, err := resolveIMDBIds(ids)
+		if err != nil {
+			SendError(w, r, err)
+			return
+		}
+
+		for i := range catalogItems {
+			item := &catalogItems[i]
+			imdbId := imdbIds[i]
+			if imdbId == "" {
+				continue
+			}
+
+			item.MetaPreview.Id = imdbId
+			if rpdbPosterBaseUrl != "" {
+				item.MetaPreview.Poster = rpdbPosterBaseUrl + imdbId + ".jpg?fallback=true"
+			} else if item.MetaPreview.Poster == "" {
+				item.MetaPreview.Poster = stremio_shared.GetCinemetaPosterURL(imdbId)
+			}
+			if item.MetaPreview.Background == "" {
+				item.MetaPreview.Background = stremio_shared.GetCinemetaBackgroundURL(imdbId)
+			}
+
+			items = append(items, item.MetaPreview)
+		}
+
 	case "letterboxd":
 		letterboxdIds := []string{}
 		for i := range catalogItems {
@@ -696,6 +913,44 @@ func handleCatalog(w http.ResponseWriter, r *http.Request) {
 			items = append(items, item.MetaPreview)
 		}
 
+	case "plex":
+		ids := make([]externalIds, len(catalogItems))
+		for i := range catalogItems {
+			item := catalogItems[i].item.(*plex.PlexItem)
+			ids[i] = externalIds{
+				isMovie: item.Type == plex.ItemTypeMovie,
+				imdbId:  item.IMDBId,
+				tmdbId:  item.TMDBId,
+				tvdbId:  item.TVDBId,
+			}
+		}
+
+		imdbIds, err := resolveIMDBIds(ids)
+		if err != nil {
+			SendError(w, r, err)
+			return
+		}
+
+		for i := range catalogItems {
+			item := &catalogItems[i]
+			imdbId := imdbIds[i]
+			if imdbId == "" {
+				continue
+			}
+
+			item.MetaPreview.Id = imdbId
+			if rpdbPosterBaseUrl != "" {
+				item.MetaPreview.Poster = rpdbPosterBaseUrl + imdbId + ".jpg?fallback=true"
+			} else if item.MetaPreview.Poster == "" {
+				item.MetaPreview.Poster = stremio_shared.GetCinemetaPosterURL(imdbId)
+			}
+			if item.MetaPreview.Background == "" {
+				item.MetaPreview.Background = stremio_shared.GetCinemetaBackgroundURL(imdbId)
+			}
+
+			items = append(items, item.MetaPreview)
+		}
+
 	case "tmdb":
 		tmdbMovieIds := make([]string, 0, len(catalogItems))
 		tmdbShowIds := make([]string, 0, len(catalogItems))
@@ -7,8 +7,11 @@ import (
 	"github.com/MunifTanjim/stremthru/core"
 	"github.com/MunifTanjim/stremthru/internal/anilist"
 	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/feed"
+	"github.com/MunifTanjim/stremthru/internal/jellyfin"
 	"github.com/MunifTanjim/stremthru/internal/letterboxd"
 	"github.com/MunifTanjim/stremthru/internal/mdblist"
+	"github.com/MunifTanjim/stremthru/internal/plex"
 	"github.com/MunifTanjim/stremthru/internal/shared"
 	stremio_shared "github.com/MunifTanjim/stremthru/internal/stremio/shared"
 	"github.com/MunifTanjim/stremthru/internal/tmdb"
@@ -78,6 +81,60 @@ func GetManifest(r *http.Request, ud *UserData) (*stremio.Manifest, error) {
 				}
 				catalogs = append(catalogs, catalog)
 
+			case "feed":
+				list := &feed.FeedList{Id: idStr}
+				if err := ud.FetchFeedList(list); err != nil {
+					return nil, err
+				}
+				catalog := stremio.Catalog{
+					Type: "Feed",
+					Id:   "st.list.feed." + idStr,
+					Name: list.Name,
+					Extra: []stremio.CatalogExtra{
+						{
+							Name: "skip",
+						},
+					},
+				}
+				if hasListNames {
+					if name := ud.ListNames[idx]; name != "" {
+						catalog.Name = name
+					}
+				}
+				if hasListTypes {
+					if listType := ud.ListTypes[idx]; listType != "" {
+						catalog.Type = listType
+					}
+				}
+				catalogs = append(catalogs, catalog)
+
+			case "jellyfin":
+				list := &jellyfin.JellyfinList{Id: idStr}
+				if err := ud.FetchJellyfinList(list); err != nil {
+					return nil, err
+				}
+				catalog := stremio.Catalog{
+					Type: "Jellyfin",
+					Id:   "st.list.jellyfin." + idStr,
+					Name: list.Name,
+					Extra: []stremio.CatalogExtra{
+						{
+							Name: "skip",
+						},
+					},
+				}
+				if hasListNames {
+					if name := ud.ListNames[idx]; name != "" {
+						catalog.Name = name
+					}
+				}
+				if hasListTypes {
+					if listType := ud.ListTypes[idx]; listType != "" {
+						catalog.Type = listType
+					}
+				}
+				catalogs = append(catalogs, catalog)
+
 			case "letterboxd":
 				list := &letterboxd.LetterboxdList{Id: idStr}
 				if err := ud.FetchLetterboxdList(list); err != nil {
@@ -143,6 +200,33 @@ func GetManifest(r *http.Request, ud *UserData) (*stremio.Manifest, error) {
 				}
 				catalogs = append(catalogs, catalog)
 
+			case "plex":
+				list := &plex.PlexList{Id: idStr}
+				if err := ud.FetchPlexList(list); err != nil {
+					return nil, err
+				}
+				catalog := stremio.Catalog{
+					Type: "Plex",
+					Id:   "st.list.plex." + idStr,
+					Name: list.Name,
+					Extra: []stremio.CatalogExtra{
+						{
+							Name: "skip",
+						},
+					},
+				}
+				if hasListNames {
+					if name := ud.ListNames[idx]; name != "" {
+						catalog.Name = name
+					}
+				}
+				if hasListTypes {
+					if listType := ud.ListTypes[idx]; listType != "" {
+						catalog.Type = listType
+					}
+				}
+				catalogs = append(catalogs, catalog)
+
 			case "tmdb":
 				list := tmdb.TMDBList{Id: idStr}
 				if err := list.Fetch(ud.TMDBTokenId); err != nil {
@@ -8,9 +8,12 @@ import (
 
 	"github.com/MunifTanjim/stremthru/internal/anilist"
 	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/feed"
+	"github.com/MunifTanjim/stremthru/internal/jellyfin"
 	"github.com/MunifTanjim/stremthru/internal/letterboxd"
 	"github.com/MunifTanjim/stremthru/internal/mdblist"
 	"github.com/MunifTanjim/stremthru/internal/oauth"
+	"github.com/MunifTanjim/stremthru/internal/plex"
 	"github.com/MunifTanjim/stremthru/internal/stremio/configure"
 	stremio_shared "github.com/MunifTanjim/stremthru/internal/stremio/shared"
 	stremio_template "github.com/MunifTanjim/stremthru/internal/stremio/template"
@@ -27,7 +30,7 @@ var TraktEnabled = config.Integration.Trakt.IsEnabled()
 var AnimeEnabled = config.Feature.IsEnabled("anime")
 var TMDBEnabled = config.Integration.TMDB.IsEnabled()
 var TVDBEnabled = config.Integration.TVDB.IsEnabled()
-var LetterboxdEnabled = config.Integration.Letterboxd.IsEnabled() || config.HasPeer
+var LetterboxdEnabled = config.Integration.Letterboxd.IsEnabled() || config.Integration.Letterboxd.IsPiggybacked()
 
 func GetMetaIdMovieOptions(ud *UserData) []configure.ConfigOption {
 	metaIdMovieOptions := []configure.ConfigOption{
@@ -112,6 +115,11 @@ type TemplateData struct {
 
 	MDBListAPIKey configure.Config
 
+	JellyfinServerURL configure.Config
+	JellyfinAPIKey    configure.Config
+
+	PlexToken configure.Config
+
 	RPDBAPIKey configure.Config
 
 	TMDBTokenId configure.Config
@@ -148,6 +156,12 @@ func (td *TemplateData) HasListError() bool {
 	if td.MDBListAPIKey.Error != "" {
 		return true
 	}
+	if td.JellyfinServerURL.Error != "" || td.JellyfinAPIKey.Error != "" {
+		return true
+	}
+	if td.PlexToken.Error != "" {
+		return true
+	}
 	return false
 }
 
@@ -175,6 +189,33 @@ func getTemplateData(ud *UserData, udError userDataError, isAuthed bool, r *http
 			Autocomplete: "off",
 			Error:        udError.mdblist.api_key,
 		},
+		JellyfinServerURL: configure.Config{
+			Key:          "jellyfin_server_url",
+			Type:         configure.ConfigTypeURL,
+			Default:      ud.JellyfinServerURL,
+			Title:        "Server URL",
+			Description:  "Jellyfin Server URL, reachable from this StremThru instance",
+			Autocomplete: "off",
+			Error:        udError.jellyfin.server_url,
+		},
+		JellyfinAPIKey: configure.Config{
+			Key:          "jellyfin_api_key",
+			Type:         configure.ConfigTypePassword,
+			Default:      ud.JellyfinAPIKey,
+			Title:        "API Key",
+			Description:  "Jellyfin API Key, from <code>Dashboard > API Keys</code>",
+			Autocomplete: "off",
+			Error:        udError.jellyfin.api_key,
+		},
+		PlexToken: configure.Config{
+			Key:          "plex_token",
+			Type:         configure.ConfigTypePassword,
+			Default:      ud.PlexToken,
+			Title:        "Token",
+			Description:  `Plex <a href="https://support.plex.tv/articles/204059436-finding-an-authentication-token-x-plex-token/" target="_blank">Token</a>`,
+			Autocomplete: "off",
+			Error:        udError.plex.token,
+		},
 		RPDBAPIKey: configure.Config{
 			Key:          "rpdb_api_key",
 			Type:         configure.ConfigTypePassword,
@@ -299,6 +340,24 @@ func getTemplateData(ud *UserData, udError userDataError, isAuthed bool, r *http
 						list.URL = l.GetURL()
 					}
 
+				case "feed":
+					l := feed.FeedList{Id: id}
+					if err := ud.FetchFeedList(&l); err != nil {
+						log.Error("failed to fetch list", "error", err, "id", listId)
+						list.Error.URL = "Failed to Fetch List: " + err.Error()
+					} else {
+						list.URL = l.GetURL()
+					}
+
+				case "jellyfin":
+					l := jellyfin.JellyfinList{Id: id}
+					if err := ud.FetchJellyfinList(&l); err != nil {
+						log.Error("failed to fetch list", "error", err, "id", listId)
+						list.Error.URL = "Failed to Fetch List: " + err.Error()
+					} else {
+						list.URL = l.GetURL()
+					}
+
 				case "letterboxd":
 					l := letterboxd.LetterboxdList{Id: id}
 					if err := ud.FetchLetterboxdList(&l); err != nil {
@@ -317,6 +376,15 @@ func getTemplateData(ud *UserData, udError userDataError, isAuthed bool, r *http
 						list.URL = l.GetURL()
 					}
 
+				case "plex":
+					l := plex.PlexList{Id: id}
+					if err := ud.FetchPlexList(&l); err != nil {
+						log.Error("failed to fetch list", "error", err, "id", listId)
+						list.Error.URL = "Failed to Fetch List: " + err.Error()
+					} else {
+						list.URL = l.GetURL()
+					}
+
 				case "tmdb":
 					if td.TMDBTokenId.Error == "" {
 						l := tmdb.TMDBList{Id: id}
@@ -411,6 +479,26 @@ var executeTemplate = func() stremio_template.Executor[TemplateData] {
 				},
 			})
 		}
+		td.SupportedServices = append(td.SupportedServices, supportedService{
+			Name:     "GitHub Gist",
+			Hostname: "gist.github.com",
+			Icon:     "https://github.githubassets.com/favicons/favicon.png",
+			URLs: []supportedServiceUrl{
+				{
+					Pattern: "/{user_name}/{gist_id}",
+				},
+			},
+		})
+		td.SupportedServices = append(td.SupportedServices, supportedService{
+			Name:     "Jellyfin",
+			Hostname: "jellyfin.org",
+			Icon:     "https://jellyfin.org/images/favicon.ico",
+			URLs: []supportedServiceUrl{
+				{
+					Pattern: "{server_url}/web/#/details?id={collection_or_playlist_id}",
+				},
+			},
+		})
 		if LetterboxdEnabled {
 			td.SupportedServices = append(td.SupportedServices, supportedService{
 				Name:     "Letterboxd",
@@ -457,6 +545,22 @@ var executeTemplate = func() stremio_template.Executor[TemplateData] {
 				},
 			},
 		})
+		td.SupportedServices = append(td.SupportedServices, supportedService{
+			Name:     "Plex",
+			Hostname: "app.plex.tv",
+			Icon:     "https://app.plex.tv/desktop/favicon.ico",
+			URLs: []supportedServiceUrl{
+				{
+					Pattern: "/desktop/#!/server/{machine_id}/details?key=/library/collections/{rating_key}",
+				},
+				{
+					Pattern: "/desktop/#!/server/{machine_id}/playlist?key=/playlists/{rating_key}",
+				},
+				{
+					Pattern: "/desktop/#!/watchlist",
+				},
+			},
+		})
 		if TMDBEnabled {
 			td.SupportedServices = append(td.SupportedServices, supportedService{
 				Name:     "The Movie Database",
@@ -653,6 +757,12 @@ var executeTemplate = func() stremio_template.Executor[TemplateData] {
 			if td.MDBListAPIKey.Default != "" {
 				td.MDBListAPIKey.Default = redacted
 			}
+			if td.JellyfinAPIKey.Default != "" {
+				td.JellyfinAPIKey.Default = redacted
+			}
+			if td.PlexToken.Default != "" {
+				td.PlexToken.Default = redacted
+			}
 			if td.RPDBAPIKey.Default != "" {
 				td.RPDBAPIKey.Default = redacted
 			}
@@ -8,9 +8,12 @@ import (
 	"strings"
 
 	"github.com/MunifTanjim/stremthru/internal/anilist"
+	"github.com/MunifTanjim/stremthru/internal/feed"
+	"github.com/MunifTanjim/stremthru/internal/jellyfin"
 	"github.com/MunifTanjim/stremthru/internal/letterboxd"
 	"github.com/MunifTanjim/stremthru/internal/mdblist"
 	"github.com/MunifTanjim/stremthru/internal/oauth"
+	"github.com/MunifTanjim/stremthru/internal/plex"
 	stremio_shared "github.com/MunifTanjim/stremthru/internal/stremio/shared"
 	stremio_userdata "github.com/MunifTanjim/stremthru/internal/stremio/userdata"
 	"github.com/MunifTanjim/stremthru/internal/tmdb"
@@ -29,6 +32,11 @@ type UserData struct {
 
 	MDBListAPIkey string `json:"mdblist_api_key,omitempty"`
 
+	JellyfinServerURL string `json:"jellyfin_server_url,omitempty"`
+	JellyfinAPIKey    string `json:"jellyfin_api_key,omitempty"`
+
+	PlexToken string `json:"plex_token,omitempty"`
+
 	TMDBTokenId string            `json:"tmdb_token_id,omitempty"`
 	tmdbToken   *oauth.OAuthToken `json:"-"`
 
@@ -51,6 +59,9 @@ type UserData struct {
 	tmdbById       map[string]tmdb.TMDBList             `json:"-"`
 	tvdbById       map[string]tvdb.TVDBList             `json:"-"`
 	letterboxdById map[string]letterboxd.LetterboxdList `json:"-"`
+	jellyfinById   map[string]jellyfin.JellyfinList     `json:"-"`
+	plexById       map[string]plex.PlexList             `json:"-"`
+	feedById       map[string]feed.FeedList             `json:"-"`
 }
 
 var udManager = stremio_userdata.NewManager[UserData](&stremio_userdata.ManagerConfig{
@@ -77,6 +88,13 @@ type userDataError struct {
 	mdblist struct {
 		api_key string
 	}
+	jellyfin struct {
+		server_url string
+		api_key    string
+	}
+	plex struct {
+		token string
+	}
 	list_urls      []string
 	tmdb_token_id  string
 	trakt_token_id string
@@ -89,6 +107,12 @@ func (uderr userDataError) HasError() bool {
 	if uderr.mdblist.api_key != "" {
 		return true
 	}
+	if uderr.jellyfin.server_url != "" || uderr.jellyfin.api_key != "" {
+		return true
+	}
+	if uderr.plex.token != "" {
+		return true
+	}
 	for i := range uderr.list_urls {
 		if uderr.list_urls[i] != "" {
 			return true
@@ -102,6 +126,15 @@ func (uderr userDataError) Error() string {
 	if uderr.mdblist.api_key != "" {
 		str.WriteString("mdblist.api_key: " + uderr.mdblist.api_key + "\n")
 	}
+	if uderr.jellyfin.server_url != "" {
+		str.WriteString("jellyfin.server_url: " + uderr.jellyfin.server_url + "\n")
+	}
+	if uderr.jellyfin.api_key != "" {
+		str.WriteString("jellyfin.api_key: " + uderr.jellyfin.api_key + "\n")
+	}
+	if uderr.plex.token != "" {
+		str.WriteString("plex.token: " + uderr.plex.token + "\n")
+	}
 	for i, err := range uderr.list_urls {
 		if err != "" {
 			str.WriteString("mdblist.list[" + strconv.Itoa(i) + "].url: " + err + "\n")
@@ -146,6 +179,9 @@ func getUserData(r *http.Request, isAuthed bool) (*UserData, error) {
 		udErr := userDataError{}
 
 		ud.MDBListAPIkey = r.Form.Get("mdblist_api_key")
+		ud.JellyfinServerURL = strings.TrimSuffix(strings.TrimSpace(r.Form.Get("jellyfin_server_url")), "/")
+		ud.JellyfinAPIKey = r.Form.Get("jellyfin_api_key")
+		ud.PlexToken = r.Form.Get("plex_token")
 		ud.TMDBTokenId = r.Form.Get("tmdb_token_id")
 		ud.TraktTokenId = r.Form.Get("trakt_token_id")
 
@@ -175,6 +211,9 @@ func getUserData(r *http.Request, isAuthed bool) (*UserData, error) {
 		isTMDBConfigured := TMDBEnabled && ud.TMDBTokenId != ""
 		isTraktTvConfigured := TraktEnabled && ud.TraktTokenId != ""
 		isTVDBConfigured := TVDBEnabled
+		isJellyfinConfigured := ud.JellyfinServerURL != "" && ud.JellyfinAPIKey != ""
+		isPlexConfigured := ud.PlexToken != ""
+		jellyfinServerId := ""
 
 		if isMDBListEnabled {
 			userParams := mdblist.GetMyLimitsParams{}
@@ -184,6 +223,30 @@ func getUserData(r *http.Request, isAuthed bool) (*UserData, error) {
 			}
 		}
 
+		if isJellyfinConfigured && IsPublicInstance && !isAuthed {
+			// the server url is fetched from this instance
+			udErr.jellyfin.server_url = "Jellyfin is not supported on public instance"
+			isJellyfinConfigured = false
+		} else if isJellyfinConfigured {
+			if info, err := jellyfin.GetServerInfo(ud.JellyfinServerURL, ud.JellyfinAPIKey); err != nil {
+				udErr.jellyfin.api_key = "Invalid Server URL or API Key: " + err.Error()
+				isJellyfinConfigured = false
+			} else {
+				jellyfinServerId = info.Id
+			}
+		} else if ud.JellyfinServerURL != "" {
+			udErr.jellyfin.api_key = "API Key is required"
+		} else if ud.JellyfinAPIKey != "" {
+			udErr.jellyfin.server_url = "Server URL is required"
+		}
+
+		if isPlexConfigured {
+			if _, err := plex.GetUser(ud.PlexToken); err != nil {
+				udErr.plex.token = "Invalid Token: " + err.Error()
+				isPlexConfigured = false
+			}
+		}
+
 		if isTMDBConfigured {
 			ud.tmdbToken, err = ud.getTMDBToken()
 			if err != nil {
@@ -550,6 +613,95 @@ func getUserData(r *http.Request, isAuthed bool) (*UserData, error) {
 					continue
 				}
 				ud.Lists[idx] = "tvdb:" + list.Id
+
+			case "app.plex.tv", "watch.plex.tv":
+				if !isPlexConfigured {
+					udErr.list_urls[idx] = "Plex Token is required"
+					continue
+				}
+
+				kind, machineId, ratingKey, err := plex.ParseListURL(listUrl)
+				if err != nil {
+					udErr.list_urls[idx] = "Unsupported Plex URL"
+					continue
+				}
+
+				list := plex.PlexList{}
+				if kind == plex.ListKindWatchlist {
+					user, err := plex.GetUser(ud.PlexToken)
+					if err != nil {
+						udErr.list_urls[idx] = "Failed to fetch user: " + err.Error()
+						continue
+					}
+					list.Id = plex.NewListId(kind, user.UUID)
+				} else {
+					list.Id = plex.NewListId(kind, machineId, ratingKey)
+				}
+
+				err = ud.FetchPlexList(&list)
+				if err != nil {
+					udErr.list_urls[idx] = "Failed to fetch List: " + err.Error()
+					continue
+				}
+				ud.Lists[idx] = "plex:" + list.Id
+
+			case "gist.github.com":
+				list := feed.FeedList{URL: listUrl.String()}
+				if _, _, err := feed.ParseListURL(listUrl); err != nil {
+					udErr.list_urls[idx] = "Invalid Gist URL: " + err.Error()
+					continue
+				}
+
+				err := ud.FetchFeedList(&list)
+				if err != nil {
+					udErr.list_urls[idx] = "Failed to fetch List: " + err.Error()
+					continue
+				}
+				ud.Lists[idx] = "feed:" + list.Id
+
+			default:
+				if ud.JellyfinServerURL == "" || !jellyfin.IsServerURL(listUrl, ud.JellyfinServerURL) {
+					if IsPublicInstance && !isAuthed {
+						udErr.list_urls[idx] = "Unsupported List URL"
+						continue
+					}
+
+					list := feed.FeedList{URL: listUrl.String()}
+					if _, _, err := feed.ParseListURL(listUrl); err != nil {
+						udErr.list_urls[idx] = "Invalid List URL: " + err.Error()
+						continue
+					}
+
+					err := ud.FetchFeedList(&list)
+					if err != nil {
+						udErr.list_urls[idx] = "Failed to fetch List: " + err.Error()
+						continue
+					}
+					ud.Lists[idx] = "feed:" + list.Id
+					continue
+				}
+				if !isJellyfinConfigured {
+					udErr.list_urls[idx] = "Jellyfin Server URL and API Key are required"
+					continue
+				}
+
+				itemId, serverId, err := jellyfin.ParseListURL(listUrl)
+				if err != nil {
+					udErr.list_urls[idx] = "Unsupported Jellyfin URL"
+					continue
+				}
+				if serverId != "" && serverId != jellyfinServerId {
+					udErr.list_urls[idx] = "Invalid URL: server mismatch"
+					continue
+				}
+
+				list := jellyfin.JellyfinList{Id: jellyfin.NewListId(jellyfinServerId, itemId)}
+				err = ud.FetchJellyfinList(&list)
+				if err != nil {
+					udErr.list_urls[idx] = "Failed to fetch List: " + err.Error()
+					continue
+				}
+				ud.Lists[idx] = "jellyfin:" + list.Id
 			}
 		}
 
@@ -769,3 +921,63 @@ func (ud *UserData) FetchTVDBList(list *tvdb.TVDBList) error {
 	ud.tvdbById[list.Id] = *list
 	return nil
 }
+
+func (ud *UserData) FetchJellyfinList(list *jellyfin.JellyfinList) error {
+	if ud.JellyfinServerURL == "" || ud.JellyfinAPIKey == "" {
+		return errors.New("Jellyfin Server URL or API Key missing")
+	}
+	if ud.jellyfinById == nil {
+		ud.jellyfinById = map[string]jellyfin.JellyfinList{}
+	}
+	if list.Id != "" {
+		if l, ok := ud.jellyfinById[list.Id]; ok {
+			*list = l
+			return nil
+		}
+	}
+	if err := list.Fetch(ud.JellyfinServerURL, ud.JellyfinAPIKey); err != nil {
+		return err
+	}
+
+	ud.jellyfinById[list.Id] = *list
+	return nil
+}
+
+func (ud *UserData) FetchPlexList(list *plex.PlexList) error {
+	if ud.PlexToken == "" {
+		return errors.New("Plex Token missing")
+	}
+	if ud.plexById == nil {
+		ud.plexById = map[string]plex.PlexList{}
+	}
+	if list.Id != "" {
+		if l, ok := ud.plexById[list.Id]; ok {
+			*list = l
+			return nil
+		}
+	}
+	if err := list.Fetch(ud.PlexToken); err != nil {
+		return err
+	}
+
+	ud.plexById[list.Id] = *list
+	return nil
+}
+
+func (ud *UserData) FetchFeedList(list *feed.FeedList) error {
+	if ud.feedById == nil {
+		ud.feedById = map[string]feed.FeedList{}
+	}
+	if list.Id != "" {
+		if l, ok := ud.feedById[list.Id]; ok {
+			*list = l
+			return nil
+		}
+	}
+	if err := list.Fetch(); err != nil {
+		return err
+	}
+
+	ud.feedById[list.Id] = *list
+	return nil
+}
@@ -0,0 +1,337 @@
+package stremio_profile
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/db"
+	"github.com/MunifTanjim/stremthru/internal/util"
+	"github.com/MunifTanjim/stremthru/stremio"
+	"github.com/rs/xid"
+)
+
+const ProfileTableName = "stremio_addon_profile"
+
+type Profile struct {
+	Id        string
+	Name      string
+	Addons    []stremio.Addon
+	CreatedAt db.Timestamp
+	UpdatedAt db.Timestamp
+}
+
+var ProfileColumn = struct {
+	Id        string
+	Name      string
+	Addons    string
+	CreatedAt string
+	UpdatedAt string
+}{
+	Id:        "id",
+	Name:      "name",
+	Addons:    "addons",
+	CreatedAt: "cat",
+	UpdatedAt: "uat",
+}
+
+var ProfileColumns = []string{
+	ProfileColumn.Id,
+	ProfileColumn.Name,
+	ProfileColumn.Addons,
+	ProfileColumn.CreatedAt,
+	ProfileColumn.UpdatedAt,
+}
+
+func scanProfile(row interface{ Scan(dest ...any) error }) (*Profile, error) {
+	p := &Profile{}
+	var addons string
+	if err := row.Scan(
+		&p.Id,
+		&p.Name,
+		&addons,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(addons), &p.Addons); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+var query_get_profile = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ?`,
+	db.JoinColumnNames(ProfileColumns...),
+	ProfileTableName,
+	ProfileColumn.Id,
+)
+
+func GetProfile(id string) (*Profile, error) {
+	p, err := scanProfile(db.QueryRow(query_get_profile, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+var query_get_profiles = fmt.Sprintf(
+	`SELECT %s FROM %s ORDER BY %s ASC`,
+	db.JoinColumnNames(ProfileColumns...),
+	ProfileTableName,
+	ProfileColumn.Name,
+)
+
+func GetProfiles() ([]Profile, error) {
+	rows, err := db.Query(query_get_profiles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := []Profile{}
+	for rows.Next() {
+		p, err := scanProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+var query_upsert_profile = fmt.Sprintf(
+	`INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s`,
+	ProfileTableName,
+	db.JoinColumnNames(ProfileColumns[:3]...),
+	util.RepeatJoin("?", 3, ", "),
+	ProfileColumn.Id,
+	strings.Join([]string{
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ProfileColumn.Name, ProfileColumn.Name),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, ProfileColumn.Addons, ProfileColumn.Addons),
+		fmt.Sprintf(`%s = %s`, ProfileColumn.UpdatedAt, db.CurrentTimestamp),
+	}, ", "),
+)
+
+func UpsertProfile(p *Profile) error {
+	if p.Id == "" {
+		p.Id = xid.New().String()
+	}
+	if p.Addons == nil {
+		p.Addons = []stremio.Addon{}
+	}
+	addons, err := json.Marshal(p.Addons)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(query_upsert_profile, p.Id, p.Name, string(addons))
+	if err != nil {
+		return err
+	}
+	p.UpdatedAt = db.Timestamp{Time: time.Now()}
+	return nil
+}
+
+var query_unset_account_profile = fmt.Sprintf(
+	`UPDATE %s SET %s = '' WHERE %s = ?`,
+	AccountTableName,
+	AccountColumn.ProfileId,
+	AccountColumn.ProfileId,
+)
+
+var query_delete_profile = fmt.Sprintf(
+	`DELETE FROM %s WHERE %s = ?`,
+	ProfileTableName,
+	ProfileColumn.Id,
+)
+
+func DeleteProfile(id string) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			err = tx.Commit()
+			return
+		}
+		tErr := tx.Rollback()
+		err = errors.Join(tErr, err)
+	}()
+
+	if _, err = tx.Exec(query_unset_account_profile, id); err != nil {
+		return err
+	}
+	_, err = tx.Exec(query_delete_profile, id)
+	return err
+}
+
+const AccountTableName = "stremio_linked_account"
+
+// Account is a Stremio account linked by an admin, with the auth key
+// used to manage its addons.
+type Account struct {
+	Id        string
+	Email     string
+	AuthKey   string
+	ProfileId string
+	AppliedAt db.Timestamp
+	CreatedAt db.Timestamp
+	UpdatedAt db.Timestamp
+}
+
+var AccountColumn = struct {
+	Id        string
+	Email     string
+	AuthKey   string
+	ProfileId string
+	AppliedAt string
+	CreatedAt string
+	UpdatedAt string
+}{
+	Id:        "id",
+	Email:     "email",
+	AuthKey:   "auth_key",
+	ProfileId: "profile_id",
+	AppliedAt: "aat",
+	CreatedAt: "cat",
+	UpdatedAt: "uat",
+}
+
+var AccountColumns = []string{
+	AccountColumn.Id,
+	AccountColumn.Email,
+	AccountColumn.AuthKey,
+	AccountColumn.ProfileId,
+	AccountColumn.AppliedAt,
+	AccountColumn.CreatedAt,
+	AccountColumn.UpdatedAt,
+}
+
+func scanAccount(row interface{ Scan(dest ...any) error }) (*Account, error) {
+	a := &Account{}
+	if err := row.Scan(
+		&a.Id,
+		&a.Email,
+		&a.AuthKey,
+		&a.ProfileId,
+		&a.AppliedAt,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+var query_get_account = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ?`,
+	db.JoinColumnNames(AccountColumns...),
+	AccountTableName,
+	AccountColumn.Id,
+)
+
+func GetAccount(id string) (*Account, error) {
+	a, err := scanAccount(db.QueryRow(query_get_account, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+var query_get_accounts = fmt.Sprintf(
+	`SELECT %s FROM %s ORDER BY %s ASC`,
+	db.JoinColumnNames(AccountColumns...),
+	AccountTableName,
+	AccountColumn.Email,
+)
+
+func GetAccounts() ([]Account, error) {
+	rows, err := db.Query(query_get_accounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []Account{}
+	for rows.Next() {
+		a, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+var query_upsert_account = fmt.Sprintf(
+	`INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s`,
+	AccountTableName,
+	db.JoinColumnNames(AccountColumns[:3]...),
+	util.RepeatJoin("?", 3, ", "),
+	AccountColumn.Id,
+	strings.Join([]string{
+		fmt.Sprintf(`%s = EXCLUDED.%s`, AccountColumn.Email, AccountColumn.Email),
+		fmt.Sprintf(`%s = EXCLUDED.%s`, AccountColumn.AuthKey, AccountColumn.AuthKey),
+		fmt.Sprintf(`%s = %s`, AccountColumn.UpdatedAt, db.CurrentTimestamp),
+	}, ", "),
+)
+
+func LinkAccount(id, email, authKey string) error {
+	_, err := db.Exec(query_upsert_account, id, email, authKey)
+	return err
+}
+
+var query_set_account_profile = fmt.Sprintf(
+	`UPDATE %s SET %s = ?, %s = %s WHERE %s = ?`,
+	AccountTableName,
+	AccountColumn.ProfileId,
+	AccountColumn.UpdatedAt,
+	db.CurrentTimestamp,
+	AccountColumn.Id,
+)
+
+func SetAccountProfile(id, profileId string) error {
+	_, err := db.Exec(query_set_account_profile, profileId, id)
+	return err
+}
+
+var query_set_account_applied_at = fmt.Sprintf(
+	`UPDATE %s SET %s = %s WHERE %s = ?`,
+	AccountTableName,
+	AccountColumn.AppliedAt,
+	db.CurrentTimestamp,
+	AccountColumn.Id,
+)
+
+func setAccountAppliedAt(id string) error {
+	_, err := db.Exec(query_set_account_applied_at, id)
+	return err
+}
+
+var query_unlink_account = fmt.Sprintf(
+	`DELETE FROM %s WHERE %s = ?`,
+	AccountTableName,
+	AccountColumn.Id,
+)
+
+func UnlinkAccount(id string) error {
+	_, err := db.Exec(query_unlink_account, id)
+	return err
+}
@@ -0,0 +1,7 @@
+package stremio_profile
+
+import (
+	"github.com/MunifTanjim/stremthru/internal/logger"
+)
+
+var log = logger.Scoped("stremio/profile")
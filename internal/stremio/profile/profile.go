@@ -0,0 +1,69 @@
+package stremio_profile
+
+import (
+	"encoding/json"
+	"errors"
+
+	stremio_api "github.com/MunifTanjim/stremthru/internal/stremio/api"
+	stremio_backup "github.com/MunifTanjim/stremthru/internal/stremio/backup"
+	"github.com/MunifTanjim/stremthru/stremio"
+)
+
+var client = stremio_api.NewClient(&stremio_api.ClientConfig{})
+
+func GetAccountAddons(authKey string) ([]stremio.Addon, error) {
+	params := &stremio_api.GetAddonsParams{}
+	params.APIKey = authKey
+	res, err := client.GetAddons(params)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data.Addons, nil
+}
+
+// Apply replaces the addon collection of the account with the profile.
+func Apply(p *Profile, a *Account) error {
+	params := &stremio_api.SetAddonsParams{Addons: p.Addons}
+	params.APIKey = a.AuthKey
+	res, err := client.SetAddons(params)
+	if err != nil {
+		return err
+	}
+	if !res.Data.Success {
+		return errors.New("failed to set addons")
+	}
+	if err := setAccountAppliedAt(a.Id); err != nil {
+		log.Error("failed to set account applied at", "error", err, "account_id", a.Id)
+	}
+	return nil
+}
+
+// GetDrift compares the current addon collection of the account against
+// the profile, i.e. the changes needed to go from the profile to the account.
+func GetDrift(p *Profile, a *Account) (*stremio_backup.Diff, error) {
+	addons, err := GetAccountAddons(a.AuthKey)
+	if err != nil {
+		return nil, err
+	}
+	from, err := toAddonsSnapshot(p.Addons)
+	if err != nil {
+		return nil, err
+	}
+	to, err := toAddonsSnapshot(addons)
+	if err != nil {
+		return nil, err
+	}
+	return stremio_backup.GetDiff(from, to)
+}
+
+func toAddonsSnapshot(addons []stremio.Addon) (*stremio_backup.Snapshot, error) {
+	blob, err := json.Marshal(addons)
+	if err != nil {
+		return nil, err
+	}
+	return &stremio_backup.Snapshot{
+		Kind:      stremio_backup.SnapshotKindAddons,
+		ItemCount: len(addons),
+		Data:      string(blob),
+	}, nil
+}
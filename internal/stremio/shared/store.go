@@ -28,6 +28,14 @@ func GetStoreCodeOptions(includeP2P bool) []configure.ConfigOption {
 	if config.IsPublicInstance {
 		options[0].Disabled = true
 		options[0].Label = ""
+	} else {
+		if len(config.StoreLocalDirs) > 0 {
+			options = append(options, configure.ConfigOption{Value: "lc", Label: "Local 🧪"})
+		}
+		options = append(options,
+			configure.ConfigOption{Value: "qb", Label: "qBittorrent 🧪"},
+			configure.ConfigOption{Value: "tr", Label: "Transmission 🧪"},
+		)
 	}
 	if P2PEnabled && includeP2P {
 		options = append(options, configure.ConfigOption{
@@ -0,0 +1,91 @@
+package stremio_sidekick
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/MunifTanjim/stremthru/stremio"
+)
+
+func findAddonIndex(addons []stremio.Addon, transportUrl string) int {
+	for i := range addons {
+		if addons[i].TransportUrl == transportUrl {
+			return i
+		}
+	}
+	return -1
+}
+
+// moveAddon moves the addon at idx in the direction: top, up, down or bottom.
+func moveAddon(addons []stremio.Addon, idx int, direction string) []stremio.Addon {
+	totalAddons := len(addons)
+	if idx < 0 || idx >= totalAddons {
+		return addons
+	}
+
+	switch direction {
+	case "top":
+		if idx == 0 {
+			break
+		}
+		moved := make([]stremio.Addon, 0, totalAddons)
+		moved = append(moved, addons[idx])
+		moved = append(moved, addons[:idx]...)
+		moved = append(moved, addons[idx+1:]...)
+		return moved
+	case "up":
+		if idx == 0 {
+			break
+		}
+		addons[idx], addons[idx-1] = addons[idx-1], addons[idx]
+	case "down":
+		if idx == totalAddons-1 {
+			break
+		}
+		addons[idx], addons[idx+1] = addons[idx+1], addons[idx]
+	case "bottom":
+		if idx == totalAddons-1 {
+			break
+		}
+		moved := make([]stremio.Addon, 0, totalAddons)
+		moved = append(moved, addons[:idx]...)
+		moved = append(moved, addons[idx+1:]...)
+		moved = append(moved, addons[idx])
+		return moved
+	}
+	return addons
+}
+
+// modifyAddon applies the modifications from the addon modify form.
+func modifyAddon(addon *stremio.Addon, r *http.Request, isAdmin bool) {
+	addon.Manifest.Name = r.FormValue("name")
+	addon.Manifest.Description = r.FormValue("description")
+	if logo := r.FormValue("logo"); logo != "" {
+		addon.Manifest.Logo = logo
+	}
+	for i := range addon.Manifest.Catalogs {
+		catalog := &addon.Manifest.Catalogs[i]
+		if name := r.FormValue("catalog_name[" + strconv.Itoa(i) + "]"); name != "" {
+			catalog.Name = name
+		}
+		if hidden := r.FormValue("catalog_hidden[" + strconv.Itoa(i) + "]"); hidden != "" {
+			if canToggleCatalogBoard(catalog) {
+				toggleCatalogBoard(catalog, hidden == "true")
+			}
+		}
+	}
+	if isAdmin {
+		isConfigurable := r.FormValue("configurable") == "true"
+		isProtected := r.FormValue("protected") == "true"
+
+		if addon.Manifest.BehaviorHints != nil {
+			addon.Manifest.BehaviorHints.Configurable = isConfigurable
+		}
+		if addon.Flags == nil {
+			addon.Flags = &stremio.AddonFlags{}
+		}
+		if !addon.Flags.Official {
+			addon.Flags.Protected = isProtected
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package stremio_sidekick
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/MunifTanjim/stremthru/stremio"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveAddon(t *testing.T) {
+	newAddons := func() []stremio.Addon {
+		return []stremio.Addon{{TransportUrl: "a"}, {TransportUrl: "b"}, {TransportUrl: "c"}}
+	}
+	urls := func(addons []stremio.Addon) string {
+		result := ""
+		for _, addon := range addons {
+			result += addon.TransportUrl
+		}
+		return result
+	}
+
+	for _, tc := range []struct {
+		idx       int
+		direction string
+		expected  string
+	}{
+		{1, "top", "bac"},
+		{1, "up", "bac"},
+		{1, "down", "acb"},
+		{1, "bottom", "acb"},
+		{0, "top", "abc"},
+		{0, "up", "abc"},
+		{2, "down", "abc"},
+		{2, "bottom", "abc"},
+		{2, "top", "cab"},
+		{0, "bottom", "bca"},
+		{-1, "top", "abc"},
+		{1, "sideways", "abc"},
+	} {
+		assert.Equal(t, tc.expected, urls(moveAddon(newAddons(), tc.idx, tc.direction)), "idx=%d direction=%s", tc.idx, tc.direction)
+	}
+}
+
+func TestModifyAddon(t *testing.T) {
+	addon := stremio.Addon{
+		TransportUrl: "a",
+		Manifest: stremio.Manifest{
+			Name:          "A",
+			Description:   "Addon A",
+			Logo:          "https://a/logo.png",
+			BehaviorHints: &stremio.BehaviorHints{Configurable: true},
+			Catalogs: []stremio.Catalog{
+				{Type: "movie", Id: "top", Name: "Top"},
+			},
+		},
+	}
+
+	form := url.Values{
+		"name":            {"Renamed"},
+		"description":     {"Modified"},
+		"catalog_name[0]": {"Popular"},
+		"configurable":    {"false"},
+		"protected":       {"true", "false"},
+	}
+	r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	modifyAddon(&addon, r, false)
+	assert.Equal(t, "Renamed", addon.Manifest.Name)
+	assert.Equal(t, "Modified", addon.Manifest.Description)
+	assert.Equal(t, "https://a/logo.png", addon.Manifest.Logo)
+	assert.Equal(t, "Popular", addon.Manifest.Catalogs[0].Name)
+	assert.True(t, addon.Manifest.BehaviorHints.Configurable, "modifiers need admin")
+	assert.Nil(t, addon.Flags)
+
+	modifyAddon(&addon, r, true)
+	assert.False(t, addon.Manifest.BehaviorHints.Configurable)
+	assert.True(t, addon.Flags.Protected)
+}
@@ -0,0 +1,266 @@
+package stremio_sidekick
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/shared"
+	stremio_api "github.com/MunifTanjim/stremthru/internal/stremio/api"
+	stremio_backup "github.com/MunifTanjim/stremthru/internal/stremio/backup"
+	"github.com/MunifTanjim/stremthru/internal/stremio/configure"
+)
+
+var backupIntervalOptions = []configure.ConfigOption{
+	{Value: "6h", Label: "Every 6 Hours"},
+	{Value: "12h", Label: "Every 12 Hours"},
+	{Value: "24h", Label: "Daily"},
+	{Value: "72h", Label: "Every 3 Days"},
+	{Value: "168h", Label: "Weekly"},
+}
+
+const defaultBackupInterval = 24 * time.Hour
+const defaultBackupRetention = 7
+
+func formatBackupInterval(d time.Duration) string {
+	return strconv.Itoa(int(d.Hours())) + "h"
+}
+
+// getBackupAccountId resolves the Stremio account for the auth key, the
+// email in the cookie is not trusted for accessing the stored snapshots.
+func getBackupAccountId(cookie *CookieValue) (string, error) {
+	params := &stremio_api.GetUserParams{}
+	params.APIKey = cookie.AuthKey()
+	res, err := client.GetUser(params)
+	if err != nil {
+		return "", err
+	}
+	return res.Data.Id, nil
+}
+
+// getBackupsTemplateData allows only the admins, the schedule keeps the auth
+// key and the snapshots are stored in the database.
+func getBackupsTemplateData(w http.ResponseWriter, r *http.Request) (*CookieValue, *TemplateData, string, bool) {
+	cookie, err := getCookieValue(w, r)
+	if err != nil {
+		SendError(w, r, err)
+		return nil, nil, "", false
+	}
+
+	td := getTemplateData(cookie, w, r)
+	if !td.HasAuthAdmin {
+		shared.ErrorForbidden(r).Send(w, r)
+		return nil, nil, "", false
+	}
+
+	accountId, err := getBackupAccountId(cookie)
+	if err != nil {
+		SendError(w, r, err)
+		return nil, nil, "", false
+	}
+
+	return cookie, td, accountId, true
+}
+
+func loadBackups(td *TemplateData, accountId string) error {
+	td.Backups.IsLoaded = true
+	td.Backups.IntervalOptions = backupIntervalOptions
+	td.Backups.MaxRetention = stremio_backup.MaxRetention
+	td.Backups.Interval = formatBackupInterval(defaultBackupInterval)
+	td.Backups.Retention = defaultBackupRetention
+
+	schedule, err := stremio_backup.GetSchedule(accountId)
+	if err != nil {
+		return err
+	}
+	if schedule != nil {
+		td.Backups.HasSchedule = true
+		td.Backups.Enabled = schedule.Enabled
+		td.Backups.Interval = formatBackupInterval(schedule.Interval)
+		td.Backups.Retention = schedule.Retention
+		td.Backups.LastRunAt = schedule.LastRunAt
+		td.Backups.LastError = schedule.LastError
+	}
+
+	snapshots, err := stremio_backup.GetSnapshots(accountId)
+	if err != nil {
+		return err
+	}
+	td.Backups.Snapshots = snapshots
+	return nil
+}
+
+func sendBackupsSection(w http.ResponseWriter, r *http.Request, td *TemplateData, accountId string) {
+	if err := loadBackups(td, accountId); err != nil {
+		SendError(w, r, err)
+		return
+	}
+	buf, err := executeTemplate(td, "sidekick_backups_section.html")
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	SendHTML(w, 200, buf)
+}
+
+func handleBackups(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodGet) && !IsMethod(r, http.MethodPost) && !IsMethod(r, http.MethodDelete) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	cookie, td, accountId, ok := getBackupsTemplateData(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		interval, err := time.ParseDuration(r.FormValue("interval"))
+		if err != nil || interval < stremio_backup.MinInterval {
+			interval = defaultBackupInterval
+		}
+		retention, err := strconv.Atoi(r.FormValue("retention"))
+		if err != nil || retention < 1 {
+			retention = defaultBackupRetention
+		}
+		retention = min(retention, stremio_backup.MaxRetention)
+
+		err = stremio_backup.UpsertSchedule(&stremio_backup.Schedule{
+			AccountId: accountId,
+			Email:     cookie.Email(),
+			AuthKey:   cookie.AuthKey(),
+			Interval:  interval,
+			Retention: retention,
+			Enabled:   r.FormValue("enabled") == "1",
+		})
+		if err != nil {
+			td.Backups.HasError = true
+			td.Backups.Message = "Failed to save: " + err.Error()
+		} else {
+			td.Backups.Message = "Saved"
+		}
+	case http.MethodDelete:
+		if err := stremio_backup.DeleteAll(accountId); err != nil {
+			td.Backups.HasError = true
+			td.Backups.Message = "Failed to delete: " + err.Error()
+		} else {
+			td.Backups.Message = "Deleted"
+		}
+	}
+
+	sendBackupsSection(w, r, td, accountId)
+}
+
+func handleBackupsRun(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	cookie, td, accountId, ok := getBackupsTemplateData(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := stremio_backup.GetSchedule(accountId)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	if schedule == nil {
+		schedule = &stremio_backup.Schedule{
+			AccountId: accountId,
+			Email:     cookie.Email(),
+			AuthKey:   cookie.AuthKey(),
+			Interval:  defaultBackupInterval,
+			Retention: defaultBackupRetention,
+			Enabled:   false,
+		}
+		if err := stremio_backup.UpsertSchedule(schedule); err != nil {
+			SendError(w, r, err)
+			return
+		}
+	}
+	schedule.AuthKey = cookie.AuthKey()
+
+	if err := stremio_backup.Run(schedule); err != nil {
+		td.Backups.HasError = true
+		td.Backups.Message = "Failed to backup: " + err.Error()
+	} else {
+		td.Backups.Message = "Backup Completed"
+	}
+
+	sendBackupsSection(w, r, td, accountId)
+}
+
+func handleBackupsDiff(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	_, td, accountId, ok := getBackupsTemplateData(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	from, err := stremio_backup.GetSnapshot(accountId, query.Get("from"))
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	to, err := stremio_backup.GetSnapshot(accountId, query.Get("to"))
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	if from == nil || to == nil {
+		td.Backups.DiffError = "Snapshot not found!"
+	} else if from.Kind != to.Kind {
+		td.Backups.DiffError = "Can not compare " + string(from.Kind) + " with " + string(to.Kind) + "!"
+	} else if diff, err := stremio_backup.GetDiff(from, to); err != nil {
+		td.Backups.DiffError = "Failed to compare: " + err.Error()
+	} else {
+		td.Backups.Diff = diff
+	}
+
+	sendBackupsSection(w, r, td, accountId)
+}
+
+func handleBackupRestore(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	cookie, td, accountId, ok := getBackupsTemplateData(w, r)
+	if !ok {
+		return
+	}
+
+	snapshot, err := stremio_backup.GetSnapshot(accountId, r.PathValue("id"))
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	if snapshot == nil {
+		shared.ErrorNotFound(r).Send(w, r)
+		return
+	}
+
+	if err := stremio_backup.Rollback(snapshot, cookie.AuthKey()); err != nil {
+		td.Backups.HasError = true
+		td.Backups.Message = "Failed to rollback: " + err.Error()
+	} else if snapshot.Kind == stremio_backup.SnapshotKindAddons {
+		w.Header().Add("HX-Redirect", "/stremio/sidekick/?addon_operation=move&try_load_addons=1")
+		SendResponse(w, r, 200, "")
+		return
+	} else {
+		td.Backups.Message = "Successfully Rolled Back to " + snapshot.CreatedAt.Format(time.DateTime)
+	}
+
+	sendBackupsSection(w, r, td, accountId)
+}
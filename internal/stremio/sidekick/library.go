@@ -0,0 +1,223 @@
+package stremio_sidekick
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/shared"
+	stremio_api "github.com/MunifTanjim/stremthru/internal/stremio/api"
+	stremio_library "github.com/MunifTanjim/stremthru/internal/stremio/library"
+)
+
+const defaultContinueWatchingStaleDays = 30
+
+func getContinueWatchingStaleDays(r *http.Request) int {
+	days, err := strconv.Atoi(r.FormValue("days"))
+	if err != nil || days < 1 {
+		return defaultContinueWatchingStaleDays
+	}
+	return days
+}
+
+func getContinueWatchingStaleBefore(days int) time.Time {
+	return time.Now().AddDate(0, 0, -days)
+}
+
+func loadLibraryManage(td *TemplateData, authKey string, staleDays int) error {
+	td.LibraryManage.IsLoaded = true
+	td.LibraryManage.StaleDays = staleDays
+
+	items, err := stremio_library.GetItems(authKey)
+	if err != nil {
+		return err
+	}
+
+	series := []stremio_api.LibraryItem{}
+	for i := range items {
+		item := &items[i]
+		if item.Removed {
+			continue
+		}
+		td.LibraryManage.ItemCount++
+		if item.Type == "series" && strings.HasPrefix(item.Id, "tt") {
+			series = append(series, *item)
+		}
+	}
+	slices.SortFunc(series, func(a, b stremio_api.LibraryItem) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	td.LibraryManage.Series = series
+
+	td.LibraryManage.StaleContinueWatching = stremio_library.GetStaleContinueWatching(items, getContinueWatchingStaleBefore(staleDays))
+
+	duplicates, err := stremio_library.FindDuplicates(items)
+	if err != nil {
+		return err
+	}
+	td.LibraryManage.Duplicates = duplicates
+
+	return nil
+}
+
+func sendLibraryManageSection(w http.ResponseWriter, r *http.Request, td *TemplateData, authKey string, staleDays int) {
+	if err := loadLibraryManage(td, authKey, staleDays); err != nil {
+		SendError(w, r, err)
+		return
+	}
+	buf, err := executeTemplate(td, "sidekick_library_manage_section.html")
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	SendHTML(w, 200, buf)
+}
+
+func handleLibraryManage(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodGet) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	cookie, err := getCookieValue(w, r)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	td := getTemplateData(cookie, w, r)
+	sendLibraryManageSection(w, r, td, cookie.AuthKey(), getContinueWatchingStaleDays(r))
+}
+
+func handleLibraryDuplicates(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	cookie, err := getCookieValue(w, r)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	td := getTemplateData(cookie, w, r)
+
+	items, err := stremio_library.GetItems(cookie.AuthKey())
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	groups, err := stremio_library.FindDuplicates(items)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	if count, err := stremio_library.RemoveDuplicates(cookie.AuthKey(), groups); err != nil {
+		td.LibraryManage.HasError = true
+		td.LibraryManage.Message = "Failed to remove duplicates: " + err.Error()
+	} else {
+		td.LibraryManage.Message = "Removed " + strconv.Itoa(count) + " duplicate item(s)"
+	}
+
+	sendLibraryManageSection(w, r, td, cookie.AuthKey(), getContinueWatchingStaleDays(r))
+}
+
+func handleLibrarySeason(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	cookie, err := getCookieValue(w, r)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	td := getTemplateData(cookie, w, r)
+
+	itemId := r.FormValue("item_id")
+	watched := r.FormValue("watched") == "1"
+	season, err := strconv.Atoi(r.FormValue("season"))
+	if itemId == "" || err != nil || season < 0 {
+		td.LibraryManage.HasError = true
+		td.LibraryManage.Message = "Invalid series or season!"
+	} else if count, err := stremio_library.SetSeasonWatched(cookie.AuthKey(), itemId, season, watched); err != nil {
+		td.LibraryManage.HasError = true
+		td.LibraryManage.Message = "Failed to update season: " + err.Error()
+	} else {
+		status := "unwatched"
+		if watched {
+			status = "watched"
+		}
+		td.LibraryManage.Message = "Marked " + strconv.Itoa(count) + " episode(s) as " + status
+	}
+
+	sendLibraryManageSection(w, r, td, cookie.AuthKey(), getContinueWatchingStaleDays(r))
+}
+
+func handleLibraryContinueWatching(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	cookie, err := getCookieValue(w, r)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	td := getTemplateData(cookie, w, r)
+	staleDays := getContinueWatchingStaleDays(r)
+
+	items, err := stremio_library.GetItems(cookie.AuthKey())
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	staleItems := stremio_library.GetStaleContinueWatching(items, getContinueWatchingStaleBefore(staleDays))
+	if count, err := stremio_library.ClearContinueWatching(cookie.AuthKey(), staleItems); err != nil {
+		td.LibraryManage.HasError = true
+		td.LibraryManage.Message = "Failed to clear continue watching: " + err.Error()
+	} else {
+		td.LibraryManage.Message = "Cleared " + strconv.Itoa(count) + " item(s) from continue watching"
+	}
+
+	sendLibraryManageSection(w, r, td, cookie.AuthKey(), staleDays)
+}
+
+func handleLibraryImport(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	cookie, err := getCookieValue(w, r)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	td := getTemplateData(cookie, w, r)
+
+	source := stremio_library.ImportSource(r.FormValue("source"))
+	entries, err := stremio_library.ParseExport(source, []byte(r.FormValue("blob")))
+	if err != nil {
+		td.LibraryManage.HasError = true
+		td.LibraryManage.Message = "Failed to parse: " + err.Error()
+	} else if result, err := stremio_library.ImportWatchHistory(cookie.AuthKey(), entries, r.FormValue("add_to_library") == "1"); err != nil {
+		td.LibraryManage.HasError = true
+		td.LibraryManage.Message = "Failed to import: " + err.Error()
+	} else {
+		td.LibraryManage.Message = "Imported " + strconv.Itoa(result.Updated) + " item(s)"
+		if result.Unresolved > 0 {
+			td.LibraryManage.Message += ", " + strconv.Itoa(result.Unresolved) + " entry(s) not matched"
+		}
+	}
+
+	sendLibraryManageSection(w, r, td, cookie.AuthKey(), getContinueWatchingStaleDays(r))
+}
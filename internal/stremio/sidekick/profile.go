@@ -0,0 +1,488 @@
+package stremio_sidekick
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/MunifTanjim/stremthru/internal/shared"
+	stremio_addon "github.com/MunifTanjim/stremthru/internal/stremio/addon"
+	stremio_api "github.com/MunifTanjim/stremthru/internal/stremio/api"
+	stremio_backup "github.com/MunifTanjim/stremthru/internal/stremio/backup"
+	stremio_profile "github.com/MunifTanjim/stremthru/internal/stremio/profile"
+	"github.com/MunifTanjim/stremthru/stremio"
+)
+
+type ProfileAccount struct {
+	stremio_profile.Account
+	ProfileName string
+	IsChecked   bool
+	Drift       *stremio_backup.Diff
+	Error       string
+}
+
+func (pa ProfileAccount) IsDrifted() bool {
+	return pa.Drift != nil && !pa.Drift.IsEmpty()
+}
+
+func loadProfiles(td *TemplateData, checkDrift bool) error {
+	td.Profiles.IsLoaded = true
+
+	profiles, err := stremio_profile.GetProfiles()
+	if err != nil {
+		return err
+	}
+	td.Profiles.Profiles = profiles
+
+	profileById := make(map[string]*stremio_profile.Profile, len(profiles))
+	for i := range profiles {
+		profileById[profiles[i].Id] = &profiles[i]
+	}
+
+	accounts, err := stremio_profile.GetAccounts()
+	if err != nil {
+		return err
+	}
+	td.Profiles.Accounts = make([]ProfileAccount, len(accounts))
+	for i := range accounts {
+		pa := &td.Profiles.Accounts[i]
+		pa.Account = accounts[i]
+		if p, ok := profileById[pa.ProfileId]; ok {
+			pa.ProfileName = p.Name
+		}
+	}
+
+	if checkDrift {
+		var wg sync.WaitGroup
+		for i := range td.Profiles.Accounts {
+			pa := &td.Profiles.Accounts[i]
+			p, ok := profileById[pa.ProfileId]
+			if !ok {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				pa.IsChecked = true
+				drift, err := stremio_profile.GetDrift(p, &pa.Account)
+				if err != nil {
+					pa.Error = err.Error()
+					return
+				}
+				pa.Drift = drift
+			}()
+		}
+		wg.Wait()
+	}
+
+	return nil
+}
+
+func sendProfilesSection(w http.ResponseWriter, r *http.Request, td *TemplateData, checkDrift bool) {
+	if err := loadProfiles(td, checkDrift); err != nil {
+		SendError(w, r, err)
+		return
+	}
+	buf, err := executeTemplate(td, "sidekick_profiles_section.html")
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	SendHTML(w, 200, buf)
+}
+
+func getProfilesTemplateData(w http.ResponseWriter, r *http.Request) (*CookieValue, *TemplateData, bool) {
+	cookie, err := getCookieValue(w, r)
+	if err != nil {
+		SendError(w, r, err)
+		return nil, nil, false
+	}
+
+	td := getTemplateData(cookie, w, r)
+	if !td.HasAuthAdmin {
+		shared.ErrorForbidden(r).Send(w, r)
+		return nil, nil, false
+	}
+
+	return cookie, td, true
+}
+
+func handleProfiles(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodGet) && !IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	cookie, td, ok := getProfilesTemplateData(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		name := strings.TrimSpace(r.FormValue("name"))
+		if name == "" {
+			td.Profiles.HasError = true
+			td.Profiles.Message = "Missing profile name!"
+		} else if addons, err := stremio_profile.GetAccountAddons(cookie.AuthKey()); err != nil {
+			td.Profiles.HasError = true
+			td.Profiles.Message = "Failed to get addons: " + err.Error()
+		} else if err := stremio_profile.UpsertProfile(&stremio_profile.Profile{
+			Name:   name,
+			Addons: addons,
+		}); err != nil {
+			td.Profiles.HasError = true
+			td.Profiles.Message = "Failed to create profile: " + err.Error()
+		} else {
+			td.Profiles.Message = "Created profile " + name
+		}
+	}
+
+	sendProfilesSection(w, r, td, r.URL.Query().Get("check_drift") == "1")
+}
+
+func getProfile(w http.ResponseWriter, r *http.Request) (*stremio_profile.Profile, bool) {
+	profile, err := stremio_profile.GetProfile(r.PathValue("profileId"))
+	if err != nil {
+		SendError(w, r, err)
+		return nil, false
+	}
+	if profile == nil {
+		shared.ErrorNotFound(r).Send(w, r)
+		return nil, false
+	}
+	return profile, true
+}
+
+func handleProfile(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodGet) && !IsMethod(r, http.MethodPost) && !IsMethod(r, http.MethodDelete) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	cookie, td, ok := getProfilesTemplateData(w, r)
+	if !ok {
+		return
+	}
+
+	profile, ok := getProfile(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		td.Profiles.Editing = profile
+	case http.MethodPost:
+		if name := strings.TrimSpace(r.FormValue("name")); name != "" {
+			profile.Name = name
+		}
+		if r.FormValue("addons") == "current" {
+			addons, err := stremio_profile.GetAccountAddons(cookie.AuthKey())
+			if err != nil {
+				td.Profiles.HasError = true
+				td.Profiles.Message = "Failed to get addons: " + err.Error()
+				break
+			}
+			profile.Addons = addons
+		}
+		if err := stremio_profile.UpsertProfile(profile); err != nil {
+			td.Profiles.HasError = true
+			td.Profiles.Message = "Failed to update profile: " + err.Error()
+		} else {
+			td.Profiles.Message = "Updated profile " + profile.Name
+		}
+		if r.FormValue("editing") == "1" {
+			td.Profiles.Editing = profile
+		}
+	case http.MethodDelete:
+		if err := stremio_profile.DeleteProfile(profile.Id); err != nil {
+			td.Profiles.HasError = true
+			td.Profiles.Message = "Failed to delete profile: " + err.Error()
+		} else {
+			td.Profiles.Message = "Deleted profile " + profile.Name
+		}
+	}
+
+	sendProfilesSection(w, r, td, false)
+}
+
+// saveEditingProfile saves the profile after an addon edit, and keeps the
+// profile open in the editor.
+func saveEditingProfile(w http.ResponseWriter, r *http.Request, td *TemplateData, profile *stremio_profile.Profile) {
+	if !td.Profiles.HasError {
+		if err := stremio_profile.UpsertProfile(profile); err != nil {
+			td.Profiles.HasError = true
+			td.Profiles.Message = "Failed to update profile: " + err.Error()
+		}
+	}
+	td.Profiles.Editing = profile
+	sendProfilesSection(w, r, td, false)
+}
+
+func handleProfileAddons(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	_, td, ok := getProfilesTemplateData(w, r)
+	if !ok {
+		return
+	}
+
+	profile, ok := getProfile(w, r)
+	if !ok {
+		return
+	}
+
+	manifestUrl, err := stremio_addon.NormalizeManifestURL(r.FormValue("manifest_url"))
+	if err != nil || manifestUrl == "" {
+		td.Profiles.HasError = true
+		td.Profiles.Message = "Invalid manifest url!"
+	} else if findAddonIndex(profile.Addons, manifestUrl) != -1 {
+		td.Profiles.HasError = true
+		td.Profiles.Message = "Addon already exists!"
+	} else if baseUrl, err := stremio_addon.ExtractBaseURL(manifestUrl); err != nil {
+		td.Profiles.HasError = true
+		td.Profiles.Message = "Invalid manifest url!"
+	} else if res, err := addon_client.GetManifest(&stremio_addon.GetManifestParams{BaseURL: baseUrl}); err != nil {
+		td.Profiles.HasError = true
+		td.Profiles.Message = "Failed to get manifest: " + err.Error()
+	} else {
+		profile.Addons = append(profile.Addons, stremio.Addon{
+			TransportUrl: manifestUrl,
+			Manifest:     res.Data,
+			Flags:        &stremio.AddonFlags{},
+		})
+		td.Profiles.Message = "Added addon " + res.Data.Name
+	}
+
+	saveEditingProfile(w, r, td, profile)
+}
+
+func handleProfileAddon(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodDelete) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	_, td, ok := getProfilesTemplateData(w, r)
+	if !ok {
+		return
+	}
+
+	profile, ok := getProfile(w, r)
+	if !ok {
+		return
+	}
+
+	if idx := findAddonIndex(profile.Addons, r.PathValue("transportUrl")); idx != -1 {
+		td.Profiles.Message = "Removed addon " + profile.Addons[idx].Manifest.Name
+		profile.Addons = slices.Delete(profile.Addons, idx, idx+1)
+	}
+
+	saveEditingProfile(w, r, td, profile)
+}
+
+func handleProfileAddonMove(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	_, td, ok := getProfilesTemplateData(w, r)
+	if !ok {
+		return
+	}
+
+	profile, ok := getProfile(w, r)
+	if !ok {
+		return
+	}
+
+	idx := findAddonIndex(profile.Addons, r.PathValue("transportUrl"))
+	profile.Addons = moveAddon(profile.Addons, idx, r.PathValue("direction"))
+
+	saveEditingProfile(w, r, td, profile)
+}
+
+func handleProfileAddonModify(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	_, td, ok := getProfilesTemplateData(w, r)
+	if !ok {
+		return
+	}
+
+	profile, ok := getProfile(w, r)
+	if !ok {
+		return
+	}
+
+	if idx := findAddonIndex(profile.Addons, r.PathValue("transportUrl")); idx != -1 {
+		modifyAddon(&profile.Addons[idx], r, td.HasAuthAdmin)
+		td.Profiles.Message = "Modified addon " + profile.Addons[idx].Manifest.Name
+	}
+
+	saveEditingProfile(w, r, td, profile)
+}
+
+func handleProfileAccounts(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	cookie, td, ok := getProfilesTemplateData(w, r)
+	if !ok {
+		return
+	}
+
+	authKey := strings.TrimSpace(r.FormValue("token"))
+	if authKey == "" {
+		authKey = cookie.AuthKey()
+	}
+
+	params := &stremio_api.GetUserParams{}
+	params.APIKey = authKey
+	res, err := client.GetUser(params)
+	if err != nil {
+		td.Profiles.HasError = true
+		td.Profiles.Message = "Failed to link account: " + err.Error()
+	} else if err := stremio_profile.LinkAccount(res.Data.Id, res.Data.Email, authKey); err != nil {
+		td.Profiles.HasError = true
+		td.Profiles.Message = "Failed to link account: " + err.Error()
+	} else {
+		td.Profiles.Message = "Linked account " + res.Data.Email
+	}
+
+	sendProfilesSection(w, r, td, false)
+}
+
+func handleProfileAccount(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodPost) && !IsMethod(r, http.MethodDelete) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	_, td, ok := getProfilesTemplateData(w, r)
+	if !ok {
+		return
+	}
+
+	account, err := stremio_profile.GetAccount(r.PathValue("accountId"))
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+	if account == nil {
+		shared.ErrorNotFound(r).Send(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		profileId := r.FormValue("profile_id")
+		if profileId != "" {
+			if profile, err := stremio_profile.GetProfile(profileId); err != nil {
+				SendError(w, r, err)
+				return
+			} else if profile == nil {
+				td.Profiles.HasError = true
+				td.Profiles.Message = "Profile not found!"
+				break
+			}
+		}
+		if err := stremio_profile.SetAccountProfile(account.Id, profileId); err != nil {
+			td.Profiles.HasError = true
+			td.Profiles.Message = "Failed to set profile: " + err.Error()
+		}
+	case http.MethodDelete:
+		if err := stremio_profile.UnlinkAccount(account.Id); err != nil {
+			td.Profiles.HasError = true
+			td.Profiles.Message = "Failed to unlink account: " + err.Error()
+		} else {
+			td.Profiles.Message = "Unlinked account " + account.Email
+		}
+	}
+
+	sendProfilesSection(w, r, td, false)
+}
+
+func handleProfilesApply(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodPost) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	_, td, ok := getProfilesTemplateData(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	accountIds := r.Form["account_id"]
+	if len(accountIds) == 0 {
+		td.Profiles.HasError = true
+		td.Profiles.Message = "No account selected!"
+		sendProfilesSection(w, r, td, false)
+		return
+	}
+
+	profileById := map[string]*stremio_profile.Profile{}
+	var errs []error
+	appliedCount := 0
+	for _, accountId := range accountIds {
+		account, err := stremio_profile.GetAccount(accountId)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if account == nil || account.ProfileId == "" {
+			continue
+		}
+		profile, ok := profileById[account.ProfileId]
+		if !ok {
+			profile, err = stremio_profile.GetProfile(account.ProfileId)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			profileById[account.ProfileId] = profile
+		}
+		if profile == nil {
+			continue
+		}
+		if err := stremio_profile.Apply(profile, account); err != nil {
+			errs = append(errs, errors.New(account.Email+": "+err.Error()))
+			continue
+		}
+		appliedCount++
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		td.Profiles.HasError = true
+		td.Profiles.Message = "Failed to apply profile: " + err.Error()
+	} else {
+		td.Profiles.Message = "Applied profile to " + pluralizeAccounts(appliedCount)
+	}
+
+	sendProfilesSection(w, r, td, false)
+}
+
+func pluralizeAccounts(count int) string {
+	if count == 1 {
+		return "1 account"
+	}
+	return strconv.Itoa(count) + " accounts"
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/MunifTanjim/stremthru/internal/shared"
 	"github.com/MunifTanjim/stremthru/internal/stremio/addon"
 	"github.com/MunifTanjim/stremthru/internal/stremio/api"
+	stremio_backup "github.com/MunifTanjim/stremthru/internal/stremio/backup"
 	stremio_shared "github.com/MunifTanjim/stremthru/internal/stremio/shared"
 	"github.com/MunifTanjim/stremthru/stremio"
 )
@@ -176,6 +177,9 @@ func handleLogin(w http.ResponseWriter, r *http.Request) {
 		})
 		if err == nil {
 			setCookie(w, res.Data.AuthKey, res.Data.User.Email)
+			if err := stremio_backup.SetScheduleAuthKey(res.Data.User.Id, res.Data.User.Email, res.Data.AuthKey); err != nil {
+				LogError(r, "failed to refresh backup schedule auth key", err)
+			}
 			if r.Header.Get("hx-request") == "true" {
 				w.Header().Add("hx-refresh", "true")
 				w.Header().Add("hx-redirect", "/stremio/sidekick")
@@ -213,6 +217,9 @@ func handleLogin(w http.ResponseWriter, r *http.Request) {
 		res, err := client.GetUser(params)
 		if err == nil {
 			setCookie(w, token, res.Data.Email)
+			if err := stremio_backup.SetScheduleAuthKey(res.Data.Id, res.Data.Email, token); err != nil {
+				LogError(r, "failed to refresh backup schedule auth key", err)
+			}
 			if r.Header.Get("hx-request") == "true" {
 				w.Header().Add("hx-refresh", "true")
 				w.Header().Add("hx-redirect", "/stremio/sidekick")
@@ -622,51 +629,14 @@ func handleAddonMove(w http.ResponseWriter, r *http.Request) {
 	}
 
 	currAddons := get_res.Data.Addons
-	totalAddons := len(currAddons)
 
 	td := getTemplateData(cookie, w, r)
-	td.Addons = make([]stremio.Addon, 0, totalAddons)
-	td.Addons = append(td.Addons, currAddons...)
+	td.Addons = slices.Clone(currAddons)
 
-	idx := -1
-	for i := range td.Addons {
-		if td.Addons[i].TransportUrl == transportUrl {
-			idx = i
-			break
-		}
-	}
+	idx := findAddonIndex(td.Addons, transportUrl)
 
 	if idx != -1 {
-		switch direction {
-		case "top":
-			if idx == 0 {
-				break
-			}
-			addons := make([]stremio.Addon, 0, totalAddons)
-			addons = append(addons, td.Addons[idx])
-			addons = append(addons, td.Addons[:idx]...)
-			addons = append(addons, td.Addons[idx+1:]...)
-			td.Addons = addons
-		case "up":
-			if idx == 0 {
-				break
-			}
-			td.Addons[idx], td.Addons[idx-1] = td.Addons[idx-1], td.Addons[idx]
-		case "down":
-			if idx == totalAddons-1 {
-				break
-			}
-			td.Addons[idx], td.Addons[idx+1] = td.Addons[idx+1], td.Addons[idx]
-		case "bottom":
-			if idx == totalAddons-1 {
-				break
-			}
-			addons := make([]stremio.Addon, 0, totalAddons)
-			addons = append(addons, td.Addons[:idx]...)
-			addons = append(addons, td.Addons[idx+1:]...)
-			addons = append(addons, td.Addons[idx])
-			td.Addons = addons
-		}
+		td.Addons = moveAddon(td.Addons, idx, direction)
 
 		if td.AddonError == "" {
 			set_params := &stremio_api.SetAddonsParams{
@@ -944,9 +914,6 @@ func handleAddonModify(w http.ResponseWriter, r *http.Request) {
 	log := server.GetReqCtx(r).Log
 
 	transportUrl := r.PathValue("transportUrl")
-	name := r.FormValue("name")
-	description := r.FormValue("description")
-	logo := r.FormValue("logo")
 
 	cookie, err := getCookieValue(w, r)
 	if err != nil {
@@ -967,46 +934,10 @@ func handleAddonModify(w http.ResponseWriter, r *http.Request) {
 	td := getTemplateData(cookie, w, r)
 	td.Addons = slices.Clone(currAddons)
 
-	idx := -1
-	for i := range td.Addons {
-		if td.Addons[i].TransportUrl == transportUrl {
-			idx = i
-			break
-		}
-	}
+	idx := findAddonIndex(td.Addons, transportUrl)
 
 	if idx != -1 {
-		addon := &td.Addons[idx]
-		addon.Manifest.Name = name
-		addon.Manifest.Description = description
-		if logo != "" {
-			addon.Manifest.Logo = logo
-		}
-		for i := range addon.Manifest.Catalogs {
-			catalog := &addon.Manifest.Catalogs[i]
-			if name := r.FormValue("catalog_name[" + strconv.Itoa(i) + "]"); name != "" {
-				catalog.Name = name
-			}
-			if hidden := r.FormValue("catalog_hidden[" + strconv.Itoa(i) + "]"); hidden != "" {
-				if canToggleCatalogBoard(catalog) {
-					toggleCatalogBoard(catalog, hidden == "true")
-				}
-			}
-		}
-		if td.HasAuthAdmin {
-			isConfigurable := r.FormValue("configurable") == "true"
-			isProtected := r.FormValue("protected") == "true"
-
-			if addon.Manifest.BehaviorHints != nil {
-				addon.Manifest.BehaviorHints.Configurable = isConfigurable
-			}
-			if addon.Flags == nil {
-				addon.Flags = &stremio.AddonFlags{}
-			}
-			if !addon.Flags.Official {
-				addon.Flags.Protected = isProtected
-			}
-		}
+		modifyAddon(&td.Addons[idx], r, td.HasAuthAdmin)
 		set_params := &stremio_api.SetAddonsParams{
 			Addons: td.Addons,
 		}
@@ -1149,6 +1080,26 @@ func AddStremioSidekickEndpoints(mux *http.ServeMux) {
 
 	router.HandleFunc("/library/backup", handleLibraryBackup)
 	router.HandleFunc("/library/restore", handleLibraryRestore)
+	router.HandleFunc("/library/manage", handleLibraryManage)
+	router.HandleFunc("/library/duplicates", handleLibraryDuplicates)
+	router.HandleFunc("/library/season", handleLibrarySeason)
+	router.HandleFunc("/library/continue-watching", handleLibraryContinueWatching)
+	router.HandleFunc("/library/import", handleLibraryImport)
+
+	router.HandleFunc("/backups", handleBackups)
+	router.HandleFunc("/backups/run", handleBackupsRun)
+	router.HandleFunc("/backups/diff", handleBackupsDiff)
+	router.HandleFunc("/backups/{id}/restore", handleBackupRestore)
+
+	router.HandleFunc("/profiles", handleProfiles)
+	router.HandleFunc("/profiles/apply", handleProfilesApply)
+	router.HandleFunc("/profiles/accounts", handleProfileAccounts)
+	router.HandleFunc("/profiles/accounts/{accountId}", handleProfileAccount)
+	router.HandleFunc("/profiles/{profileId}", handleProfile)
+	router.HandleFunc("/profiles/{profileId}/addons", handleProfileAddons)
+	router.HandleFunc("/profiles/{profileId}/addons/{transportUrl}", handleProfileAddon)
+	router.HandleFunc("/profiles/{profileId}/addons/{transportUrl}/move/{direction}", handleProfileAddonMove)
+	router.HandleFunc("/profiles/{profileId}/addons/{transportUrl}/modify", handleProfileAddonModify)
 
 	mux.Handle("/stremio/sidekick/", http.StripPrefix("/stremio/sidekick", commonMiddleware(router)))
 }
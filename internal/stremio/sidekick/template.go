@@ -8,6 +8,12 @@ import (
 	"strings"
 
 	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/db"
+	stremio_api "github.com/MunifTanjim/stremthru/internal/stremio/api"
+	stremio_backup "github.com/MunifTanjim/stremthru/internal/stremio/backup"
+	"github.com/MunifTanjim/stremthru/internal/stremio/configure"
+	stremio_library "github.com/MunifTanjim/stremthru/internal/stremio/library"
+	stremio_profile "github.com/MunifTanjim/stremthru/internal/stremio/profile"
 	stremio_shared "github.com/MunifTanjim/stremthru/internal/stremio/shared"
 	stremio_template "github.com/MunifTanjim/stremthru/internal/stremio/template"
 	"github.com/MunifTanjim/stremthru/stremio"
@@ -50,6 +56,43 @@ type TemplateData struct {
 		}
 	}
 
+	LibraryManage struct {
+		IsLoaded              bool
+		ItemCount             int
+		Duplicates            []stremio_library.DuplicateGroup
+		Series                []stremio_api.LibraryItem
+		StaleDays             int
+		StaleContinueWatching []stremio_api.LibraryItem
+		HasError              bool
+		Message               string
+	}
+
+	Backups struct {
+		IsLoaded        bool
+		HasSchedule     bool
+		Enabled         bool
+		Interval        string
+		IntervalOptions []configure.ConfigOption
+		Retention       int
+		MaxRetention    int
+		LastRunAt       db.Timestamp
+		LastError       string
+		Snapshots       []stremio_backup.Snapshot
+		Diff            *stremio_backup.Diff
+		DiffError       string
+		HasError        bool
+		Message         string
+	}
+
+	Profiles struct {
+		IsLoaded bool
+		Profiles []stremio_profile.Profile
+		Accounts []ProfileAccount
+		Editing  *stremio_profile.Profile
+		HasError bool
+		Message  string
+	}
+
 	CanAuthAdmin   bool
 	HasAuthAdmin   bool
 	AuthAdminError string
@@ -131,6 +174,9 @@ var executeTemplate = func() stremio_template.Executor[TemplateData] {
 			}
 			return ""
 		},
+		"last_index": func(addons []stremio.Addon) int {
+			return len(addons) - 1
+		},
 		"catalog_has_board":        hasCatalogBoard,
 		"catalog_can_toggle_board": canToggleCatalogBoard,
 	}, "sidekick.html", "sidekick_*.html")
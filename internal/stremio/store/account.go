@@ -0,0 +1,97 @@
+package stremio_store
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/context"
+	"github.com/MunifTanjim/stremthru/store"
+	"github.com/MunifTanjim/stremthru/stremio"
+)
+
+const accountExpiryWarningDuration = 7 * 24 * time.Hour
+
+func getStoreAccountDescription(user *store.User) string {
+	var desc strings.Builder
+	desc.WriteString("👤 " + user.Id)
+	if user.Email != "" && user.Email != user.Id {
+		desc.WriteString(" (" + user.Email + ")")
+	}
+	desc.WriteString("\n🏷️ " + string(user.SubscriptionStatus))
+	if user.Plan != "" {
+		desc.WriteString(" · " + user.Plan)
+	}
+	if user.PremiumUntil != nil {
+		desc.WriteString("\n📅 Until " + user.PremiumUntil.Format(time.DateOnly))
+	}
+	if user.Points != nil {
+		desc.WriteString("\n⭐ " + strconv.Itoa(*user.Points) + " Points")
+	}
+	if user.ActiveSlots != nil || user.MaxSlots != nil {
+		desc.WriteString("\n🎰 Slots: ")
+		if user.ActiveSlots != nil {
+			desc.WriteString(strconv.Itoa(*user.ActiveSlots))
+		} else {
+			desc.WriteString("?")
+		}
+		if user.MaxSlots != nil {
+			desc.WriteString("/" + strconv.Itoa(*user.MaxSlots))
+		}
+	}
+	return desc.String()
+}
+
+// getStoreAccountExpiryWarning returns the warning message if the
+// subscription expires within `accountExpiryWarningDuration`.
+func getStoreAccountExpiryWarning(user *store.User, now time.Time) string {
+	if user.PremiumUntil == nil {
+		return ""
+	}
+	left := user.PremiumUntil.Sub(now)
+	if left > accountExpiryWarningDuration {
+		return ""
+	}
+	if left <= 0 {
+		return "Subscription expired on " + user.PremiumUntil.Format(time.DateOnly)
+	}
+	days := int(math.Ceil(left.Hours() / 24))
+	return "Subscription expires in " + strconv.Itoa(days) + " day(s), on " + user.PremiumUntil.Format(time.DateOnly)
+}
+
+func getStoreAccountVideo(r *http.Request, ctx *context.StoreContext, storeCode string, eud string, released time.Time) *stremio.MetaVideo {
+	params := &store.GetUserParams{}
+	params.APIKey = ctx.StoreAuthToken
+	user, err := ctx.Store.GetUser(params)
+	if err != nil {
+		LogError(r, "failed to get user", err)
+		return nil
+	}
+
+	actionURL := ExtractRequestBaseURL(r).JoinPath("/stremio/store/" + eud + "/_/action/" + getStoreActionIdPrefix(storeCode) + "account").String()
+	description := getStoreAccountDescription(user)
+
+	video := &stremio.MetaVideo{
+		Id:       getStoreActionIdPrefix(storeCode) + "account",
+		Title:    "Account",
+		Released: released,
+		Overview: description,
+		Streams:  []stremio.Stream{},
+	}
+	if warning := getStoreAccountExpiryWarning(user, time.Now()); warning != "" {
+		video.Title = "⚠️ Account"
+		video.Streams = append(video.Streams, stremio.Stream{
+			URL:         actionURL,
+			Name:        "⚠️ Expiring",
+			Description: warning,
+		})
+	}
+	video.Streams = append(video.Streams, stremio.Stream{
+		URL:         actionURL,
+		Name:        "Account",
+		Description: description,
+	})
+	return video
+}
@@ -0,0 +1,34 @@
+package stremio_store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetStoreAccountExpiryWarning(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	for _, tc := range []struct {
+		name         string
+		premiumUntil *time.Time
+		warning      string
+	}{
+		{"unknown", nil, ""},
+		{"far", at(30 * 24 * time.Hour), ""},
+		{"close", at(2*24*time.Hour + time.Hour), "Subscription expires in 3 day(s), on 2025-01-03"},
+		{"today", at(time.Hour), "Subscription expires in 1 day(s), on 2025-01-01"},
+		{"expired", at(-24 * time.Hour), "Subscription expired on 2024-12-31"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			user := &store.User{PremiumUntil: tc.premiumUntil}
+			assert.Equal(t, tc.warning, getStoreAccountExpiryWarning(user, now))
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/MunifTanjim/go-ptt"
 	"github.com/MunifTanjim/stremthru/core"
 	"github.com/MunifTanjim/stremthru/internal/cache"
+	"github.com/MunifTanjim/stremthru/internal/context"
 	"github.com/MunifTanjim/stremthru/internal/shared"
 	stremio_addon "github.com/MunifTanjim/stremthru/internal/stremio/addon"
 	stremio_store_usenet "github.com/MunifTanjim/stremthru/internal/stremio/store/usenet"
@@ -324,7 +325,7 @@ func getStoreContentInfo(s store.Store, storeToken string, id string, clientIp s
 	return &contentInfo{magnet, ""}, nil
 }
 
-func getStoreActionMeta(r *http.Request, storeCode string, eud string) stremio.Meta {
+func getStoreActionMeta(r *http.Request, ctx *context.StoreContext, storeCode string, eud string) stremio.Meta {
 	released := time.Now().UTC()
 	meta := stremio.Meta{
 		Id:          getStoreActionId(storeCode),
@@ -347,6 +348,9 @@ func getStoreActionMeta(r *http.Request, storeCode string, eud string) stremio.M
 			},
 		},
 	}
+	if video := getStoreAccountVideo(r, ctx, storeCode, eud, released); video != nil {
+		meta.Videos = append([]stremio.MetaVideo{*video}, meta.Videos...)
+	}
 	return meta
 }
 
@@ -399,7 +403,7 @@ func handleMeta(w http.ResponseWriter, r *http.Request) {
 
 	if id == getStoreActionId(idStoreCode) {
 		res := stremio.MetaHandlerResponse{
-			Meta: getStoreActionMeta(r, idStoreCode, eud),
+			Meta: getStoreActionMeta(r, ctx, idStoreCode, eud),
 		}
 
 		SendResponse(w, r, 200, res)
@@ -24,6 +24,14 @@ func getStoreNameConfig(defaultValue string) configure.Config {
 	if config.IsPublicInstance {
 		options[0].Disabled = true
 		options[0].Label = ""
+	} else {
+		if len(config.StoreLocalDirs) > 0 {
+			options = append(options, configure.ConfigOption{Value: "local", Label: "Local 🧪"})
+		}
+		options = append(options,
+			configure.ConfigOption{Value: "qbittorrent", Label: "qBittorrent 🧪"},
+			configure.ConfigOption{Value: "transmission", Label: "Transmission 🧪"},
+		)
 	}
 	config := configure.Config{
 		Key:      "store_name",
@@ -88,7 +88,7 @@ func handleStrem(w http.ResponseWriter, r *http.Request) {
 			ClientIP: ctx.ClientIP,
 		}
 		amParams.APIKey = ctx.StoreAuthToken
-		amRes, err := ctx.Store.AddMagnet(amParams)
+		amRes, err := shared.AddMagnet(ctx.Store, amParams)
 		if err != nil {
 			result := &stremResult{
 				error_log:   "failed to add magnet",
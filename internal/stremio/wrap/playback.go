@@ -88,7 +88,7 @@ func handleStrem(w http.ResponseWriter, r *http.Request) {
 			ClientIP: ctx.ClientIP,
 		}
 		amParams.APIKey = ctx.StoreAuthToken
-		amRes, err := ctx.Store.AddMagnet(amParams)
+		amRes, err := shared.AddMagnet(ctx.Store, amParams)
 		if err != nil {
 			return &stremResult{
 				error_log:   "failed to add magnet",
@@ -216,3 +216,82 @@ func handleStrem(w http.ResponseWriter, r *http.Request) {
 	log.Debug("redirecting to stream link")
 	http.Redirect(w, r, strem.link, http.StatusFound)
 }
+
+func handleUnrestrict(w http.ResponseWriter, r *http.Request) {
+	if !IsMethod(r, http.MethodGet) && !IsMethod(r, http.MethodHead) {
+		shared.ErrorMethodNotAllowed(r).Send(w, r)
+		return
+	}
+
+	log := server.GetReqCtx(r).Log
+
+	ud, err := getUserData(r)
+	if err != nil {
+		SendError(w, r, err)
+		return
+	}
+
+	ctx, err := ud.GetRequestContext(r)
+	if err != nil {
+		LogError(r, "failed to get request context", err)
+		shared.ErrorBadRequest(r, "failed to get request context: "+err.Error()).Send(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+
+	link := query.Get("link")
+	if link == "" {
+		shared.ErrorBadRequest(r, "missing link").Send(w, r)
+		return
+	}
+
+	s := ud.GetStoreByCode(query.Get("s"))
+	ctx.Store, ctx.StoreAuthToken = s.Store, s.AuthToken
+	storeCode := ctx.Store.GetName().Code()
+
+	cacheKey := strings.Join([]string{ctx.ClientIP, string(storeCode), ctx.StoreAuthToken, link}, ":")
+
+	stremLink := ""
+	if stremLinkCache.Get(cacheKey, &stremLink) {
+		log.Debug("redirecting to cached unrestricted link")
+		http.Redirect(w, r, stremLink, http.StatusFound)
+		return
+	}
+
+	result, err, _ := stremGroup.Do(cacheKey, func() (any, error) {
+		log.Debug("unrestricting link")
+		ulRes, err := shared.UnrestrictStremThruLink(r, ctx, link, "")
+		if err != nil {
+			strem := &stremResult{
+				error_log:   "failed to unrestrict link",
+				error_video: "500",
+			}
+			if sterr, ok := err.(core.StremThruError); ok && sterr.GetStatusCode() == http.StatusTeapot {
+				strem.error_video = "downloading"
+			}
+			return strem, err
+		}
+
+		stremLinkCache.Add(cacheKey, ulRes.Link)
+
+		return &stremResult{
+			link: ulRes.Link,
+		}, nil
+	})
+
+	strem := result.(*stremResult)
+
+	if strem.error_log != "" {
+		if err != nil {
+			LogError(r, strem.error_log, err)
+		} else {
+			log.Error(strem.error_log)
+		}
+		redirectToStaticVideo(w, r, cacheKey, strem.error_video)
+		return
+	}
+
+	log.Debug("redirecting to unrestricted link")
+	http.Redirect(w, r, strem.link, http.StatusFound)
+}
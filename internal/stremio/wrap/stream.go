@@ -2,6 +2,7 @@ package stremio_wrap
 
 import (
 	"errors"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -19,7 +20,7 @@ import (
 	stremio_transformer "github.com/MunifTanjim/stremthru/internal/stremio/transformer"
 	"github.com/MunifTanjim/stremthru/internal/torrent_info"
 	"github.com/MunifTanjim/stremthru/internal/torrent_stream"
-	"github.com/MunifTanjim/stremthru/internal/worker"
+	"github.com/MunifTanjim/stremthru/internal/worker/worker_queue"
 	"github.com/MunifTanjim/stremthru/store"
 	"github.com/MunifTanjim/stremthru/stremio"
 )
@@ -157,7 +158,7 @@ func (ud UserData) fetchStream(ctx *context.StoreContext, r *http.Request, rType
 			}
 			if isImdbStremId {
 				if len(tInfos) > 0 {
-					worker.TorrentPusherQueue.Queue(stremId)
+					worker_queue.TorrentPusherQueue.Queue(stremId)
 				}
 				go torrent_info.Upsert(tInfos, torrentInfoCategory, false)
 			}
@@ -228,6 +229,14 @@ func (ud UserData) fetchStream(ctx *context.StoreContext, r *http.Request, rType
 		hasErrByStoreCode = cmRes.HasErrByStoreCode
 	}
 
+	var hostLinkStores []hostLinkStore
+	for i := range allStreams {
+		if allStreams[i].URL != "" {
+			hostLinkStores = ud.getHostLinkStores(log)
+			break
+		}
+	}
+
 	cachedStreams := []stremio.Stream{}
 	uncachedStreams := []stremio.Stream{}
 	for i := range allStreams {
@@ -283,6 +292,23 @@ func (ud UserData) fetchStream(ctx *context.StoreContext, r *http.Request, rType
 				}
 			}
 		} else if stream.URL != "" {
+			if s := findHostLinkStore(hostLinkStores, stream.URL); s != nil {
+				storeCode := strings.ToUpper(string(s.store.GetName().Code()))
+				uurl := shared.ExtractRequestBaseURL(r).JoinPath("/stremio/wrap/" + eud + "/_/unrestrict/")
+				if stream.BehaviorHints != nil && stream.BehaviorHints.Filename != "" {
+					uurl = uurl.JoinPath(url.PathEscape(stream.BehaviorHints.Filename))
+				}
+				uurl.RawQuery = "s=" + storeCode + "&link=" + url.QueryEscape(stream.URL)
+				stream.URL = uurl.String()
+				stream.Name = "🔗 [" + storeCode + "] " + stream.Name
+
+				if ctx.IsProxyAuthorized && config.StoreContentProxy.IsEnabled(string(s.store.GetName())) {
+					stream.Name = "✨ " + stream.Name
+				}
+
+				cachedStreams = append(cachedStreams, *stream.Stream)
+				continue
+			}
 			if !stream.noContentProxy {
 				var headers map[string]string
 				if stream.BehaviorHints != nil && stream.BehaviorHints.ProxyHeaders != nil && stream.BehaviorHints.ProxyHeaders.Request != nil {
@@ -319,3 +345,36 @@ func (ud UserData) fetchStream(ctx *context.StoreContext, r *http.Request, rType
 		Streams: streams,
 	}, nil
 }
+
+type hostLinkStore struct {
+	store store.Store
+	token string
+	hosts []string
+}
+
+// getHostLinkStores returns the configured stores that can unrestrict file
+// hoster links, along with their supported hosts.
+func (ud UserData) getHostLinkStores(log *slog.Logger) []hostLinkStore {
+	hlStores := []hostLinkStore{}
+	for _, s := range ud.GetStores() {
+		if _, ok := s.Store.(store.LinkUnrestrictor); !ok {
+			continue
+		}
+		hosts, err := shared.ListStoreHosts(s.Store, s.AuthToken)
+		if err != nil {
+			log.Warn("failed to list store hosts", "store", s.Store.GetName(), "error", err)
+			continue
+		}
+		hlStores = append(hlStores, hostLinkStore{store: s.Store, token: s.AuthToken, hosts: hosts})
+	}
+	return hlStores
+}
+
+func findHostLinkStore(hlStores []hostLinkStore, link string) *hostLinkStore {
+	for i := range hlStores {
+		if shared.IsHostLink(link, hlStores[i].hosts) {
+			return &hlStores[i]
+		}
+	}
+	return nil
+}
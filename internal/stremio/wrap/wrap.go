@@ -159,5 +159,8 @@ func AddStremioWrapEndpoints(mux *http.ServeMux) {
 	router.HandleFunc("/{userData}/_/strem/{magnetHash}/{fileIdx}/{$}", withCors(handleStrem))
 	router.HandleFunc("/{userData}/_/strem/{magnetHash}/{fileIdx}/{fileName}", withCors(handleStrem))
 
+	router.HandleFunc("/{userData}/_/unrestrict/{$}", withCors(handleUnrestrict))
+	router.HandleFunc("/{userData}/_/unrestrict/{fileName}", withCors(handleUnrestrict))
+
 	mux.Handle("/stremio/wrap/", http.StripPrefix("/stremio/wrap", commonMiddleware(router)))
 }
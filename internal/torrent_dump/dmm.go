@@ -0,0 +1,146 @@
+package torrent_dump
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/MunifTanjim/stremthru/internal/lzstring"
+)
+
+const dmmHashlistURL = "https://debridmediamanager.com/hashlist"
+
+type DMMHashlistItem struct {
+	Filename string `json:"filename"`
+	Hash     string `json:"hash"`
+	Bytes    int64  `json:"bytes"`
+}
+
+type wrappedDMMHashlistItems struct {
+	Title    string            `json:"title"`
+	Torrents []DMMHashlistItem `json:"torrents"`
+}
+
+// <iframe .. src="https://..."
+var dmmHashlistURLRegex = regexp.MustCompile(`<iframe *src="(.+)".*>`)
+
+// <meta .. content="0;url=https://..."
+var dmmHashlistFallbackURLRegex = regexp.MustCompile(`content="(.+)".*`)
+
+func parseDMMHashlistBlob(blob string) ([]DMMHashlistItem, error) {
+	items := []DMMHashlistItem{}
+	if strings.HasPrefix(blob, "{") {
+		wrappedItems := wrappedDMMHashlistItems{}
+		if err := json.Unmarshal([]byte(blob), &wrappedItems); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal wrapped hashlist items: %w", err)
+		}
+		items = wrappedItems.Torrents
+	} else {
+		if err := json.Unmarshal([]byte(blob), &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal hashlist items: %w", err)
+		}
+	}
+	return items, nil
+}
+
+// ParseDMMHashlist parses the items from a hashlist html file. A raw json
+// blob, i.e. an array of items or the wrapped object, is accepted too.
+func ParseDMMHashlist(content []byte) ([]DMMHashlistItem, error) {
+	text := strings.TrimSpace(string(content))
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return parseDMMHashlistBlob(text)
+	}
+
+	dataUrl := ""
+	matches := dmmHashlistURLRegex.FindAllStringSubmatch(text, -1)
+	if len(matches) > 0 {
+		dataUrl = matches[0][1]
+	}
+	if dataUrl == "" {
+		matches = dmmHashlistFallbackURLRegex.FindAllStringSubmatch(text, -1)
+		if len(matches) > 0 {
+			dataUrl = matches[0][1]
+			dataUrl = strings.TrimPrefix(dataUrl, "0;url=")
+		}
+	}
+	if dataUrl == "" {
+		return nil, errors.New("failed to extract data url")
+	}
+	u, err := url.Parse(dataUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse data url: %w", err)
+	}
+	encodedData := u.Fragment
+	if encodedData == "" {
+		return nil, nil
+	}
+	blob, err := lzstring.DecompressFromEncodedUriComponent(encodedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress data: %w", err)
+	}
+	return parseDMMHashlistBlob(blob)
+}
+
+type dmmHashlistWriter struct {
+	w     io.Writer
+	z     *lzstring.Writer
+	count int
+}
+
+// newDMMHashlistWriter writes the items as a hashlist html file, in the same
+// shape as the files in the debridmediamanager/hashlists repository. The
+// items are compressed as they are written, so they are not kept in memory.
+func newDMMHashlistWriter(w io.Writer, title string) (*dmmHashlistWriter, error) {
+	// the data url is uri safe, no html escaping needed
+	if _, err := io.WriteString(w, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+</head>
+<body>
+<iframe src="`+dmmHashlistURL+`#`); err != nil {
+		return nil, err
+	}
+	hw := &dmmHashlistWriter{w: w, z: lzstring.NewWriter(w)}
+	titleBlob, err := json.Marshal(title)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(hw.z, `{"title":`+string(titleBlob)+`,"torrents":[`); err != nil {
+		return nil, err
+	}
+	return hw, nil
+}
+
+func (hw *dmmHashlistWriter) Write(item *DMMHashlistItem) error {
+	blob, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	if hw.count > 0 {
+		if _, err := io.WriteString(hw.z, ","); err != nil {
+			return err
+		}
+	}
+	hw.count++
+	_, err = hw.z.Write(blob)
+	return err
+}
+
+func (hw *dmmHashlistWriter) Close() error {
+	if _, err := io.WriteString(hw.z, `]}`); err != nil {
+		return err
+	}
+	if err := hw.z.Close(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(hw.w, `"></iframe>
+</body>
+</html>
+`)
+	return err
+}
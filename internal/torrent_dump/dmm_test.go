@@ -0,0 +1,56 @@
+package torrent_dump
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MunifTanjim/stremthru/internal/lzstring"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDMMHashlist(t *testing.T) {
+	items := []DMMHashlistItem{
+		{Filename: "Movie.2024.1080p.WEB-DL.mkv", Hash: "0123456789abcdef0123456789abcdef01234567", Bytes: 1234567890},
+		{Filename: "Show.S01.2160p.WEB-DL", Hash: "89abcdef0123456789abcdef0123456789abcdef", Bytes: 9876543210},
+	}
+
+	var buf bytes.Buffer
+	hw, err := newDMMHashlistWriter(&buf, "test")
+	assert.NoError(t, err)
+	for i := range items {
+		assert.NoError(t, hw.Write(&items[i]))
+	}
+	assert.NoError(t, hw.Close())
+
+	parsed, err := ParseDMMHashlist(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, items, parsed)
+
+	for _, tc := range []struct {
+		name    string
+		content string
+		items   []DMMHashlistItem
+	}{
+		{
+			name:    "raw array",
+			content: `[{"filename":"a","hash":"h","bytes":1}]`,
+			items:   []DMMHashlistItem{{Filename: "a", Hash: "h", Bytes: 1}},
+		},
+		{
+			name:    "raw wrapped",
+			content: `{"title":"t","torrents":[{"filename":"a","hash":"h","bytes":1}]}`,
+			items:   []DMMHashlistItem{{Filename: "a", Hash: "h", Bytes: 1}},
+		},
+		{
+			name:    "meta refresh",
+			content: `<meta http-equiv="refresh" content="0;url=https://debridmediamanager.com/hashlist#` + lzstring.CompressToEncodedUriComponent(`[{"filename":"a","hash":"h","bytes":1}]`) + `">`,
+			items:   []DMMHashlistItem{{Filename: "a", Hash: "h", Bytes: 1}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := ParseDMMHashlist([]byte(tc.content))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.items, parsed)
+		})
+	}
+}
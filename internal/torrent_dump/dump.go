@@ -0,0 +1,300 @@
+package torrent_dump
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/core"
+	"github.com/MunifTanjim/stremthru/internal/anidb"
+	"github.com/MunifTanjim/stremthru/internal/db"
+	"github.com/MunifTanjim/stremthru/internal/imdb_torrent"
+	ti "github.com/MunifTanjim/stremthru/internal/torrent_info"
+	ts "github.com/MunifTanjim/stremthru/internal/torrent_stream"
+)
+
+type Format string
+
+const (
+	// gzip compressed, one Record per line
+	FormatNDJSON Format = "ndjson"
+	// debridmediamanager hashlist html, only torrent name and size
+	FormatDMM Format = "dmm"
+)
+
+func (f Format) IsValid() bool {
+	return f == FormatNDJSON || f == FormatDMM
+}
+
+type AniDBMapping struct {
+	TId          string                  `json:"tid"`
+	SeasonType   anidb.TorrentSeasonType `json:"s_type"`
+	Season       int                     `json:"s"`
+	EpisodeStart int                     `json:"ep_start"`
+	EpisodeEnd   int                     `json:"ep_end"`
+	Episodes     db.CommaSeperatedInt    `json:"eps"`
+}
+
+type Record struct {
+	ti.TorrentItem
+	IMDBIds []string       `json:"imdb_ids,omitempty"`
+	AniDB   []AniDBMapping `json:"anidb,omitempty"`
+}
+
+const exportBatchSize = 1000
+
+func listRecords(since time.Time, afterHash string) ([]Record, error) {
+	items, err := ti.ListForExport(since, afterHash, exportBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	hashes := make([]string, len(items))
+	for i := range items {
+		hashes[i] = items[i].Hash
+	}
+	filesByHash, err := ts.GetFilesByHashes(hashes)
+	if err != nil {
+		return nil, err
+	}
+	imdbIdsByHash, err := imdb_torrent.GetTIdsByHashes(hashes)
+	if err != nil {
+		return nil, err
+	}
+	anidbTorrentsByHash, err := anidb.GetTorrentsByHashes(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, len(items))
+	for i, item := range items {
+		item.Files = filesByHash[item.Hash]
+		record := Record{
+			TorrentItem: item,
+			IMDBIds:     imdbIdsByHash[item.Hash],
+		}
+		for _, t := range anidbTorrentsByHash[item.Hash] {
+			record.AniDB = append(record.AniDB, AniDBMapping{
+				TId:          t.TId,
+				SeasonType:   t.SeasonType,
+				Season:       t.Season,
+				EpisodeStart: t.EpisodeStart,
+				EpisodeEnd:   t.EpisodeEnd,
+				Episodes:     t.Episodes,
+			})
+		}
+		records[i] = record
+	}
+	return records, nil
+}
+
+// Export writes the torrents in the given format, batch by batch. With
+// non-zero since, only the torrents changed since then are exported.
+func Export(w io.Writer, format Format, since time.Time) (count int, err error) {
+	var write func(records []Record) error
+	var finish func() error
+
+	switch format {
+	case FormatNDJSON:
+		gzw := gzip.NewWriter(w)
+		enc := json.NewEncoder(gzw)
+		write = func(records []Record) error {
+			for i := range records {
+				if err := enc.Encode(&records[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		finish = gzw.Close
+	case FormatDMM:
+		hw, err := newDMMHashlistWriter(w, "StremThru")
+		if err != nil {
+			return 0, err
+		}
+		write = func(records []Record) error {
+			for _, r := range records {
+				if r.Size <= 0 {
+					continue
+				}
+				if err := hw.Write(&DMMHashlistItem{
+					Filename: r.TorrentTitle,
+					Hash:     r.Hash,
+					Bytes:    r.Size,
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		finish = hw.Close
+	default:
+		return 0, errors.New("unsupported format: " + string(format))
+	}
+
+	afterHash := ""
+	for {
+		records, err := listRecords(since, afterHash)
+		if err != nil {
+			return count, err
+		}
+		if len(records) == 0 {
+			break
+		}
+		if err := write(records); err != nil {
+			return count, err
+		}
+		count += len(records)
+		afterHash = records[len(records)-1].Hash
+		log.Debug("exported torrents", "format", format, "count", count)
+	}
+
+	return count, finish()
+}
+
+type ImportResult struct {
+	Torrents      int `json:"torrents"`
+	IMDBMappings  int `json:"imdb_mappings"`
+	AniDBMappings int `json:"anidb_mappings"`
+}
+
+const importBatchSize = 500
+
+func importRecords(records []Record, source ti.TorrentInfoSource, result *ImportResult) error {
+	items := make([]ti.TorrentInfoInsertData, 0, len(records))
+	imdbTorrents := []imdb_torrent.IMDBTorrent{}
+	anidbTorrents := []anidb.AniDBTorrent{}
+	for _, r := range records {
+		magnet, err := core.ParseMagnetLink(r.Hash)
+		if err != nil || len(magnet.Hash) != 40 {
+			continue
+		}
+		item := r.TorrentItem
+		item.Hash = magnet.Hash
+		if source != ti.TorrentInfoSourceUnknown {
+			item.Source = source
+		}
+		items = append(items, item)
+		for _, tid := range r.IMDBIds {
+			imdbTorrents = append(imdbTorrents, imdb_torrent.IMDBTorrent{
+				TId:  tid,
+				Hash: item.Hash,
+			})
+		}
+		for _, m := range r.AniDB {
+			anidbTorrents = append(anidbTorrents, anidb.AniDBTorrent{
+				TId:          m.TId,
+				Hash:         item.Hash,
+				SeasonType:   m.SeasonType,
+				Season:       m.Season,
+				EpisodeStart: m.EpisodeStart,
+				EpisodeEnd:   m.EpisodeEnd,
+				Episodes:     m.Episodes,
+			})
+		}
+	}
+
+	if err := ti.Upsert(items, "", false); err != nil {
+		return err
+	}
+	if err := imdb_torrent.Insert(imdbTorrents); err != nil {
+		return err
+	}
+	if err := anidb.UpsertTorrents(anidbTorrents); err != nil {
+		return err
+	}
+	result.Torrents += len(items)
+	result.IMDBMappings += len(imdbTorrents)
+	result.AniDBMappings += len(anidbTorrents)
+	return nil
+}
+
+// Import upserts the torrents in the given format, gzip compressed input is
+// detected automatically. The source, if given, is attributed to every
+// torrent, overriding the one in the input. For the dmm format it defaults
+// to dmm.
+func Import(r io.Reader, format Format, source ti.TorrentInfoSource) (*ImportResult, error) {
+	if source != ti.TorrentInfoSourceUnknown && !source.IsValid() {
+		return nil, errors.New("invalid source: " + string(source))
+	}
+
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gzr.Close()
+		br = bufio.NewReader(gzr)
+	}
+
+	result := &ImportResult{}
+
+	switch format {
+	case FormatNDJSON:
+		dec := json.NewDecoder(br)
+		records := make([]Record, 0, importBatchSize)
+		for {
+			record := Record{}
+			err := dec.Decode(&record)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return result, err
+			}
+			records = append(records, record)
+			if len(records) == importBatchSize {
+				if err := importRecords(records, source, result); err != nil {
+					return result, err
+				}
+				records = records[:0]
+			}
+		}
+		if err := importRecords(records, source, result); err != nil {
+			return result, err
+		}
+	case FormatDMM:
+		if source == ti.TorrentInfoSourceUnknown {
+			source = ti.TorrentInfoSourceDMM
+		}
+		content, err := io.ReadAll(br)
+		if err != nil {
+			return nil, err
+		}
+		items, err := ParseDMMHashlist(content)
+		if err != nil {
+			return nil, err
+		}
+		records := make([]Record, 0, len(items))
+		for _, item := range items {
+			if item.Bytes == 0 || item.Filename == "" || item.Filename == "Magnet" {
+				continue
+			}
+			records = append(records, Record{
+				TorrentItem: ti.TorrentItem{
+					Hash:         item.Hash,
+					TorrentTitle: item.Filename,
+					Size:         item.Bytes,
+				},
+			})
+		}
+		for cRecords := range slices.Chunk(records, importBatchSize) {
+			if err := importRecords(cRecords, source, result); err != nil {
+				return result, err
+			}
+		}
+	default:
+		return nil, errors.New("unsupported format: " + string(format))
+	}
+
+	log.Info("imported torrents", "format", format, "source", source, "torrents", result.Torrents, "imdb_mappings", result.IMDBMappings, "anidb_mappings", result.AniDBMappings)
+	return result, nil
+}
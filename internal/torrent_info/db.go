@@ -76,23 +76,50 @@ func (csi *CommaSeperatedInt) Scan(value any) error {
 type TorrentInfoSource string
 
 const (
-	TorrentInfoSourceAnimeTosho  TorrentInfoSource = "ato"
-	TorrentInfoSourceDHT         TorrentInfoSource = "dht"
-	TorrentInfoSourceDMM         TorrentInfoSource = "dmm"
-	TorrentInfoSourceMediaFusion TorrentInfoSource = "mfn"
-	TorrentInfoSourceTorrentio   TorrentInfoSource = "tio"
-	TorrentInfoSourceAllDebrid   TorrentInfoSource = "ad"
-	TorrentInfoSourceDebrider    TorrentInfoSource = "dr"
-	TorrentInfoSourceDebridLink  TorrentInfoSource = "dl"
-	TorrentInfoSourceEasyDebrid  TorrentInfoSource = "ed"
-	TorrentInfoSourceOffcloud    TorrentInfoSource = "oc"
-	TorrentInfoSourcePikPak      TorrentInfoSource = "pp"
-	TorrentInfoSourcePremiumize  TorrentInfoSource = "pm"
-	TorrentInfoSourceRealDebrid  TorrentInfoSource = "rd"
-	TorrentInfoSourceTorBox      TorrentInfoSource = "tb"
-	TorrentInfoSourceUnknown     TorrentInfoSource = ""
+	TorrentInfoSourceAnimeTosho   TorrentInfoSource = "ato"
+	TorrentInfoSourceDHT          TorrentInfoSource = "dht"
+	TorrentInfoSourceDMM          TorrentInfoSource = "dmm"
+	TorrentInfoSourceMediaFusion  TorrentInfoSource = "mfn"
+	TorrentInfoSourceTorrentio    TorrentInfoSource = "tio"
+	TorrentInfoSourceAllDebrid    TorrentInfoSource = "ad"
+	TorrentInfoSourceDebrider     TorrentInfoSource = "dr"
+	TorrentInfoSourceDebridLink   TorrentInfoSource = "dl"
+	TorrentInfoSourceEasyDebrid   TorrentInfoSource = "ed"
+	TorrentInfoSourceLocal        TorrentInfoSource = "lc"
+	TorrentInfoSourceOffcloud     TorrentInfoSource = "oc"
+	TorrentInfoSourcePikPak       TorrentInfoSource = "pp"
+	TorrentInfoSourcePremiumize   TorrentInfoSource = "pm"
+	TorrentInfoSourceQBittorrent  TorrentInfoSource = "qb"
+	TorrentInfoSourceRealDebrid   TorrentInfoSource = "rd"
+	TorrentInfoSourceTorBox       TorrentInfoSource = "tb"
+	TorrentInfoSourceTransmission TorrentInfoSource = "tr"
+	TorrentInfoSourceUnknown      TorrentInfoSource = ""
 )
 
+var torrentInfoSources = []TorrentInfoSource{
+	TorrentInfoSourceAnimeTosho,
+	TorrentInfoSourceDHT,
+	TorrentInfoSourceDMM,
+	TorrentInfoSourceMediaFusion,
+	TorrentInfoSourceTorrentio,
+	TorrentInfoSourceAllDebrid,
+	TorrentInfoSourceDebrider,
+	TorrentInfoSourceDebridLink,
+	TorrentInfoSourceEasyDebrid,
+	TorrentInfoSourceLocal,
+	TorrentInfoSourceOffcloud,
+	TorrentInfoSourcePikPak,
+	TorrentInfoSourcePremiumize,
+	TorrentInfoSourceQBittorrent,
+	TorrentInfoSourceRealDebrid,
+	TorrentInfoSourceTorBox,
+	TorrentInfoSourceTransmission,
+}
+
+func (s TorrentInfoSource) IsValid() bool {
+	return slices.Contains(torrentInfoSources, s)
+}
+
 type TorrentInfoCategory string
 
 const (
@@ -1633,3 +1660,55 @@ func MarkForReparseBelowVersion(version int) error {
 	_, err := db.Exec(query_mark_for_reparse_below_version, version)
 	return err
 }
+
+var query_list_for_export_select = fmt.Sprintf(
+	"SELECT %s FROM %s ti WHERE ti.%s > ?",
+	list_query_columns,
+	TableName,
+	Column.Hash,
+)
+var query_list_for_export_cond_since = fmt.Sprintf(
+	" AND (ti.%s >= ? OR ti.%s IN (SELECT %s FROM %s WHERE %s >= ?) OR ti.%s IN (SELECT %s FROM %s WHERE %s >= ?) OR ti.%s IN (SELECT %s FROM %s WHERE %s >= ?))",
+	Column.UpdatedAt,
+	Column.Hash, ts.Column.Hash, ts.TableName, ts.Column.UAt,
+	Column.Hash, imdb_torrent.Column.Hash, imdb_torrent.TableName, imdb_torrent.Column.UAt,
+	Column.Hash, anidb.TorrentColumn.Hash, anidb.TorrentTableName, anidb.TorrentColumn.UAt,
+)
+var query_list_for_export_after_cond = fmt.Sprintf(
+	" ORDER BY ti.%s LIMIT ?",
+	Column.Hash,
+)
+
+// ListForExport lists the torrents ordered by hash, starting after the
+// given hash. With non-zero since, only the torrents changed since then,
+// including their files and mappings, are listed.
+func ListForExport(since time.Time, afterHash string, limit int) ([]TorrentItem, error) {
+	query := query_list_for_export_select
+	args := []any{afterHash}
+	if !since.IsZero() {
+		query += query_list_for_export_cond_since
+		sinceAt := db.Timestamp{Time: since}
+		args = append(args, sinceAt, sinceAt, sinceAt, sinceAt)
+	}
+	query += query_list_for_export_after_cond
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []TorrentItem{}
+	for rows.Next() {
+		item := TorrentItem{}
+		if err := rows.Scan(&item.Hash, &item.TorrentTitle, &item.Size, &item.Source, &item.Category, &item.Seeders, &item.Leechers); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
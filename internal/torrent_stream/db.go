@@ -611,3 +611,44 @@ func GetStats() (*Stats, error) {
 	}
 	return &stats, nil
 }
+
+var query_get_updated_at_by_hashes = fmt.Sprintf(
+	`SELECT %s, MAX(%s) FROM %s WHERE %s IN `,
+	Column.Hash,
+	Column.UAt,
+	TableName,
+	Column.Hash,
+)
+
+func GetUpdatedAtByHashes(hashes []string) (map[string]time.Time, error) {
+	byHash := map[string]time.Time{}
+	if len(hashes) == 0 {
+		return byHash, nil
+	}
+
+	args := make([]any, len(hashes))
+	for i, hash := range hashes {
+		args[i] = hash
+	}
+
+	query := query_get_updated_at_by_hashes + "(" + util.RepeatJoin("?", len(hashes), ",") + ") GROUP BY " + Column.Hash
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		hash := ""
+		var updatedAt db.Timestamp
+		if err := rows.Scan(&hash, &updatedAt); err != nil {
+			return nil, err
+		}
+		byHash[hash] = updatedAt.Time
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return byHash, nil
+}
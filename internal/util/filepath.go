@@ -1,6 +1,9 @@
 package util
 
-import "strings"
+import (
+	"path/filepath"
+	"strings"
+)
 
 func RemoveRootFolderFromPath(path string) (newPath string, rootFolder string) {
 	var ok bool
@@ -10,3 +13,9 @@ func RemoveRootFolderFromPath(path string) (newPath string, rootFolder string) {
 	}
 	return newPath, ""
 }
+
+// IsPathWithin checks if path is root itself or is inside root.
+func IsPathWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
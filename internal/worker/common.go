@@ -1,30 +1,12 @@
 package worker
 
 import (
-	"sync"
+	"slices"
 	"time"
 
 	"github.com/MunifTanjim/stremthru/internal/kv"
 )
 
-type IdQueue struct {
-	m            sync.Map
-	debounceTime time.Duration
-	transform    func(id string) string
-	disabled     bool
-}
-
-func (q *IdQueue) Queue(sid string) {
-	if q.disabled {
-		return
-	}
-	q.m.Swap(q.transform(sid), time.Now().Add(q.debounceTime))
-}
-
-func (q *IdQueue) delete(sid string) {
-	q.m.Delete(q.transform(sid))
-}
-
 type Error struct {
 	string
 	cause error
@@ -64,6 +46,18 @@ func (t JobTracker[T]) GetLast() (*kv.ParsedKV[Job[T]], error) {
 	return kv, nil
 }
 
+// List returns the tracked jobs, most recent first.
+func (t JobTracker[T]) List() ([]kv.ParsedKV[Job[T]], error) {
+	jobs, err := t.kv.List()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(jobs, func(a, b kv.ParsedKV[Job[T]]) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return jobs, nil
+}
+
 func (t JobTracker[T]) Set(id string, status string, err string, data *T) error {
 	if id == "" {
 		panic("job id cannot be empty")
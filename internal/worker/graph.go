@@ -0,0 +1,204 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// resourceTag is declared as `<name>:read` or `<name>:write`. A worker
+// holding `<name>:write` excludes every other worker holding `<name>:*`,
+// readers only exclude writers.
+type resourceTag struct {
+	name  string
+	write bool
+}
+
+func (r resourceTag) String() string {
+	if r.write {
+		return r.name + ":write"
+	}
+	return r.name + ":read"
+}
+
+func (r resourceTag) conflictsWith(other resourceTag) bool {
+	return r.name == other.name && (r.write || other.write)
+}
+
+func parseResourceTag(tag string) (resourceTag, error) {
+	name, mode, _ := strings.Cut(tag, ":")
+	if name == "" {
+		return resourceTag{}, fmt.Errorf("invalid resource tag: %q", tag)
+	}
+	switch mode {
+	case "read":
+		return resourceTag{name: name}, nil
+	case "write":
+		return resourceTag{name: name, write: true}, nil
+	default:
+		return resourceTag{}, fmt.Errorf("invalid resource tag: %q, expected <name>:read or <name>:write", tag)
+	}
+}
+
+func parseResourceTags(tags []string) ([]resourceTag, error) {
+	resources := make([]resourceTag, 0, len(tags))
+	for _, tag := range tags {
+		resource, err := parseResourceTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+var graph = struct {
+	sync.Mutex
+	// closed and replaced on every release, to wake up the waiting workers
+	released chan struct{}
+}{
+	released: make(chan struct{}),
+}
+
+func (w *Worker) isActive() bool {
+	w.state.mu.Lock()
+	defer w.state.mu.Unlock()
+
+	return w.state.active
+}
+
+func (w *Worker) conflictsWith(other *Worker) bool {
+	return slices.Contains(w.conflictsWithNames, other.name) || slices.Contains(other.conflictsWithNames, w.name)
+}
+
+// waitReason returns why the worker can not start now, checking the running
+// workers for conflicts and shared resources, and the active, i.e. waiting
+// or running, workers it depends on.
+func (w *Worker) waitReason(workers []*Worker) string {
+	for _, other := range workers {
+		if other == w || other.disabled {
+			continue
+		}
+		if slices.Contains(w.dependsOn, other.name) && other.isActive() {
+			return "depends on " + other.name
+		}
+		if !other.acquired {
+			continue
+		}
+		if w.conflictsWith(other) {
+			return "conflicts with " + other.name
+		}
+		for _, resource := range w.resources {
+			for _, otherResource := range other.resources {
+				if resource.conflictsWith(otherResource) {
+					return other.name + " holds " + otherResource.String()
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// acquire marks the worker as running, unless it has to wait.
+func (w *Worker) acquire() (bool, string) {
+	registry.RLock()
+	workers := slices.Clone(registry.workers)
+	registry.RUnlock()
+
+	graph.Lock()
+	defer graph.Unlock()
+
+	if reason := w.waitReason(workers); reason != "" {
+		return false, reason
+	}
+	w.acquired = true
+	return true, ""
+}
+
+func (w *Worker) release() {
+	graph.Lock()
+	defer graph.Unlock()
+
+	if !w.acquired {
+		return
+	}
+	w.acquired = false
+	close(graph.released)
+	graph.released = make(chan struct{})
+}
+
+func onRelease() <-chan struct{} {
+	graph.Lock()
+	defer graph.Unlock()
+
+	return graph.released
+}
+
+// checkWorkerGraph validates the declared dependencies, including the
+// disabled workers, and detects the cycles in DependsOn.
+func checkWorkerGraph(workers []*Worker) error {
+	byName := make(map[string]*Worker, len(workers))
+	for _, w := range workers {
+		if _, ok := byName[w.name]; ok {
+			return fmt.Errorf("duplicate worker: %s", w.name)
+		}
+		byName[w.name] = w
+	}
+
+	var errs []error
+	for _, w := range workers {
+		for _, name := range w.dependsOn {
+			if name == w.name {
+				errs = append(errs, fmt.Errorf("worker %s depends on itself", w.name))
+			} else if _, ok := byName[name]; !ok {
+				errs = append(errs, fmt.Errorf("worker %s depends on unknown worker: %s", w.name, name))
+			}
+		}
+		for _, name := range w.conflictsWithNames {
+			if name == w.name {
+				errs = append(errs, fmt.Errorf("worker %s conflicts with itself", w.name))
+			} else if _, ok := byName[name]; !ok {
+				errs = append(errs, fmt.Errorf("worker %s conflicts with unknown worker: %s", w.name, name))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(workers))
+	path := []string{}
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := append(path[slices.Index(path, name):], name)
+			return fmt.Errorf("worker dependency cycle: %s", strings.Join(cycle, " -> "))
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range byName[name].dependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+	for _, w := range workers {
+		if err := visit(w.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseResourceTag(t *testing.T) {
+	for _, tc := range []struct {
+		tag      string
+		expected resourceTag
+		err      bool
+	}{
+		{"torrent_info:read", resourceTag{name: "torrent_info"}, false},
+		{"torrent_info:write", resourceTag{name: "torrent_info", write: true}, false},
+		{"torrent_info", resourceTag{}, true},
+		{"torrent_info:delete", resourceTag{}, true},
+		{":write", resourceTag{}, true},
+	} {
+		t.Run(tc.tag, func(t *testing.T) {
+			resource, err := parseResourceTag(tc.tag)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, resource)
+			assert.Equal(t, tc.tag, resource.String())
+		})
+	}
+}
+
+func TestCheckWorkerGraph(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		workers []*Worker
+		err     string
+	}{
+		{
+			name: "valid",
+			workers: []*Worker{
+				{name: "a"},
+				{name: "b", dependsOn: []string{"a"}},
+				{name: "c", dependsOn: []string{"a", "b"}, conflictsWithNames: []string{"d"}},
+				{name: "d", disabled: true},
+			},
+		},
+		{
+			name: "unknown worker",
+			workers: []*Worker{
+				{name: "a", dependsOn: []string{"b"}},
+				{name: "c", conflictsWithNames: []string{"d"}},
+			},
+			err: "worker a depends on unknown worker: b\nworker c conflicts with unknown worker: d",
+		},
+		{
+			name: "self",
+			workers: []*Worker{
+				{name: "a", dependsOn: []string{"a"}},
+			},
+			err: "worker a depends on itself",
+		},
+		{
+			name: "duplicate",
+			workers: []*Worker{
+				{name: "a"},
+				{name: "a", disabled: true},
+			},
+			err: "duplicate worker: a",
+		},
+		{
+			name: "cycle",
+			workers: []*Worker{
+				{name: "a"},
+				{name: "b", dependsOn: []string{"a", "d"}},
+				{name: "c", dependsOn: []string{"b"}},
+				{name: "d", dependsOn: []string{"c"}},
+			},
+			err: "worker dependency cycle: b -> d -> c -> b",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkWorkerGraph(tc.workers)
+			if tc.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestWorkerWaitReason(t *testing.T) {
+	mustParse := func(tags ...string) []resourceTag {
+		resources, err := parseResourceTags(tags)
+		if err != nil {
+			panic(err)
+		}
+		return resources
+	}
+
+	imdb := &Worker{name: "sync-imdb"}
+	dmm := &Worker{name: "sync-dmm-hashlist", dependsOn: []string{"sync-imdb"}, resources: mustParse("torrent_info:write")}
+	mapper := &Worker{name: "map-imdb-torrent", resources: mustParse("torrent_info:read")}
+	parser := &Worker{name: "parse-torrent", resources: mustParse("torrent_info:read")}
+	housekeeping := &Worker{name: "store-housekeeping", conflictsWithNames: []string{"store-migration"}}
+	migration := &Worker{name: "store-migration"}
+	workers := []*Worker{imdb, dmm, mapper, parser, housekeeping, migration}
+
+	reset := func() {
+		for _, w := range workers {
+			w.acquired = false
+			w.state.active = false
+		}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		setup    func()
+		worker   *Worker
+		expected string
+	}{
+		{
+			name:     "idle",
+			setup:    func() {},
+			worker:   dmm,
+			expected: "",
+		},
+		{
+			name: "dependency waiting",
+			setup: func() {
+				imdb.state.active = true
+			},
+			worker:   dmm,
+			expected: "depends on sync-imdb",
+		},
+		{
+			name: "reader holds resource",
+			setup: func() {
+				mapper.acquired = true
+			},
+			worker:   dmm,
+			expected: "map-imdb-torrent holds torrent_info:read",
+		},
+		{
+			name: "writer holds resource",
+			setup: func() {
+				dmm.acquired = true
+			},
+			worker:   mapper,
+			expected: "sync-dmm-hashlist holds torrent_info:write",
+		},
+		{
+			name: "readers share resource",
+			setup: func() {
+				parser.acquired = true
+			},
+			worker:   mapper,
+			expected: "",
+		},
+		{
+			name: "conflict",
+			setup: func() {
+				housekeeping.acquired = true
+			},
+			worker:   migration,
+			expected: "conflicts with store-housekeeping",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reset()
+			tc.setup()
+			assert.Equal(t, tc.expected, tc.worker.waitReason(workers))
+		})
+	}
+}
@@ -42,7 +42,7 @@ func InitSyncLetterboxdList(conf *WorkerConfig) *Worker {
 				}
 
 				log.Debug("fetching list by id from upstream", "id", l.Id)
-				res, err := Peer.FetchLetterboxdList(&peer.FetchLetterboxdListParams{
+				res, err := peer.Upstreams.FetchLetterboxdList(&peer.FetchLetterboxdListParams{
 					ListId: l.Id,
 				})
 				if err != nil {
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/MunifTanjim/stremthru/core"
-	"github.com/MunifTanjim/stremthru/internal/buddy"
 	"github.com/MunifTanjim/stremthru/internal/magnet_cache"
 	"github.com/MunifTanjim/stremthru/internal/peer"
 	"github.com/MunifTanjim/stremthru/internal/shared"
@@ -45,7 +44,7 @@ func InitMagnetCachePullerWorker(conf *WorkerConfig) *Worker {
 			}
 
 			for i, cHashes := range slices.Collect(slices.Chunk(hashes, 500)) {
-				if buddy.Peer.IsHaltedCheckMagnet() {
+				if peer.Upstreams.IsHaltedCheckMagnet() {
 					time.Sleep(15 * time.Second)
 				}
 
@@ -63,16 +62,13 @@ func InitMagnetCachePullerWorker(conf *WorkerConfig) *Worker {
 				params.ClientIP = clientIp
 				params.SId = sid
 				start := time.Now()
-				res, err := buddy.Peer.CheckMagnet(params)
+				res, err := peer.Upstreams.CheckMagnet(params)
 				duration := time.Since(start)
-				if duration.Seconds() > 10 {
-					Peer.HaltCheckMagnet()
-				}
 				if err != nil {
 					w.Log.Error("failed partially to check magnet", "store", s.GetName(), "error", core.PackError(err), "duration", duration)
 				} else {
 					w.Log.Info("check magnet", "store", s.GetName(), "hash_count", len(cHashes), "duration", duration)
-					for _, item := range res.Data.Items {
+					for _, item := range res.Items {
 						files := torrent_stream.Files{}
 						if item.Status == store.MagnetStatusCached {
 							cached[item.Hash] = true
@@ -642,6 +642,10 @@ func InitMapAniDBTorrentWorker(conf *WorkerConfig) *Worker {
 
 		totalCount := 0
 		for {
+			if err := w.Err(); err != nil {
+				return err
+			}
+
 			hashes, err := torrent_info.GetAniDBUnmappedHashes(batch_size)
 			if err != nil {
 				return err
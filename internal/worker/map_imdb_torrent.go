@@ -27,6 +27,10 @@ func InitMapIMDBTorrentWorker(conf *WorkerConfig) *Worker {
 
 		totalCount := 0
 		for {
+			if err := w.Err(); err != nil {
+				return err
+			}
+
 			hashes, err := torrent_info.GetIMDBUnmappedHashes(batch_size)
 			if err != nil {
 				return err
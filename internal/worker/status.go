@@ -0,0 +1,410 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/kv"
+)
+
+var (
+	ErrWorkerNotFound       = errors.New("worker not found")
+	ErrWorkerDisabled       = errors.New("worker is disabled")
+	ErrWorkerPaused         = errors.New("worker is paused")
+	ErrWorkerRunning        = errors.New("worker is already running")
+	ErrWorkerLocked         = errors.New("another instance is running")
+	ErrWorkerNotRunning     = errors.New("worker is not running")
+	ErrWorkerNotCancellable = errors.New("worker can not be cancelled while running")
+	ErrWorkerCancelled      = errors.New("cancelled")
+)
+
+type workerState struct {
+	mu           sync.Mutex
+	active       bool
+	triggered    bool
+	pendingRun   bool
+	ctx          context.Context
+	cancel       context.CancelFunc
+	jobId        string
+	runningSince time.Time
+	waitReason   string
+	waitingSince time.Time
+}
+
+func (s *workerState) begin() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active {
+		return false
+	}
+	s.active = true
+	s.triggered = s.pendingRun
+	s.pendingRun = false
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	return true
+}
+
+func (s *workerState) end() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cancel()
+	s.active = false
+	s.triggered = false
+	s.jobId = ""
+	s.runningSince = time.Time{}
+	s.waitReason = ""
+	s.waitingSince = time.Time{}
+}
+
+func (s *workerState) setWaiting(reason string, wait bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !wait {
+		s.waitReason = ""
+		s.waitingSince = time.Time{}
+		return
+	}
+	if s.waitingSince.IsZero() {
+		s.waitingSince = time.Now()
+	}
+	s.waitReason = reason
+}
+
+func (s *workerState) setRunning(jobId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobId = jobId
+	s.runningSince = time.Now()
+}
+
+func (s *workerState) isTriggered() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.triggered
+}
+
+func (s *workerState) done() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ctx.Done()
+}
+
+// Context is cancelled when the running job is cancelled.
+func (w *Worker) Context() context.Context {
+	w.state.mu.Lock()
+	defer w.state.mu.Unlock()
+
+	if w.state.ctx == nil {
+		return context.Background()
+	}
+	return w.state.ctx
+}
+
+// Err returns ErrWorkerCancelled if the running job is cancelled. Executors
+// should check it between units of work and return it as is.
+func (w *Worker) Err() error {
+	if w.Context().Err() != nil {
+		return ErrWorkerCancelled
+	}
+	return nil
+}
+
+var workerPausedStore = kv.NewKVStore[bool](&kv.KVStoreConfig{
+	Type: "worker:paused",
+})
+
+func (w *Worker) isPaused() bool {
+	paused := false
+	if err := workerPausedStore.GetValue(w.name, &paused); err != nil {
+		w.Log.Error("failed to get paused state", "error", err)
+	}
+	return paused
+}
+
+// nextRunAt returns the time of the next scheduled run, the task timer is
+// reset on every tick.
+func (w *Worker) nextRunAt(now time.Time) time.Time {
+	if w.runAtStartupAfter > 0 {
+		if startupRunAt := w.scheduledAt.Add(w.runAtStartupAfter); startupRunAt.After(now) && startupRunAt.Before(w.scheduledAt.Add(w.interval)) {
+			return startupRunAt
+		}
+	}
+	elapsed := now.Sub(w.scheduledAt)
+	ticks := elapsed/w.interval + 1
+	return w.scheduledAt.Add(ticks * w.interval)
+}
+
+var registry = struct {
+	sync.RWMutex
+	workers []*Worker
+}{}
+
+func registerWorker(w *Worker) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.workers = append(registry.workers, w)
+}
+
+func getWorker(name string) (*Worker, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for _, w := range registry.workers {
+		if w.name == name {
+			if w.disabled {
+				return nil, ErrWorkerDisabled
+			}
+			return w, nil
+		}
+	}
+	return nil, ErrWorkerNotFound
+}
+
+type WorkerJob struct {
+	Id        string        `json:"id"`
+	Status    string        `json:"status"`
+	Err       string        `json:"error,omitempty"`
+	StartedAt time.Time     `json:"started_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Duration  time.Duration `json:"duration"`
+}
+
+type WorkerStatus struct {
+	Name          string      `json:"name"`
+	Disabled      bool        `json:"disabled"`
+	Paused        bool        `json:"paused"`
+	Interval      string      `json:"interval"`
+	DependsOn     []string    `json:"depends_on"`
+	ConflictsWith []string    `json:"conflicts_with"`
+	Resources     []string    `json:"resources"`
+	Cancellable   bool        `json:"cancellable"`
+	Running       bool        `json:"running"`
+	RunningSince  *time.Time  `json:"running_since,omitempty"`
+	Waiting       string      `json:"waiting,omitempty"` // reason
+	WaitingSince  *time.Time  `json:"waiting_since,omitempty"`
+	LastRunAt     *time.Time  `json:"last_run_at,omitempty"`
+	LastStatus    string      `json:"last_status,omitempty"`
+	LastError     string      `json:"last_error,omitempty"`
+	NextRunAt     *time.Time  `json:"next_run_at,omitempty"`
+	History       []WorkerJob `json:"history"`
+}
+
+const workerHistoryLimit = 20
+
+func (w *Worker) getStatus() (*WorkerStatus, error) {
+	status := &WorkerStatus{
+		Name:          w.name,
+		Disabled:      w.disabled,
+		Interval:      w.interval.String(),
+		DependsOn:     []string{},
+		ConflictsWith: []string{},
+		Resources:     []string{},
+		Cancellable:   w.cancellable,
+		History:       []WorkerJob{},
+	}
+	status.DependsOn = append(status.DependsOn, w.dependsOn...)
+	status.ConflictsWith = append(status.ConflictsWith, w.conflictsWithNames...)
+	for _, resource := range w.resources {
+		status.Resources = append(status.Resources, resource.String())
+	}
+	if w.disabled {
+		return status, nil
+	}
+
+	status.Paused = w.isPaused()
+
+	w.state.mu.Lock()
+	if !w.state.runningSince.IsZero() {
+		status.Running = true
+		runningSince := w.state.runningSince
+		status.RunningSince = &runningSince
+	}
+	if w.state.waitReason != "" {
+		status.Waiting = w.state.waitReason
+		waitingSince := w.state.waitingSince
+		status.WaitingSince = &waitingSince
+	}
+	w.state.mu.Unlock()
+
+	if !status.Paused {
+		nextRunAt := w.nextRunAt(time.Now())
+		status.NextRunAt = &nextRunAt
+	}
+
+	jobs, err := w.jobTracker.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range jobs[:min(len(jobs), workerHistoryLimit)] {
+		job := &jobs[i]
+		wj := WorkerJob{
+			Id:        job.Key,
+			Status:    job.Value.Status,
+			Err:       job.Value.Err,
+			StartedAt: job.CreatedAt,
+			UpdatedAt: job.UpdatedAt,
+		}
+		if wj.Status != "started" {
+			wj.Duration = job.UpdatedAt.Sub(job.CreatedAt)
+		}
+		status.History = append(status.History, wj)
+	}
+	if len(status.History) > 0 {
+		last := status.History[0]
+		status.LastRunAt = &last.StartedAt
+		status.LastStatus = last.Status
+		status.LastError = last.Err
+	}
+
+	return status, nil
+}
+
+// ListWorkers returns the status of every worker, in the order they were
+// initialized. The running and waiting state is local to this instance.
+func ListWorkers() ([]WorkerStatus, error) {
+	registry.RLock()
+	workers := slices.Clone(registry.workers)
+	registry.RUnlock()
+
+	statuses := make([]WorkerStatus, 0, len(workers))
+	for _, w := range workers {
+		status, err := w.getStatus()
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+	return statuses, nil
+}
+
+func GetWorkerStatus(name string) (*WorkerStatus, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for _, w := range registry.workers {
+		if w.name == name {
+			return w.getStatus()
+		}
+	}
+	return nil, ErrWorkerNotFound
+}
+
+// RunWorker triggers a run now, skipping the `already done` check for
+// exclusive workers.
+func RunWorker(name string) error {
+	w, err := getWorker(name)
+	if err != nil {
+		return err
+	}
+	if w.isPaused() {
+		return ErrWorkerPaused
+	}
+
+	w.state.mu.Lock()
+	defer w.state.mu.Unlock()
+
+	if w.state.active || w.state.pendingRun {
+		return ErrWorkerRunning
+	}
+	if !w.runOnce(1 * time.Millisecond) {
+		return errors.New("failed to schedule run")
+	}
+	w.state.pendingRun = true
+	w.Log.Info("triggered run")
+	return nil
+}
+
+// ExecWorker runs the worker once in the foreground, without scheduling any
+// worker, skipping the `already done` check for exclusive workers. It is
+// meant for a process that does not call InitWorkers. It fails with
+// ErrWorkerLocked if the worker is running on another instance.
+func ExecWorker(name string) error {
+	stop := initWorkers(true)
+	defer stop()
+
+	w, err := getWorker(name)
+	if err != nil {
+		return err
+	}
+	if w.isPaused() {
+		return ErrWorkerPaused
+	}
+
+	w.state.mu.Lock()
+	w.state.pendingRun = true
+	w.state.mu.Unlock()
+
+	return w.exec()
+}
+
+// WorkerNames returns the names of the workers, once they are initialized.
+func WorkerNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	names := make([]string, len(registry.workers))
+	for i, w := range registry.workers {
+		names[i] = w.name
+	}
+	return names
+}
+
+func setWorkerPaused(name string, paused bool) error {
+	w, err := getWorker(name)
+	if err != nil {
+		return err
+	}
+	if paused {
+		err = workerPausedStore.Set(w.name, true)
+	} else {
+		err = workerPausedStore.Del(w.name)
+	}
+	if err != nil {
+		return err
+	}
+	w.Log.Info("set paused", "paused", paused)
+	return nil
+}
+
+// PauseWorker skips the upcoming runs, across all instances, until resumed.
+// It does not affect the running job.
+func PauseWorker(name string) error {
+	return setWorkerPaused(name, true)
+}
+
+func ResumeWorker(name string) error {
+	return setWorkerPaused(name, false)
+}
+
+// CancelWorker cancels the running, or waiting, job on this instance. The
+// executor stops at its next check of Worker.Err, so a running job of a worker
+// that is not cancellable can not be cancelled.
+func CancelWorker(name string) error {
+	w, err := getWorker(name)
+	if err != nil {
+		return err
+	}
+
+	w.state.mu.Lock()
+	defer w.state.mu.Unlock()
+
+	if !w.state.active {
+		return ErrWorkerNotRunning
+	}
+	if !w.cancellable && !w.state.runningSince.IsZero() {
+		return ErrWorkerNotCancellable
+	}
+	w.state.cancel()
+	w.Log.Info("cancelled", "jobId", w.state.jobId)
+	return nil
+}
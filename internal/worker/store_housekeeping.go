@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/config"
+	store_housekeeping "github.com/MunifTanjim/stremthru/internal/store/housekeeping"
+)
+
+func InitStoreHousekeepingWorker(conf *WorkerConfig) *Worker {
+	conf.Executor = func(w *Worker) error {
+		log := w.Log
+
+		// users sharing a token run the same rule only once
+		type runKey struct {
+			store string
+			token string
+			rule  config.StoreHousekeepingRule
+		}
+		seen := map[runKey]struct{}{}
+		for _, entry := range config.StoreHousekeeping.List() {
+			if err := w.Err(); err != nil {
+				return err
+			}
+
+			key := runKey{
+				store: entry.Store,
+				token: config.StoreAuthToken.GetToken(entry.User, entry.Store),
+				rule:  entry.Rule,
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			log.Debug("running housekeeping", "user", entry.User, "store", entry.Store, "dry_run", entry.Rule.DryRun)
+			result, err := store_housekeeping.Run(entry.User, entry.Store, entry.Rule)
+			if err != nil {
+				log.Warn("failed to run housekeeping", "error", err, "user", entry.User, "store", entry.Store)
+				continue
+			}
+			log.Info("housekeeping done", "user", entry.User, "store", entry.Store, "checked", result.Checked, "matched", result.Matched, "removed", result.Removed, "failed", result.Failed, "dry_run", entry.Rule.DryRun)
+
+			time.Sleep(5 * time.Second)
+		}
+
+		return nil
+	}
+
+	worker := NewWorker(conf)
+
+	return worker
+}
@@ -0,0 +1,45 @@
+package worker
+
+import (
+	store_migration "github.com/MunifTanjim/stremthru/internal/store/migration"
+)
+
+func InitStoreMigrationWorker(conf *WorkerConfig) *Worker {
+	conf.Executor = func(w *Worker) error {
+		log := w.Log
+
+		jobs, err := store_migration.GetPendingJobs()
+		if err != nil {
+			return err
+		}
+
+		for i := range jobs {
+			if err := w.Err(); err != nil {
+				return err
+			}
+
+			job := &jobs[i]
+			claimed, err := store_migration.ClaimJob(job)
+			if err != nil {
+				log.Warn("failed to claim migration", "error", err, "job_id", job.Id)
+				continue
+			}
+			if !claimed {
+				continue
+			}
+
+			log.Info("running migration", "job_id", job.Id, "user", job.User, "source_store", job.SrcStore, "destination_store", job.DstStore)
+			if err := store_migration.Run(w.Context(), job); err != nil {
+				log.Warn("failed to run migration", "error", err, "job_id", job.Id)
+				continue
+			}
+			log.Info("migration done", "job_id", job.Id, "total", job.Total, "added", job.Added, "skipped", job.Skipped, "failed", job.Failed)
+		}
+
+		return nil
+	}
+
+	worker := NewWorker(conf)
+
+	return worker
+}
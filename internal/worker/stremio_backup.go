@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"time"
+
+	stremio_backup "github.com/MunifTanjim/stremthru/internal/stremio/backup"
+)
+
+func InitStremioBackupWorker(conf *WorkerConfig) *Worker {
+	conf.Executor = func(w *Worker) error {
+		log := w.Log
+
+		schedules, err := stremio_backup.GetEnabledSchedules()
+		if err != nil {
+			return err
+		}
+
+		for i := range schedules {
+			if err := w.Err(); err != nil {
+				return err
+			}
+
+			s := &schedules[i]
+			if !s.IsDue() {
+				continue
+			}
+
+			log.Debug("backing up", "account_id", s.AccountId)
+			if err := stremio_backup.Run(s); err != nil {
+				log.Warn("failed to backup", "error", err, "account_id", s.AccountId)
+			}
+
+			time.Sleep(1 * time.Second)
+		}
+
+		return nil
+	}
+
+	worker := NewWorker(conf)
+
+	return worker
+}
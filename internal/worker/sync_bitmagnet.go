@@ -66,6 +66,10 @@ func InitSyncBitmagnetWorker(conf *WorkerConfig) *Worker {
 		offset := 0
 		hasMore := true
 		for hasMore {
+			if err := w.Err(); err != nil {
+				return err
+			}
+
 			items, err := bitmagnet.GetTorrents(database, limit, offset, cursor_updated_at)
 			if err != nil {
 				return err
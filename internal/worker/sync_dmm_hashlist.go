@@ -1,8 +1,6 @@
 package worker
 
 import (
-	"encoding/json"
-	"errors"
 	"io"
 	"io/fs"
 	"net/url"
@@ -17,22 +15,11 @@ import (
 	"github.com/MunifTanjim/stremthru/internal/cache"
 	"github.com/MunifTanjim/stremthru/internal/config"
 	"github.com/MunifTanjim/stremthru/internal/dmm_hashlist"
-	"github.com/MunifTanjim/stremthru/internal/lzstring"
+	"github.com/MunifTanjim/stremthru/internal/torrent_dump"
 	"github.com/MunifTanjim/stremthru/internal/torrent_info"
 	"github.com/MunifTanjim/stremthru/internal/util"
 )
 
-type DMMHashlistItem struct {
-	Filename string `json:"filename"`
-	Hash     string `json:"hash"`
-	Bytes    int64  `json:"bytes"`
-}
-
-type wrappedDMMHashlistItems struct {
-	Title    string            `json:"title"`
-	Torrents []DMMHashlistItem `json:"torrents"`
-}
-
 func InitSyncDMMHashlistWorker(conf *WorkerConfig) *Worker {
 	REPO_URL := util.MustParseURL("https://github.com/debridmediamanager/hashlists.git")
 	if config.Integration.GitHub.User != "" && config.Integration.GitHub.Token != "" {
@@ -95,11 +82,7 @@ func InitSyncDMMHashlistWorker(conf *WorkerConfig) *Worker {
 		return nil
 	}
 
-	// <iframe .. src="https://..."
-	urlRegex := regexp.MustCompile(`<iframe *src="(.+)".*>`)
-	// <meta .. content="0;url=https://..."
-	fallbackUrlRegex := regexp.MustCompile(`content="(.+)".*`)
-	extractHashlistItems := func(filename string) ([]DMMHashlistItem, error) {
+	extractHashlistItems := func(filename string) ([]torrent_dump.DMMHashlistItem, error) {
 		file, err := os.Open(path.Join(REPO_DIR, filename))
 		if err != nil {
 			return nil, Error{"failed to get working directory", err}
@@ -109,48 +92,7 @@ func InitSyncDMMHashlistWorker(conf *WorkerConfig) *Worker {
 		if err != nil {
 			return nil, Error{"failed to read file", err}
 		}
-		dataUrl := ""
-		matches := urlRegex.FindAllStringSubmatch(string(fileContent), -1)
-		if len(matches) > 0 {
-			dataUrl = matches[0][1]
-		}
-		if dataUrl == "" {
-			matches = fallbackUrlRegex.FindAllStringSubmatch(string(fileContent), -1)
-			if len(matches) > 0 {
-				dataUrl = matches[0][1]
-				dataUrl = strings.TrimPrefix(dataUrl, "0;url=")
-			}
-		}
-		if dataUrl == "" {
-			return nil, errors.New("failed to extract data url")
-		}
-		u, err := url.Parse(dataUrl)
-		if err != nil {
-			return nil, Error{"failed to parse data url", err}
-		}
-		encodedData := u.Fragment
-		if encodedData == "" {
-			return nil, nil
-		}
-		blob, err := lzstring.DecompressFromEncodedUriComponent(encodedData)
-		if err != nil {
-			return nil, Error{"failed to decompress data", err}
-		}
-		items := []DMMHashlistItem{}
-		if strings.HasPrefix(blob, "{") {
-			wrappedItems := wrappedDMMHashlistItems{}
-			err := json.Unmarshal([]byte(blob), &wrappedItems)
-			if err != nil {
-				return nil, Error{"failed to unmarshal wrapped hashlist items", err}
-			}
-			items = wrappedItems.Torrents
-		} else {
-			err := json.Unmarshal([]byte(blob), &items)
-			if err != nil {
-				return nil, Error{"failed to unmarshal hashlist items", err}
-			}
-		}
-		return items, nil
+		return torrent_dump.ParseDMMHashlist(fileContent)
 	}
 
 	processHashlistFile := func(w *Worker, filename string, hashSeen *cache.LRUCache[struct{}], totalCount int) (int, error) {
@@ -171,7 +113,7 @@ func InitSyncDMMHashlistWorker(conf *WorkerConfig) *Worker {
 		}
 
 		hashes := []string{}
-		itemByHash := map[string]DMMHashlistItem{}
+		itemByHash := map[string]torrent_dump.DMMHashlistItem{}
 		for _, item := range items {
 			magnet, err := core.ParseMagnetLink(item.Hash)
 			if err != nil || len(magnet.Hash) != 40 {
@@ -241,6 +183,9 @@ func InitSyncDMMHashlistWorker(conf *WorkerConfig) *Worker {
 
 		totalCount := 0
 		for _, filename := range files {
+			if err := w.Err(); err != nil {
+				return err
+			}
 			if !hashlistFilenameRegex.MatchString(filename) {
 				continue
 			}
@@ -30,6 +30,10 @@ func InitParseTorrentWorker(conf *WorkerConfig) *Worker {
 	conf.Executor = func(w *Worker) error {
 		log := w.Log
 		for {
+			if err := w.Err(); err != nil {
+				return err
+			}
+
 			tInfos, err := ti.GetUnparsed(5000)
 			if err != nil {
 				return err
@@ -1,8 +1,8 @@
 package worker
 
 import (
+	"errors"
 	"log/slog"
-	"sync"
 	"time"
 
 	"github.com/MunifTanjim/stremthru/internal/config"
@@ -14,41 +14,47 @@ import (
 	"github.com/madflojo/tasks"
 )
 
-var mutex sync.Mutex
-var running_worker struct {
-	sync_anidb_titles           bool
-	sync_animetosho             bool
-	sync_bitmagnet              bool
-	sync_dmm_hashlist           bool
-	sync_imdb                   bool
-	map_anidb_torrent           bool
-	map_imdb_torrent            bool
-	sync_animeapi               bool
-	sync_anidb_tvdb_episode_map bool
-	sync_manami_anime_database  bool
-}
-
 type Worker struct {
 	scheduler  *tasks.Scheduler
-	shouldWait func() (bool, string)
-	onStart    func()
-	onEnd      func()
 	Log        *slog.Logger
 	jobTracker *JobTracker[struct{}]
+
+	name               string
+	interval           time.Duration
+	runAtStartupAfter  time.Duration
+	disabled           bool
+	dependsOn          []string
+	conflictsWithNames []string
+	resources          []resourceTag
+	cancellable        bool
+	acquired           bool // guarded by graph
+	taskId             string
+	exec               func() error
+	scheduledAt        time.Time
+	state              workerState
 }
 
 type WorkerConfig struct {
-	Disabled          bool
-	Executor          func(w *Worker) error
-	Interval          time.Duration
+	// waits while any of these workers is waiting or running
+	DependsOn []string
+	// never runs at the same time as these workers, and vice versa
+	ConflictsWith []string
+	// executor checks Worker.Err between units of work
+	Cancellable bool
+	Disabled    bool
+	Executor    func(w *Worker) error
+	Interval    time.Duration
+	// HeartbeatInterval is the interval of the job status update
 	HeartbeatInterval time.Duration
 	Log               *slog.Logger
 	Name              string
-	OnEnd             func()
-	OnStart           func()
+	// resource tags, e.g. `torrent_info:write`, held while running
+	Resources         []string
 	RunAtStartupAfter time.Duration
 	RunExclusive      bool
-	ShouldWait        func() (bool, string)
+
+	// not scheduled, only run with ExecWorker
+	manual bool
 }
 
 func NewWorker(conf *WorkerConfig) *Worker {
@@ -56,7 +62,20 @@ func NewWorker(conf *WorkerConfig) *Worker {
 		panic("worker name cannot be empty")
 	}
 
+	resources, err := parseResourceTags(conf.Resources)
+	if err != nil {
+		panic(err)
+	}
+
 	if conf.Disabled {
+		registerWorker(&Worker{
+			name:               conf.Name,
+			interval:           conf.Interval,
+			disabled:           true,
+			dependsOn:          conf.DependsOn,
+			conflictsWithNames: conf.ConflictsWith,
+			resources:          resources,
+		})
 		return nil
 	}
 
@@ -72,11 +91,16 @@ func NewWorker(conf *WorkerConfig) *Worker {
 	log := conf.Log
 
 	worker := &Worker{
-		scheduler:  tasks.New(),
-		shouldWait: conf.ShouldWait,
-		onStart:    conf.OnStart,
-		onEnd:      conf.OnEnd,
-		Log:        log,
+		scheduler: tasks.New(),
+		Log:       log,
+
+		name:               conf.Name,
+		interval:           conf.Interval,
+		runAtStartupAfter:  conf.RunAtStartupAfter,
+		dependsOn:          conf.DependsOn,
+		conflictsWithNames: conf.ConflictsWith,
+		resources:          resources,
+		cancellable:        conf.Cancellable,
 	}
 
 	jobTrackerExpiresIn := max(3*24*time.Hour, 10*conf.Interval)
@@ -84,619 +108,406 @@ func NewWorker(conf *WorkerConfig) *Worker {
 	worker.jobTracker = jobTracker
 
 	jobId := ""
-	id, err := worker.scheduler.Add(&tasks.Task{
-		Interval:          conf.Interval,
-		RunSingleInstance: true,
-		TaskFunc: func() (err error) {
-			isAlreadyRunning := jobId != ""
-			defer func() {
-				if perr, stack := util.HandlePanic(recover(), true); perr != nil {
-					err = perr
-					log.Error("Worker Panic", "error", err, "stack", stack)
-				} else if err == nil && !isAlreadyRunning {
-					jobId = ""
-				}
-				worker.onEnd()
-			}()
+	run := func() (err error) {
+		if !worker.state.begin() {
+			log.Debug("skipping, already running")
+			return nil
+		}
+		defer worker.state.end()
 
-			for {
-				wait, reason := worker.shouldWait()
-				if !wait {
-					break
-				}
-				log.Info("waiting, " + reason)
-				time.Sleep(1 * time.Minute)
-			}
-			worker.onStart()
+		if worker.isPaused() {
+			log.Info("skipping, paused")
+			return nil
+		}
 
-			if isAlreadyRunning {
-				return nil
+		isAlreadyRunning := jobId != ""
+		defer func() {
+			if perr, stack := util.HandlePanic(recover(), true); perr != nil {
+				err = perr
+				log.Error("Worker Panic", "error", err, "stack", stack)
+			} else if err == nil && !isAlreadyRunning {
+				jobId = ""
 			}
-
-			lock := db.NewAdvisoryLock("worker", conf.Name)
-			if lock == nil {
-				log.Error("failed to create advisory lock", "name", conf.Name)
+			worker.release()
+		}()
+
+		for {
+			released := onRelease()
+			acquired, reason := worker.acquire()
+			worker.state.setWaiting(reason, !acquired)
+			if acquired {
+				break
+			}
+			log.Info("waiting, " + reason)
+			select {
+			case <-released:
+			case <-time.After(1 * time.Minute):
+			case <-worker.state.done():
+				log.Info("cancelled, while waiting")
 				return nil
 			}
+		}
 
-			if !lock.TryAcquire() {
-				log.Debug("skipping, another instance is running", "name", lock.GetName())
-				return nil
-			}
-			defer lock.Release()
+		if isAlreadyRunning {
+			return nil
+		}
 
-			var tjob *kv.ParsedKV[Job[struct{}]]
-			if conf.RunExclusive {
-				tjob, err = jobTracker.GetLast()
-				if err != nil {
-					return err
-				}
-				if tjob != nil {
-					status := tjob.Value.Status
-					switch status {
-					case "started":
-						if !util.HasDurationPassedSince(tjob.UpdatedAt, conf.HeartbeatInterval+heartbeatIntervalTolerance) {
-							if util.HasDurationPassedSince(tjob.CreatedAt, conf.Interval) {
-								log.Warn("skipping, last job is still running, for too long", "jobId", tjob.Key, "status", status)
-							} else {
-								log.Info("skipping, last job is still running", "jobId", tjob.Key, "status", status)
-							}
-							return nil
-						}
+		lock := db.NewAdvisoryLock("worker", conf.Name)
+		if lock == nil {
+			log.Error("failed to create advisory lock", "name", conf.Name)
+			return nil
+		}
 
-						log.Warn("last job heartbeat timed out, restarting", "jobId", tjob.Key, "status", status)
-						if err := jobTracker.Set(tjob.Key, "failed", "heartbeat timed out", nil); err != nil {
-							log.Error("failed to set last job status", "error", err, "jobId", tjob.Key, "status", "failed")
+		if !lock.TryAcquire() {
+			log.Debug("skipping, another instance is running", "name", lock.GetName())
+			if conf.manual {
+				return ErrWorkerLocked
+			}
+			return nil
+		}
+		defer lock.Release()
+
+		var tjob *kv.ParsedKV[Job[struct{}]]
+		if conf.RunExclusive {
+			tjob, err = jobTracker.GetLast()
+			if err != nil {
+				return err
+			}
+			if tjob != nil {
+				status := tjob.Value.Status
+				switch status {
+				case "started":
+					if !util.HasDurationPassedSince(tjob.UpdatedAt, conf.HeartbeatInterval+heartbeatIntervalTolerance) {
+						if util.HasDurationPassedSince(tjob.CreatedAt, conf.Interval) {
+							log.Warn("skipping, last job is still running, for too long", "jobId", tjob.Key, "status", status)
+						} else {
+							log.Info("skipping, last job is still running", "jobId", tjob.Key, "status", status)
 						}
-					case "done":
-						if !util.HasDurationPassedSince(tjob.CreatedAt, conf.Interval) {
-							log.Info("already done", "jobId", tjob.Key, "status", status)
-							return nil
+						if conf.manual {
+							return ErrWorkerLocked
 						}
-					case "failed":
-						log.Warn("last job failed", "jobId", tjob.Key, "status", status, "error", tjob.Value.Err)
+						return nil
+					}
+
+					log.Warn("last job heartbeat timed out, restarting", "jobId", tjob.Key, "status", status)
+					if err := jobTracker.Set(tjob.Key, "failed", "heartbeat timed out", nil); err != nil {
+						log.Error("failed to set last job status", "error", err, "jobId", tjob.Key, "status", "failed")
+					}
+				case "done":
+					if !util.HasDurationPassedSince(tjob.CreatedAt, conf.Interval) && !worker.state.isTriggered() {
+						log.Info("already done", "jobId", tjob.Key, "status", status)
+						return nil
 					}
+				case "failed":
+					log.Warn("last job failed", "jobId", tjob.Key, "status", status, "error", tjob.Value.Err)
 				}
 			}
+		}
 
-			jobId = time.Now().Format(time.DateTime)
+		jobId = time.Now().Format(time.DateTime)
 
-			err = jobTracker.Set(jobId, "started", "", nil)
-			if err != nil {
-				log.Error("failed to set job status", "error", err, "jobId", jobId, "status", "started")
-				return err
-			}
+		err = jobTracker.Set(jobId, "started", "", nil)
+		if err != nil {
+			log.Error("failed to set job status", "error", err, "jobId", jobId, "status", "started")
+			return err
+		}
 
-			if !lock.Release() {
-				log.Error("failed to release advisory lock", "name", lock.GetName())
-				return nil
-			}
+		if !lock.Release() {
+			log.Error("failed to release advisory lock", "name", lock.GetName())
+			return nil
+		}
 
-			heartbeat := time.NewTicker(conf.HeartbeatInterval)
-			heartbeat_done := make(chan struct{})
-			defer close(heartbeat_done)
-			go func() {
-				for {
-					select {
-					case <-heartbeat.C:
-						if jobId == "" {
-							return
-						}
-						if err := jobTracker.Set(jobId, "started", "", nil); err != nil {
-							log.Error("failed to set job status heartbeat", "error", err, "jobId", jobId)
-						}
-					case <-heartbeat_done:
-						heartbeat.Stop()
+		worker.state.setRunning(jobId)
+
+		heartbeat := time.NewTicker(conf.HeartbeatInterval)
+		heartbeat_done := make(chan struct{})
+		defer close(heartbeat_done)
+		go func() {
+			for {
+				select {
+				case <-heartbeat.C:
+					if jobId == "" {
 						return
 					}
+					if err := jobTracker.Set(jobId, "started", "", nil); err != nil {
+						log.Error("failed to set job status heartbeat", "error", err, "jobId", jobId)
+					}
+				case <-heartbeat_done:
+					heartbeat.Stop()
+					return
 				}
-			}()
-
-			if err = conf.Executor(worker); err != nil {
-				return err
-			}
-
-			err = jobTracker.Set(jobId, "done", "", nil)
-			if err != nil {
-				log.Error("failed to set job status", "error", err, "jobId", jobId, "status", "done")
-				return err
 			}
+		}()
 
-			log.Info("done", "jobId", jobId)
+		err = conf.Executor(worker)
+		if cerr := worker.Err(); cerr != nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
 
+		err = jobTracker.Set(jobId, "done", "", nil)
+		if err != nil {
+			log.Error("failed to set job status", "error", err, "jobId", jobId, "status", "done")
 			return err
-		},
-		ErrFunc: func(err error) {
-			log.Error("Worker Failure", "error", err)
+		}
 
-			defer func() {
-				if perr, stack := util.HandlePanic(recover(), true); perr != nil {
-					log.Error("Worker Err Panic", "error", perr, "stack", stack)
-				}
-				jobId = ""
-			}()
+		log.Info("done", "jobId", jobId)
+
+		return err
+	}
+
+	onErr := func(err error) {
+		log.Error("Worker Failure", "error", err)
+
+		defer func() {
+			if perr, stack := util.HandlePanic(recover(), true); perr != nil {
+				log.Error("Worker Err Panic", "error", perr, "stack", stack)
+			}
+			jobId = ""
+		}()
 
-			if terr := jobTracker.Set(jobId, "failed", err.Error(), nil); terr != nil {
-				log.Error("failed to set job status", "error", terr, "jobId", jobId, "status", "failed")
+		if terr := jobTracker.Set(jobId, "failed", err.Error(), nil); terr != nil {
+			log.Error("failed to set job status", "error", terr, "jobId", jobId, "status", "failed")
+		}
+	}
+
+	if conf.manual {
+		worker.exec = func() error {
+			err := run()
+			if err != nil && !errors.Is(err, ErrWorkerLocked) {
+				onErr(err)
 			}
-		},
+			return err
+		}
+		registerWorker(worker)
+		return worker
+	}
+
+	worker.scheduledAt = time.Now()
+	id, err := worker.scheduler.Add(&tasks.Task{
+		Interval:          conf.Interval,
+		RunSingleInstance: true,
+		TaskFunc:          run,
+		ErrFunc:           onErr,
 	})
 
 	if err != nil {
 		panic(err)
 	}
 
+	worker.taskId = id
+
 	log.Info("Started Worker", "id", id)
 
 	if conf.RunAtStartupAfter != 0 {
-		if task, err := worker.scheduler.Lookup(id); err == nil && task != nil {
-			t := task.Clone()
-			t.Interval = conf.RunAtStartupAfter
-			t.RunOnce = true
-			worker.scheduler.Add(t)
-		}
+		worker.runOnce(conf.RunAtStartupAfter)
 	}
 
+	registerWorker(worker)
+
 	return worker
 }
 
+// runOnce schedules an one-off run of the worker, after the delay.
+func (w *Worker) runOnce(after time.Duration) bool {
+	task, err := w.scheduler.Lookup(w.taskId)
+	if err != nil || task == nil {
+		return false
+	}
+	t := task.Clone()
+	t.Interval = after
+	t.RunOnce = true
+	_, err = w.scheduler.Add(t)
+	return err == nil
+}
+
 func InitWorkers() func() {
+	return initWorkers(false)
+}
+
+func initWorkers(manual bool) func() {
 	workers := []*Worker{}
 
-	if worker := InitParseTorrentWorker(&WorkerConfig{
+	add := func(init func(conf *WorkerConfig) *Worker, conf *WorkerConfig) {
+		conf.manual = manual
+		if worker := init(conf); worker != nil {
+			workers = append(workers, worker)
+		}
+	}
+
+	add(InitParseTorrentWorker, &WorkerConfig{
 		Name:         "parse-torrent",
 		Interval:     5 * time.Minute,
 		RunExclusive: true,
-		ShouldWait: func() (bool, string) {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			if running_worker.sync_animetosho {
-				return true, "sync_animetosho is running"
-			}
-			if running_worker.sync_bitmagnet {
-				return true, "sync_bitmagnet is running"
-			}
-			if running_worker.sync_dmm_hashlist {
-				return true, "sync_dmm_hashlist is running"
-			}
-			if running_worker.sync_imdb {
-				return true, "sync_imdb is running"
-			}
-			if running_worker.map_anidb_torrent {
-				return true, "map_anidb_torrent is running"
-			}
-			if running_worker.map_imdb_torrent {
-				return true, "map_imdb_torrent is running"
-			}
-			return false, ""
-		},
-		OnStart: func() {},
-		OnEnd:   func() {},
-	}); worker != nil {
-		workers = append(workers, worker)
-	}
+		DependsOn:    []string{"sync-imdb"},
+		Resources:    []string{"torrent_info:write"},
+		Cancellable:  true,
+	})
 
-	if worker := InitPushTorrentsWorker(&WorkerConfig{
-		Disabled: TorrentPusherQueue.disabled,
+	add(InitPushTorrentsWorker, &WorkerConfig{
+		Disabled: worker_queue.TorrentPusherQueue.Disabled,
 		Name:     "push-torrent",
 		Interval: 10 * time.Minute,
-		ShouldWait: func() (bool, string) {
-			return false, ""
-		},
-		OnStart: func() {},
-		OnEnd:   func() {},
-	}); worker != nil {
-		workers = append(workers, worker)
-	}
+	})
 
-	if worker := InitCrawlStoreWorker(&WorkerConfig{
-		Name:     "crawl-store",
-		Interval: 30 * time.Minute,
-		ShouldWait: func() (bool, string) {
-			mutex.Lock()
-			defer mutex.Unlock()
-			if running_worker.sync_dmm_hashlist {
-				return true, "sync_dmm_hashlist is running"
-			}
-			if running_worker.sync_imdb {
-				return true, "sync_imdb is running"
-			}
-			if running_worker.map_imdb_torrent {
-				return true, "map_imdb_torrent is running"
-			}
-			return false, ""
-		},
-		OnStart: func() {},
-		OnEnd:   func() {},
-	}); worker != nil {
-		workers = append(workers, worker)
-	}
+	add(InitCrawlStoreWorker, &WorkerConfig{
+		Name:      "crawl-store",
+		Interval:  30 * time.Minute,
+		DependsOn: []string{"sync-imdb"},
+		Resources: []string{"torrent_info:write"},
+	})
 
-	if worker := InitSyncIMDBWorker(&WorkerConfig{
+	add(InitSyncIMDBWorker, &WorkerConfig{
 		Disabled:          !config.Feature.IsEnabled("imdb_title"),
 		Name:              "sync-imdb",
 		Interval:          24 * time.Hour,
 		RunAtStartupAfter: 30 * time.Second,
 		RunExclusive:      true,
-		ShouldWait: func() (bool, string) {
-			return false, ""
-		},
-		OnStart: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.sync_imdb = true
-		},
-		OnEnd: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.sync_imdb = false
-		},
-	}); worker != nil {
-		workers = append(workers, worker)
-	}
+	})
 
-	if worker := InitSyncDMMHashlistWorker(&WorkerConfig{
+	add(InitSyncDMMHashlistWorker, &WorkerConfig{
 		Disabled:          !config.Feature.IsEnabled("dmm_hashlist"),
 		Name:              "sync-dmm-hashlist",
 		Interval:          6 * time.Hour,
 		RunAtStartupAfter: 30 * time.Second,
 		RunExclusive:      true,
-		ShouldWait: func() (bool, string) {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			if running_worker.sync_imdb {
-				return true, "sync_imdb is running"
-			}
-			return false, ""
-		},
-		OnStart: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.sync_dmm_hashlist = true
-		},
-		OnEnd: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.sync_dmm_hashlist = false
-		},
-	}); worker != nil {
-		workers = append(workers, worker)
-	}
+		DependsOn:         []string{"sync-imdb"},
+		Resources:         []string{"torrent_info:write"},
+		Cancellable:       true,
+	})
 
-	if worker := InitMapIMDBTorrentWorker(&WorkerConfig{
+	add(InitMapIMDBTorrentWorker, &WorkerConfig{
 		Disabled:          !config.Feature.IsEnabled("imdb_title"),
 		Name:              "map-imdb-torrent",
 		Interval:          30 * time.Minute,
 		RunAtStartupAfter: 30 * time.Second,
 		RunExclusive:      true,
-		ShouldWait: func() (bool, string) {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			if running_worker.sync_imdb {
-				return true, "sync_imdb is running"
-			}
-			if running_worker.sync_animetosho {
-				return true, "sync_animetosho is running"
-			}
-			if running_worker.sync_bitmagnet {
-				return true, "sync_bitmagnet is running"
-			}
-			if running_worker.sync_dmm_hashlist {
-				return true, "sync_dmm_hashlist is running"
-			}
-			return false, ""
-		},
-		OnStart: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.map_imdb_torrent = true
-		},
-		OnEnd: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.map_imdb_torrent = false
-		},
-	}); worker != nil {
-		workers = append(workers, worker)
-	}
+		DependsOn:         []string{"sync-imdb"},
+		Resources:         []string{"torrent_info:read"},
+		Cancellable:       true,
+	})
 
-	if worker := InitMagnetCachePullerWorker(&WorkerConfig{
+	add(InitMagnetCachePullerWorker, &WorkerConfig{
 		Disabled: worker_queue.MagnetCachePullerQueue.Disabled,
 		Name:     "pull-magnet-cache",
 		Interval: 5 * time.Minute,
-		ShouldWait: func() (bool, string) {
-			return false, ""
-		},
-		OnStart: func() {},
-		OnEnd:   func() {},
-	}); worker != nil {
-		workers = append(workers, worker)
-	}
+	})
 
-	if worker := InitMapAnimeIdWorker(&WorkerConfig{
+	add(InitMapAnimeIdWorker, &WorkerConfig{
 		Disabled:     worker_queue.AnimeIdMapperQueue.Disabled,
 		Name:         "map-anime-id",
 		Interval:     10 * time.Minute,
 		RunExclusive: true,
-		ShouldWait: func() (bool, string) {
-			return false, ""
-		},
-		OnStart: func() {},
-		OnEnd:   func() {},
-	}); worker != nil {
-		workers = append(workers, worker)
-	}
+	})
 
-	if worker := InitSyncAnimeAPIWorker(&WorkerConfig{
+	add(InitSyncAnimeAPIWorker, &WorkerConfig{
 		Disabled:          !config.Feature.IsEnabled("anime"),
 		Name:              "sync-animeapi",
 		Interval:          1 * 24 * time.Hour,
 		RunAtStartupAfter: 45 * time.Second,
 		RunExclusive:      true,
-		ShouldWait: func() (bool, string) {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			if running_worker.sync_imdb {
-				return true, "sync_imdb is running"
-			}
-
-			return false, ""
-		},
-		OnStart: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.sync_animeapi = true
-		},
-		OnEnd: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.sync_animeapi = false
-		},
-	}); worker != nil {
-		workers = append(workers, worker)
-	}
+		DependsOn:         []string{"sync-imdb"},
+	})
 
-	if worker := InitSyncAniDBTitlesWorker(&WorkerConfig{
+	add(InitSyncAniDBTitlesWorker, &WorkerConfig{
 		Disabled:          !config.Feature.IsEnabled("anime"),
 		Name:              "sync-anidb-titles",
 		Interval:          1 * 24 * time.Hour,
 		RunAtStartupAfter: 30 * time.Second,
 		RunExclusive:      true,
-		ShouldWait: func() (bool, string) {
-			return false, ""
-		},
-		OnStart: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.sync_anidb_titles = true
-		},
-		OnEnd: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.sync_anidb_titles = false
-		},
-	}); worker != nil {
-		workers = append(workers, worker)
-	}
+	})
 
-	if worker := InitSyncAniDBTVDBEpisodeMapWorker(&WorkerConfig{
+	add(InitSyncAniDBTVDBEpisodeMapWorker, &WorkerConfig{
 		Disabled:          !config.Feature.IsEnabled("anime"),
 		Name:              "sync-anidb-tvdb-episode-map",
 		Interval:          1 * 24 * time.Hour,
 		RunAtStartupAfter: 45 * time.Second,
 		RunExclusive:      true,
-		ShouldWait: func() (bool, string) {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			if running_worker.sync_anidb_titles {
-				return true, "sync_anidb_titles is running"
-			}
-
-			return false, ""
-		},
-		OnStart: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.sync_anidb_tvdb_episode_map = true
-		},
-		OnEnd: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.sync_anidb_tvdb_episode_map = false
-		},
-	}); worker != nil {
-		workers = append(workers, worker)
-	}
+		DependsOn:         []string{"sync-anidb-titles"},
+	})
 
-	if worker := InitSyncManamiAnimeDatabaseWorker(&WorkerConfig{
+	add(InitSyncManamiAnimeDatabaseWorker, &WorkerConfig{
 		Disabled:          !config.Feature.IsEnabled("anime"),
 		Name:              "manami-anime-database",
 		Interval:          6 * 24 * time.Hour,
 		RunAtStartupAfter: 60 * time.Second,
 		RunExclusive:      true,
-		ShouldWait: func() (bool, string) {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			if running_worker.sync_anidb_titles {
-				return true, "sync_anidb_titles is running"
-			}
-
-			if running_worker.sync_animeapi {
-				return true, "sync_animeapi is running"
-			}
-
-			return false, ""
-		},
-		OnStart: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.sync_manami_anime_database = true
-		},
-		OnEnd: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.sync_manami_anime_database = false
-		},
-	}); worker != nil {
-		workers = append(workers, worker)
-	}
+		DependsOn:         []string{"sync-anidb-titles", "sync-animeapi"},
+	})
 
-	if worker := InitMapAniDBTorrentWorker(&WorkerConfig{
+	add(InitMapAniDBTorrentWorker, &WorkerConfig{
 		Disabled:          !config.Feature.IsEnabled("anime"),
 		Name:              "map-anidb-torrent",
 		Interval:          30 * time.Minute,
 		RunAtStartupAfter: 90 * time.Second,
 		RunExclusive:      true,
-		ShouldWait: func() (bool, string) {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			if running_worker.sync_animetosho {
-				return true, "sync_animetosho is running"
-			}
-			if running_worker.sync_bitmagnet {
-				return true, "sync_bitmagnet is running"
-			}
-			if running_worker.sync_dmm_hashlist {
-				return true, "sync_dmm_hashlist is running"
-			}
-
-			if running_worker.sync_anidb_titles {
-				return true, "sync_anidb_titles is running"
-			}
-			if running_worker.sync_anidb_tvdb_episode_map {
-				return true, "sync_anidb_tvdb_episode_map is running"
-			}
-			if running_worker.sync_animeapi {
-				return true, "sync_animeapi is running"
-			}
-			if running_worker.sync_manami_anime_database {
-				return true, "sync_manami_anime_database is running"
-			}
-
-			return false, ""
-		},
-		OnStart: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.map_anidb_torrent = true
-		},
-		OnEnd: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.map_anidb_torrent = false
-		},
-	}); worker != nil {
-		workers = append(workers, worker)
-	}
+		DependsOn:         []string{"sync-anidb-titles", "sync-anidb-tvdb-episode-map", "sync-animeapi", "manami-anime-database"},
+		Resources:         []string{"torrent_info:read"},
+		Cancellable:       true,
+	})
 
-	if worker := InitSyncLetterboxdList(&WorkerConfig{
+	add(InitSyncLetterboxdList, &WorkerConfig{
 		Disabled:     worker_queue.LetterboxdListSyncerQueue.Disabled,
 		Interval:     5 * time.Minute,
 		Name:         "sync-letterboxd-list",
-		OnEnd:        func() {},
-		OnStart:      func() {},
 		RunExclusive: true,
-		ShouldWait: func() (bool, string) {
-			return false, ""
-		},
-	}); worker != nil {
-		workers = append(workers, worker)
-	}
+	})
+
+	add(InitStremioBackupWorker, &WorkerConfig{
+		Disabled:     config.IsPublicInstance || !config.Feature.IsEnabled(config.FeatureStremioSidekick),
+		Interval:     15 * time.Minute,
+		Name:         "stremio-backup",
+		RunExclusive: true,
+		Cancellable:  true,
+	})
+
+	add(InitStoreHousekeepingWorker, &WorkerConfig{
+		Disabled:          config.IsPublicInstance || !config.StoreHousekeeping.IsEnabled(),
+		Interval:          6 * time.Hour,
+		Name:              "store-housekeeping",
+		RunAtStartupAfter: 5 * time.Minute,
+		RunExclusive:      true,
+		ConflictsWith:     []string{"store-migration"},
+		Cancellable:       true,
+	})
 
-	if worker := InitSyncBitmagnetWorker(&WorkerConfig{
+	add(InitStoreMigrationWorker, &WorkerConfig{
+		Disabled:          config.IsPublicInstance,
+		Interval:          1 * time.Minute,
+		Name:              "store-migration",
+		RunAtStartupAfter: 2 * time.Minute,
+		RunExclusive:      true,
+		Cancellable:       true,
+	})
+
+	add(InitSyncBitmagnetWorker, &WorkerConfig{
 		Disabled:          !config.Integration.Bitmagnet.IsEnabled(),
 		Name:              "sync-bitmagnet",
 		Interval:          60 * time.Minute,
 		RunAtStartupAfter: 90 * time.Second,
 		RunExclusive:      true,
-		ShouldWait: func() (bool, string) {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			if running_worker.sync_imdb {
-				return true, "sync_imdb is running"
-			}
-
-			if running_worker.sync_dmm_hashlist {
-				return true, "sync_dmm_hashlist is running"
-			}
-
-			return false, ""
-		},
-		OnStart: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.sync_bitmagnet = true
-		},
-		OnEnd: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.sync_bitmagnet = false
-		},
-	}); worker != nil {
-		workers = append(workers, worker)
-	}
+		DependsOn:         []string{"sync-imdb"},
+		Resources:         []string{"torrent_info:write"},
+		Cancellable:       true,
+	})
 
-	if worker := InitSyncAnimeToshoWorker(&WorkerConfig{
+	add(InitSyncAnimeToshoWorker, &WorkerConfig{
 		Disabled:          !config.Feature.IsEnabled("anime"),
 		Name:              "sync-animetosho",
 		Interval:          24 * time.Hour,
 		RunAtStartupAfter: 90 * time.Second,
 		RunExclusive:      true,
-		ShouldWait: func() (bool, string) {
-			mutex.Lock()
-			defer mutex.Unlock()
+		DependsOn:         []string{"sync-imdb"},
+		Resources:         []string{"torrent_info:write"},
+	})
 
-			if running_worker.sync_imdb {
-				return true, "sync_imdb is running"
-			}
-			if running_worker.sync_bitmagnet {
-				return true, "sync_bitmagnet is running"
-			}
-			if running_worker.sync_dmm_hashlist {
-				return true, "sync_dmm_hashlist is running"
-			}
-			return false, ""
-		},
-		OnStart: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.sync_animetosho = true
-		},
-		OnEnd: func() {
-			mutex.Lock()
-			defer mutex.Unlock()
-
-			running_worker.sync_animetosho = false
-		},
-	}); worker != nil {
-		workers = append(workers, worker)
+	registry.RLock()
+	err := checkWorkerGraph(registry.workers)
+	registry.RUnlock()
+	if err != nil {
+		panic(err)
 	}
 
 	return func() {
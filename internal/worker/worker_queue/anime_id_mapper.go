@@ -12,6 +12,7 @@ type AnimeIdMapperQueueItem struct {
 }
 
 var AnimeIdMapperQueue = WorkerQueue[AnimeIdMapperQueueItem]{
+	name:         "anime_id_mapper",
 	debounceTime: 1 * time.Minute,
 	getKey: func(item AnimeIdMapperQueueItem) string {
 		return item.Service + ":" + item.Id
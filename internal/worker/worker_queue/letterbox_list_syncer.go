@@ -11,6 +11,7 @@ type LetterboxdListSyncerQueueItem struct {
 }
 
 var LetterboxdListSyncerQueue = WorkerQueue[LetterboxdListSyncerQueueItem]{
+	name: "letterboxd_list_syncer",
 	debounceTime: func() time.Duration {
 		if config.Integration.Letterboxd.IsEnabled() {
 			return 1 * time.Minute
@@ -15,6 +15,7 @@ type MagnetCachePullerQueueItem struct {
 }
 
 var MagnetCachePullerQueue = WorkerQueue[MagnetCachePullerQueueItem]{
+	name:         "magnet_cache_puller",
 	debounceTime: 5 * time.Minute,
 	getKey: func(item MagnetCachePullerQueueItem) string {
 		return item.StoreCode + ":" + item.SId + ":" + item.Hash
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"sync"
 	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/config"
 )
 
 type WorkerQueueItem[T any] struct {
@@ -11,13 +13,43 @@ type WorkerQueueItem[T any] struct {
 	t time.Time
 }
 
+// WorkerQueue is kept in memory by default. With `STREMTHRU_WORKER_QUEUE=db`
+// it is persisted in the database, see queue_db.go.
 type WorkerQueue[T any] struct {
-	m            sync.Map
-	getKey       func(item T) string
-	getGroupKey  func(item T) string
-	transform    func(item *T) *T
-	debounceTime time.Duration
-	Disabled     bool
+	m                 sync.Map
+	name              string
+	getKey            func(item T) string
+	getGroupKey       func(item T) string
+	transform         func(item *T) *T
+	debounceTime      time.Duration
+	maxAttempts       int           // only for db
+	visibilityTimeout time.Duration // only for db
+	claimLimit        int           // only for db
+	dropOnFailure     bool          // only for memory, failed item is not retried
+	Disabled          bool
+}
+
+var isDurable = config.WorkerQueue == "db"
+
+func (q *WorkerQueue[T]) getMaxAttempts() int {
+	if q.maxAttempts > 0 {
+		return q.maxAttempts
+	}
+	return defaultMaxAttempts
+}
+
+func (q *WorkerQueue[T]) getVisibilityTimeout() time.Duration {
+	if q.visibilityTimeout > 0 {
+		return q.visibilityTimeout
+	}
+	return defaultVisibilityTimeout
+}
+
+func (q *WorkerQueue[T]) getClaimLimit() int {
+	if q.claimLimit > 0 {
+		return q.claimLimit
+	}
+	return defaultClaimLimit
 }
 
 var ErrWorkerQueueItemDelayed = errors.New("worker queue item delayed")
@@ -27,6 +59,10 @@ func (q *WorkerQueue[T]) Queue(item T) {
 		return
 	}
 	item = *q.transform(&item)
+	if isDurable {
+		q.dbQueue(item)
+		return
+	}
 	q.m.Swap(q.getKey(item), WorkerQueueItem[T]{
 		v: item,
 		t: time.Now().Add(q.debounceTime),
@@ -38,6 +74,10 @@ func (q *WorkerQueue[T]) delete(item T) {
 }
 
 func (q *WorkerQueue[T]) Process(f func(item T) error) {
+	if isDurable {
+		q.dbProcess(f)
+		return
+	}
 	q.m.Range(func(k, v any) bool {
 		_, keyOk := k.(string)
 		val, valOk := v.(WorkerQueueItem[T])
@@ -47,6 +87,9 @@ func (q *WorkerQueue[T]) Process(f func(item T) error) {
 					log.Debug("WorkerQueue process delayed", "key", q.getKey(val.v))
 				} else {
 					log.Error("WorkerQueue process failed", "error", err, "key", q.getKey(val.v))
+					if q.dropOnFailure {
+						q.delete(val.v)
+					}
 				}
 			} else {
 				q.delete(val.v)
@@ -57,6 +100,10 @@ func (q *WorkerQueue[T]) Process(f func(item T) error) {
 }
 
 func (q *WorkerQueue[T]) ProcessGroup(f func(groupKey string, items []T) error) {
+	if isDurable {
+		q.dbProcessGroup(f)
+		return
+	}
 	byGroupKey := map[string][]T{}
 	q.m.Range(func(k, v any) bool {
 		_, keyOk := k.(string)
@@ -76,6 +123,11 @@ func (q *WorkerQueue[T]) ProcessGroup(f func(groupKey string, items []T) error)
 				log.Debug("WorkerQueue processGroup delayed", "group_key", groupKey)
 			} else {
 				log.Error("WorkerQueue processGroup failed", "error", err, "group_key", groupKey)
+				if q.dropOnFailure {
+					for i := range items {
+						q.delete(items[i])
+					}
+				}
 			}
 		} else {
 			for i := range items {
@@ -0,0 +1,317 @@
+package worker_queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/db"
+	"github.com/MunifTanjim/stremthru/internal/util"
+	"github.com/rs/xid"
+)
+
+type ItemStatus string
+
+const (
+	ItemStatusQueued  ItemStatus = "queued"
+	ItemStatusClaimed ItemStatus = "claimed"
+	ItemStatusDead    ItemStatus = "dead"
+)
+
+const (
+	defaultMaxAttempts       = 5
+	defaultVisibilityTimeout = 30 * time.Minute
+	defaultClaimLimit        = 500
+	retryBaseDelay           = 1 * time.Minute
+	retryMaxDelay            = 6 * time.Hour
+	deadItemRetention        = 7 * 24 * time.Hour
+)
+
+const TableName = "worker_queue_item"
+
+var Column = struct {
+	Queue     string
+	Key       string
+	Value     string
+	Status    string
+	Attempts  string
+	ClaimId   string
+	RunAt     string
+	Error     string
+	CreatedAt string
+	UpdatedAt string
+}{
+	Queue:     "q",
+	Key:       "k",
+	Value:     "v",
+	Status:    "status",
+	Attempts:  "attempts",
+	ClaimId:   "claim_id",
+	RunAt:     "run_at",
+	Error:     "err",
+	CreatedAt: "cat",
+	UpdatedAt: "uat",
+}
+
+// retryDelay is the exponential backoff for the given attempt count.
+func retryDelay(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := float64(retryBaseDelay) * math.Pow(2, float64(attempts-1))
+	if delay > float64(retryMaxDelay) {
+		return retryMaxDelay
+	}
+	return time.Duration(delay)
+}
+
+var query_queue_item = fmt.Sprintf(
+	`INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s, %s) DO UPDATE SET %s = EXCLUDED.%s, %s = EXCLUDED.%s, %s = 0, %s = '', %s = EXCLUDED.%s, %s = '', %s = %s`,
+	TableName,
+	db.JoinColumnNames(Column.Queue, Column.Key, Column.Value, Column.Status, Column.RunAt),
+	util.RepeatJoin("?", 5, ", "),
+	Column.Queue,
+	Column.Key,
+	Column.Value, Column.Value,
+	Column.Status, Column.Status,
+	Column.Attempts,
+	Column.ClaimId,
+	Column.RunAt, Column.RunAt,
+	Column.Error,
+	Column.UpdatedAt, db.CurrentTimestamp,
+)
+
+// dbQueue upserts the item. Re-queueing resets the attempts and revives a
+// dead item. If the item is claimed, the claim is dropped, so that the
+// in-flight result does not remove the newer value.
+func (q *WorkerQueue[T]) dbQueue(item T) {
+	key := q.getKey(item)
+	value, err := json.Marshal(item)
+	if err != nil {
+		log.Error("WorkerQueue queue failed", "error", err, "queue", q.name, "key", key)
+		return
+	}
+	runAt := db.Timestamp{Time: time.Now().Add(q.debounceTime)}
+	if _, err := db.Exec(query_queue_item, q.name, key, string(value), ItemStatusQueued, runAt); err != nil {
+		log.Error("WorkerQueue queue failed", "error", err, "queue", q.name, "key", key)
+	}
+}
+
+var query_purge_dead_items = fmt.Sprintf(
+	`DELETE FROM %s WHERE %s = ? AND %s = '%s' AND %s < ?`,
+	TableName,
+	Column.Queue,
+	Column.Status, ItemStatusDead,
+	Column.UpdatedAt,
+)
+
+var query_bury_expired_items = fmt.Sprintf(
+	`UPDATE %s SET %s = '%s', %s = '', %s = 'visibility timeout exceeded', %s = %s WHERE %s = ? AND %s = '%s' AND %s <= ? AND %s >= ?`,
+	TableName,
+	Column.Status, ItemStatusDead,
+	Column.ClaimId,
+	Column.Error,
+	Column.UpdatedAt, db.CurrentTimestamp,
+	Column.Queue,
+	Column.Status, ItemStatusClaimed,
+	Column.RunAt,
+	Column.Attempts,
+)
+
+var query_claim_items = fmt.Sprintf(
+	`UPDATE %s SET %s = '%s', %s = ?, %s = %s + 1, %s = ?, %s = %s WHERE %s = ? AND %s IN (SELECT %s FROM %s WHERE %s = ? AND %s IN ('%s', '%s') AND %s <= ? ORDER BY %s LIMIT ?)`,
+	TableName,
+	Column.Status, ItemStatusClaimed,
+	Column.ClaimId,
+	Column.Attempts, Column.Attempts,
+	Column.RunAt,
+	Column.UpdatedAt, db.CurrentTimestamp,
+	Column.Queue,
+	Column.Key,
+	Column.Key,
+	TableName,
+	Column.Queue,
+	Column.Status, ItemStatusQueued, ItemStatusClaimed,
+	Column.RunAt,
+	Column.RunAt,
+)
+
+var query_get_claimed_items = fmt.Sprintf(
+	`SELECT %s FROM %s WHERE %s = ? AND %s = ?`,
+	db.JoinColumnNames(Column.Key, Column.Value, Column.Attempts),
+	TableName,
+	Column.Queue,
+	Column.ClaimId,
+)
+
+type claimedItem[T any] struct {
+	key      string
+	value    T
+	attempts int
+}
+
+// claim marks the visible items as claimed, hiding them from other claims
+// until the visibility timeout. An item is visible when it is queued and
+// its debounce time has passed, or when its previous claim has expired,
+// i.e. the instance processing it has crashed. Only one instance claims at
+// a time, and upto the claim limit items are claimed, the rest are left for
+// the next run.
+func (q *WorkerQueue[T]) claim() (string, []claimedItem[T], error) {
+	lock := db.NewAdvisoryLock("worker_queue", q.name)
+	if lock == nil {
+		return "", nil, errors.New("failed to create advisory lock")
+	}
+	defer lock.Release()
+
+	if !lock.TryAcquire() {
+		log.Debug("WorkerQueue claim skipped, another instance is claiming", "queue", q.name)
+		return "", nil, lock.Err()
+	}
+
+	now := time.Now()
+
+	if _, err := db.Exec(query_purge_dead_items, q.name, db.Timestamp{Time: now.Add(-deadItemRetention)}); err != nil {
+		return "", nil, err
+	}
+
+	if _, err := db.Exec(query_bury_expired_items, q.name, db.Timestamp{Time: now}, q.getMaxAttempts()); err != nil {
+		return "", nil, err
+	}
+
+	claimId := xid.New().String()
+	visibleAt := db.Timestamp{Time: now.Add(q.getVisibilityTimeout())}
+	if _, err := db.Exec(query_claim_items, claimId, visibleAt, q.name, q.name, db.Timestamp{Time: now}, q.getClaimLimit()); err != nil {
+		return "", nil, err
+	}
+
+	rows, err := db.Query(query_get_claimed_items, q.name, claimId)
+	if err != nil {
+		return "", nil, err
+	}
+	defer rows.Close()
+
+	items := []claimedItem[T]{}
+	for rows.Next() {
+		var value string
+		item := claimedItem[T]{}
+		if err := rows.Scan(&item.key, &value, &item.attempts); err != nil {
+			return "", nil, err
+		}
+		if err := json.Unmarshal([]byte(value), &item.value); err != nil {
+			log.Error("WorkerQueue claim failed to decode item", "error", err, "queue", q.name, "key", item.key)
+			q.release(claimId, &item, err)
+			continue
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return "", nil, err
+	}
+	return claimId, items, nil
+}
+
+var query_ack_item = fmt.Sprintf(
+	`DELETE FROM %s WHERE %s = ? AND %s = ? AND %s = ?`,
+	TableName,
+	Column.Queue,
+	Column.Key,
+	Column.ClaimId,
+)
+
+func (q *WorkerQueue[T]) ack(claimId string, item *claimedItem[T]) {
+	if _, err := db.Exec(query_ack_item, q.name, item.key, claimId); err != nil {
+		log.Error("WorkerQueue ack failed", "error", err, "queue", q.name, "key", item.key)
+	}
+}
+
+var query_release_item = fmt.Sprintf(
+	`UPDATE %s SET %s = ?, %s = '', %s = %s + ?, %s = ?, %s = ?, %s = %s WHERE %s = ? AND %s = ? AND %s = ?`,
+	TableName,
+	Column.Status,
+	Column.ClaimId,
+	Column.Attempts, Column.Attempts,
+	Column.RunAt,
+	Column.Error,
+	Column.UpdatedAt, db.CurrentTimestamp,
+	Column.Queue,
+	Column.Key,
+	Column.ClaimId,
+)
+
+// release puts the item back in the queue. A delayed item is retried on the
+// next run without counting the attempt. A failed item is retried with
+// backoff, or moved to the dead state after the max attempts.
+func (q *WorkerQueue[T]) release(claimId string, item *claimedItem[T], err error) {
+	status, attemptsDelta, runAt, errMsg := ItemStatusQueued, 0, time.Now(), ""
+	if errors.Is(err, ErrWorkerQueueItemDelayed) {
+		attemptsDelta = -1
+	} else {
+		errMsg = err.Error()
+		if item.attempts >= q.getMaxAttempts() {
+			status = ItemStatusDead
+			log.Warn("WorkerQueue item dead", "error", err, "queue", q.name, "key", item.key, "attempts", item.attempts)
+		} else {
+			runAt = runAt.Add(retryDelay(item.attempts))
+		}
+	}
+	if _, err := db.Exec(query_release_item, status, attemptsDelta, db.Timestamp{Time: runAt}, errMsg, q.name, item.key, claimId); err != nil {
+		log.Error("WorkerQueue release failed", "error", err, "queue", q.name, "key", item.key)
+	}
+}
+
+func (q *WorkerQueue[T]) dbProcess(f func(item T) error) {
+	claimId, items, err := q.claim()
+	if err != nil {
+		log.Error("WorkerQueue claim failed", "error", err, "queue", q.name)
+		return
+	}
+	for i := range items {
+		item := &items[i]
+		if err := f(item.value); err != nil {
+			if err == ErrWorkerQueueItemDelayed {
+				log.Debug("WorkerQueue process delayed", "queue", q.name, "key", item.key)
+			} else {
+				log.Error("WorkerQueue process failed", "error", err, "queue", q.name, "key", item.key, "attempts", item.attempts)
+			}
+			q.release(claimId, item, err)
+		} else {
+			q.ack(claimId, item)
+		}
+	}
+}
+
+func (q *WorkerQueue[T]) dbProcessGroup(f func(groupKey string, items []T) error) {
+	claimId, items, err := q.claim()
+	if err != nil {
+		log.Error("WorkerQueue claim failed", "error", err, "queue", q.name)
+		return
+	}
+	byGroupKey := map[string][]*claimedItem[T]{}
+	for i := range items {
+		item := &items[i]
+		groupKey := q.getGroupKey(item.value)
+		byGroupKey[groupKey] = append(byGroupKey[groupKey], item)
+	}
+	for groupKey, items := range byGroupKey {
+		values := make([]T, len(items))
+		for i := range items {
+			values[i] = items[i].value
+		}
+		if err := f(groupKey, values); err != nil {
+			if err == ErrWorkerQueueItemDelayed {
+				log.Debug("WorkerQueue processGroup delayed", "queue", q.name, "group_key", groupKey)
+			} else {
+				log.Error("WorkerQueue processGroup failed", "error", err, "queue", q.name, "group_key", groupKey)
+			}
+			for _, item := range items {
+				q.release(claimId, item, err)
+			}
+		} else {
+			for _, item := range items {
+				q.ack(claimId, item)
+			}
+		}
+	}
+}
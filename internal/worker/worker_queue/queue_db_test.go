@@ -0,0 +1,254 @@
+package worker_queue
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/db"
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryDelay(t *testing.T) {
+	for _, tc := range []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{0, 1 * time.Minute},
+		{1, 1 * time.Minute},
+		{2, 2 * time.Minute},
+		{5, 16 * time.Minute},
+		{9, 256 * time.Minute},
+		{10, 6 * time.Hour},
+		{100, 6 * time.Hour},
+	} {
+		assert.Equal(t, tc.expected, retryDelay(tc.attempts), "attempts=%d", tc.attempts)
+	}
+}
+
+type testQueueItem struct {
+	Id    string `json:"id"`
+	Value int    `json:"value"`
+}
+
+var setupTestDBOnce sync.Once
+
+func setupTestDB(t *testing.T) {
+	t.Helper()
+
+	if db.Dialect != db.DBDialectSQLite {
+		t.Skip("requires sqlite")
+	}
+	setupTestDBOnce.Do(func() {
+		db.Open()
+		migration, err := os.ReadFile("../../../migrations/sqlite/20250925100000_create_worker_queue_item_table.sql")
+		if err != nil {
+			panic(err)
+		}
+		up, _, _ := strings.Cut(string(migration), "-- +goose Down")
+		if _, err := db.Exec(up); err != nil {
+			panic(err)
+		}
+	})
+}
+
+func newTestQueue(t *testing.T) *WorkerQueue[testQueueItem] {
+	setupTestDB(t)
+
+	q := &WorkerQueue[testQueueItem]{
+		name: "test:" + xid.New().String(),
+		getKey: func(item testQueueItem) string {
+			return item.Id
+		},
+		getGroupKey: func(item testQueueItem) string {
+			return ""
+		},
+		transform: func(item *testQueueItem) *testQueueItem {
+			return item
+		},
+	}
+	t.Cleanup(func() {
+		db.Exec(fmt.Sprintf(`DELETE FROM %s WHERE %s = ?`, TableName, Column.Queue), q.name)
+	})
+	return q
+}
+
+type testQueueRow struct {
+	value    string
+	status   ItemStatus
+	attempts int
+	claimId  string
+}
+
+func getTestQueueRow(t *testing.T, q *WorkerQueue[testQueueItem], key string) *testQueueRow {
+	t.Helper()
+
+	query := fmt.Sprintf(
+		`SELECT %s FROM %s WHERE %s = ? AND %s = ?`,
+		db.JoinColumnNames(Column.Value, Column.Status, Column.Attempts, Column.ClaimId),
+		TableName,
+		Column.Queue,
+		Column.Key,
+	)
+	row := &testQueueRow{}
+	err := db.QueryRow(query, q.name, key).Scan(&row.value, &row.status, &row.attempts, &row.claimId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	assert.NoError(t, err)
+	return row
+}
+
+func TestWorkerQueueClaim(t *testing.T) {
+	q := newTestQueue(t)
+	q.claimLimit = 2
+
+	for i := range 3 {
+		q.dbQueue(testQueueItem{Id: strconv.Itoa(i), Value: i})
+	}
+
+	claimId, items, err := q.claim()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, claimId)
+	assert.Len(t, items, 2, "limited")
+	for _, item := range items {
+		assert.Equal(t, item.key, item.value.Id)
+		assert.Equal(t, 1, item.attempts)
+	}
+
+	_, remainingItems, err := q.claim()
+	assert.NoError(t, err)
+	assert.Len(t, remainingItems, 1, "claimed items are hidden")
+
+	_, noItems, err := q.claim()
+	assert.NoError(t, err)
+	assert.Len(t, noItems, 0)
+
+	q.ack(claimId, &items[0])
+	assert.Nil(t, getTestQueueRow(t, q, items[0].key))
+	row := getTestQueueRow(t, q, items[1].key)
+	assert.Equal(t, ItemStatusClaimed, row.status)
+	assert.Equal(t, claimId, row.claimId)
+}
+
+func TestWorkerQueueVisibilityTimeout(t *testing.T) {
+	q := newTestQueue(t)
+	q.visibilityTimeout = time.Nanosecond
+
+	q.dbQueue(testQueueItem{Id: "a"})
+
+	claimId, items, err := q.claim()
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+
+	reClaimId, items, err := q.claim()
+	assert.NoError(t, err)
+	assert.Len(t, items, 1, "claim expired")
+	assert.NotEqual(t, claimId, reClaimId)
+	assert.Equal(t, 2, items[0].attempts)
+
+	q.ack(claimId, &items[0])
+	assert.NotNil(t, getTestQueueRow(t, q, "a"), "expired claim can not ack")
+
+	q.ack(reClaimId, &items[0])
+	assert.Nil(t, getTestQueueRow(t, q, "a"))
+}
+
+func TestWorkerQueueDeadLetter(t *testing.T) {
+	t.Run("release", func(t *testing.T) {
+		q := newTestQueue(t)
+		q.maxAttempts = 2
+
+		q.dbQueue(testQueueItem{Id: "a"})
+
+		claimId, items, err := q.claim()
+		assert.NoError(t, err)
+		assert.Len(t, items, 1)
+
+		q.release(claimId, &items[0], ErrWorkerQueueItemDelayed)
+		row := getTestQueueRow(t, q, "a")
+		assert.Equal(t, ItemStatusQueued, row.status)
+		assert.Equal(t, 0, row.attempts, "delayed is not counted")
+
+		claimId, items, err = q.claim()
+		assert.NoError(t, err)
+		assert.Len(t, items, 1)
+
+		q.release(claimId, &items[0], errors.New("failed"))
+		row = getTestQueueRow(t, q, "a")
+		assert.Equal(t, ItemStatusQueued, row.status)
+		assert.Equal(t, 1, row.attempts)
+
+		_, items, err = q.claim()
+		assert.NoError(t, err)
+		assert.Len(t, items, 0, "retried with backoff")
+
+		_, err = db.Exec(fmt.Sprintf(`UPDATE %s SET %s = ? WHERE %s = ?`, TableName, Column.RunAt, Column.Queue), db.Timestamp{Time: time.Now()}, q.name)
+		assert.NoError(t, err)
+
+		claimId, items, err = q.claim()
+		assert.NoError(t, err)
+		assert.Len(t, items, 1)
+		assert.Equal(t, 2, items[0].attempts)
+
+		q.release(claimId, &items[0], errors.New("failed"))
+		row = getTestQueueRow(t, q, "a")
+		assert.Equal(t, ItemStatusDead, row.status)
+
+		_, items, err = q.claim()
+		assert.NoError(t, err)
+		assert.Len(t, items, 0)
+	})
+
+	t.Run("visibility timeout", func(t *testing.T) {
+		q := newTestQueue(t)
+		q.maxAttempts = 1
+		q.visibilityTimeout = time.Nanosecond
+
+		q.dbQueue(testQueueItem{Id: "a"})
+
+		_, items, err := q.claim()
+		assert.NoError(t, err)
+		assert.Len(t, items, 1)
+
+		_, items, err = q.claim()
+		assert.NoError(t, err)
+		assert.Len(t, items, 0)
+		row := getTestQueueRow(t, q, "a")
+		assert.Equal(t, ItemStatusDead, row.status)
+		assert.Empty(t, row.claimId)
+	})
+}
+
+func TestWorkerQueueDedupe(t *testing.T) {
+	q := newTestQueue(t)
+
+	q.dbQueue(testQueueItem{Id: "a", Value: 1})
+	q.dbQueue(testQueueItem{Id: "a", Value: 2})
+
+	claimId, items, err := q.claim()
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, 2, items[0].value.Value)
+
+	q.dbQueue(testQueueItem{Id: "a", Value: 3})
+	row := getTestQueueRow(t, q, "a")
+	assert.Equal(t, ItemStatusQueued, row.status)
+	assert.Empty(t, row.claimId, "claim dropped")
+	assert.Equal(t, 0, row.attempts)
+
+	q.ack(claimId, &items[0])
+	assert.NotNil(t, getTestQueueRow(t, q, "a"), "newer value is kept")
+
+	_, items, err = q.claim()
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, 3, items[0].value.Value)
+}
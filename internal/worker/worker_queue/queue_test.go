@@ -0,0 +1,45 @@
+package worker_queue
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerQueueDropOnFailure(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		dropOnFailure bool
+		err           error
+		remaining     int
+	}{
+		{"retry", false, errors.New("failed"), 1},
+		{"drop", true, errors.New("failed"), 0},
+		{"drop delayed", true, ErrWorkerQueueItemDelayed, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			q := &WorkerQueue[testQueueItem]{
+				getKey: func(item testQueueItem) string {
+					return item.Id
+				},
+				transform: func(item *testQueueItem) *testQueueItem {
+					return item
+				},
+				dropOnFailure: tc.dropOnFailure,
+			}
+			q.m.Store("a", WorkerQueueItem[testQueueItem]{v: testQueueItem{Id: "a"}})
+
+			q.Process(func(item testQueueItem) error {
+				return tc.err
+			})
+
+			remaining := 0
+			q.m.Range(func(k, v any) bool {
+				remaining++
+				return true
+			})
+			assert.Equal(t, tc.remaining, remaining)
+		})
+	}
+}
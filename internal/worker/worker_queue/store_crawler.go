@@ -10,6 +10,7 @@ type StoreCrawlerQueueItem struct {
 }
 
 var StoreCrawlerQueue = WorkerQueue[StoreCrawlerQueueItem]{
+	name:         "store_crawler",
 	debounceTime: 15 * time.Minute,
 	getKey: func(item StoreCrawlerQueueItem) string {
 		return item.StoreCode + ":" + item.StoreToken
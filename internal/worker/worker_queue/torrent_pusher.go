@@ -0,0 +1,22 @@
+package worker_queue
+
+import (
+	"strings"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/config"
+)
+
+var TorrentPusherQueue = WorkerQueue[string]{
+	name:         "torrent_pusher",
+	debounceTime: 5 * time.Minute,
+	getKey: func(sid string) string {
+		return sid
+	},
+	transform: func(sid *string) *string {
+		id, _, _ := strings.Cut(*sid, ":")
+		return &id
+	},
+	dropOnFailure: true,
+	Disabled:      !config.HasPeerRole(config.PeerRolePushTorrents, true),
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/MunifTanjim/stremthru/internal/config"
 	"github.com/MunifTanjim/stremthru/internal/db"
@@ -12,20 +15,33 @@ import (
 	"github.com/MunifTanjim/stremthru/store"
 )
 
+var appState = &config.AppState{
+	StoreNames: []string{
+		string(store.StoreNameAlldebrid),
+		string(store.StoreNameDebridLink),
+		string(store.StoreNameEasyDebrid),
+		string(store.StoreNameLocal),
+		string(store.StoreNameOffcloud),
+		string(store.StoreNamePikPak),
+		string(store.StoreNamePremiumize),
+		string(store.StoreNameQBittorrent),
+		string(store.StoreNameRealDebrid),
+		string(store.StoreNameTorBox),
+		string(store.StoreNameTransmission),
+	},
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
+	serve()
+}
+
+func serve() {
 	// Print app configuration
-	config.PrintConfig(&config.AppState{
-		StoreNames: []string{
-			string(store.StoreNameAlldebrid),
-			string(store.StoreNameDebridLink),
-			string(store.StoreNameEasyDebrid),
-			string(store.StoreNameOffcloud),
-			string(store.StoreNamePikPak),
-			string(store.StoreNamePremiumize),
-			string(store.StoreNameRealDebrid),
-			string(store.StoreNameTorBox),
-		},
-	})
+	config.PrintConfig(appState)
 
 	// Open database
 	db.Open()
@@ -48,9 +64,29 @@ func main() {
 	endpoint.AddTorrentEndpoints(mux)
 	endpoint.AddTorznabEndpoints(mux)
 	endpoint.AddExperimentEndpoints(mux)
+	endpoint.AddWorkerEndpoints(mux)
+	endpoint.AddPeerEndpoints(mux)
+	endpoint.AddPeerTokenEndpoints(mux)
+	endpoint.AddConfigEndpoints(mux)
+	endpoint.AddContentProxyEndpoints(mux)
+	endpoint.AddTorrentDumpEndpoints(mux)
 
 	handler := shared.RootServerContext(mux)
 
+	// Reload config on SIGHUP
+	reloadSignal := make(chan os.Signal, 1)
+	signal.Notify(reloadSignal, syscall.SIGHUP)
+	go func() {
+		for range reloadSignal {
+			result, err := config.Reload()
+			if err != nil {
+				log.Printf("failed to reload config: %v", err)
+				continue
+			}
+			log.Printf("reloaded config, applied: %v, restart required: %v", result.Applied, result.RestartRequired)
+		}
+	}()
+
 	// Configure server address
 	addr := ":" + config.Port
 	if config.Environment == config.EnvDev {
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS "public"."plex_list" (
+    "id" text NOT NULL,
+    "kind" text NOT NULL,
+    "name" text NOT NULL,
+    "overview" text NOT NULL,
+    "uat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "public"."plex_item" (
+    "id" text NOT NULL,
+    "type" text NOT NULL,
+    "name" text NOT NULL,
+    "overview" text NOT NULL,
+    "year" int NOT NULL,
+    "runtime" int NOT NULL,
+    "poster" text NOT NULL,
+    "background" text NOT NULL,
+    "imdb_id" text NOT NULL,
+    "tmdb_id" text NOT NULL,
+    "tvdb_id" text NOT NULL,
+    "genres" json NOT NULL DEFAULT '[]',
+    "uat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "public"."plex_list_item" (
+    "list_id" text NOT NULL,
+    "item_id" text NOT NULL,
+    "order" int NOT NULL,
+    PRIMARY KEY ("list_id", "item_id")
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."plex_list_item";
+DROP TABLE IF EXISTS "public"."plex_item";
+DROP TABLE IF EXISTS "public"."plex_list";
+-- +goose StatementEnd
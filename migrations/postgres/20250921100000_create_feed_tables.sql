@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS "public"."feed_list" (
+    "id" text NOT NULL,
+    "url" text NOT NULL,
+    "name" text NOT NULL,
+    "uat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "public"."feed_list_item" (
+    "list_id" text NOT NULL,
+    "imdb_id" text NOT NULL,
+    "type" text NOT NULL,
+    "title" text NOT NULL,
+    "year" int NOT NULL,
+    "order" int NOT NULL,
+    PRIMARY KEY ("list_id", "imdb_id")
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."feed_list_item";
+DROP TABLE IF EXISTS "public"."feed_list";
+-- +goose StatementEnd
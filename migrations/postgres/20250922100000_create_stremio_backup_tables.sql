@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS "public"."stremio_backup_schedule" (
+    "account_id" text NOT NULL,
+    "email" text NOT NULL,
+    "auth_key" text NOT NULL,
+    "interval_seconds" int NOT NULL,
+    "retention" int NOT NULL,
+    "enabled" boolean NOT NULL DEFAULT false,
+    "lrat" timestamptz,
+    "lerr" text NOT NULL DEFAULT '',
+    "cat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "uat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("account_id")
+);
+
+CREATE TABLE IF NOT EXISTS "public"."stremio_backup_snapshot" (
+    "id" text NOT NULL,
+    "account_id" text NOT NULL,
+    "kind" text NOT NULL,
+    "hash" text NOT NULL,
+    "item_count" int NOT NULL,
+    "data" text NOT NULL,
+    "cat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "stremio_backup_snapshot_idx_account_id_kind_cat" ON "public"."stremio_backup_snapshot" ("account_id", "kind", "cat");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS "stremio_backup_snapshot_idx_account_id_kind_cat";
+DROP TABLE IF EXISTS "public"."stremio_backup_snapshot";
+DROP TABLE IF EXISTS "public"."stremio_backup_schedule";
+-- +goose StatementEnd
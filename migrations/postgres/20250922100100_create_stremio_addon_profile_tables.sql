@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS "public"."stremio_addon_profile" (
+    "id" text NOT NULL,
+    "name" text NOT NULL,
+    "addons" json NOT NULL DEFAULT '[]',
+    "cat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "uat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "public"."stremio_linked_account" (
+    "id" text NOT NULL,
+    "email" text NOT NULL,
+    "auth_key" text NOT NULL,
+    "profile_id" text NOT NULL DEFAULT '',
+    "aat" timestamptz,
+    "cat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "uat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."stremio_linked_account";
+DROP TABLE IF EXISTS "public"."stremio_addon_profile";
+-- +goose StatementEnd
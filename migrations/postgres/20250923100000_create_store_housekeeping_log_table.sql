@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS "public"."store_housekeeping_log" (
+    "id" text NOT NULL,
+    "username" text NOT NULL,
+    "store" text NOT NULL,
+    "hash" text NOT NULL,
+    "magnet_id" text NOT NULL,
+    "name" text NOT NULL,
+    "reason" text NOT NULL,
+    "dry_run" boolean NOT NULL DEFAULT false,
+    "err" text NOT NULL DEFAULT '',
+    "cat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "store_housekeeping_log_idx_username_store_cat" ON "public"."store_housekeeping_log" ("username", "store", "cat");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS "store_housekeeping_log_idx_username_store_cat";
+DROP TABLE IF EXISTS "public"."store_housekeeping_log";
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS "public"."store_migration_job" (
+    "id" text NOT NULL,
+    "username" text NOT NULL,
+    "src_store" text NOT NULL,
+    "dst_store" text NOT NULL,
+    "status" text NOT NULL,
+    "total" int NOT NULL DEFAULT 0,
+    "added" int NOT NULL DEFAULT 0,
+    "skipped" int NOT NULL DEFAULT 0,
+    "failed" int NOT NULL DEFAULT 0,
+    "err" text NOT NULL DEFAULT '',
+    "cat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "uat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "store_migration_job_idx_username_cat" ON "public"."store_migration_job" ("username", "cat");
+CREATE INDEX IF NOT EXISTS "store_migration_job_idx_status" ON "public"."store_migration_job" ("status");
+
+CREATE TABLE IF NOT EXISTS "public"."store_migration_item" (
+    "job_id" text NOT NULL,
+    "hash" text NOT NULL,
+    "name" text NOT NULL,
+    "cached" boolean NOT NULL DEFAULT false,
+    "status" text NOT NULL,
+    "err" text NOT NULL DEFAULT '',
+    "uat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("job_id", "hash")
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."store_migration_item";
+DROP INDEX IF EXISTS "store_migration_job_idx_status";
+DROP INDEX IF EXISTS "store_migration_job_idx_username_cat";
+DROP TABLE IF EXISTS "public"."store_migration_job";
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS "public"."worker_queue_item" (
+    "q" text NOT NULL,
+    "k" text NOT NULL,
+    "v" text NOT NULL,
+    "status" text NOT NULL,
+    "attempts" int NOT NULL DEFAULT 0,
+    "claim_id" text NOT NULL DEFAULT '',
+    "run_at" timestamptz NOT NULL,
+    "err" text NOT NULL DEFAULT '',
+    "cat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "uat" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("q", "k")
+);
+
+CREATE INDEX IF NOT EXISTS "worker_queue_item_idx_q_status_run_at" ON "public"."worker_queue_item" ("q", "status", "run_at");
+CREATE INDEX IF NOT EXISTS "worker_queue_item_idx_q_claim_id" ON "public"."worker_queue_item" ("q", "claim_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS "worker_queue_item_idx_q_claim_id";
+DROP INDEX IF EXISTS "worker_queue_item_idx_q_status_run_at";
+DROP TABLE IF EXISTS "public"."worker_queue_item";
+-- +goose StatementEnd
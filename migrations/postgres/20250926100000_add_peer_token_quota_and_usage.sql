@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "public"."peer_token" ADD COLUMN IF NOT EXISTS "quota" int NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS "public"."peer_token_usage" (
+    "id" character varying NOT NULL,
+    "requests" int NOT NULL DEFAULT 0,
+    "torrents_pushed" int NOT NULL DEFAULT 0,
+    "window_at" timestamptz NOT NULL,
+    "window_requests" int NOT NULL DEFAULT 0,
+    "instance_id" character varying NOT NULL DEFAULT '',
+    "last_seen_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."peer_token_usage";
+
+ALTER TABLE "public"."peer_token" DROP COLUMN IF EXISTS "quota";
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "public"."store_migration_job" ADD COLUMN IF NOT EXISTS "checked" boolean NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "public"."store_migration_job" DROP COLUMN IF EXISTS "checked";
+-- +goose StatementEnd
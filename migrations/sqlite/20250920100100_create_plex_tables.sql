@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS `plex_list` (
+    `id` varchar NOT NULL,
+    `kind` varchar NOT NULL,
+    `name` varchar NOT NULL,
+    `overview` varchar NOT NULL,
+    `uat` datetime NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `plex_item` (
+    `id` varchar NOT NULL,
+    `type` varchar NOT NULL,
+    `name` varchar NOT NULL,
+    `overview` varchar NOT NULL,
+    `year` int NOT NULL,
+    `runtime` int NOT NULL,
+    `poster` varchar NOT NULL,
+    `background` varchar NOT NULL,
+    `imdb_id` varchar NOT NULL,
+    `tmdb_id` varchar NOT NULL,
+    `tvdb_id` varchar NOT NULL,
+    `genres` json NOT NULL DEFAULT (json('[]')),
+    `uat` datetime NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `plex_list_item` (
+    `list_id` varchar NOT NULL,
+    `item_id` varchar NOT NULL,
+    `order` int NOT NULL,
+    PRIMARY KEY (`list_id`, `item_id`)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS `plex_list_item`;
+DROP TABLE IF EXISTS `plex_item`;
+DROP TABLE IF EXISTS `plex_list`;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS `feed_list` (
+    `id` varchar NOT NULL,
+    `url` varchar NOT NULL,
+    `name` varchar NOT NULL,
+    `uat` datetime NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `feed_list_item` (
+    `list_id` varchar NOT NULL,
+    `imdb_id` varchar NOT NULL,
+    `type` varchar NOT NULL,
+    `title` varchar NOT NULL,
+    `year` int NOT NULL,
+    `order` int NOT NULL,
+    PRIMARY KEY (`list_id`, `imdb_id`)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS `feed_list_item`;
+DROP TABLE IF EXISTS `feed_list`;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS `stremio_backup_schedule` (
+    `account_id` varchar NOT NULL,
+    `email` varchar NOT NULL,
+    `auth_key` varchar NOT NULL,
+    `interval_seconds` int NOT NULL,
+    `retention` int NOT NULL,
+    `enabled` bool NOT NULL DEFAULT false,
+    `lrat` datetime,
+    `lerr` varchar NOT NULL DEFAULT '',
+    `cat` datetime NOT NULL DEFAULT (unixepoch()),
+    `uat` datetime NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (`account_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `stremio_backup_snapshot` (
+    `id` varchar NOT NULL,
+    `account_id` varchar NOT NULL,
+    `kind` varchar NOT NULL,
+    `hash` varchar NOT NULL,
+    `item_count` int NOT NULL,
+    `data` text NOT NULL,
+    `cat` datetime NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (`id`)
+);
+
+CREATE INDEX IF NOT EXISTS `stremio_backup_snapshot_idx_account_id_kind_cat` ON `stremio_backup_snapshot` (`account_id`, `kind`, `cat`);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS `stremio_backup_snapshot_idx_account_id_kind_cat`;
+DROP TABLE IF EXISTS `stremio_backup_snapshot`;
+DROP TABLE IF EXISTS `stremio_backup_schedule`;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS `stremio_addon_profile` (
+    `id` varchar NOT NULL,
+    `name` varchar NOT NULL,
+    `addons` json NOT NULL DEFAULT (json('[]')),
+    `cat` datetime NOT NULL DEFAULT (unixepoch()),
+    `uat` datetime NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `stremio_linked_account` (
+    `id` varchar NOT NULL,
+    `email` varchar NOT NULL,
+    `auth_key` varchar NOT NULL,
+    `profile_id` varchar NOT NULL DEFAULT '',
+    `aat` datetime,
+    `cat` datetime NOT NULL DEFAULT (unixepoch()),
+    `uat` datetime NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (`id`)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS `stremio_linked_account`;
+DROP TABLE IF EXISTS `stremio_addon_profile`;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS `store_housekeeping_log` (
+    `id` varchar NOT NULL,
+    `username` varchar NOT NULL,
+    `store` varchar NOT NULL,
+    `hash` varchar NOT NULL,
+    `magnet_id` varchar NOT NULL,
+    `name` varchar NOT NULL,
+    `reason` varchar NOT NULL,
+    `dry_run` bool NOT NULL DEFAULT false,
+    `err` varchar NOT NULL DEFAULT '',
+    `cat` datetime NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (`id`)
+);
+
+CREATE INDEX IF NOT EXISTS `store_housekeeping_log_idx_username_store_cat` ON `store_housekeeping_log` (`username`, `store`, `cat`);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS `store_housekeeping_log_idx_username_store_cat`;
+DROP TABLE IF EXISTS `store_housekeeping_log`;
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS `store_migration_job` (
+    `id` varchar NOT NULL,
+    `username` varchar NOT NULL,
+    `src_store` varchar NOT NULL,
+    `dst_store` varchar NOT NULL,
+    `status` varchar NOT NULL,
+    `total` int NOT NULL DEFAULT 0,
+    `added` int NOT NULL DEFAULT 0,
+    `skipped` int NOT NULL DEFAULT 0,
+    `failed` int NOT NULL DEFAULT 0,
+    `err` varchar NOT NULL DEFAULT '',
+    `cat` datetime NOT NULL DEFAULT (unixepoch()),
+    `uat` datetime NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (`id`)
+);
+
+CREATE INDEX IF NOT EXISTS `store_migration_job_idx_username_cat` ON `store_migration_job` (`username`, `cat`);
+CREATE INDEX IF NOT EXISTS `store_migration_job_idx_status` ON `store_migration_job` (`status`);
+
+CREATE TABLE IF NOT EXISTS `store_migration_item` (
+    `job_id` varchar NOT NULL,
+    `hash` varchar NOT NULL,
+    `name` varchar NOT NULL,
+    `cached` bool NOT NULL DEFAULT false,
+    `status` varchar NOT NULL,
+    `err` varchar NOT NULL DEFAULT '',
+    `uat` datetime NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (`job_id`, `hash`)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS `store_migration_item`;
+DROP INDEX IF EXISTS `store_migration_job_idx_status`;
+DROP INDEX IF EXISTS `store_migration_job_idx_username_cat`;
+DROP TABLE IF EXISTS `store_migration_job`;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS `worker_queue_item` (
+    `q` varchar NOT NULL,
+    `k` varchar NOT NULL,
+    `v` varchar NOT NULL,
+    `status` varchar NOT NULL,
+    `attempts` int NOT NULL DEFAULT 0,
+    `claim_id` varchar NOT NULL DEFAULT '',
+    `run_at` datetime NOT NULL,
+    `err` varchar NOT NULL DEFAULT '',
+    `cat` datetime NOT NULL DEFAULT (unixepoch()),
+    `uat` datetime NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (`q`, `k`)
+);
+
+CREATE INDEX IF NOT EXISTS `worker_queue_item_idx_q_status_run_at` ON `worker_queue_item` (`q`, `status`, `run_at`);
+CREATE INDEX IF NOT EXISTS `worker_queue_item_idx_q_claim_id` ON `worker_queue_item` (`q`, `claim_id`);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS `worker_queue_item_idx_q_claim_id`;
+DROP INDEX IF EXISTS `worker_queue_item_idx_q_status_run_at`;
+DROP TABLE IF EXISTS `worker_queue_item`;
+-- +goose StatementEnd
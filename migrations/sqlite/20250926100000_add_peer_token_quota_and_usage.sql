@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE `peer_token` ADD COLUMN `quota` int NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS `peer_token_usage` (
+    `id` varchar NOT NULL,
+    `requests` int NOT NULL DEFAULT 0,
+    `torrents_pushed` int NOT NULL DEFAULT 0,
+    `window_at` datetime NOT NULL,
+    `window_requests` int NOT NULL DEFAULT 0,
+    `instance_id` varchar NOT NULL DEFAULT '',
+    `last_seen_at` datetime NOT NULL DEFAULT (unixepoch()),
+    PRIMARY KEY (`id`)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS `peer_token_usage`;
+
+ALTER TABLE `peer_token` DROP COLUMN `quota`;
+-- +goose StatementEnd
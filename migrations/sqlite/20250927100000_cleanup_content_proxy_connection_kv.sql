@@ -0,0 +1,8 @@
+-- +goose Up
+-- +goose StatementBegin
+DELETE FROM `kv` WHERE `t` = 'cproxyconn' OR `t` LIKE 'cproxyconn:%';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+DELETE FROM `jellyfin_list_item`;
+DELETE FROM `jellyfin_item`;
+DELETE FROM `jellyfin_list`;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- +goose StatementEnd
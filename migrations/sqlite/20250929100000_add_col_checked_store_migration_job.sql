@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE `store_migration_job` ADD COLUMN `checked` bool NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE `store_migration_job` DROP COLUMN `checked`;
+-- +goose StatementEnd
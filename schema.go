@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"errors"
 	"log"
 	"os"
 
@@ -12,14 +13,11 @@ import (
 //go:embed migrations/**/*.sql
 var migrationsFS embed.FS
 
-func RunSchemaMigration(uri db.ConnectionURI, database *db.DB) {
-	l := log.New(os.Stderr, "=", 0)
-
+func setupSchemaMigration(uri db.ConnectionURI) (dir string) {
 	goose.SetBaseFS(migrationsFS)
 	goose.SetTableName("db_migration_version")
 	goose.SetLogger(log.New(os.Stderr, "=   ", 0))
 
-	dir := ""
 	switch uri.Dialect {
 	case db.DBDialectSQLite:
 		goose.SetDialect("sqlite")
@@ -28,6 +26,13 @@ func RunSchemaMigration(uri db.ConnectionURI, database *db.DB) {
 		goose.SetDialect("postgres")
 		dir = "migrations/postgres"
 	}
+	return dir
+}
+
+func RunSchemaMigration(uri db.ConnectionURI, database *db.DB) {
+	l := log.New(os.Stderr, "=", 0)
+
+	dir := setupSchemaMigration(uri)
 
 	lock := db.NewAdvisoryLock("goose", "migration")
 
@@ -55,3 +60,22 @@ func RunSchemaMigration(uri db.ConnectionURI, database *db.DB) {
 	l.Println()
 	l.Print("========================\n\n")
 }
+
+// RollbackSchemaMigration reverts the last applied migration.
+func RollbackSchemaMigration(uri db.ConnectionURI, database *db.DB) error {
+	dir := setupSchemaMigration(uri)
+
+	lock := db.NewAdvisoryLock("goose", "migration")
+
+	if !lock.Acquire() {
+		return errors.New("failed to acquire lock for migration")
+	}
+	defer lock.Release()
+
+	return goose.Down(database.DB, dir)
+}
+
+func PrintSchemaMigrationStatus(uri db.ConnectionURI, database *db.DB) error {
+	dir := setupSchemaMigration(uri)
+	return goose.Status(database.DB, dir)
+}
@@ -0,0 +1,138 @@
+// Package stremthru is the Go client for the StremThru HTTP API.
+package stremthru
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const Version = "0.1.0"
+
+const userAgent = "stremthru:sdk:go/" + Version
+
+var DefaultHTTPClient = &http.Client{
+	Timeout: 90 * time.Second,
+}
+
+type APIClientConfig struct {
+	BaseURL string // e.g. http://127.0.0.1:8080
+
+	// Proxy authorization, `username:password` or its base64 encoded form.
+	// The store token configured for the user is used unless StoreToken is
+	// set, and the preferred store unless StoreName is set.
+	ProxyAuth string
+
+	StoreName  StoreName
+	StoreToken string
+
+	ClientIP   string // forwarded to the store, unless set in params
+	HTTPClient *http.Client
+	UserAgent  string // appended to the default user agent
+}
+
+type APIClient struct {
+	BaseURL    *url.URL
+	HTTPClient *http.Client
+	proxyAuth  string
+	storeName  StoreName
+	storeToken string
+	clientIP   string
+	agent      string
+
+	reqHeader func(header http.Header, ctx *Ctx)
+
+	Store *StoreClient
+}
+
+func NewAPIClient(conf *APIClientConfig) *APIClient {
+	if conf.HTTPClient == nil {
+		conf.HTTPClient = DefaultHTTPClient
+	}
+
+	c := &APIClient{}
+
+	baseUrl, err := url.Parse(conf.BaseURL)
+	if err != nil {
+		panic(err)
+	}
+
+	c.BaseURL = baseUrl
+	c.HTTPClient = conf.HTTPClient
+	if conf.ProxyAuth != "" {
+		c.proxyAuth = conf.ProxyAuth
+		if strings.Contains(c.proxyAuth, ":") {
+			c.proxyAuth = base64.StdEncoding.EncodeToString([]byte(strings.TrimSpace(c.proxyAuth)))
+		}
+	}
+	c.storeName = conf.StoreName
+	c.storeToken = conf.StoreToken
+	c.clientIP = conf.ClientIP
+	c.agent = strings.TrimSpace(userAgent + " " + conf.UserAgent)
+
+	c.reqHeader = func(header http.Header, ctx *Ctx) {
+		header.Set("User-Agent", c.agent)
+		header.Set("Accept", "application/json")
+		if c.proxyAuth != "" {
+			header.Set("X-StremThru-Authorization", "Basic "+c.proxyAuth)
+		}
+		if c.storeName != "" {
+			header.Set("X-StremThru-Store-Name", string(c.storeName))
+		}
+		if token := ctx.getAPIKey(c.storeToken); token != "" {
+			header.Set("X-StremThru-Store-Authorization", "Bearer "+token)
+		}
+	}
+
+	c.Store = &StoreClient{client: c}
+
+	return c
+}
+
+func (c APIClient) Request(method, path string, params RequestParams, v ResponseEnvelop) (*http.Response, error) {
+	if params == nil {
+		params = &Ctx{}
+	}
+	req, err := params.getCtx().newRequest(c.BaseURL, method, path, c.reqHeader)
+	if err != nil {
+		return nil, newError("failed to create request", err)
+	}
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		error := newError("failed to send request", err)
+		error.Method = req.Method
+		error.Path = req.URL.Path
+		return nil, error
+	}
+	err = processResponseBody(res, v)
+	return res, err
+}
+
+func (c APIClient) getClientIP(clientIP string) string {
+	if clientIP != "" {
+		return clientIP
+	}
+	return c.clientIP
+}
+
+type HealthData struct {
+	Status string `json:"status"`
+}
+
+type HealthParams struct {
+	Ctx
+}
+
+func (c APIClient) Health(params *HealthParams) (*HealthData, error) {
+	if params == nil {
+		params = &HealthParams{}
+	}
+	response := &Response[HealthData]{}
+	_, err := c.Request("GET", "/v0/health", params, response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
@@ -0,0 +1,292 @@
+package stremthru
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/endpoint"
+	"github.com/MunifTanjim/stremthru/internal/shared"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	endpoint.AddHealthEndpoints(mux)
+	endpoint.AddMetaEndpoints(mux)
+	endpoint.AddProxyEndpoints(mux)
+	endpoint.AddStoreEndpoints(mux)
+	endpoint.AddTorrentEndpoints(mux)
+	server := httptest.NewServer(shared.RootServerContext(mux))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHealth(t *testing.T) {
+	server := newTestServer(t)
+	client := NewAPIClient(&APIClientConfig{BaseURL: server.URL})
+
+	data, err := client.Health(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", data.Status)
+}
+
+func TestErrors(t *testing.T) {
+	server := newTestServer(t)
+
+	type expected struct {
+		typ        ErrorType
+		code       ErrorCode
+		statusCode int
+		storeName  StoreName
+	}
+
+	for _, tc := range []struct {
+		name     string
+		conf     APIClientConfig
+		call     func(c *APIClient) error
+		expected expected
+	}{
+		{
+			name: "missing store",
+			call: func(c *APIClient) error {
+				_, err := c.Store.GetUser(nil)
+				return err
+			},
+			expected: expected{ErrorTypeAPI, ErrorCodeBadRequest, 400, ""},
+		},
+		{
+			name: "invalid store name",
+			conf: APIClientConfig{StoreName: "foo", StoreToken: "token"},
+			call: func(c *APIClient) error {
+				_, err := c.Store.GetUser(nil)
+				return err
+			},
+			expected: expected{ErrorTypeStore, ErrorCodeStoreNameInvalid, 400, "foo"},
+		},
+		{
+			name: "missing store token",
+			conf: APIClientConfig{StoreName: StoreNameAlldebrid},
+			call: func(c *APIClient) error {
+				_, err := c.Store.GetUser(nil)
+				return err
+			},
+			expected: expected{ErrorTypeAPI, ErrorCodeUnauthorized, 401, "alldebrid"},
+		},
+		{
+			name: "store token in params",
+			conf: APIClientConfig{StoreName: StoreNameAlldebrid},
+			call: func(c *APIClient) error {
+				params := &ListMagnetsParams{SortBy: "foo"}
+				params.APIKey = "token"
+				_, err := c.Store.ListMagnets(params)
+				return err
+			},
+			expected: expected{ErrorTypeAPI, ErrorCodeBadRequest, 400, "alldebrid"},
+		},
+		{
+			name: "store error",
+			conf: APIClientConfig{StoreName: StoreNameLocal, StoreToken: "user:pass"},
+			call: func(c *APIClient) error {
+				_, err := c.Store.GetUser(nil)
+				return err
+			},
+			expected: expected{ErrorTypeStore, ErrorCodeForbidden, 403, "local"},
+		},
+		{
+			name: "file selection not supported",
+			conf: APIClientConfig{StoreName: StoreNameAlldebrid, StoreToken: "token"},
+			call: func(c *APIClient) error {
+				_, err := c.Store.AddMagnet(&AddMagnetParams{
+					Magnet: "magnet:?xt=urn:btih:0000000000000000000000000000000000000000",
+					Files:  &MagnetFileSelection{Mode: MagnetFileSelectionModeVideo},
+				})
+				return err
+			},
+			expected: expected{ErrorTypeStore, ErrorCodeNotImplemented, 501, "alldebrid"},
+		},
+		{
+			name: "proxy without authorization",
+			call: func(c *APIClient) error {
+				_, err := c.ProxifyLinks(&ProxifyLinksParams{Links: []string{"https://example.com/video.mkv"}})
+				return err
+			},
+			expected: expected{ErrorTypeAPI, ErrorCodeForbidden, 403, ""},
+		},
+		{
+			name: "unsupported sid",
+			call: func(c *APIClient) error {
+				_, err := c.ListTorrents(&ListTorrentsParams{SId: "kitsu:1"})
+				return err
+			},
+			expected: expected{ErrorTypeAPI, ErrorCodeBadRequest, 400, ""},
+		},
+		{
+			name: "invalid id type",
+			call: func(c *APIClient) error {
+				_, err := c.GetIdMap(&GetIdMapParams{Type: "episode", Id: "tt0000000"})
+				return err
+			},
+			expected: expected{ErrorTypeAPI, ErrorCodeBadRequest, 400, ""},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := tc.conf
+			conf.BaseURL = server.URL
+			err := tc.call(NewAPIClient(&conf))
+
+			var e *Error
+			if !assert.ErrorAs(t, err, &e) {
+				return
+			}
+			assert.Equal(t, tc.expected.typ, e.Type)
+			assert.Equal(t, tc.expected.code, e.Code)
+			assert.Equal(t, tc.expected.statusCode, e.StatusCode)
+			assert.Equal(t, tc.expected.storeName, e.StoreName)
+			assert.NotEmpty(t, e.RequestId)
+		})
+	}
+}
+
+func TestNonJSONError(t *testing.T) {
+	server := newTestServer(t)
+	client := NewAPIClient(&APIClientConfig{BaseURL: server.URL})
+
+	_, err := client.Request("GET", "/v0/unknown", nil, &Response[any]{})
+	var e *Error
+	if assert.ErrorAs(t, err, &e) {
+		assert.Equal(t, ErrorTypeUnknown, e.Type)
+		assert.Equal(t, 404, e.StatusCode)
+	}
+}
+
+func TestContext(t *testing.T) {
+	server := newTestServer(t)
+	client := NewAPIClient(&APIClientConfig{BaseURL: server.URL})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	params := &HealthParams{}
+	params.Context = ctx
+	_, err := client.Health(params)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRequest(t *testing.T) {
+	var req *http.Request
+	var body map[string]any
+	server := httptest.NewServer(shared.RootServerContext(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		body = nil
+		if r.Header.Get("Content-Type") == "application/json" {
+			json.NewDecoder(r.Body).Decode(&body)
+		} else {
+			r.ParseForm()
+		}
+		shared.SendResponse(w, r, 200, map[string]any{}, nil)
+	})))
+	defer server.Close()
+
+	client := NewAPIClient(&APIClientConfig{
+		BaseURL:    server.URL,
+		ProxyAuth:  "user:pass",
+		StoreName:  StoreNameRealDebrid,
+		StoreToken: "token",
+		ClientIP:   "127.0.0.1",
+		UserAgent:  "test",
+	})
+
+	t.Run("headers", func(t *testing.T) {
+		_, err := client.Store.GetUser(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "Basic dXNlcjpwYXNz", req.Header.Get("X-StremThru-Authorization"))
+		assert.Equal(t, "realdebrid", req.Header.Get("X-StremThru-Store-Name"))
+		assert.Equal(t, "Bearer token", req.Header.Get("X-StremThru-Store-Authorization"))
+		assert.Equal(t, "stremthru:sdk:go/"+Version+" test", req.Header.Get("User-Agent"))
+
+		params := &GetUserParams{}
+		params.APIKey = "other-token"
+		_, err = client.Store.GetUser(params)
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer other-token", req.Header.Get("X-StremThru-Store-Authorization"))
+	})
+
+	t.Run("add magnet", func(t *testing.T) {
+		_, err := client.Store.AddMagnet(&AddMagnetParams{
+			Magnet:   "magnet:?xt=urn:btih:0000000000000000000000000000000000000000",
+			ClientIP: "10.0.0.1",
+			Files:    &MagnetFileSelection{Indices: []int{1, 2}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "POST", req.Method)
+		assert.Equal(t, "/v0/store/magnets", req.URL.Path)
+		assert.Equal(t, "10.0.0.1", req.URL.Query().Get("client_ip"))
+		assert.Equal(t, map[string]any{
+			"magnet": "magnet:?xt=urn:btih:0000000000000000000000000000000000000000",
+			"files":  map[string]any{"indices": []any{float64(1), float64(2)}},
+		}, body)
+	})
+
+	t.Run("check magnet", func(t *testing.T) {
+		_, err := client.Store.CheckMagnet(&CheckMagnetParams{
+			Magnets: []string{"magnet:?xt=urn:btih:0000000000000000000000000000000000000000", "1111111111111111111111111111111111111111"},
+			SId:     "tt0000000",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "/v0/store/magnets/check", req.URL.Path)
+		query := req.URL.Query()
+		assert.Len(t, query["magnet"], 2)
+		assert.Equal(t, "tt0000000", query.Get("sid"))
+		assert.Equal(t, "127.0.0.1", query.Get("client_ip"))
+	})
+
+	t.Run("list magnets", func(t *testing.T) {
+		_, err := client.Store.ListMagnets(&ListMagnetsParams{
+			Limit:      50,
+			Offset:     100,
+			Query:      "big buck",
+			Status:     []MagnetStatus{MagnetStatusDownloaded, MagnetStatusCached},
+			AddedAfter: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC),
+			SortBy:     ListMagnetsSortBySize,
+			SortDesc:   true,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "GET", req.Method)
+		assert.Equal(t, "/v0/store/magnets", req.URL.Path)
+		query := req.URL.Query()
+		assert.Equal(t, "50", query.Get("limit"))
+		assert.Equal(t, "100", query.Get("offset"))
+		assert.Equal(t, "big buck", query.Get("q"))
+		assert.Equal(t, "downloaded,cached", query.Get("status"))
+		assert.Equal(t, "2025-10-01T00:00:00Z", query.Get("added_after"))
+		assert.False(t, query.Has("added_before"))
+		assert.Equal(t, "-size", query.Get("sort"))
+	})
+
+	t.Run("remove magnet", func(t *testing.T) {
+		_, err := client.Store.RemoveMagnet(&RemoveMagnetParams{Id: "a/b"})
+		assert.NoError(t, err)
+		assert.Equal(t, "DELETE", req.Method)
+		assert.Equal(t, "/v0/store/magnets/a%2Fb", req.URL.EscapedPath())
+	})
+
+	t.Run("proxify links", func(t *testing.T) {
+		_, err := client.ProxifyLinks(&ProxifyLinksParams{
+			Links:     []string{"https://example.com/a.mkv", "https://example.com/b.mkv"},
+			Headers:   []map[string]string{nil, {"Referer": "https://example.com"}},
+			Filenames: []string{"a.mkv"},
+			ExpiresIn: 1 * time.Hour,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "POST", req.Method)
+		assert.Equal(t, []string{"https://example.com/a.mkv", "https://example.com/b.mkv"}, req.PostForm["url"])
+		assert.False(t, req.PostForm.Has("req_headers[0]"))
+		assert.Equal(t, "Referer: https://example.com", req.PostForm.Get("req_headers[1]"))
+		assert.Equal(t, "a.mkv", req.PostForm.Get("filename[0]"))
+		assert.Equal(t, "3600", req.PostForm.Get("exp"))
+	})
+}
@@ -0,0 +1,109 @@
+package stremthru
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Ctx is embedded in the params of every request.
+type Ctx struct {
+	APIKey  string          `json:"-"` // store token, overrides APIClientConfig.StoreToken
+	Context context.Context `json:"-"` // default: context.Background()
+	Form    *url.Values     `json:"-"`
+	JSON    any             `json:"-"`
+	Headers *http.Header    `json:"-"`
+	Query   *url.Values     `json:"-"`
+}
+
+// RequestParams is implemented by the params types by embedding Ctx.
+type RequestParams interface {
+	getCtx() *Ctx
+}
+
+func (ctx *Ctx) getCtx() *Ctx {
+	return ctx
+}
+
+func (ctx *Ctx) getAPIKey(fallbackAPIKey string) string {
+	if ctx.APIKey != "" {
+		return ctx.APIKey
+	}
+	return fallbackAPIKey
+}
+
+func (ctx *Ctx) getContext() context.Context {
+	if ctx.Context == nil {
+		return context.Background()
+	}
+	return ctx.Context
+}
+
+func (ctx *Ctx) prepareBody(method string, query url.Values) (body io.Reader, contentType string, err error) {
+	if ctx.JSON != nil {
+		jsonBytes, err := json.Marshal(ctx.JSON)
+		if err != nil {
+			return nil, "", err
+		}
+		body = bytes.NewBuffer(jsonBytes)
+		contentType = "application/json"
+	}
+	if ctx.Form != nil {
+		if method == http.MethodHead || method == http.MethodGet || ctx.JSON != nil {
+			for key, values := range *ctx.Form {
+				for _, value := range values {
+					query.Add(key, value)
+				}
+			}
+		} else {
+			body = strings.NewReader(ctx.Form.Encode())
+			contentType = "application/x-www-form-urlencoded"
+		}
+	}
+	return body, contentType, nil
+}
+
+func (ctx *Ctx) newRequest(baseURL *url.URL, method, path string, header func(header http.Header, ctx *Ctx)) (*http.Request, error) {
+	reqUrl := baseURL.JoinPath(path)
+
+	query := reqUrl.Query()
+	if ctx.Query != nil {
+		for key, values := range *ctx.Query {
+			for _, value := range values {
+				query.Add(key, value)
+			}
+		}
+	}
+
+	body, contentType, err := ctx.prepareBody(method, query)
+	if err != nil {
+		return nil, err
+	}
+
+	reqUrl.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx.getContext(), method, reqUrl.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	if header != nil {
+		header(req.Header, ctx)
+	}
+	if ctx.Headers != nil {
+		for key, values := range *ctx.Headers {
+			for _, value := range values {
+				req.Header.Add(key, value)
+			}
+		}
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return req, nil
+}
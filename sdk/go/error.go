@@ -0,0 +1,75 @@
+package stremthru
+
+import (
+	"encoding/json"
+)
+
+type ErrorType string
+
+const (
+	ErrorTypeAPI      ErrorType = "api_error"
+	ErrorTypeStore    ErrorType = "store_error"
+	ErrorTypeUpstream ErrorType = "upstream_error"
+	ErrorTypeUnknown  ErrorType = "unknown_error"
+)
+
+type ErrorCode string
+
+const (
+	ErrorCodeUnknown ErrorCode = "UNKNOWN"
+
+	ErrorCodeBadGateway                  ErrorCode = "BAD_GATEWAY"
+	ErrorCodeBadRequest                  ErrorCode = "BAD_REQUEST"
+	ErrorCodeConflict                    ErrorCode = "CONFLICT"
+	ErrorCodeForbidden                   ErrorCode = "FORBIDDEN"
+	ErrorCodeGone                        ErrorCode = "GONE"
+	ErrorCodeInternalServerError         ErrorCode = "INTERNAL_SERVER_ERROR"
+	ErrorCodeMethodNotAllowed            ErrorCode = "METHOD_NOT_ALLOWED"
+	ErrorCodeNotFound                    ErrorCode = "NOT_FOUND"
+	ErrorCodeNotImplemented              ErrorCode = "NOT_IMPLEMENTED"
+	ErrorCodePaymentRequired             ErrorCode = "PAYMENT_REQUIRED"
+	ErrorCodeProxyAuthenticationRequired ErrorCode = "PROXY_AUTHENTICATION_REQUIRED"
+	ErrorCodeServiceUnavailable          ErrorCode = "SERVICE_UNAVAILABLE"
+	ErrorCodeTooManyRequests             ErrorCode = "TOO_MANY_REQUESTS"
+	ErrorCodeUnauthorized                ErrorCode = "UNAUTHORIZED"
+	ErrorCodeUnavailableForLegalReasons  ErrorCode = "UNAVAILABLE_FOR_LEGAL_REASONS"
+	ErrorCodeUnprocessableEntity         ErrorCode = "UNPROCESSABLE_ENTITY"
+	ErrorCodeUnsupportedMediaType        ErrorCode = "UNSUPPORTED_MEDIA_TYPE"
+
+	ErrorCodeStoreLimitExceeded ErrorCode = "STORE_LIMIT_EXCEEDED"
+	ErrorCodeStoreMagnetInvalid ErrorCode = "STORE_MAGNET_INVALID"
+	ErrorCodeStoreNameInvalid   ErrorCode = "STORE_NAME_INVALID"
+)
+
+// Error is returned for the errors sent by the server, and for the ones
+// encountered while sending the request or reading the response, with
+// ErrorTypeUnknown. The `__cause__` and `__upstream_cause__` fields sent
+// by the server are not decoded.
+type Error struct {
+	RequestId  string    `json:"request_id"`
+	Type       ErrorType `json:"type"`
+	Code       ErrorCode `json:"code,omitempty"`
+	Msg        string    `json:"message"`
+	Method     string    `json:"method,omitempty"`
+	Path       string    `json:"path,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+	StoreName  StoreName `json:"store_name,omitempty"`
+
+	Cause error `json:"-"`
+}
+
+func newError(msg string, cause error) *Error {
+	return &Error{Type: ErrorTypeUnknown, Code: ErrorCodeUnknown, Msg: msg, Cause: cause}
+}
+
+func (e *Error) Error() string {
+	ret, _ := json.Marshal(e)
+	if e.Cause != nil {
+		return string(ret) + ": " + e.Cause.Error()
+	}
+	return string(ret)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
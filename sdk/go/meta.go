@@ -0,0 +1,49 @@
+package stremthru
+
+import (
+	"net/url"
+)
+
+type IdType string
+
+const (
+	IdTypeMovie IdType = "movie"
+	IdTypeShow  IdType = "show"
+)
+
+type IdMapAnime struct {
+	AniDB       string `json:"anidb,omitempty"`
+	AniList     string `json:"anilist,omitempty"`
+	AniSearch   string `json:"anisearch,omitempty"`
+	AnimePlanet string `json:"animeplanet,omitempty"`
+	Kitsu       string `json:"kitsu,omitempty"`
+	LiveChart   string `json:"livechart,omitempty"`
+	MAL         string `json:"mal,omitempty"`
+	NotifyMoe   string `json:"notifymoe,omitempty"`
+}
+
+type IdMap struct {
+	Type       IdType      `json:"type"`
+	IMDB       string      `json:"imdb,omitempty"`
+	TMDB       string      `json:"tmdb,omitempty"`
+	TVDB       string      `json:"tvdb,omitempty"`
+	TVMaze     string      `json:"tvmaze,omitempty"`
+	Trakt      string      `json:"trakt,omitempty"`
+	Letterboxd string      `json:"lboxd,omitempty"`
+	Anime      *IdMapAnime `json:"anime,omitempty"`
+}
+
+type GetIdMapParams struct {
+	Ctx
+	Type IdType
+	Id   string // e.g. tt0000000 or tvdb:0000
+}
+
+func (c APIClient) GetIdMap(params *GetIdMapParams) (*IdMap, error) {
+	response := &Response[IdMap]{}
+	_, err := c.Request("GET", "/v0/meta/id-map/"+url.PathEscape(string(params.Type))+"/"+url.PathEscape(params.Id), params, response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
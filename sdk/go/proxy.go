@@ -0,0 +1,50 @@
+package stremthru
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ProxifyLinksParams struct {
+	Ctx
+	Links     []string
+	Headers   []map[string]string // request headers, per link
+	Filenames []string            // per link
+	ExpiresIn time.Duration       // default: never
+}
+
+type ProxifyLinksData struct {
+	Items      []string `json:"items"`
+	TotalItems int      `json:"total_items"`
+}
+
+// ProxifyLinks creates content proxy links using `/v0/proxy`. It requires
+// proxy authorization.
+func (c APIClient) ProxifyLinks(params *ProxifyLinksParams) (*ProxifyLinksData, error) {
+	form := &url.Values{"url": params.Links}
+	for i := range params.Links {
+		idx := strconv.Itoa(i)
+		if i < len(params.Headers) && len(params.Headers[i]) > 0 {
+			headers := make([]string, 0, len(params.Headers[i]))
+			for k, v := range params.Headers[i] {
+				headers = append(headers, k+": "+v)
+			}
+			form.Set("req_headers["+idx+"]", strings.Join(headers, "\n"))
+		}
+		if i < len(params.Filenames) && params.Filenames[i] != "" {
+			form.Set("filename["+idx+"]", params.Filenames[i])
+		}
+	}
+	if params.ExpiresIn > 0 {
+		form.Set("exp", strconv.Itoa(int(params.ExpiresIn.Seconds())))
+	}
+	params.Form = form
+	response := &Response[ProxifyLinksData]{}
+	_, err := c.Request("POST", "/v0/proxy", params, response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
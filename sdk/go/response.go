@@ -0,0 +1,58 @@
+package stremthru
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type ResponseEnvelop interface {
+	GetError() error
+}
+
+type Response[D any] struct {
+	Data  D      `json:"data,omitempty"`
+	Error *Error `json:"error,omitempty"`
+}
+
+func (r Response[any]) GetError() error {
+	if r.Error == nil {
+		return nil
+	}
+	return r.Error
+}
+
+func newResponseError(res *http.Response, cause error) *Error {
+	err := newError(http.StatusText(res.StatusCode), cause)
+	err.StatusCode = res.StatusCode
+	err.RequestId = res.Header.Get("Request-ID")
+	return err
+}
+
+func processResponseBody(res *http.Response, v ResponseEnvelop) error {
+	body, err := io.ReadAll(res.Body)
+	defer res.Body.Close()
+
+	if err != nil {
+		error := newError("failed to read response", err)
+		error.StatusCode = res.StatusCode
+		return error
+	}
+
+	if res.StatusCode != http.StatusNoContent || len(strings.TrimSpace(string(body))) != 0 {
+		if err := json.Unmarshal(body, v); err != nil {
+			return newResponseError(res, err)
+		}
+	}
+
+	if err := v.GetError(); err != nil {
+		return err
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return newResponseError(res, nil)
+	}
+
+	return nil
+}
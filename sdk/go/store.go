@@ -0,0 +1,403 @@
+package stremthru
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type StoreName string
+
+const (
+	StoreNameAlldebrid    StoreName = "alldebrid"
+	StoreNameDebrider     StoreName = "debrider"
+	StoreNameDebridLink   StoreName = "debridlink"
+	StoreNameEasyDebrid   StoreName = "easydebrid"
+	StoreNameLocal        StoreName = "local"
+	StoreNameOffcloud     StoreName = "offcloud"
+	StoreNamePikPak       StoreName = "pikpak"
+	StoreNamePremiumize   StoreName = "premiumize"
+	StoreNameQBittorrent  StoreName = "qbittorrent"
+	StoreNameRealDebrid   StoreName = "realdebrid"
+	StoreNameTorBox       StoreName = "torbox"
+	StoreNameTransmission StoreName = "transmission"
+)
+
+type UserSubscriptionStatus string
+
+const (
+	UserSubscriptionStatusPremium UserSubscriptionStatus = "premium"
+	UserSubscriptionStatusTrial   UserSubscriptionStatus = "trial"
+	UserSubscriptionStatusExpired UserSubscriptionStatus = "expired"
+)
+
+type User struct {
+	Id                 string                 `json:"id"`
+	Email              string                 `json:"email"`
+	SubscriptionStatus UserSubscriptionStatus `json:"subscription_status"`
+	Plan               string                 `json:"plan,omitempty"`
+	PremiumUntil       *time.Time             `json:"premium_until,omitempty"`
+	Points             *int                   `json:"points,omitempty"`
+	ActiveSlots        *int                   `json:"active_slots,omitempty"`
+	MaxSlots           *int                   `json:"max_slots,omitempty"`
+}
+
+type GetUserParams struct {
+	Ctx
+}
+
+type MagnetFile struct {
+	Idx       int    `json:"index"`
+	Link      string `json:"link,omitempty"`
+	Path      string `json:"path"`
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	VideoHash string `json:"video_hash,omitempty"`
+	Source    string `json:"source,omitempty"`
+}
+
+type MagnetStatus string
+
+const (
+	MagnetStatusCached      MagnetStatus = "cached" // cached in store, ready to download instantly
+	MagnetStatusQueued      MagnetStatus = "queued"
+	MagnetStatusDownloading MagnetStatus = "downloading"
+	MagnetStatusProcessing  MagnetStatus = "processing" // compressing / moving
+	MagnetStatusDownloaded  MagnetStatus = "downloaded"
+	MagnetStatusUploading   MagnetStatus = "uploading"
+	MagnetStatusFailed      MagnetStatus = "failed"
+	MagnetStatusInvalid     MagnetStatus = "invalid"
+	MagnetStatusUnknown     MagnetStatus = "unknown"
+)
+
+type CheckMagnetParams struct {
+	Ctx
+	Magnets   []string
+	ClientIP  string
+	SId       string
+	LocalOnly bool
+}
+
+type CheckMagnetDataItem struct {
+	Hash   string       `json:"hash"`
+	Magnet string       `json:"magnet"`
+	Status MagnetStatus `json:"status"`
+	Files  []MagnetFile `json:"files"`
+}
+
+type CheckMagnetData struct {
+	Items []CheckMagnetDataItem `json:"items"`
+}
+
+type MagnetFileSelectionMode string
+
+const (
+	MagnetFileSelectionModeVideo MagnetFileSelectionMode = "video"
+	MagnetFileSelectionModeAll   MagnetFileSelectionMode = "all"
+)
+
+// MagnetFileSelection selects the files to download. A file is selected if
+// it matches the mode, any of the indices or any of the patterns.
+type MagnetFileSelection struct {
+	Mode     MagnetFileSelectionMode `json:"mode,omitempty"`
+	Indices  []int                   `json:"indices,omitempty"`  // MagnetFile.Idx
+	Patterns []string                `json:"patterns,omitempty"` // glob, matched against path and name
+}
+
+type AddMagnetParams struct {
+	Ctx
+	Magnet   string
+	ClientIP string
+	Files    *MagnetFileSelection // default: store behavior
+}
+
+type AddMagnetData struct {
+	Id      string       `json:"id"`
+	Hash    string       `json:"hash"`
+	Magnet  string       `json:"magnet"`
+	Name    string       `json:"name"`
+	Size    int64        `json:"size"`
+	Status  MagnetStatus `json:"status"`
+	Files   []MagnetFile `json:"files"`
+	AddedAt time.Time    `json:"added_at"`
+}
+
+type GetMagnetParams struct {
+	Ctx
+	Id       string
+	ClientIP string
+}
+
+type GetMagnetData struct {
+	Id      string       `json:"id"`
+	Name    string       `json:"name"`
+	Hash    string       `json:"hash"`
+	Size    int64        `json:"size"`
+	Status  MagnetStatus `json:"status"`
+	Files   []MagnetFile `json:"files"`
+	AddedAt time.Time    `json:"added_at"`
+}
+
+type ListMagnetsSortBy string
+
+const (
+	ListMagnetsSortByAddedAt ListMagnetsSortBy = "added_at"
+	ListMagnetsSortByName    ListMagnetsSortBy = "name"
+	ListMagnetsSortBySize    ListMagnetsSortBy = "size"
+)
+
+type ListMagnetsParams struct {
+	Ctx
+	Limit    int // min 1, max 500, default 100
+	Offset   int // default 0
+	ClientIP string
+
+	Query       string         // match all the words in name, case-insensitive
+	Status      []MagnetStatus // match any of the statuses
+	AddedAfter  time.Time
+	AddedBefore time.Time
+	SortBy      ListMagnetsSortBy // default: store order
+	SortDesc    bool
+}
+
+type ListMagnetsDataItem struct {
+	Id      string       `json:"id"`
+	Hash    string       `json:"hash"`
+	Name    string       `json:"name"`
+	Size    int64        `json:"size"`
+	Status  MagnetStatus `json:"status"`
+	AddedAt time.Time    `json:"added_at"`
+}
+
+type ListMagnetsData struct {
+	Items       []ListMagnetsDataItem `json:"items"`
+	TotalItems  int                   `json:"total_items"`
+	IsTruncated bool                  `json:"is_truncated,omitempty"` // only a part of the magnets are queried
+}
+
+type RemoveMagnetParams struct {
+	Ctx
+	Id string
+}
+
+type RemoveMagnetData struct {
+	Id string `json:"id"`
+}
+
+type GenerateLinkParams struct {
+	Ctx
+	Link     string
+	ClientIP string
+}
+
+type GenerateLinkData struct {
+	Link string `json:"link"`
+}
+
+type UnrestrictLinkParams struct {
+	Ctx
+	Link     string
+	Password string
+	ClientIP string
+}
+
+type UnrestrictLinkData struct {
+	Link string `json:"link"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	Host string `json:"host"`
+}
+
+type ListHostsParams struct {
+	Ctx
+}
+
+type ListHostsData struct {
+	Items []string `json:"items"` // host domains, e.g. 1fichier.com
+}
+
+// StoreClient wraps the `/v0/store/*` endpoints. The store token in
+// params.APIKey, if set, overrides the one in APIClientConfig.
+type StoreClient struct {
+	client *APIClient
+}
+
+// GetName returns the configured store name, which is empty when the
+// preferred store for the proxy authorized user is used.
+func (c *StoreClient) GetName() StoreName {
+	return c.client.storeName
+}
+
+func (c *StoreClient) GetUser(params *GetUserParams) (*User, error) {
+	if params == nil {
+		params = &GetUserParams{}
+	}
+	response := &Response[User]{}
+	_, err := c.client.Request("GET", "/v0/store/user", params, response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+func (c *StoreClient) CheckMagnet(params *CheckMagnetParams) (*CheckMagnetData, error) {
+	params.Query = &url.Values{"magnet": params.Magnets}
+	if clientIP := c.client.getClientIP(params.ClientIP); clientIP != "" {
+		params.Query.Set("client_ip", clientIP)
+	}
+	if params.SId != "" {
+		params.Query.Set("sid", params.SId)
+	}
+	if params.LocalOnly {
+		params.Query.Set("local_only", "1")
+	}
+	response := &Response[CheckMagnetData]{}
+	_, err := c.client.Request("GET", "/v0/store/magnets/check", params, response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+type addMagnetPayload struct {
+	Magnet string               `json:"magnet"`
+	Files  *MagnetFileSelection `json:"files,omitempty"`
+}
+
+func (c *StoreClient) AddMagnet(params *AddMagnetParams) (*AddMagnetData, error) {
+	params.Query = &url.Values{}
+	if clientIP := c.client.getClientIP(params.ClientIP); clientIP != "" {
+		params.Query.Set("client_ip", clientIP)
+	}
+	params.JSON = &addMagnetPayload{
+		Magnet: params.Magnet,
+		Files:  params.Files,
+	}
+	response := &Response[AddMagnetData]{}
+	_, err := c.client.Request("POST", "/v0/store/magnets", params, response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+func (c *StoreClient) GetMagnet(params *GetMagnetParams) (*GetMagnetData, error) {
+	params.Query = &url.Values{}
+	if clientIP := c.client.getClientIP(params.ClientIP); clientIP != "" {
+		params.Query.Set("client_ip", clientIP)
+	}
+	response := &Response[GetMagnetData]{}
+	_, err := c.client.Request("GET", "/v0/store/magnets/"+url.PathEscape(params.Id), params, response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+func (c *StoreClient) ListMagnets(params *ListMagnetsParams) (*ListMagnetsData, error) {
+	// params.Query is the search query, shadowing params.Ctx.Query
+	query := &url.Values{}
+	if params.Limit != 0 {
+		query.Set("limit", strconv.Itoa(params.Limit))
+	}
+	if params.Offset != 0 {
+		query.Set("offset", strconv.Itoa(params.Offset))
+	}
+	if clientIP := c.client.getClientIP(params.ClientIP); clientIP != "" {
+		query.Set("client_ip", clientIP)
+	}
+	if params.Query != "" {
+		query.Set("q", params.Query)
+	}
+	if len(params.Status) > 0 {
+		statuses := make([]string, len(params.Status))
+		for i, status := range params.Status {
+			statuses[i] = string(status)
+		}
+		query.Set("status", strings.Join(statuses, ","))
+	}
+	if !params.AddedAfter.IsZero() {
+		query.Set("added_after", params.AddedAfter.UTC().Format(time.RFC3339))
+	}
+	if !params.AddedBefore.IsZero() {
+		query.Set("added_before", params.AddedBefore.UTC().Format(time.RFC3339))
+	}
+	if params.SortBy != "" {
+		sort := string(params.SortBy)
+		if params.SortDesc {
+			sort = "-" + sort
+		}
+		query.Set("sort", sort)
+	}
+	params.Ctx.Query = query
+	response := &Response[ListMagnetsData]{}
+	_, err := c.client.Request("GET", "/v0/store/magnets", params, response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+func (c *StoreClient) RemoveMagnet(params *RemoveMagnetParams) (*RemoveMagnetData, error) {
+	response := &Response[RemoveMagnetData]{}
+	_, err := c.client.Request("DELETE", "/v0/store/magnets/"+url.PathEscape(params.Id), params, response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+type generateLinkPayload struct {
+	Link string `json:"link"`
+}
+
+func (c *StoreClient) GenerateLink(params *GenerateLinkParams) (*GenerateLinkData, error) {
+	params.Query = &url.Values{}
+	if clientIP := c.client.getClientIP(params.ClientIP); clientIP != "" {
+		params.Query.Set("client_ip", clientIP)
+	}
+	params.JSON = &generateLinkPayload{
+		Link: params.Link,
+	}
+	response := &Response[GenerateLinkData]{}
+	_, err := c.client.Request("POST", "/v0/store/link/generate", params, response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+type unrestrictLinkPayload struct {
+	Link     string `json:"link"`
+	Password string `json:"password,omitempty"`
+}
+
+func (c *StoreClient) UnrestrictLink(params *UnrestrictLinkParams) (*UnrestrictLinkData, error) {
+	params.Query = &url.Values{}
+	if clientIP := c.client.getClientIP(params.ClientIP); clientIP != "" {
+		params.Query.Set("client_ip", clientIP)
+	}
+	params.JSON = &unrestrictLinkPayload{
+		Link:     params.Link,
+		Password: params.Password,
+	}
+	response := &Response[UnrestrictLinkData]{}
+	_, err := c.client.Request("POST", "/v0/store/link/unrestrict", params, response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
+
+func (c *StoreClient) ListHosts(params *ListHostsParams) (*ListHostsData, error) {
+	if params == nil {
+		params = &ListHostsParams{}
+	}
+	response := &Response[ListHostsData]{}
+	_, err := c.client.Request("GET", "/v0/store/link/hosts", params, response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
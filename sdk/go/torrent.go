@@ -0,0 +1,55 @@
+package stremthru
+
+import (
+	"net/url"
+)
+
+type TorrentFile struct {
+	Path      string `json:"p"`
+	Idx       int    `json:"i"`
+	Size      int64  `json:"s"`
+	Name      string `json:"n"`
+	SId       string `json:"sid,omitempty"`
+	ASId      string `json:"asid,omitempty"`
+	Source    string `json:"src,omitempty"`
+	VideoHash string `json:"vhash,omitempty"`
+}
+
+type Torrent struct {
+	Hash     string        `json:"hash"`
+	Name     string        `json:"name"`
+	Size     int64         `json:"size"`
+	Source   string        `json:"src"`
+	Category string        `json:"category"`
+	Seeders  int           `json:"seeders"`
+	Leechers int           `json:"leechers"`
+	Files    []TorrentFile `json:"files"`
+}
+
+type ListTorrentsData struct {
+	Items      []Torrent `json:"items"`
+	TotalItems int       `json:"total_items"`
+}
+
+type ListTorrentsParams struct {
+	Ctx
+	SId           string // e.g. tt0000000, tt0000000:1:2 or anidb:0000
+	LocalOnly     bool
+	NoMissingSize bool
+}
+
+func (c APIClient) ListTorrents(params *ListTorrentsParams) (*ListTorrentsData, error) {
+	params.Query = &url.Values{"sid": []string{params.SId}}
+	if params.LocalOnly {
+		params.Query.Set("local_only", "1")
+	}
+	if params.NoMissingSize {
+		params.Query.Set("no_missing_size", "1")
+	}
+	response := &Response[ListTorrentsData]{}
+	_, err := c.Request("GET", "/v0/torrents", params, response)
+	if err != nil {
+		return nil, err
+	}
+	return &response.Data, nil
+}
@@ -12,6 +12,10 @@ import (
 
 var DefaultHTTPClient = config.DefaultHTTPClient
 
+var requestClient = request.NewClient(&request.ClientConfig{
+	RequestsPerMinute: config.StoreRateLimit.Get(string(store.StoreNameAlldebrid)),
+})
+
 type APIClientConfig struct {
 	BaseURL    string // default: https://api.alldebrid.com
 	APIKey     string
@@ -78,7 +82,7 @@ func (c APIClient) Request(method, path string, params request.Context, v Respon
 		error.Cause = err
 		return nil, error
 	}
-	res, err := c.HTTPClient.Do(req)
+	res, err := requestClient.Do(c.HTTPClient, req, params.GetAPIKey(c.apiKey))
 	err = processResponseBody(res, err, v)
 	if err != nil {
 		err := UpstreamErrorWithCause(err)
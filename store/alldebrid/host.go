@@ -0,0 +1,17 @@
+package alldebrid
+
+type GetHostDomainsData struct {
+	Hosts       []string `json:"hosts"`
+	Streams     []string `json:"streams"`
+	Redirectors []string `json:"redirectors"`
+}
+
+type GetHostDomainsParams struct {
+	Ctx
+}
+
+func (c APIClient) GetHostDomains(params *GetHostDomainsParams) (APIResponse[GetHostDomainsData], error) {
+	response := &Response[GetHostDomainsData]{}
+	res, err := c.Request("GET", "/v4/hosts/domains", params, response)
+	return newAPIResponse(res, response.Data), err
+}
@@ -62,9 +62,11 @@ func (c *StoreClient) GetUser(params *store.GetUserParams) (*store.User, error)
 		return nil, err
 	}
 	data := &store.User{
-		Id:    res.Data.Username,
-		Email: res.Data.Email,
+		Id:     res.Data.Username,
+		Email:  res.Data.Email,
+		Points: &res.Data.FidelityPoints,
 	}
+	data.SetPremiumUntil(time.Unix(int64(res.Data.PremiumUntil), 0))
 	if res.Data.IsPremium {
 		data.SubscriptionStatus = store.UserSubscriptionStatusPremium
 	} else if res.Data.IsTrial {
@@ -339,3 +341,71 @@ func (c *StoreClient) GenerateLink(params *store.GenerateLinkParams) (*store.Gen
 
 	return data, nil
 }
+
+func (c *StoreClient) ListHosts(params *store.ListHostsParams) (*store.ListHostsData, error) {
+	res, err := c.client.GetHostDomains(&GetHostDomainsParams{
+		Ctx: params.Ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]string, 0, len(res.Data.Hosts)+len(res.Data.Streams)+len(res.Data.Redirectors))
+	items = append(items, res.Data.Hosts...)
+	items = append(items, res.Data.Streams...)
+	items = append(items, res.Data.Redirectors...)
+
+	data := &store.ListHostsData{
+		Items: items,
+	}
+	return data, nil
+}
+
+func (c *StoreClient) UnrestrictLink(params *store.UnrestrictLinkParams) (*store.UnrestrictLinkData, error) {
+	ul, err := c.client.UnlockLink(&UnlockLinkParams{
+		Ctx:      params.Ctx,
+		Link:     params.Link,
+		Password: params.Password,
+		UserIP:   params.ClientIP,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	link := ul.Data
+
+	data := &store.UnrestrictLinkData{
+		Link: link.Link,
+		Name: link.Filename,
+		Size: int64(link.Filesize),
+		Host: link.HostDomain,
+	}
+
+	if link.Delayed != 0 {
+		for range 3 {
+			time.Sleep(2 * time.Second)
+			dl, err := c.client.GetDelayedLink(&GetDelayedLinkParams{
+				Ctx: params.Ctx,
+				Id:  link.Delayed,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if dl.Data.Status == DelayedLinkStatusCodeAvailable {
+				data.Link = dl.Data.Link
+				return data, nil
+			}
+			if dl.Data.Status == DelayedLinkStatusCodeError {
+				error := core.NewStoreError("failed to generate delayed link")
+				error.StoreName = string(store.StoreNameAlldebrid)
+				return nil, error
+			}
+		}
+
+		error := core.NewStoreError("link generation delayed, try later")
+		error.StatusCode = http.StatusTeapot
+		return nil, error
+	}
+
+	return data, nil
+}
@@ -12,6 +12,10 @@ import (
 
 var DefaultHTTPClient = config.DefaultHTTPClient
 
+var requestClient = request.NewClient(&request.ClientConfig{
+	RequestsPerMinute: config.StoreRateLimit.Get(string(store.StoreNameDebrider)),
+})
+
 type APIClientConfig struct {
 	BaseURL    string // default: https://debrider.app/api
 	APIKey     string
@@ -77,7 +81,7 @@ func (c APIClient) Request(method, path string, params request.Context, v reques
 		error.Cause = err
 		return nil, error
 	}
-	res, err := c.HTTPClient.Do(req)
+	res, err := requestClient.Do(c.HTTPClient, req, params.GetAPIKey(c.apiKey))
 	err = request.ProcessResponseBody(res, err, v)
 	if err != nil {
 		err := UpstreamErrorWithCause(err)
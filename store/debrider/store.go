@@ -326,6 +326,13 @@ func (s *StoreClient) GetUser(params *store.GetUserParams) (*store.User, error)
 		Id:                 res.Data.Id,
 		Email:              res.Data.Email,
 		SubscriptionStatus: store.UserSubscriptionStatusExpired,
+		Plan:               res.Data.Subscription.Plan.Name,
+	}
+	if endDate, err := time.Parse(time.RFC3339, res.Data.Subscription.EndDate); err == nil {
+		data.SetPremiumUntil(endDate)
+	}
+	if maxSlots := res.Data.Subscription.Plan.Metadata.ConcurrentSlots; maxSlots > 0 {
+		data.MaxSlots = &maxSlots
 	}
 	switch res.Data.Subscription.Status {
 	case "active":
@@ -14,6 +14,10 @@ import (
 
 var DefaultHTTPClient = config.DefaultHTTPClient
 
+var requestClient = request.NewClient(&request.ClientConfig{
+	RequestsPerMinute: config.StoreRateLimit.Get(string(store.StoreNameDebridLink)),
+})
+
 type APIClientConfig struct {
 	BaseURL    string // default https://debrid-link.com/api
 	APIKey     string
@@ -83,7 +87,7 @@ func (c APIClient) Request(method, path string, params request.Context, v Respon
 		error.Cause = err
 		return nil, error
 	}
-	res, err := c.HTTPClient.Do(req)
+	res, err := requestClient.Do(c.HTTPClient, req, params.GetAPIKey(c.apiKey))
 	err = processResponseBody(res, err, v)
 	if err != nil {
 		err := UpstreamErrorWithCause(err)
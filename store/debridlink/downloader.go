@@ -0,0 +1,41 @@
+package debridlink
+
+type DownloaderLink struct {
+	Id          string `json:"id"`
+	Expired     bool   `json:"expired"`
+	Chunk       int    `json:"chunk"`
+	Host        string `json:"host"`
+	Size        int64  `json:"size"`
+	Created     int64  `json:"created"`
+	Url         string `json:"url"`
+	DownloadUrl string `json:"downloadUrl"`
+	Name        string `json:"name"`
+}
+
+type AddDownloaderLinkData = DownloaderLink
+
+type AddDownloaderLinkParams struct {
+	Ctx
+	Url      string `json:"url"`
+	Password string `json:"password,omitempty"`
+	IP       string `json:"ip,omitempty"`
+}
+
+func (c APIClient) AddDownloaderLink(params *AddDownloaderLinkParams) (APIResponse[AddDownloaderLinkData], error) {
+	params.JSON = params
+	response := &Response[AddDownloaderLinkData]{}
+	res, err := c.Request("POST", "/v2/downloader/add", params, response)
+	return newAPIResponse(res, response.Value), err
+}
+
+type ListDownloaderHostnamesData = []string
+
+type ListDownloaderHostnamesParams struct {
+	Ctx
+}
+
+func (c APIClient) ListDownloaderHostnames(params *ListDownloaderHostnamesParams) (APIResponse[ListDownloaderHostnamesData], error) {
+	response := &Response[ListDownloaderHostnamesData]{}
+	res, err := c.Request("GET", "/v2/downloader/hostnames", params, response)
+	return newAPIResponse(res, response.Value), err
+}
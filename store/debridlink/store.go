@@ -56,11 +56,15 @@ func (c *StoreClient) GetUser(params *store.GetUserParams) (*store.User, error)
 	}
 	id := strings.Split(url.Path, "/")[2]
 	data := &store.User{
-		Id:    id,
-		Email: res.Data.Email,
+		Id:     id,
+		Email:  res.Data.Email,
+		Points: &res.Data.Pts,
 	}
 	if res.Data.PremiumLeft != 0 {
 		data.SubscriptionStatus = store.UserSubscriptionStatusPremium
+		if res.Data.PremiumLeft > 0 {
+			data.SetPremiumUntil(time.Now().Add(time.Duration(res.Data.PremiumLeft) * time.Second))
+		}
 	} else {
 		data.SubscriptionStatus = store.UserSubscriptionStatusExpired
 	}
@@ -292,3 +296,35 @@ func (c *StoreClient) GenerateLink(params *store.GenerateLinkParams) (*store.Gen
 	data := &store.GenerateLinkData{Link: params.Link}
 	return data, nil
 }
+
+func (c *StoreClient) ListHosts(params *store.ListHostsParams) (*store.ListHostsData, error) {
+	res, err := c.client.ListDownloaderHostnames(&ListDownloaderHostnamesParams{
+		Ctx: params.Ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.ListHostsData{
+		Items: res.Data,
+	}
+	return data, nil
+}
+
+func (c *StoreClient) UnrestrictLink(params *store.UnrestrictLinkParams) (*store.UnrestrictLinkData, error) {
+	res, err := c.client.AddDownloaderLink(&AddDownloaderLinkParams{
+		Ctx:      params.Ctx,
+		Url:      params.Link,
+		Password: params.Password,
+		IP:       params.ClientIP,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.UnrestrictLinkData{
+		Link: res.Data.DownloadUrl,
+		Name: res.Data.Name,
+		Size: res.Data.Size,
+		Host: res.Data.Host,
+	}
+	return data, nil
+}
@@ -324,6 +324,7 @@ func (s *StoreClient) GetUser(params *store.GetUserParams) (*store.User, error)
 	if res.Data.PaidUntil > time.Now().Unix() {
 		data.SubscriptionStatus = store.UserSubscriptionStatusPremium
 	}
+	data.SetPremiumUntil(time.Unix(res.Data.PaidUntil, 0))
 	return data, nil
 }
 
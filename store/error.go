@@ -1,6 +1,10 @@
 package store
 
-import "github.com/MunifTanjim/stremthru/core"
+import (
+	"net/http"
+
+	"github.com/MunifTanjim/stremthru/core"
+)
 
 var ErrorInvalidStoreName = func(name string) *core.StoreError {
 	err := core.NewStoreError("invalid store name")
@@ -8,3 +12,19 @@ var ErrorInvalidStoreName = func(name string) *core.StoreError {
 	err.StoreName = name
 	return err
 }
+
+var ErrorFileSelectionNotSupported = func(name string) *core.StoreError {
+	err := core.NewStoreError("file selection is not supported")
+	err.Code = core.ErrorCodeNotImplemented
+	err.StatusCode = http.StatusNotImplemented
+	err.StoreName = name
+	return err
+}
+
+var ErrorNoFileSelected = func(name string) *core.StoreError {
+	err := core.NewStoreError("no file matched the file selection")
+	err.Code = core.ErrorCodeBadRequest
+	err.StatusCode = http.StatusBadRequest
+	err.StoreName = name
+	return err
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"errors"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/MunifTanjim/stremthru/core"
+)
+
+func (fs *MagnetFileSelection) Validate() error {
+	switch fs.Mode {
+	case "", MagnetFileSelectionModeVideo, MagnetFileSelectionModeAll:
+	default:
+		return errors.New("invalid file selection mode: " + string(fs.Mode))
+	}
+	if fs.Mode == "" && len(fs.Indices) == 0 && len(fs.Patterns) == 0 {
+		return errors.New("empty file selection")
+	}
+	for _, idx := range fs.Indices {
+		if idx < 0 {
+			return errors.New("invalid file index")
+		}
+	}
+	for _, pattern := range fs.Patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.New("invalid file pattern: " + pattern)
+		}
+	}
+	return nil
+}
+
+// IsSelected reports whether the file at idx with filePath is selected.
+func (fs *MagnetFileSelection) IsSelected(idx int, filePath string) bool {
+	switch fs.Mode {
+	case MagnetFileSelectionModeAll:
+		return true
+	case MagnetFileSelectionModeVideo:
+		if core.HasVideoExtension(filePath) {
+			return true
+		}
+	}
+	if slices.Contains(fs.Indices, idx) {
+		return true
+	}
+	filePath = strings.ToLower(strings.TrimPrefix(filePath, "/"))
+	name := path.Base(filePath)
+	for _, pattern := range fs.Patterns {
+		pattern = strings.ToLower(pattern)
+		if ok, _ := path.Match(pattern, filePath); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
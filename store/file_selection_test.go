@@ -0,0 +1,59 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMagnetFileSelection(t *testing.T) {
+	files := []string{
+		"/Show.S01E01.mkv",
+		"/Show.S01E02.mkv",
+		"/Subs/Show.S01E01.srt",
+		"/Extras/Sample.mkv",
+		"/info.nfo",
+	}
+
+	getSelected := func(fs *MagnetFileSelection) []int {
+		selected := []int{}
+		for idx, path := range files {
+			if fs.IsSelected(idx, path) {
+				selected = append(selected, idx)
+			}
+		}
+		return selected
+	}
+
+	for _, tc := range []struct {
+		name      string
+		selection MagnetFileSelection
+		result    []int
+	}{
+		{"all", MagnetFileSelection{Mode: MagnetFileSelectionModeAll}, []int{0, 1, 2, 3, 4}},
+		{"video", MagnetFileSelection{Mode: MagnetFileSelectionModeVideo}, []int{0, 1, 3}},
+		{"video with subtitles", MagnetFileSelection{Mode: MagnetFileSelectionModeVideo, Patterns: []string{"*.SRT"}}, []int{0, 1, 2, 3}},
+		{"indices", MagnetFileSelection{Indices: []int{1, 4}}, []int{1, 4}},
+		{"name pattern", MagnetFileSelection{Patterns: []string{"*s01e01*"}}, []int{0, 2}},
+		{"path pattern", MagnetFileSelection{Patterns: []string{"extras/*"}}, []int{3}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.NoError(t, tc.selection.Validate())
+			assert.Equal(t, tc.result, getSelected(&tc.selection))
+		})
+	}
+
+	for _, tc := range []struct {
+		name      string
+		selection MagnetFileSelection
+	}{
+		{"empty", MagnetFileSelection{}},
+		{"invalid mode", MagnetFileSelection{Mode: "some"}},
+		{"invalid index", MagnetFileSelection{Indices: []int{-1}}},
+		{"invalid pattern", MagnetFileSelection{Patterns: []string{"[a-"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Error(t, tc.selection.Validate())
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// HasQuery reports whether the params have any search, filter or sort.
+func (p *ListMagnetsParams) HasQuery() bool {
+	return p.HasFilter() || p.SortBy != ""
+}
+
+// HasFilter reports whether the params have any search or filter.
+func (p *ListMagnetsParams) HasFilter() bool {
+	return p.Query != "" || len(p.Status) > 0 || !p.AddedAfter.IsZero() || !p.AddedBefore.IsZero()
+}
+
+func (p *ListMagnetsParams) Match(item *ListMagnetsDataItem) bool {
+	if len(p.Status) > 0 && !slices.Contains(p.Status, item.Status) {
+		return false
+	}
+	if !p.AddedAfter.IsZero() && !item.AddedAt.After(p.AddedAfter) {
+		return false
+	}
+	if !p.AddedBefore.IsZero() && !item.AddedAt.Before(p.AddedBefore) {
+		return false
+	}
+	if p.Query != "" {
+		name := strings.ToLower(item.Name)
+		for _, word := range strings.Fields(strings.ToLower(p.Query)) {
+			if !strings.Contains(name, word) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func compareListMagnetsDataItem(a, b *ListMagnetsDataItem, sortBy ListMagnetsSortBy) int {
+	switch sortBy {
+	case ListMagnetsSortByAddedAt:
+		return a.AddedAt.Compare(b.AddedAt)
+	case ListMagnetsSortByName:
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	case ListMagnetsSortBySize:
+		return cmp.Compare(a.Size, b.Size)
+	default:
+		return 0
+	}
+}
+
+// QueryListMagnetsItems returns the items matching the search and filter in
+// params, sorted as requested. The items are not paginated.
+func QueryListMagnetsItems(params *ListMagnetsParams, items []ListMagnetsDataItem) []ListMagnetsDataItem {
+	if !params.HasQuery() {
+		return items
+	}
+
+	result := items
+	if params.HasFilter() {
+		result = make([]ListMagnetsDataItem, 0, len(items))
+		for i := range items {
+			if params.Match(&items[i]) {
+				result = append(result, items[i])
+			}
+		}
+	} else {
+		result = slices.Clone(items)
+	}
+
+	if params.SortBy != "" {
+		slices.SortStableFunc(result, func(a, b ListMagnetsDataItem) int {
+			c := compareListMagnetsDataItem(&a, &b, params.SortBy)
+			if params.SortDesc {
+				return -c
+			}
+			return c
+		})
+	}
+
+	return result
+}
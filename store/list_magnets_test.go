@@ -0,0 +1,49 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryListMagnetsItems(t *testing.T) {
+	now := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	items := []ListMagnetsDataItem{
+		{Id: "1", Name: "Big.Buck.Bunny.1080p", Size: 300, Status: MagnetStatusDownloaded, AddedAt: now.Add(-1 * day)},
+		{Id: "2", Name: "Sintel.2160p", Size: 500, Status: MagnetStatusDownloading, AddedAt: now.Add(-2 * day)},
+		{Id: "3", Name: "big buck bunny 720p", Size: 100, Status: MagnetStatusFailed, AddedAt: now.Add(-3 * day)},
+		{Id: "4", Name: "Tears.of.Steel", Size: 200, Status: MagnetStatusDownloaded, AddedAt: now.Add(-4 * day)},
+	}
+
+	getIds := func(items []ListMagnetsDataItem) []string {
+		ids := []string{}
+		for _, item := range items {
+			ids = append(ids, item.Id)
+		}
+		return ids
+	}
+
+	for _, tc := range []struct {
+		name   string
+		params ListMagnetsParams
+		result []string
+	}{
+		{"no query", ListMagnetsParams{}, []string{"1", "2", "3", "4"}},
+		{"query", ListMagnetsParams{Query: "BUNNY big"}, []string{"1", "3"}},
+		{"status", ListMagnetsParams{Status: []MagnetStatus{MagnetStatusDownloaded, MagnetStatusFailed}}, []string{"1", "3", "4"}},
+		{"added range", ListMagnetsParams{AddedAfter: now.Add(-4 * day), AddedBefore: now.Add(-1 * day)}, []string{"2", "3"}},
+		{"sort by name", ListMagnetsParams{SortBy: ListMagnetsSortByName}, []string{"3", "1", "2", "4"}},
+		{"sort by size desc", ListMagnetsParams{SortBy: ListMagnetsSortBySize, SortDesc: true}, []string{"2", "1", "4", "3"}},
+		{"sort by added_at", ListMagnetsParams{SortBy: ListMagnetsSortByAddedAt}, []string{"4", "3", "2", "1"}},
+		{"query and sort", ListMagnetsParams{Query: "bunny", SortBy: ListMagnetsSortBySize}, []string{"3", "1"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.result, getIds(QueryListMagnetsItems(&tc.params, items)))
+		})
+	}
+
+	assert.Equal(t, []string{"1", "2", "3", "4"}, getIds(items), "input is not modified")
+}
@@ -0,0 +1,306 @@
+package local
+
+import (
+	"cmp"
+	"crypto/sha1"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/core"
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/imdb_title"
+	"github.com/MunifTanjim/stremthru/internal/torrent_stream"
+	"github.com/MunifTanjim/stremthru/internal/util"
+	"github.com/MunifTanjim/stremthru/store"
+)
+
+const indexStaleTime = 5 * time.Minute
+
+type indexFile struct {
+	Idx       int
+	Path      string
+	Name      string
+	Size      int64
+	LocalPath string
+}
+
+// indexItem is a top level file or directory inside the local directory,
+// exposed as a magnet by the store.
+type indexItem struct {
+	Hash string
+	// IsInfoHash is false for the hash generated from the local path, when
+	// there is no `.torrent` file.
+	IsInfoHash bool
+	Name       string
+	Size       int64
+	AddedAt    time.Time
+	Files      []indexFile
+}
+
+type libraryIndex struct {
+	items  []indexItem
+	byHash map[string]*indexItem
+}
+
+func (idx *libraryIndex) get(hash string) *indexItem {
+	return idx.byHash[strings.ToLower(hash)]
+}
+
+// getSidecarInfoHash returns the info hash of the first readable `.torrent`
+// file among the candidates.
+func getSidecarInfoHash(candidates ...string) string {
+	for _, candidate := range candidates {
+		data, err := os.ReadFile(candidate)
+		if err != nil {
+			continue
+		}
+		hash, err := getInfoHash(data)
+		if err != nil {
+			log.Warn("failed to read torrent file", "error", err, "path", candidate)
+			continue
+		}
+		return hash
+	}
+	return ""
+}
+
+func getLocalHash(localPath string) string {
+	sum := sha1.Sum([]byte("stremthru:store:local:" + localPath))
+	return hex.EncodeToString(sum[:])
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+func buildIndexItem(root string, entry fs.DirEntry) (*indexItem, error) {
+	localPath := filepath.Join(root, entry.Name())
+	info, err := entry.Info()
+	if err != nil {
+		return nil, err
+	}
+
+	item := &indexItem{
+		Name:    entry.Name(),
+		AddedAt: info.ModTime().UTC(),
+		Files:   []indexFile{},
+	}
+	sidecars := []string{localPath + ".torrent"}
+
+	if entry.IsDir() {
+		err := filepath.WalkDir(localPath, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path != localPath && isHidden(d.Name()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				return nil
+			}
+			if strings.EqualFold(filepath.Ext(d.Name()), ".torrent") && filepath.Dir(path) == localPath {
+				sidecars = append(sidecars, path)
+				return nil
+			}
+			if !core.HasVideoExtension(d.Name()) {
+				return nil
+			}
+			fInfo, err := d.Info()
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(localPath, path)
+			if err != nil {
+				return err
+			}
+			item.Files = append(item.Files, indexFile{
+				Path:      "/" + filepath.ToSlash(rel),
+				Name:      d.Name(),
+				Size:      fInfo.Size(),
+				LocalPath: path,
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else if core.HasVideoExtension(entry.Name()) {
+		sidecars = append(sidecars, strings.TrimSuffix(localPath, filepath.Ext(localPath))+".torrent")
+		item.Files = append(item.Files, indexFile{
+			Path:      "/" + entry.Name(),
+			Name:      entry.Name(),
+			Size:      info.Size(),
+			LocalPath: localPath,
+		})
+	}
+
+	if len(item.Files) == 0 {
+		return nil, nil
+	}
+
+	slices.SortFunc(item.Files, func(a, b indexFile) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	for i := range item.Files {
+		item.Files[i].Idx = i
+		item.Size += item.Files[i].Size
+	}
+
+	item.Hash = getSidecarInfoHash(sidecars...)
+	item.IsInfoHash = item.Hash != ""
+	if !item.IsInfoHash {
+		item.Hash = getLocalHash(localPath)
+	}
+	return item, nil
+}
+
+func buildIndex(dirs []string) (*libraryIndex, error) {
+	idx := &libraryIndex{
+		items:  []indexItem{},
+		byHash: map[string]*indexItem{},
+	}
+	for _, root := range dirs {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if isHidden(entry.Name()) || strings.EqualFold(filepath.Ext(entry.Name()), ".torrent") {
+				continue
+			}
+			item, err := buildIndexItem(root, entry)
+			if err != nil {
+				log.Warn("failed to index", "error", err, "path", filepath.Join(root, entry.Name()))
+				continue
+			}
+			if item == nil {
+				continue
+			}
+			idx.items = append(idx.items, *item)
+		}
+	}
+	slices.SortStableFunc(idx.items, func(a, b indexItem) int {
+		return cmp.Compare(b.AddedAt.Unix(), a.AddedAt.Unix())
+	})
+	for i := range idx.items {
+		item := &idx.items[i]
+		if _, seen := idx.byHash[item.Hash]; !seen {
+			idx.byHash[item.Hash] = item
+		}
+	}
+	return idx, nil
+}
+
+// getStremId maps the file to IMDB id, based on the parsed name.
+func getStremId(itemName string, file *indexFile) string {
+	r, err := util.ParseTorrentTitle(file.Name)
+	if err != nil || r.Title == "" {
+		if r, err = util.ParseTorrentTitle(itemName); err != nil || r.Title == "" {
+			return ""
+		}
+	}
+	year, _ := strconv.Atoi(r.Year)
+	titleType := imdb_title.SearchTitleTypeMovie
+	if len(r.Episodes) > 0 {
+		titleType = imdb_title.SearchTitleTypeShow
+	}
+	title, err := imdb_title.SearchOne(r.Title, titleType, year, false)
+	if err != nil {
+		log.Warn("failed to search imdb title", "error", err, "title", r.Title)
+		return ""
+	}
+	if title == nil {
+		return ""
+	}
+	if titleType == imdb_title.SearchTitleTypeMovie {
+		return title.TId
+	}
+	season := 1
+	if len(r.Seasons) > 0 {
+		season = r.Seasons[0]
+	}
+	return title.TId + ":" + strconv.Itoa(season) + ":" + strconv.Itoa(r.Episodes[0])
+}
+
+var trackedHashes = util.NewSet[string]()
+
+// trackFiles records the files with the mapped IMDB ids, so that the
+// catalog and streams can find them. Items without an info hash are skipped,
+// the torrent streams are shared with other stores.
+func trackFiles(items []indexItem) {
+	source := string(store.StoreCodeLocal)
+	data := []torrent_stream.InsertData{}
+	for i := range items {
+		item := &items[i]
+		if !item.IsInfoHash {
+			continue
+		}
+		for j := range item.Files {
+			f := &item.Files[j]
+			data = append(data, torrent_stream.InsertData{
+				Hash: item.Hash,
+				File: torrent_stream.File{
+					Path:   f.Path,
+					Idx:    f.Idx,
+					Size:   f.Size,
+					Name:   f.Name,
+					SId:    getStremId(item.Name, f),
+					Source: source,
+				},
+			})
+		}
+	}
+	if err := torrent_stream.Record(data, false); err != nil {
+		log.Error("failed to record files", "error", err)
+	}
+}
+
+var index struct {
+	sync.Mutex
+	value   *libraryIndex
+	builtAt time.Time
+}
+
+// getIndex returns the index for `STREMTHRU_STORE_LOCAL_DIR`, rebuilding it
+// when stale.
+func getIndex() (*libraryIndex, error) {
+	index.Lock()
+	defer index.Unlock()
+
+	if index.value != nil && time.Since(index.builtAt) < indexStaleTime {
+		return index.value, nil
+	}
+
+	start := time.Now()
+	idx, err := buildIndex(config.StoreLocalDirs)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug("built index", "count", len(idx.items), "duration", time.Since(start).String())
+
+	index.value = idx
+	index.builtAt = time.Now()
+
+	untracked := []indexItem{}
+	for i := range idx.items {
+		if hash := idx.items[i].Hash; !trackedHashes.Has(hash) {
+			trackedHashes.Add(hash)
+			untracked = append(untracked, idx.items[i])
+		}
+	}
+	if len(untracked) > 0 {
+		go trackFiles(untracked)
+	}
+
+	return idx, nil
+}
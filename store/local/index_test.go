@@ -0,0 +1,69 @@
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildIndex(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"Show.S01/Show.S01E01.mkv":      "e01",
+		"Show.S01/Show.S01E02.mkv":      "e002",
+		"Show.S01/Show.S01.nfo":         "nfo",
+		"Show.S01/.hidden/Sample.mkv":   "sample",
+		"Show.S01.torrent":              "d4:infod4:name8:Show.S01ee",
+		"Movie.2020.mkv":                "movie",
+		"Notes.txt":                     "notes",
+		".trash/Old.Movie.mkv":          "old",
+		"Empty/readme.md":               "",
+		"Other/Other.2021.1080p/a.mp4":  "mp4",
+		"Other/Other.2021.1080p/b.webm": "webm",
+	} {
+		path := filepath.Join(root, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	idx, err := buildIndex([]string{root})
+	assert.NoError(t, err)
+
+	names := []string{}
+	for _, item := range idx.items {
+		names = append(names, item.Name)
+	}
+	assert.ElementsMatch(t, []string{"Show.S01", "Movie.2020.mkv", "Other"}, names)
+
+	show := idx.get(getInfoHashOf(t, "d4:infod4:name8:Show.S01ee"))
+	if assert.NotNil(t, show) {
+		assert.Equal(t, "Show.S01", show.Name)
+		assert.True(t, show.IsInfoHash)
+		assert.Equal(t, int64(7), show.Size)
+		assert.Equal(t, []indexFile{
+			{Idx: 0, Path: "/Show.S01E01.mkv", Name: "Show.S01E01.mkv", Size: 3, LocalPath: filepath.Join(root, "Show.S01/Show.S01E01.mkv")},
+			{Idx: 1, Path: "/Show.S01E02.mkv", Name: "Show.S01E02.mkv", Size: 4, LocalPath: filepath.Join(root, "Show.S01/Show.S01E02.mkv")},
+		}, show.Files)
+	}
+
+	movie := idx.get(getLocalHash(filepath.Join(root, "Movie.2020.mkv")))
+	if assert.NotNil(t, movie) {
+		assert.False(t, movie.IsInfoHash)
+		assert.Len(t, movie.Files, 1)
+		assert.Equal(t, "/Movie.2020.mkv", movie.Files[0].Path)
+	}
+
+	other := idx.get(getLocalHash(filepath.Join(root, "Other")))
+	if assert.NotNil(t, other) {
+		assert.Len(t, other.Files, 2)
+		assert.Equal(t, "/Other.2021.1080p/a.mp4", other.Files[0].Path)
+	}
+}
+
+func getInfoHashOf(t *testing.T, torrent string) string {
+	hash, err := getInfoHash([]byte(torrent))
+	assert.NoError(t, err)
+	return hash
+}
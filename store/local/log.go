@@ -0,0 +1,5 @@
+package local
+
+import "github.com/MunifTanjim/stremthru/internal/logger"
+
+var log = logger.Scoped("store/local")
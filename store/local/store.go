@@ -0,0 +1,298 @@
+package local
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/MunifTanjim/stremthru/core"
+	"github.com/MunifTanjim/stremthru/internal/config"
+	store_file "github.com/MunifTanjim/stremthru/internal/store/file"
+	"github.com/MunifTanjim/stremthru/store"
+)
+
+type StoreClientConfig struct{}
+
+type StoreClient struct {
+	Name   store.StoreName
+	config *StoreClientConfig
+}
+
+func (s *StoreClient) GetName() store.StoreName {
+	return s.Name
+}
+
+type LockedFileLink string
+
+const lockedFileLinkPrefix = "stremthru://store/local/"
+
+func (l LockedFileLink) encodeData(hash string, fileIdx int) string {
+	return core.Base64Encode(hash + ":" + strconv.Itoa(fileIdx))
+}
+
+func (l LockedFileLink) decodeData(encoded string) (hash string, fileIdx int, err error) {
+	decoded, err := core.Base64Decode(encoded)
+	if err != nil {
+		return "", 0, err
+	}
+	hash, idx, found := strings.Cut(decoded, ":")
+	if !found {
+		return "", 0, core.NewAPIError("invalid link")
+	}
+	fileIdx, err = strconv.Atoi(idx)
+	if err != nil {
+		return "", 0, err
+	}
+	return hash, fileIdx, nil
+}
+
+func (l LockedFileLink) Create(hash string, fileIdx int) string {
+	return lockedFileLinkPrefix + l.encodeData(hash, fileIdx)
+}
+
+func (l LockedFileLink) Parse() (hash string, fileIdx int, err error) {
+	encoded := strings.TrimPrefix(string(l), lockedFileLinkPrefix)
+	return l.decodeData(encoded)
+}
+
+// authenticate checks the API key, in `username:password` format, against
+// `STREMTHRU_PROXY_AUTH`.
+func authenticate(apiKey string) (string, error) {
+	if config.IsPublicInstance || len(config.StoreLocalDirs) == 0 {
+		error := core.NewStoreError("store not available")
+		error.StoreName = string(store.StoreNameLocal)
+		error.StatusCode = http.StatusForbidden
+		error.Code = core.ErrorCodeForbidden
+		return "", error
+	}
+	user, password, ok := strings.Cut(apiKey, ":")
+	if !ok || user == "" || password == "" || config.ProxyAuthPassword.GetPassword(user) != password {
+		error := core.NewStoreError("invalid api key")
+		error.StoreName = string(store.StoreNameLocal)
+		error.StatusCode = http.StatusUnauthorized
+		error.Code = core.ErrorCodeUnauthorized
+		return "", error
+	}
+	return user, nil
+}
+
+func (s *StoreClient) getItem(apiKey, hash string) (*indexItem, error) {
+	if _, err := authenticate(apiKey); err != nil {
+		return nil, err
+	}
+	idx, err := getIndex()
+	if err != nil {
+		return nil, err
+	}
+	item := idx.get(hash)
+	if item == nil {
+		return nil, errorMagnetNotFound()
+	}
+	return item, nil
+}
+
+func (s *StoreClient) getFiles(item *indexItem) []store.MagnetFile {
+	source := string(s.GetName().Code())
+	files := make([]store.MagnetFile, len(item.Files))
+	for i := range item.Files {
+		f := &item.Files[i]
+		files[i] = store.MagnetFile{
+			Idx:    f.Idx,
+			Link:   LockedFileLink("").Create(item.Hash, f.Idx),
+			Path:   f.Path,
+			Name:   f.Name,
+			Size:   f.Size,
+			Source: source,
+		}
+	}
+	return files
+}
+
+func errorMagnetNotFound() error {
+	err := core.NewAPIError("not found")
+	err.StatusCode = http.StatusNotFound
+	err.StoreName = string(store.StoreNameLocal)
+	return err
+}
+
+// AddMagnet only finds the magnet in the local directory, new content can
+// not be downloaded.
+func (s *StoreClient) AddMagnet(params *store.AddMagnetParams) (*store.AddMagnetData, error) {
+	magnet, err := core.ParseMagnetLink(params.Magnet)
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.getItem(params.GetAPIKey(""), magnet.Hash)
+	if err != nil {
+		return nil, err
+	}
+	data := &store.AddMagnetData{
+		Id:      item.Hash,
+		Hash:    item.Hash,
+		Magnet:  magnet.Link,
+		Name:    item.Name,
+		Size:    item.Size,
+		Status:  store.MagnetStatusDownloaded,
+		Files:   s.getFiles(item),
+		AddedAt: item.AddedAt,
+	}
+	return data, nil
+}
+
+func (s *StoreClient) CheckMagnet(params *store.CheckMagnetParams) (*store.CheckMagnetData, error) {
+	if _, err := authenticate(params.GetAPIKey("")); err != nil {
+		return nil, err
+	}
+	idx, err := getIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	data := &store.CheckMagnetData{
+		Items: make([]store.CheckMagnetDataItem, 0, len(params.Magnets)),
+	}
+	for _, m := range params.Magnets {
+		magnet, err := core.ParseMagnetLink(m)
+		if err != nil {
+			return nil, err
+		}
+		item := store.CheckMagnetDataItem{
+			Hash:   magnet.Hash,
+			Magnet: magnet.Link,
+			Status: store.MagnetStatusUnknown,
+			Files:  []store.MagnetFile{},
+		}
+		if indexed := idx.get(magnet.Hash); indexed != nil {
+			files := s.getFiles(indexed)
+			for i := range files {
+				files[i].Link = ""
+			}
+			item.Name = indexed.Name
+			item.Size = indexed.Size
+			item.Status = store.MagnetStatusCached
+			item.Files = files
+		}
+		data.Items = append(data.Items, item)
+	}
+	return data, nil
+}
+
+func (s *StoreClient) GenerateLink(params *store.GenerateLinkParams) (*store.GenerateLinkData, error) {
+	hash, fileIdx, err := LockedFileLink(params.Link).Parse()
+	if err != nil {
+		error := core.NewAPIError("invalid link")
+		error.StoreName = string(store.StoreNameLocal)
+		error.StatusCode = http.StatusBadRequest
+		error.Cause = err
+		return nil, error
+	}
+
+	item, err := s.getItem(params.GetAPIKey(""), hash)
+	if err != nil {
+		return nil, err
+	}
+	if fileIdx < 0 || fileIdx >= len(item.Files) {
+		err := core.NewAPIError("file not found")
+		err.StoreName = string(store.StoreNameLocal)
+		err.StatusCode = http.StatusNotFound
+		return nil, err
+	}
+
+	link, err := store_file.CreateLink(item.Files[fileIdx].LocalPath)
+	if err != nil {
+		return nil, err
+	}
+	return &store.GenerateLinkData{Link: link}, nil
+}
+
+func (s *StoreClient) GetMagnet(params *store.GetMagnetParams) (*store.GetMagnetData, error) {
+	item, err := s.getItem(params.GetAPIKey(""), params.Id)
+	if err != nil {
+		return nil, err
+	}
+	data := &store.GetMagnetData{
+		Id:      item.Hash,
+		Hash:    item.Hash,
+		Name:    item.Name,
+		Size:    item.Size,
+		Status:  store.MagnetStatusDownloaded,
+		Files:   s.getFiles(item),
+		AddedAt: item.AddedAt,
+	}
+	return data, nil
+}
+
+func (s *StoreClient) GetUser(params *store.GetUserParams) (*store.User, error) {
+	user, err := authenticate(params.GetAPIKey(""))
+	if err != nil {
+		return nil, err
+	}
+	data := &store.User{
+		Id:                 user,
+		Email:              user,
+		SubscriptionStatus: store.UserSubscriptionStatusPremium,
+	}
+	return data, nil
+}
+
+func (s *StoreClient) CanQueryListMagnets() bool {
+	return true
+}
+
+func (s *StoreClient) ListMagnets(params *store.ListMagnetsParams) (*store.ListMagnetsData, error) {
+	if _, err := authenticate(params.GetAPIKey("")); err != nil {
+		return nil, err
+	}
+	idx, err := getIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	lm := make([]store.ListMagnetsDataItem, 0, len(idx.items))
+	for i := range idx.items {
+		item := &idx.items[i]
+		lm = append(lm, store.ListMagnetsDataItem{
+			Id:      item.Hash,
+			Hash:    item.Hash,
+			Name:    item.Name,
+			Size:    item.Size,
+			Status:  store.MagnetStatusDownloaded,
+			AddedAt: item.AddedAt,
+		})
+	}
+	lm = store.QueryListMagnetsItems(params, lm)
+
+	totalItems := len(lm)
+	limit := params.Limit
+	if limit == 0 {
+		limit = 100
+	}
+	start := min(params.Offset, totalItems)
+	end := min(start+limit, totalItems)
+
+	data := &store.ListMagnetsData{
+		Items:      lm[start:end],
+		TotalItems: totalItems,
+	}
+	return data, nil
+}
+
+// RemoveMagnet is not supported, files in the local directory are never
+// deleted by StremThru.
+func (s *StoreClient) RemoveMagnet(params *store.RemoveMagnetParams) (*store.RemoveMagnetData, error) {
+	if _, err := authenticate(params.GetAPIKey("")); err != nil {
+		return nil, err
+	}
+	err := core.NewStoreError("removing from local store is not supported")
+	err.StoreName = string(store.StoreNameLocal)
+	err.StatusCode = http.StatusMethodNotAllowed
+	err.Code = core.ErrorCodeMethodNotAllowed
+	return nil, err
+}
+
+func NewStoreClient(config *StoreClientConfig) *StoreClient {
+	c := &StoreClient{}
+	c.Name = store.StoreNameLocal
+	c.config = config
+	return c
+}
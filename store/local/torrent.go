@@ -0,0 +1,85 @@
+package local
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"strconv"
+)
+
+var errInvalidTorrentFile = errors.New("invalid torrent file")
+
+// skipBencodeValue returns the offset right after the bencoded value
+// starting at offset.
+func skipBencodeValue(data []byte, offset int) (int, error) {
+	if offset >= len(data) {
+		return 0, errInvalidTorrentFile
+	}
+	switch c := data[offset]; {
+	case c == 'i':
+		end := bytes.IndexByte(data[offset:], 'e')
+		if end == -1 {
+			return 0, errInvalidTorrentFile
+		}
+		return offset + end + 1, nil
+	case c == 'l' || c == 'd':
+		offset++
+		for offset < len(data) && data[offset] != 'e' {
+			next, err := skipBencodeValue(data, offset)
+			if err != nil {
+				return 0, err
+			}
+			offset = next
+		}
+		if offset >= len(data) {
+			return 0, errInvalidTorrentFile
+		}
+		return offset + 1, nil
+	case '0' <= c && c <= '9':
+		sep := bytes.IndexByte(data[offset:], ':')
+		if sep == -1 {
+			return 0, errInvalidTorrentFile
+		}
+		length, err := strconv.Atoi(string(data[offset : offset+sep]))
+		if err != nil || length < 0 {
+			return 0, errInvalidTorrentFile
+		}
+		end := offset + sep + 1 + length
+		if end > len(data) {
+			return 0, errInvalidTorrentFile
+		}
+		return end, nil
+	default:
+		return 0, errInvalidTorrentFile
+	}
+}
+
+// getInfoHash returns the info hash of the torrent file, i.e. the sha1 of
+// the bencoded `info` dictionary.
+func getInfoHash(data []byte) (string, error) {
+	if len(data) == 0 || data[0] != 'd' {
+		return "", errInvalidTorrentFile
+	}
+	offset := 1
+	for offset < len(data) && data[offset] != 'e' {
+		keyEnd, err := skipBencodeValue(data, offset)
+		if err != nil {
+			return "", err
+		}
+		key := data[offset:keyEnd]
+		valueEnd, err := skipBencodeValue(data, keyEnd)
+		if err != nil {
+			return "", err
+		}
+		if bytes.Equal(key, []byte("4:info")) {
+			if data[keyEnd] != 'd' {
+				return "", errInvalidTorrentFile
+			}
+			sum := sha1.Sum(data[keyEnd:valueEnd])
+			return hex.EncodeToString(sum[:]), nil
+		}
+		offset = valueEnd
+	}
+	return "", errInvalidTorrentFile
+}
@@ -0,0 +1,42 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetInfoHash(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		input  string
+		output string
+		err    bool
+	}{
+		{
+			"single file",
+			"d8:announce14:http://tracker4:infod6:lengthi42e4:name9:movie.mkv12:piece lengthi16384e6:pieces0:ee",
+			"20dfd37fbfc436189509ee98146323da76e61544",
+			false,
+		},
+		{
+			"info before other keys",
+			"d4:infod6:lengthi42e4:name9:movie.mkv12:piece lengthi16384e6:pieces0:e8:url-listl14:http://mirror/ee",
+			"20dfd37fbfc436189509ee98146323da76e61544",
+			false,
+		},
+		{"missing info", "d8:announce14:http://trackere", "", true},
+		{"truncated", "d4:infod6:lengthi42e", "", true},
+		{"not a dict", "l4:infoe", "", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			hash, err := getInfoHash([]byte(tc.input))
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.output, hash)
+			}
+		})
+	}
+}
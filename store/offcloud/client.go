@@ -15,6 +15,10 @@ import (
 
 var DefaultHTTPClient = config.DefaultHTTPClient
 
+var requestClient = request.NewClient(&request.ClientConfig{
+	RequestsPerMinute: config.StoreRateLimit.Get(string(store.StoreNameOffcloud)),
+})
+
 type APIClientConfig struct {
 	BaseURL    string // default: https://offcloud.com
 	APIKey     string
@@ -76,8 +80,8 @@ func NewAPIClient(conf *APIClientConfig) *APIClient {
 
 type Ctx = request.Ctx
 
-func (c APIClient) doRequest(req *http.Request, v ResponseEnvelop) (*http.Response, error) {
-	res, err := c.HTTPClient.Do(req)
+func (c APIClient) doRequest(params request.Context, req *http.Request, v ResponseEnvelop) (*http.Response, error) {
+	res, err := requestClient.Do(c.HTTPClient, req, params.GetAPIKey(c.apiKey))
 	err = processResponseBody(res, err, v)
 	if err != nil {
 		err := UpstreamErrorWithCause(err)
@@ -102,7 +106,7 @@ func (c APIClient) Request(method, path string, params request.Context, v Respon
 		error.Cause = err
 		return nil, error
 	}
-	return c.doRequest(req, v)
+	return c.doRequest(params, req, v)
 }
 
 func (c APIClient) ServerRequest(server, method, path string, params request.Context, v ResponseEnvelop) (*http.Response, error) {
@@ -118,7 +122,7 @@ func (c APIClient) ServerRequest(server, method, path string, params request.Con
 		error.Cause = err
 		return nil, error
 	}
-	return c.doRequest(req, v)
+	return c.doRequest(params, req, v)
 }
 
 type GetFileSizeParams struct {
@@ -239,7 +239,7 @@ func (c APIClient) ListCloudDownloadEntries(params *ListCloudDownloadEntriesPara
 		return newAPIResponse(nil, *response), error
 	}
 
-	res, err := c.doRequest(req, response)
+	res, err := c.doRequest(params, req, response)
 	return newAPIResponse(res, *response), err
 }
 
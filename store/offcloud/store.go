@@ -276,6 +276,10 @@ func (s *StoreClient) GetUser(params *store.GetUserParams) (*store.User, error)
 	if stats_res.Data.ExpirationDate.After(time.Now()) {
 		data.SubscriptionStatus = store.UserSubscriptionStatusPremium
 	}
+	data.Plan = string(stats_res.Data.MembershipType)
+	if data.Plan != string(MembershipTypeLifetime) {
+		data.SetPremiumUntil(stats_res.Data.ExpirationDate.Time)
+	}
 	return data, nil
 }
 
@@ -13,6 +13,10 @@ import (
 
 var DefaultHTTPClient = config.DefaultHTTPClient
 
+var requestClient = request.NewClient(&request.ClientConfig{
+	RequestsPerMinute: config.StoreRateLimit.Get(string(store.StoreNamePikPak)),
+})
+
 type APIClientConfig struct {
 	APIKey     string
 	HTTPClient *http.Client
@@ -115,7 +119,7 @@ func (c APIClient) doRequest(params request.Context, req *http.Request, v Respon
 		ctx.PreparePikpakHeader(&req.Header)
 	}
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := requestClient.Do(c.HTTPClient, req, params.GetAPIKey(""))
 	err = processResponseBody(res, err, v)
 	if err != nil {
 		err := UpstreamErrorWithCause(err)
@@ -402,6 +402,10 @@ func (s *StoreClient) GetUser(params *store.GetUserParams) (*store.User, error)
 	if vipRes.Data.Type == VIPTypePlatinum {
 		data.SubscriptionStatus = store.UserSubscriptionStatusPremium
 	}
+	data.Plan = string(vipRes.Data.Type)
+	if expire, err := time.Parse(time.RFC3339, vipRes.Data.Expire); err == nil {
+		data.SetPremiumUntil(expire)
+	}
 	return data, nil
 }
 
@@ -0,0 +1,22 @@
+package premiumize
+
+type ListServicesData struct {
+	DirectDL []string            `json:"directdl"`
+	Cache    []string            `json:"cache"`
+	Aliases  map[string][]string `json:"aliases"`
+}
+
+type listServicesData struct {
+	ResponseContainer
+	ListServicesData
+}
+
+type ListServicesParams struct {
+	Ctx
+}
+
+func (c APIClient) ListServices(params *ListServicesParams) (APIResponse[ListServicesData], error) {
+	response := &listServicesData{}
+	res, err := c.Request("GET", "/services/list", params, response)
+	return newAPIResponse(res, response.ListServicesData), err
+}
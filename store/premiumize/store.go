@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"path"
 	"slices"
 	"strings"
@@ -174,6 +175,7 @@ func (c *StoreClient) GetUser(params *store.GetUserParams) (*store.User, error)
 		Email: "",
 	}
 	if res.Data.PremiumUntil != 0 {
+		data.SetPremiumUntil(time.Unix(int64(res.Data.PremiumUntil), 0))
 		data.SubscriptionStatus = store.UserSubscriptionStatusPremium
 		if res.Data.PremiumUntil < int(time.Now().Unix()) {
 			data.SubscriptionStatus = store.UserSubscriptionStatusExpired
@@ -843,3 +845,61 @@ func (c *StoreClient) GenerateLink(params *store.GenerateLinkParams) (*store.Gen
 	data := &store.GenerateLinkData{Link: params.Link}
 	return data, nil
 }
+
+func (c *StoreClient) ListHosts(params *store.ListHostsParams) (*store.ListHostsData, error) {
+	res, err := c.client.ListServices(&ListServicesParams{
+		Ctx: params.Ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := []string{}
+	for _, host := range res.Data.DirectDL {
+		items = append(items, host)
+		items = append(items, res.Data.Aliases[host]...)
+	}
+
+	data := &store.ListHostsData{
+		Items: items,
+	}
+	return data, nil
+}
+
+func (c *StoreClient) UnrestrictLink(params *store.UnrestrictLinkParams) (*store.UnrestrictLinkData, error) {
+	res, err := c.client.CreateDirectDownloadLink(&CreateDirectDownloadLinkParams{
+		Ctx: params.Ctx,
+		Src: params.Link,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var file *CreateDirectDownloadLinkDataContent
+	for i := range res.Data.Content {
+		f := &res.Data.Content[i]
+		if file == nil || f.Size > file.Size {
+			file = f
+		}
+	}
+	if file == nil {
+		error := core.NewAPIError("no file found for link")
+		error.StoreName = string(store.StoreNamePremiumize)
+		error.StatusCode = http.StatusNotFound
+		error.Code = core.ErrorCodeNotFound
+		return nil, error
+	}
+
+	data := &store.UnrestrictLinkData{
+		Link: file.Link,
+		Name: file.GetName(),
+		Size: file.Size,
+	}
+	if file.StreamLink != "" {
+		data.Link = file.StreamLink
+	}
+	if u, err := url.Parse(params.Link); err == nil {
+		data.Host = u.Hostname()
+	}
+	return data, nil
+}
@@ -0,0 +1,213 @@
+package qbittorrent
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/core"
+	"github.com/MunifTanjim/stremthru/internal/cache"
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/request"
+	"github.com/MunifTanjim/stremthru/store"
+)
+
+var DefaultHTTPClient = config.DefaultHTTPClient
+
+type APIClientConfig struct {
+	HTTPClient *http.Client
+	UserAgent  string
+}
+
+type APIClient struct {
+	HTTPClient *http.Client
+	agent      string
+	reqQuery   func(query *url.Values, params request.Context)
+	reqHeader  func(query *http.Header, params request.Context)
+
+	sidCache cache.Cache[string]
+}
+
+func NewAPIClient(conf *APIClientConfig) *APIClient {
+	if conf.UserAgent == "" {
+		conf.UserAgent = "stremthru"
+	}
+
+	if conf.HTTPClient == nil {
+		conf.HTTPClient = DefaultHTTPClient
+	}
+
+	c := &APIClient{}
+
+	c.HTTPClient = conf.HTTPClient
+	c.agent = conf.UserAgent
+
+	c.reqQuery = func(query *url.Values, params request.Context) {
+	}
+
+	c.reqHeader = func(header *http.Header, params request.Context) {
+		header.Add("User-Agent", c.agent)
+	}
+
+	c.sidCache = cache.NewCache[string](&cache.CacheConfig{
+		Name:     "store:qbittorrent:sid",
+		Lifetime: 30 * time.Minute,
+	})
+
+	return c
+}
+
+type Ctx = request.Ctx
+
+type apiCredential struct {
+	baseURL  *url.URL
+	username string
+	password string
+}
+
+// authenticate checks that the API key is configured in `STREMTHRU_STORE_AUTH`
+// for a user from `STREMTHRU_PROXY_AUTH`, so that the requests are only sent
+// to the clients set up by the operator.
+func authenticate(apiKey string) error {
+	if config.IsPublicInstance {
+		error := core.NewStoreError("store not available on public instance")
+		error.StoreName = string(store.StoreNameQBittorrent)
+		error.StatusCode = http.StatusForbidden
+		error.Code = core.ErrorCodeForbidden
+		return error
+	}
+	if !config.StoreAuthToken.HasToken(string(store.StoreNameQBittorrent), apiKey) {
+		error := core.NewStoreError("invalid api key")
+		error.StoreName = string(store.StoreNameQBittorrent)
+		error.StatusCode = http.StatusUnauthorized
+		error.Code = core.ErrorCodeUnauthorized
+		return error
+	}
+	return nil
+}
+
+// parseAPIKey parses the API key in `http(s)://username:password@host:port`
+// format, i.e. the qBittorrent WebUI URL with credentials.
+func parseAPIKey(apiKey string) (*apiCredential, error) {
+	u, err := url.Parse(apiKey)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		err := core.NewStoreError("invalid api key, expected webui url with credentials")
+		err.StoreName = string(store.StoreNameQBittorrent)
+		err.StatusCode = http.StatusUnauthorized
+		err.Code = core.ErrorCodeUnauthorized
+		return nil, err
+	}
+	cred := &apiCredential{
+		username: u.User.Username(),
+	}
+	cred.password, _ = u.User.Password()
+	u.User = nil
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	cred.baseURL = u
+	return cred, nil
+}
+
+func (c APIClient) login(cred *apiCredential) (string, error) {
+	params := &Ctx{
+		Form: &url.Values{
+			"username": []string{cred.username},
+			"password": []string{cred.password},
+		},
+	}
+	req, err := params.NewRequest(cred.baseURL, "POST", "/api/v2/auth/login", c.reqHeader, c.reqQuery)
+	if err != nil {
+		error := core.NewStoreError("failed to create request")
+		error.StoreName = string(store.StoreNameQBittorrent)
+		error.Cause = err
+		return "", error
+	}
+	req.Header.Set("Referer", cred.baseURL.String())
+	res, err := c.HTTPClient.Do(req)
+	response := &ResponseContainer{}
+	err = request.ProcessResponseBody(res, err, response)
+	if err == nil && response.Text != "Ok." {
+		response.StatusCode = http.StatusUnauthorized
+		err = response
+	}
+	if err != nil {
+		err := UpstreamErrorWithCause(err)
+		err.InjectReq(req)
+		err.StatusCode = http.StatusUnauthorized
+		err.Code = core.ErrorCodeUnauthorized
+		return "", err
+	}
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == "SID" && cookie.Value != "" {
+			return cookie.Value, nil
+		}
+	}
+	error := core.NewStoreError("missing session cookie")
+	error.StoreName = string(store.StoreNameQBittorrent)
+	error.StatusCode = http.StatusUnauthorized
+	error.Code = core.ErrorCodeUnauthorized
+	return "", error
+}
+
+func (c APIClient) getSID(apiKey string, cred *apiCredential, refresh bool) (string, error) {
+	sid := ""
+	if !refresh && c.sidCache.Get(apiKey, &sid) {
+		return sid, nil
+	}
+	sid, err := c.login(cred)
+	if err != nil {
+		return "", err
+	}
+	c.sidCache.Add(apiKey, sid)
+	return sid, nil
+}
+
+func (c APIClient) Request(method, path string, params request.Context, v request.ResponseContainer) (*http.Response, error) {
+	if params == nil {
+		params = &Ctx{}
+	}
+	apiKey := params.GetAPIKey("")
+	if err := authenticate(apiKey); err != nil {
+		return nil, err
+	}
+	cred, err := parseAPIKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *http.Response
+	for attempt := range 2 {
+		sid, err := c.getSID(apiKey, cred, attempt > 0)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := params.NewRequest(cred.baseURL, method, path, c.reqHeader, c.reqQuery)
+		if err != nil {
+			error := core.NewStoreError("failed to create request")
+			error.StoreName = string(store.StoreNameQBittorrent)
+			error.Cause = err
+			return nil, error
+		}
+		req.Header.Set("Referer", cred.baseURL.String())
+		req.AddCookie(&http.Cookie{Name: "SID", Value: sid})
+
+		res, err = c.HTTPClient.Do(req)
+		if err == nil && res.StatusCode == http.StatusForbidden && attempt == 0 {
+			res.Body.Close()
+			continue
+		}
+
+		err = request.ProcessResponseBody(res, err, v)
+		if err != nil {
+			err := UpstreamErrorWithCause(err)
+			err.InjectReq(req)
+			if res != nil && res.StatusCode >= http.StatusBadRequest {
+				err.StatusCode = res.StatusCode
+			}
+			return res, err
+		}
+		break
+	}
+	return res, nil
+}
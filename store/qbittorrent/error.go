@@ -0,0 +1,20 @@
+package qbittorrent
+
+import (
+	"github.com/MunifTanjim/stremthru/core"
+	"github.com/MunifTanjim/stremthru/store"
+)
+
+func UpstreamErrorWithCause(cause error) *core.UpstreamError {
+	err := core.NewUpstreamError("")
+	err.StoreName = string(store.StoreNameQBittorrent)
+
+	if rerr, ok := cause.(*ResponseContainer); ok {
+		err.Msg = rerr.Text
+		err.UpstreamCause = rerr
+	} else {
+		err.Cause = cause
+	}
+
+	return err
+}
@@ -0,0 +1,65 @@
+package qbittorrent
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/MunifTanjim/stremthru/core"
+)
+
+// ResponseContainer holds the plain text response, e.g. `Ok.` or `Fails.`,
+// returned by the WebUI API for non-JSON endpoints.
+type ResponseContainer struct {
+	StatusCode int    `json:"status_code"`
+	Text       string `json:"text,omitempty"`
+}
+
+func (e *ResponseContainer) Error() string {
+	ret, _ := json.Marshal(e)
+	return string(ret)
+}
+
+func (r *ResponseContainer) GetError(res *http.Response) error {
+	if res.StatusCode >= http.StatusBadRequest || r.Text == "Fails." {
+		r.StatusCode = res.StatusCode
+		return r
+	}
+	return nil
+}
+
+func (r *ResponseContainer) Unmarshal(res *http.Response, body []byte, v any) error {
+	r.Text = strings.TrimSpace(string(body))
+	return nil
+}
+
+type jsonResponse[T any] struct {
+	ResponseContainer
+	data T
+}
+
+func (r *jsonResponse[T]) Unmarshal(res *http.Response, body []byte, v any) error {
+	contentType := res.Header.Get("Content-Type")
+	if res.StatusCode >= http.StatusBadRequest || !strings.Contains(contentType, "application/json") {
+		return r.ResponseContainer.Unmarshal(res, body, v)
+	}
+	return core.UnmarshalJSON(res.StatusCode, body, &r.data)
+}
+
+type APIResponse[T any] struct {
+	Header     http.Header
+	StatusCode int
+	Data       T
+}
+
+func newAPIResponse[T any](res *http.Response, data T) APIResponse[T] {
+	apiResponse := APIResponse[T]{
+		StatusCode: 503,
+		Data:       data,
+	}
+	if res != nil {
+		apiResponse.Header = res.Header
+		apiResponse.StatusCode = res.StatusCode
+	}
+	return apiResponse
+}
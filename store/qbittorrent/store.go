@@ -0,0 +1,483 @@
+package qbittorrent
+
+import (
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/core"
+	store_file "github.com/MunifTanjim/stremthru/internal/store/file"
+	"github.com/MunifTanjim/stremthru/store"
+)
+
+func getMagnetStatusFromTorrent(t *Torrent) store.MagnetStatus {
+	switch t.State {
+	case TorrentStateError, TorrentStateMissingFiles:
+		return store.MagnetStatusFailed
+	case TorrentStateMetaDL, TorrentStateForcedMetaDL, TorrentStateQueuedDL, TorrentStateCheckingDL, TorrentStateCheckingResumeData, TorrentStateAllocating:
+		return store.MagnetStatusQueued
+	case TorrentStateDownloading, TorrentStateStalledDL, TorrentStateForcedDL, TorrentStatePausedDL, TorrentStateStoppedDL:
+		return store.MagnetStatusDownloading
+	case TorrentStateMoving:
+		return store.MagnetStatusProcessing
+	case TorrentStateUploading, TorrentStatePausedUP, TorrentStateStoppedUP, TorrentStateQueuedUP, TorrentStateStalledUP, TorrentStateCheckingUP, TorrentStateForcedUP:
+		return store.MagnetStatusDownloaded
+	default:
+		if t.IsCompleted() {
+			return store.MagnetStatusDownloaded
+		}
+		return store.MagnetStatusUnknown
+	}
+}
+
+type StoreClientConfig struct {
+	HTTPClient *http.Client
+	UserAgent  string
+}
+
+type StoreClient struct {
+	Name   store.StoreName
+	client *APIClient
+	config *StoreClientConfig
+}
+
+func (s *StoreClient) GetName() store.StoreName {
+	return s.Name
+}
+
+type LockedFileLink string
+
+const lockedFileLinkPrefix = "stremthru://store/qbittorrent/"
+
+func (l LockedFileLink) encodeData(hash string, fileIdx int) string {
+	return core.Base64Encode(hash + ":" + strconv.Itoa(fileIdx))
+}
+
+func (l LockedFileLink) decodeData(encoded string) (hash string, fileIdx int, err error) {
+	decoded, err := core.Base64Decode(encoded)
+	if err != nil {
+		return "", 0, err
+	}
+	hash, idx, found := strings.Cut(decoded, ":")
+	if !found {
+		return "", 0, core.NewAPIError("invalid link")
+	}
+	fileIdx, err = strconv.Atoi(idx)
+	if err != nil {
+		return "", 0, err
+	}
+	return hash, fileIdx, nil
+}
+
+func (l LockedFileLink) Create(hash string, fileIdx int) string {
+	return lockedFileLinkPrefix + l.encodeData(hash, fileIdx)
+}
+
+func (l LockedFileLink) Parse() (hash string, fileIdx int, err error) {
+	encoded := strings.TrimPrefix(string(l), lockedFileLinkPrefix)
+	return l.decodeData(encoded)
+}
+
+func (s *StoreClient) getTorrent(ctx Ctx, hash string) (*Torrent, error) {
+	res, err := s.client.ListTorrents(&ListTorrentsParams{
+		Ctx:    ctx,
+		Hashes: []string{hash},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range res.Data {
+		if strings.EqualFold(res.Data[i].Hash, hash) {
+			return &res.Data[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *StoreClient) getFiles(ctx Ctx, t *Torrent) ([]store.MagnetFile, error) {
+	res, err := s.client.ListTorrentFiles(&ListTorrentFilesParams{
+		Ctx:  ctx,
+		Hash: t.Hash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	source := string(s.GetName().Code())
+	files := make([]store.MagnetFile, len(res.Data))
+	for i := range res.Data {
+		f := &res.Data[i]
+		files[i] = store.MagnetFile{
+			Idx:    f.Index,
+			Link:   LockedFileLink("").Create(t.Hash, f.Index),
+			Path:   f.GetPath(),
+			Name:   f.GetName(),
+			Size:   f.Size,
+			Source: source,
+		}
+	}
+	return files, nil
+}
+
+// selectFiles sets the priority of the torrent's files as per the selection.
+// The files are listed once the metadata is fetched, so it waits a while. If
+// no file matches, the torrent is removed if it was just added.
+func (s *StoreClient) selectFiles(ctx Ctx, t *Torrent, selection *store.MagnetFileSelection, isNew bool) error {
+	var files []TorrentFile
+	for retry := 0; retry < 5; retry++ {
+		res, err := s.client.ListTorrentFiles(&ListTorrentFilesParams{
+			Ctx:  ctx,
+			Hash: t.Hash,
+		})
+		if err != nil {
+			return err
+		}
+		if len(res.Data) > 0 {
+			files = res.Data
+			break
+		}
+		time.Sleep(2 * time.Second)
+	}
+	if len(files) == 0 {
+		err := core.NewStoreError("torrent metadata is not available yet, file selection is not applied")
+		err.StatusCode = http.StatusServiceUnavailable
+		err.StoreName = string(store.StoreNameQBittorrent)
+		return err
+	}
+
+	selectedIds, unselectedIds := []int{}, []int{}
+	for i := range files {
+		f := &files[i]
+		if selection.IsSelected(f.Index, f.GetPath()) {
+			selectedIds = append(selectedIds, f.Index)
+		} else {
+			unselectedIds = append(unselectedIds, f.Index)
+		}
+	}
+	if len(selectedIds) == 0 {
+		if isNew {
+			if _, err := s.RemoveMagnet(&store.RemoveMagnetParams{Ctx: ctx, Id: t.Hash}); err != nil {
+				return err
+			}
+		}
+		return store.ErrorNoFileSelected(string(store.StoreNameQBittorrent))
+	}
+
+	if len(unselectedIds) > 0 {
+		if _, err := s.client.SetFilePriority(&SetFilePriorityParams{
+			Ctx:      ctx,
+			Hash:     t.Hash,
+			Ids:      unselectedIds,
+			Priority: 0,
+		}); err != nil {
+			return err
+		}
+	}
+	_, err := s.client.SetFilePriority(&SetFilePriorityParams{
+		Ctx:      ctx,
+		Hash:     t.Hash,
+		Ids:      selectedIds,
+		Priority: 1,
+	})
+	return err
+}
+
+func errorMagnetNotFound() error {
+	err := core.NewAPIError("not found")
+	err.StatusCode = http.StatusNotFound
+	err.StoreName = string(store.StoreNameQBittorrent)
+	return err
+}
+
+func (s *StoreClient) CanSelectMagnetFiles() bool {
+	return true
+}
+
+func (s *StoreClient) AddMagnet(params *store.AddMagnetParams) (*store.AddMagnetData, error) {
+	magnet, err := core.ParseMagnetLink(params.Magnet)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := s.getTorrent(params.Ctx, magnet.Hash)
+	if err != nil {
+		return nil, err
+	}
+	isNew := t == nil
+	if isNew {
+		if _, err := s.client.AddTorrent(&AddTorrentParams{
+			Ctx:  params.Ctx,
+			URLs: []string{magnet.RawLink},
+		}); err != nil {
+			return nil, err
+		}
+		// torrent is added asynchronously, it may not be listed yet
+		if t, err = s.getTorrent(params.Ctx, magnet.Hash); err != nil {
+			return nil, err
+		}
+	}
+
+	data := &store.AddMagnetData{
+		Id:     magnet.Hash,
+		Hash:   magnet.Hash,
+		Magnet: magnet.Link,
+		Name:   magnet.Name,
+		Status: store.MagnetStatusQueued,
+		Files:  []store.MagnetFile{},
+	}
+	if t == nil && params.Files != nil {
+		t = &Torrent{Hash: magnet.Hash}
+	}
+	if t == nil {
+		return data, nil
+	}
+
+	if params.Files != nil {
+		if err := s.selectFiles(params.Ctx, t, params.Files, isNew); err != nil {
+			return nil, err
+		}
+		if t, err = s.getTorrent(params.Ctx, magnet.Hash); err != nil {
+			return nil, err
+		} else if t == nil {
+			return data, nil
+		}
+	}
+
+	data.Name = t.Name
+	data.Size = t.TotalSize
+	data.Status = getMagnetStatusFromTorrent(t)
+	data.AddedAt = t.GetAddedAt()
+	if files, err := s.getFiles(params.Ctx, t); err != nil {
+		return nil, err
+	} else {
+		data.Files = files
+	}
+	return data, nil
+}
+
+// CheckMagnet reports the completed torrents in the client as cached.
+func (s *StoreClient) CheckMagnet(params *store.CheckMagnetParams) (*store.CheckMagnetData, error) {
+	magnets := make([]core.MagnetLink, len(params.Magnets))
+	hashes := make([]string, len(params.Magnets))
+	for i, m := range params.Magnets {
+		magnet, err := core.ParseMagnetLink(m)
+		if err != nil {
+			return nil, err
+		}
+		magnets[i] = magnet
+		hashes[i] = magnet.Hash
+	}
+
+	res, err := s.client.ListTorrents(&ListTorrentsParams{
+		Ctx:    params.Ctx,
+		Hashes: hashes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	torrentByHash := make(map[string]*Torrent, len(res.Data))
+	for i := range res.Data {
+		torrentByHash[strings.ToLower(res.Data[i].Hash)] = &res.Data[i]
+	}
+
+	data := &store.CheckMagnetData{
+		Items: make([]store.CheckMagnetDataItem, 0, len(magnets)),
+	}
+	for _, magnet := range magnets {
+		item := store.CheckMagnetDataItem{
+			Hash:   magnet.Hash,
+			Magnet: magnet.Link,
+			Status: store.MagnetStatusUnknown,
+			Files:  []store.MagnetFile{},
+		}
+		if t, ok := torrentByHash[magnet.Hash]; ok && t.IsCompleted() {
+			files, err := s.getFiles(params.Ctx, t)
+			if err != nil {
+				return nil, err
+			}
+			for i := range files {
+				files[i].Link = ""
+			}
+			item.Name = t.Name
+			item.Size = t.TotalSize
+			item.Status = store.MagnetStatusCached
+			item.Files = files
+		}
+		data.Items = append(data.Items, item)
+	}
+	return data, nil
+}
+
+func (s *StoreClient) GenerateLink(params *store.GenerateLinkParams) (*store.GenerateLinkData, error) {
+	hash, fileIdx, err := LockedFileLink(params.Link).Parse()
+	if err != nil {
+		error := core.NewAPIError("invalid link")
+		error.StoreName = string(store.StoreNameQBittorrent)
+		error.StatusCode = http.StatusBadRequest
+		error.Cause = err
+		return nil, error
+	}
+
+	t, err := s.getTorrent(params.Ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, errorMagnetNotFound()
+	}
+
+	res, err := s.client.ListTorrentFiles(&ListTorrentFilesParams{
+		Ctx:  params.Ctx,
+		Hash: t.Hash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	idx := slices.IndexFunc(res.Data, func(f TorrentFile) bool {
+		return f.Index == fileIdx
+	})
+	if idx == -1 {
+		err := core.NewAPIError("file not found")
+		err.StoreName = string(store.StoreNameQBittorrent)
+		err.StatusCode = http.StatusNotFound
+		return nil, err
+	}
+	file := &res.Data[idx]
+	if !file.IsCompleted() {
+		err := core.NewAPIError("file not downloaded yet")
+		err.StoreName = string(store.StoreNameQBittorrent)
+		err.StatusCode = http.StatusConflict
+		err.Code = core.ErrorCodeConflict
+		return nil, err
+	}
+
+	localPath, err := store_file.ResolvePath(path.Join(t.SavePath, file.Name))
+	if err != nil {
+		return nil, err
+	}
+	link, err := store_file.CreateLink(localPath)
+	if err != nil {
+		return nil, err
+	}
+	return &store.GenerateLinkData{Link: link}, nil
+}
+
+func (s *StoreClient) GetMagnet(params *store.GetMagnetParams) (*store.GetMagnetData, error) {
+	t, err := s.getTorrent(params.Ctx, params.Id)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, errorMagnetNotFound()
+	}
+	files, err := s.getFiles(params.Ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	data := &store.GetMagnetData{
+		Id:      strings.ToLower(t.Hash),
+		Hash:    strings.ToLower(t.Hash),
+		Name:    t.Name,
+		Size:    t.TotalSize,
+		Status:  getMagnetStatusFromTorrent(t),
+		Files:   files,
+		AddedAt: t.GetAddedAt(),
+	}
+	return data, nil
+}
+
+func (s *StoreClient) GetUser(params *store.GetUserParams) (*store.User, error) {
+	cred, err := parseAPIKey(params.GetAPIKey(""))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.client.GetVersion(&GetVersionParams{Ctx: params.Ctx}); err != nil {
+		return nil, err
+	}
+	data := &store.User{
+		Id:                 cred.username + "@" + cred.baseURL.Host,
+		Email:              cred.username,
+		SubscriptionStatus: store.UserSubscriptionStatusPremium,
+	}
+	return data, nil
+}
+
+func (s *StoreClient) CanQueryListMagnets() bool {
+	return true
+}
+
+func (s *StoreClient) ListMagnets(params *store.ListMagnetsParams) (*store.ListMagnetsData, error) {
+	ltParams := &ListTorrentsParams{
+		Ctx: params.Ctx,
+	}
+	switch params.SortBy {
+	case store.ListMagnetsSortByAddedAt:
+		ltParams.Sort, ltParams.Reverse = "added_on", params.SortDesc
+	case store.ListMagnetsSortByName:
+		ltParams.Sort, ltParams.Reverse = "name", params.SortDesc
+	case store.ListMagnetsSortBySize:
+		ltParams.Sort, ltParams.Reverse = "total_size", params.SortDesc
+	}
+	res, err := s.client.ListTorrents(ltParams)
+	if err != nil {
+		return nil, err
+	}
+
+	lm := make([]store.ListMagnetsDataItem, 0, len(res.Data))
+	for i := range res.Data {
+		t := &res.Data[i]
+		item := store.ListMagnetsDataItem{
+			Id:      strings.ToLower(t.Hash),
+			Hash:    strings.ToLower(t.Hash),
+			Name:    t.Name,
+			Size:    t.TotalSize,
+			Status:  getMagnetStatusFromTorrent(t),
+			AddedAt: t.GetAddedAt(),
+		}
+		if params.Match(&item) {
+			lm = append(lm, item)
+		}
+	}
+
+	totalItems := len(lm)
+	limit := params.Limit
+	if limit == 0 {
+		limit = 100
+	}
+	start := min(params.Offset, totalItems)
+	end := min(start+limit, totalItems)
+
+	data := &store.ListMagnetsData{
+		Items:      lm[start:end],
+		TotalItems: totalItems,
+	}
+	return data, nil
+}
+
+func (s *StoreClient) RemoveMagnet(params *store.RemoveMagnetParams) (*store.RemoveMagnetData, error) {
+	_, err := s.client.DeleteTorrents(&DeleteTorrentsParams{
+		Ctx:         params.Ctx,
+		Hashes:      []string{params.Id},
+		DeleteFiles: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.RemoveMagnetData{
+		Id: params.Id,
+	}
+	return data, nil
+}
+
+func NewStoreClient(config *StoreClientConfig) *StoreClient {
+	c := &StoreClient{}
+	c.client = NewAPIClient(&APIClientConfig{
+		HTTPClient: config.HTTPClient,
+		UserAgent:  config.UserAgent,
+	})
+	c.Name = store.StoreNameQBittorrent
+	c.config = config
+	return c
+}
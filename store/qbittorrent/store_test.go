@@ -0,0 +1,263 @@
+package qbittorrent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MunifTanjim/stremthru/core"
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/store"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	hashCompleted   = "db42af2b6ae098558b98a14cd7874ef64162cac8"
+	hashDownloading = "0123456789abcdef0123456789abcdef01234567"
+	hashNew         = "89abcdef0123456789abcdef0123456789abcdef"
+)
+
+// newStandInServer returns the server, and the file ids set by priority.
+func newStandInServer(t *testing.T) (*httptest.Server, map[string]string) {
+	torrents := map[string]*Torrent{
+		hashCompleted: {
+			Hash: hashCompleted, Name: "Movie", TotalSize: 100, Progress: 1,
+			State: TorrentStateStalledUP, SavePath: "/downloads",
+		},
+		hashDownloading: {
+			Hash: hashDownloading, Name: "Show", TotalSize: 200, Progress: 0.4,
+			State: TorrentStateDownloading, SavePath: "/downloads",
+		},
+	}
+	files := map[string][]TorrentFile{
+		hashCompleted: {
+			{Index: 0, Name: "Movie/Movie.mkv", Size: 90, Progress: 1},
+			{Index: 1, Name: "Movie/Movie.srt", Size: 10, Progress: 1},
+		},
+		hashDownloading: {
+			{Index: 0, Name: "Show.S01E01.mkv", Size: 200, Progress: 0.4},
+		},
+	}
+
+	sendJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("username") != "admin" || r.FormValue("password") != "secret" {
+			w.Write([]byte("Fails."))
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "SID", Value: "sid"})
+		w.Write([]byte("Ok."))
+	})
+	authed := func(handler http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if cookie, err := r.Cookie("SID"); err != nil || cookie.Value != "sid" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			handler(w, r)
+		}
+	}
+	mux.HandleFunc("/api/v2/torrents/info", authed(func(w http.ResponseWriter, r *http.Request) {
+		items := []Torrent{}
+		hashes := r.URL.Query().Get("hashes")
+		for hash, t := range torrents {
+			if hashes == "" || strings.Contains(hashes, hash) {
+				items = append(items, *t)
+			}
+		}
+		sendJSON(w, items)
+	}))
+	mux.HandleFunc("/api/v2/torrents/files", authed(func(w http.ResponseWriter, r *http.Request) {
+		sendJSON(w, files[r.URL.Query().Get("hash")])
+	}))
+	mux.HandleFunc("/api/v2/torrents/add", authed(func(w http.ResponseWriter, r *http.Request) {
+		torrents[hashNew] = &Torrent{Hash: hashNew, Name: "New", State: TorrentStateMetaDL}
+		files[hashNew] = []TorrentFile{
+			{Index: 0, Name: "New/New.S01E01.mkv", Size: 100},
+			{Index: 1, Name: "New/New.S01E02.mkv", Size: 100},
+			{Index: 2, Name: "New/New.S01E01.srt", Size: 1},
+			{Index: 3, Name: "New/Extras/Sample.mkv", Size: 10},
+		}
+		w.Write([]byte("Ok."))
+	}))
+	mux.HandleFunc("/api/v2/torrents/delete", authed(func(w http.ResponseWriter, r *http.Request) {
+		for _, hash := range strings.Split(r.FormValue("hashes"), "|") {
+			delete(torrents, hash)
+			delete(files, hash)
+		}
+		w.Write([]byte("Ok."))
+	}))
+	filePriority := map[string]string{}
+	mux.HandleFunc("/api/v2/torrents/filePrio", authed(func(w http.ResponseWriter, r *http.Request) {
+		filePriority[r.FormValue("priority")] = r.FormValue("id")
+		w.Write([]byte("Ok."))
+	}))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, filePriority
+}
+
+func newTestStoreClient(t *testing.T) (*StoreClient, string, map[string]string) {
+	isPublicInstance := config.IsPublicInstance
+	config.IsPublicInstance = false
+	t.Cleanup(func() {
+		config.IsPublicInstance = isPublicInstance
+	})
+
+	server, filePriority := newStandInServer(t)
+	apiKey := strings.Replace(server.URL, "http://", "http://admin:secret@", 1)
+	storeAuthToken, hasStoreAuthToken := config.StoreAuthToken["*"]
+	config.StoreAuthToken["*"] = map[string]string{string(store.StoreNameQBittorrent): apiKey}
+	t.Cleanup(func() {
+		if hasStoreAuthToken {
+			config.StoreAuthToken["*"] = storeAuthToken
+		} else {
+			delete(config.StoreAuthToken, "*")
+		}
+	})
+	return NewStoreClient(&StoreClientConfig{HTTPClient: server.Client()}), apiKey, filePriority
+}
+
+func TestAuthenticate(t *testing.T) {
+	s, apiKey, _ := newTestStoreClient(t)
+
+	params := &store.GetUserParams{}
+	params.APIKey = apiKey + "/other"
+	_, err := s.GetUser(params)
+	if assert.Error(t, err, "unconfigured api key") {
+		assert.Equal(t, http.StatusUnauthorized, err.(*core.StoreError).StatusCode)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Ok."))
+	}))
+	t.Cleanup(server.Close)
+	noSIDAPIKey := strings.Replace(server.URL, "http://", "http://admin:secret@", 1)
+	config.StoreAuthToken["*"][string(store.StoreNameQBittorrent)] = noSIDAPIKey
+	params.APIKey = noSIDAPIKey
+	_, err = s.GetUser(params)
+	if assert.Error(t, err, "missing sid") {
+		assert.Equal(t, http.StatusUnauthorized, err.(*core.StoreError).StatusCode)
+	}
+}
+
+func TestCheckMagnet(t *testing.T) {
+	s, apiKey, _ := newTestStoreClient(t)
+
+	params := &store.CheckMagnetParams{
+		Magnets: []string{hashCompleted, hashDownloading, hashNew},
+	}
+	params.APIKey = apiKey
+	data, err := s.CheckMagnet(params)
+	assert.NoError(t, err)
+	assert.Len(t, data.Items, 3)
+
+	for _, tc := range []struct {
+		hash   string
+		status store.MagnetStatus
+		files  int
+	}{
+		{hashCompleted, store.MagnetStatusCached, 2},
+		{hashDownloading, store.MagnetStatusUnknown, 0},
+		{hashNew, store.MagnetStatusUnknown, 0},
+	} {
+		t.Run(tc.hash, func(t *testing.T) {
+			for _, item := range data.Items {
+				if item.Hash == tc.hash {
+					assert.Equal(t, tc.status, item.Status)
+					assert.Len(t, item.Files, tc.files)
+					for _, f := range item.Files {
+						assert.Empty(t, f.Link)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAddMagnet(t *testing.T) {
+	s, apiKey, _ := newTestStoreClient(t)
+
+	params := &store.AddMagnetParams{Magnet: hashNew}
+	params.APIKey = apiKey
+	data, err := s.AddMagnet(params)
+	assert.NoError(t, err)
+	assert.Equal(t, hashNew, data.Hash)
+	assert.Equal(t, store.MagnetStatusQueued, data.Status)
+}
+
+func TestAddMagnetWithFileSelection(t *testing.T) {
+	s, apiKey, filePriority := newTestStoreClient(t)
+
+	params := &store.AddMagnetParams{
+		Magnet: hashNew,
+		Files: &store.MagnetFileSelection{
+			Patterns: []string{"*S01E01*"},
+			Indices:  []int{1},
+		},
+	}
+	params.APIKey = apiKey
+
+	getParams := &store.GetMagnetParams{Id: hashNew}
+	getParams.APIKey = apiKey
+
+	noMatchParams := &store.AddMagnetParams{
+		Magnet: hashNew,
+		Files:  &store.MagnetFileSelection{Patterns: []string{"*.iso"}},
+	}
+	noMatchParams.APIKey = apiKey
+	_, err := s.AddMagnet(noMatchParams)
+	assert.Error(t, err)
+	_, err = s.GetMagnet(getParams)
+	assert.Error(t, err, "new torrent is removed")
+
+	data, err := s.AddMagnet(params)
+	assert.NoError(t, err)
+	assert.Equal(t, hashNew, data.Hash)
+	assert.Equal(t, "0|1|2", filePriority["1"])
+	assert.Equal(t, "3", filePriority["0"])
+
+	_, err = s.AddMagnet(noMatchParams)
+	assert.Error(t, err)
+	_, err = s.GetMagnet(getParams)
+	assert.NoError(t, err, "existing torrent is kept")
+}
+
+func TestGetMagnet(t *testing.T) {
+	s, apiKey, _ := newTestStoreClient(t)
+
+	for _, tc := range []struct {
+		hash   string
+		status store.MagnetStatus
+		paths  []string
+	}{
+		{hashCompleted, store.MagnetStatusDownloaded, []string{"/Movie.mkv", "/Movie.srt"}},
+		{hashDownloading, store.MagnetStatusDownloading, []string{"/Show.S01E01.mkv"}},
+	} {
+		t.Run(tc.hash, func(t *testing.T) {
+			params := &store.GetMagnetParams{Id: tc.hash}
+			params.APIKey = apiKey
+			data, err := s.GetMagnet(params)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.status, data.Status)
+			paths := []string{}
+			for _, f := range data.Files {
+				paths = append(paths, f.Path)
+			}
+			assert.Equal(t, tc.paths, paths)
+		})
+	}
+
+	params := &store.GetMagnetParams{Id: hashNew}
+	params.APIKey = apiKey
+	_, err := s.GetMagnet(params)
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+package qbittorrent
+
+import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/util"
+)
+
+type TorrentState string
+
+const (
+	TorrentStateError              TorrentState = "error"
+	TorrentStateMissingFiles       TorrentState = "missingFiles"
+	TorrentStateUploading          TorrentState = "uploading"
+	TorrentStatePausedUP           TorrentState = "pausedUP"
+	TorrentStateStoppedUP          TorrentState = "stoppedUP"
+	TorrentStateQueuedUP           TorrentState = "queuedUP"
+	TorrentStateStalledUP          TorrentState = "stalledUP"
+	TorrentStateCheckingUP         TorrentState = "checkingUP"
+	TorrentStateForcedUP           TorrentState = "forcedUP"
+	TorrentStateAllocating         TorrentState = "allocating"
+	TorrentStateDownloading        TorrentState = "downloading"
+	TorrentStateMetaDL             TorrentState = "metaDL"
+	TorrentStateForcedMetaDL       TorrentState = "forcedMetaDL"
+	TorrentStatePausedDL           TorrentState = "pausedDL"
+	TorrentStateStoppedDL          TorrentState = "stoppedDL"
+	TorrentStateQueuedDL           TorrentState = "queuedDL"
+	TorrentStateStalledDL          TorrentState = "stalledDL"
+	TorrentStateCheckingDL         TorrentState = "checkingDL"
+	TorrentStateForcedDL           TorrentState = "forcedDL"
+	TorrentStateCheckingResumeData TorrentState = "checkingResumeData"
+	TorrentStateMoving             TorrentState = "moving"
+	TorrentStateUnknown            TorrentState = "unknown"
+)
+
+type Torrent struct {
+	Hash        string       `json:"hash"`
+	Name        string       `json:"name"`
+	Size        int64        `json:"size"`
+	TotalSize   int64        `json:"total_size"`
+	Progress    float64      `json:"progress"`
+	State       TorrentState `json:"state"`
+	AddedOn     int64        `json:"added_on"`
+	CompletedOn int64        `json:"completion_on"`
+	SavePath    string       `json:"save_path"`
+	ContentPath string       `json:"content_path"`
+	AmountLeft  int64        `json:"amount_left"`
+	MagnetURI   string       `json:"magnet_uri"`
+}
+
+func (t *Torrent) GetAddedAt() time.Time {
+	return time.Unix(t.AddedOn, 0).UTC()
+}
+
+func (t *Torrent) IsCompleted() bool {
+	return t.Progress >= 1
+}
+
+type TorrentFile struct {
+	Index    int     `json:"index"`
+	Name     string  `json:"name"`
+	Size     int64   `json:"size"`
+	Progress float64 `json:"progress"`
+	Priority int     `json:"priority"`
+}
+
+func (f *TorrentFile) GetPath() string {
+	filePath, _ := util.RemoveRootFolderFromPath(f.Name)
+	if filePath == "" {
+		filePath = "/" + f.Name
+	}
+	return filePath
+}
+
+func (f *TorrentFile) GetName() string {
+	return path.Base(f.Name)
+}
+
+func (f *TorrentFile) IsCompleted() bool {
+	return f.Progress >= 1
+}
+
+type GetVersionParams struct {
+	Ctx
+}
+
+func (c APIClient) GetVersion(params *GetVersionParams) (APIResponse[string], error) {
+	response := &ResponseContainer{}
+	res, err := c.Request("GET", "/api/v2/app/version", params, response)
+	return newAPIResponse(res, response.Text), err
+}
+
+type ListTorrentsParams struct {
+	Ctx
+	Hashes  []string
+	Sort    string // torrent field, default: added_on
+	Reverse bool
+}
+
+func (c APIClient) ListTorrents(params *ListTorrentsParams) (APIResponse[[]Torrent], error) {
+	params.Query = &url.Values{}
+	if params.Sort == "" {
+		params.Query.Set("sort", "added_on")
+		params.Query.Set("reverse", "true")
+	} else {
+		params.Query.Set("sort", params.Sort)
+		params.Query.Set("reverse", strconv.FormatBool(params.Reverse))
+	}
+	if len(params.Hashes) > 0 {
+		params.Query.Set("hashes", strings.Join(params.Hashes, "|"))
+	}
+	response := &jsonResponse[[]Torrent]{}
+	res, err := c.Request("GET", "/api/v2/torrents/info", params, response)
+	return newAPIResponse(res, response.data), err
+}
+
+type ListTorrentFilesParams struct {
+	Ctx
+	Hash string
+}
+
+func (c APIClient) ListTorrentFiles(params *ListTorrentFilesParams) (APIResponse[[]TorrentFile], error) {
+	params.Query = &url.Values{}
+	params.Query.Set("hash", params.Hash)
+	response := &jsonResponse[[]TorrentFile]{}
+	res, err := c.Request("GET", "/api/v2/torrents/files", params, response)
+	return newAPIResponse(res, response.data), err
+}
+
+type SetFilePriorityParams struct {
+	Ctx
+	Hash     string
+	Ids      []int
+	Priority int // 0: do not download, 1: normal
+}
+
+func (c APIClient) SetFilePriority(params *SetFilePriorityParams) (APIResponse[string], error) {
+	ids := make([]string, len(params.Ids))
+	for i, id := range params.Ids {
+		ids[i] = strconv.Itoa(id)
+	}
+	params.Form = &url.Values{}
+	params.Form.Set("hash", params.Hash)
+	params.Form.Set("id", strings.Join(ids, "|"))
+	params.Form.Set("priority", strconv.Itoa(params.Priority))
+	response := &ResponseContainer{}
+	res, err := c.Request("POST", "/api/v2/torrents/filePrio", params, response)
+	return newAPIResponse(res, response.Text), err
+}
+
+type AddTorrentParams struct {
+	Ctx
+	URLs []string
+}
+
+func (c APIClient) AddTorrent(params *AddTorrentParams) (APIResponse[string], error) {
+	params.Form = &url.Values{}
+	params.Form.Set("urls", strings.Join(params.URLs, "\n"))
+	response := &ResponseContainer{}
+	res, err := c.Request("POST", "/api/v2/torrents/add", params, response)
+	return newAPIResponse(res, response.Text), err
+}
+
+type DeleteTorrentsParams struct {
+	Ctx
+	Hashes      []string
+	DeleteFiles bool
+}
+
+func (c APIClient) DeleteTorrents(params *DeleteTorrentsParams) (APIResponse[string], error) {
+	params.Form = &url.Values{}
+	params.Form.Set("hashes", strings.Join(params.Hashes, "|"))
+	if params.DeleteFiles {
+		params.Form.Set("deleteFiles", "true")
+	} else {
+		params.Form.Set("deleteFiles", "false")
+	}
+	response := &ResponseContainer{}
+	res, err := c.Request("POST", "/api/v2/torrents/delete", params, response)
+	return newAPIResponse(res, response.Text), err
+}
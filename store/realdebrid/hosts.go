@@ -0,0 +1,44 @@
+package realdebrid
+
+import (
+	"encoding/json"
+
+	"github.com/MunifTanjim/stremthru/core"
+)
+
+type ListHostDomainsData = []string
+
+type listHostDomainsData struct {
+	*ResponseError
+	data ListHostDomainsData
+}
+
+func (c *listHostDomainsData) UnmarshalJSON(data []byte) error {
+	var rerr ResponseError
+	err := json.Unmarshal(data, &rerr)
+	if err == nil {
+		c.ResponseError = &rerr
+		return nil
+	}
+
+	var items ListHostDomainsData
+	err = core.UnmarshalJSON(200, data, &items)
+	if err == nil {
+		c.data = items
+		return nil
+	}
+
+	e := core.NewAPIError("failed to parse response")
+	e.Cause = err
+	return e
+}
+
+type ListHostDomainsParams struct {
+	Ctx
+}
+
+func (c APIClient) ListHostDomains(params *ListHostDomainsParams) (APIResponse[ListHostDomainsData], error) {
+	response := &listHostDomainsData{}
+	res, err := c.Request("GET", "/rest/1.0/hosts/domains", params, response)
+	return newAPIResponse(res, response.data), err
+}
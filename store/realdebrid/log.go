@@ -0,0 +1,5 @@
+package realdebrid
+
+import "github.com/MunifTanjim/stremthru/internal/logger"
+
+var log = logger.Scoped("realdebrid")
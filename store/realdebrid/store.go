@@ -54,6 +54,12 @@ type StoreClient struct {
 	idsByHashCache          cache.Cache[map[string]bool]
 	hashByIdCache           cache.Cache[string]
 	subscriptionStatusCache cache.Cache[store.UserSubscriptionStatus]
+	activeCountCache        cache.Cache[activeTorrentCount]
+}
+
+type activeTorrentCount struct {
+	Active int
+	Limit  int
 }
 
 func NewStoreClient(config *StoreClientConfig) *StoreClient {
@@ -87,6 +93,10 @@ func NewStoreClient(config *StoreClientConfig) *StoreClient {
 		Name:     "store:realdebrid:subscriptionStatus",
 		Lifetime: 5 * time.Minute,
 	})
+	c.activeCountCache = cache.NewLRUCache[activeTorrentCount](&cache.CacheConfig{
+		Name:     "store:realdebrid:activeCount",
+		Lifetime: 1 * time.Minute,
+	})
 
 	return c
 }
@@ -131,20 +141,50 @@ func (c *StoreClient) GetUser(params *store.GetUserParams) (*store.User, error)
 		return nil, err
 	}
 	data := &store.User{
-		Id:    strconv.Itoa(res.Data.Id),
-		Email: res.Data.Email,
+		Id:     strconv.Itoa(res.Data.Id),
+		Email:  res.Data.Email,
+		Plan:   string(res.Data.Type),
+		Points: &res.Data.Points,
 	}
 	if res.Data.Premium > 0 {
 		data.SubscriptionStatus = store.UserSubscriptionStatusPremium
 	} else {
 		data.SubscriptionStatus = store.UserSubscriptionStatusExpired
 	}
+	if expiration, err := time.Parse(time.RFC3339, res.Data.Expiration); err == nil {
+		data.SetPremiumUntil(expiration)
+	}
+	// the slots are optional, the user is still valid without them
+	if count, err := c.getActiveTorrentCount(params.Ctx); err != nil {
+		log.Warn("failed to get active torrent count", "error", err)
+	} else {
+		data.ActiveSlots = &count.Active
+		data.MaxSlots = &count.Limit
+	}
 	return data, nil
 }
 
-func shouldRemoveTorrent(t *GetTorrentInfoData) bool {
+func (c *StoreClient) getActiveTorrentCount(ctx store.Ctx) (*activeTorrentCount, error) {
+	count := activeTorrentCount{}
+	cacheKey := c.getCacheKey(&ctx, "activeCount")
+	if c.activeCountCache.Get(cacheKey, &count) {
+		return &count, nil
+	}
+	res, err := c.client.GetActiveTorrentCount(&GetActiveTorrentCountParams{
+		Ctx: ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	count.Active = res.Data.Nb
+	count.Limit = res.Data.Limit
+	c.activeCountCache.Add(cacheKey, count)
+	return &count, nil
+}
+
+func shouldRemoveTorrent(t *GetTorrentInfoData, selection *store.MagnetFileSelection) bool {
 	status := t.Status
-	return (status == TorrentStatusMagnetError || status == TorrentStatusError || status == TorrentStatusVirus || status == TorrentStatusDead) || ((status == TorrentStatusQueued || status == TorrentStatusDownloading || status == TorrentStatusDownloaded) && len(getSelectedFileIdsFromTorrent(t)) != len(getVideoFileIdsFromTorrent(t)))
+	return (status == TorrentStatusMagnetError || status == TorrentStatusError || status == TorrentStatusVirus || status == TorrentStatusDead) || ((status == TorrentStatusQueued || status == TorrentStatusDownloading || status == TorrentStatusDownloaded) && len(getSelectedFileIdsFromTorrent(t)) != len(getFileIdsToSelectFromTorrent(t, selection)))
 }
 
 func (c *StoreClient) waitForTorrentStatus(ctx store.Ctx, t *GetTorrentInfoData, status TorrentStatus, maxRetry int, retryInterval time.Duration) (*GetTorrentInfoData, error) {
@@ -189,6 +229,21 @@ func getVideoFileIdsFromTorrent(t *GetTorrentInfoData) []string {
 	return fileIds
 }
 
+// getFileIdsToSelectFromTorrent returns the video files, unless the files
+// are explicitly selected.
+func getFileIdsToSelectFromTorrent(t *GetTorrentInfoData, selection *store.MagnetFileSelection) []string {
+	if selection == nil {
+		return getVideoFileIdsFromTorrent(t)
+	}
+	fileIds := []string{}
+	for _, f := range t.Files {
+		if selection.IsSelected(f.Id-1, f.Path) {
+			fileIds = append(fileIds, strconv.Itoa(f.Id))
+		}
+	}
+	return fileIds
+}
+
 func (f *GetTorrentInfoDataFile) toStoreMagnetFile() store.MagnetFile {
 	return store.MagnetFile{
 		Idx:  f.Id - 1,
@@ -198,6 +253,10 @@ func (f *GetTorrentInfoDataFile) toStoreMagnetFile() store.MagnetFile {
 	}
 }
 
+func (c *StoreClient) CanSelectMagnetFiles() bool {
+	return true
+}
+
 func (c *StoreClient) AddMagnet(params *store.AddMagnetParams) (*store.AddMagnetData, error) {
 	magnet, err := core.ParseMagnetLink(params.Magnet)
 	if err != nil {
@@ -224,7 +283,7 @@ func (c *StoreClient) AddMagnet(params *store.AddMagnetParams) (*store.AddMagnet
 			return nil, err
 		}
 		t = &tInfo.Data
-		if shouldRemoveTorrent(&tInfo.Data) {
+		if shouldRemoveTorrent(&tInfo.Data, params.Files) {
 			_, err := c.RemoveMagnet(&store.RemoveMagnetParams{
 				Ctx: params.Ctx,
 				Id:  t.Id,
@@ -260,10 +319,20 @@ func (c *StoreClient) AddMagnet(params *store.AddMagnetParams) (*store.AddMagnet
 		if err != nil {
 			return nil, err
 		}
+		fileIds := getFileIdsToSelectFromTorrent(t, params.Files)
+		if params.Files != nil && len(fileIds) == 0 {
+			if _, err := c.RemoveMagnet(&store.RemoveMagnetParams{
+				Ctx: params.Ctx,
+				Id:  t.Id,
+			}); err != nil {
+				return nil, err
+			}
+			return nil, store.ErrorNoFileSelected(string(store.StoreNameRealDebrid))
+		}
 		_, err = c.client.StartTorrentDownload(&StartTorrentDownloadParams{
 			Ctx:     params.Ctx,
 			Id:      t.Id,
-			FileIds: getVideoFileIdsFromTorrent(t),
+			FileIds: fileIds,
 			IP:      params.ClientIP,
 		})
 		if err != nil {
@@ -353,6 +422,38 @@ func (c *StoreClient) GenerateLink(params *store.GenerateLinkParams) (*store.Gen
 	return data, nil
 }
 
+func (c *StoreClient) ListHosts(params *store.ListHostsParams) (*store.ListHostsData, error) {
+	res, err := c.client.ListHostDomains(&ListHostDomainsParams{
+		Ctx: params.Ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.ListHostsData{
+		Items: res.Data,
+	}
+	return data, nil
+}
+
+func (c *StoreClient) UnrestrictLink(params *store.UnrestrictLinkParams) (*store.UnrestrictLinkData, error) {
+	res, err := c.client.UnrestrictLink(&UnrestrictLinkParams{
+		Ctx:      params.Ctx,
+		Link:     params.Link,
+		Password: params.Password,
+		IP:       params.ClientIP,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.UnrestrictLinkData{
+		Link: res.Data.Download,
+		Name: res.Data.Filename,
+		Size: int64(res.Data.Filesize),
+		Host: res.Data.Host,
+	}
+	return data, nil
+}
+
 func (c *StoreClient) getCachedGetMagnet(params request.Context, id string) *store.GetMagnetData {
 	v := store.GetMagnetData{}
 	if c.getMagnetCache.Get(params.GetAPIKey(c.client.apiKey)+":"+id, &v) {
@@ -347,3 +347,19 @@ func (c APIClient) DeleteTorrent(params *DeleteTorrentParams) (APIResponse[Delet
 	return newAPIResponse(res, *response), err
 
 }
+
+type GetActiveTorrentCountData struct {
+	*ResponseError
+	Nb    int `json:"nb"`    // Number of currently active torrents
+	Limit int `json:"limit"` // Maximum number of active torrents you can have
+}
+
+type GetActiveTorrentCountParams struct {
+	Ctx
+}
+
+func (c APIClient) GetActiveTorrentCount(params *GetActiveTorrentCountParams) (APIResponse[GetActiveTorrentCountData], error) {
+	response := &GetActiveTorrentCountData{}
+	res, err := c.Request("GET", "/rest/1.0/torrents/activeCount", params, response)
+	return newAPIResponse(res, *response), err
+}
@@ -12,53 +12,65 @@ type Ctx = request.Ctx
 type StoreName string
 
 const (
-	StoreNameAlldebrid  StoreName = "alldebrid"
-	StoreNameDebrider   StoreName = "debrider"
-	StoreNameDebridLink StoreName = "debridlink"
-	StoreNameEasyDebrid StoreName = "easydebrid"
-	StoreNameOffcloud   StoreName = "offcloud"
-	StoreNamePikPak     StoreName = "pikpak"
-	StoreNamePremiumize StoreName = "premiumize"
-	StoreNameRealDebrid StoreName = "realdebrid"
-	StoreNameTorBox     StoreName = "torbox"
+	StoreNameAlldebrid    StoreName = "alldebrid"
+	StoreNameDebrider     StoreName = "debrider"
+	StoreNameDebridLink   StoreName = "debridlink"
+	StoreNameEasyDebrid   StoreName = "easydebrid"
+	StoreNameLocal        StoreName = "local"
+	StoreNameOffcloud     StoreName = "offcloud"
+	StoreNamePikPak       StoreName = "pikpak"
+	StoreNamePremiumize   StoreName = "premiumize"
+	StoreNameQBittorrent  StoreName = "qbittorrent"
+	StoreNameRealDebrid   StoreName = "realdebrid"
+	StoreNameTorBox       StoreName = "torbox"
+	StoreNameTransmission StoreName = "transmission"
 )
 
 type StoreCode string
 
 const (
-	StoreCodeAllDebrid  StoreCode = "ad"
-	StoreCodeDebrider   StoreCode = "dr"
-	StoreCodeDebridLink StoreCode = "dl"
-	StoreCodeEasyDebrid StoreCode = "ed"
-	StoreCodeOffcloud   StoreCode = "oc"
-	StoreCodePikPak     StoreCode = "pp"
-	StoreCodePremiumize StoreCode = "pm"
-	StoreCodeRealDebrid StoreCode = "rd"
-	StoreCodeTorBox     StoreCode = "tb"
+	StoreCodeAllDebrid    StoreCode = "ad"
+	StoreCodeDebrider     StoreCode = "dr"
+	StoreCodeDebridLink   StoreCode = "dl"
+	StoreCodeEasyDebrid   StoreCode = "ed"
+	StoreCodeLocal        StoreCode = "lc"
+	StoreCodeOffcloud     StoreCode = "oc"
+	StoreCodePikPak       StoreCode = "pp"
+	StoreCodePremiumize   StoreCode = "pm"
+	StoreCodeQBittorrent  StoreCode = "qb"
+	StoreCodeRealDebrid   StoreCode = "rd"
+	StoreCodeTorBox       StoreCode = "tb"
+	StoreCodeTransmission StoreCode = "tr"
 )
 
 var storeCodeByName = map[StoreName]StoreCode{
-	StoreNameAlldebrid:  StoreCodeAllDebrid,
-	StoreNameDebrider:   StoreCodeDebrider,
-	StoreNameDebridLink: StoreCodeDebridLink,
-	StoreNameEasyDebrid: StoreCodeEasyDebrid,
-	StoreNameOffcloud:   StoreCodeOffcloud,
-	StoreNamePikPak:     StoreCodePikPak,
-	StoreNamePremiumize: StoreCodePremiumize,
-	StoreNameRealDebrid: StoreCodeRealDebrid,
-	StoreNameTorBox:     StoreCodeTorBox,
+	StoreNameAlldebrid:    StoreCodeAllDebrid,
+	StoreNameDebrider:     StoreCodeDebrider,
+	StoreNameDebridLink:   StoreCodeDebridLink,
+	StoreNameEasyDebrid:   StoreCodeEasyDebrid,
+	StoreNameLocal:        StoreCodeLocal,
+	StoreNameOffcloud:     StoreCodeOffcloud,
+	StoreNamePikPak:       StoreCodePikPak,
+	StoreNamePremiumize:   StoreCodePremiumize,
+	StoreNameQBittorrent:  StoreCodeQBittorrent,
+	StoreNameRealDebrid:   StoreCodeRealDebrid,
+	StoreNameTorBox:       StoreCodeTorBox,
+	StoreNameTransmission: StoreCodeTransmission,
 }
 
 var storeNameByCode = map[StoreCode]StoreName{
-	StoreCodeAllDebrid:  StoreNameAlldebrid,
-	StoreCodeDebrider:   StoreNameDebrider,
-	StoreCodeDebridLink: StoreNameDebridLink,
-	StoreCodeEasyDebrid: StoreNameEasyDebrid,
-	StoreCodeOffcloud:   StoreNameOffcloud,
-	StoreCodePikPak:     StoreNamePikPak,
-	StoreCodePremiumize: StoreNamePremiumize,
-	StoreCodeRealDebrid: StoreNameRealDebrid,
-	StoreCodeTorBox:     StoreNameTorBox,
+	StoreCodeAllDebrid:    StoreNameAlldebrid,
+	StoreCodeDebrider:     StoreNameDebrider,
+	StoreCodeDebridLink:   StoreNameDebridLink,
+	StoreCodeEasyDebrid:   StoreNameEasyDebrid,
+	StoreCodeLocal:        StoreNameLocal,
+	StoreCodeOffcloud:     StoreNameOffcloud,
+	StoreCodePikPak:       StoreNamePikPak,
+	StoreCodePremiumize:   StoreNamePremiumize,
+	StoreCodeQBittorrent:  StoreNameQBittorrent,
+	StoreCodeRealDebrid:   StoreNameRealDebrid,
+	StoreCodeTorBox:       StoreNameTorBox,
+	StoreCodeTransmission: StoreNameTransmission,
 }
 
 func (sn StoreName) Code() StoreCode {
@@ -70,6 +82,12 @@ func (sn StoreName) IsValid() bool {
 	return ok
 }
 
+// IsSelfHosted reports whether the store is self-hosted, i.e. a torrent
+// client or a local directory, whose files are served by StremThru itself.
+func (sn StoreName) IsSelfHosted() bool {
+	return sn == StoreNameLocal || sn == StoreNameQBittorrent || sn == StoreNameTransmission
+}
+
 func (sn StoreName) Validate() (StoreName, *core.StoreError) {
 	if !sn.IsValid() {
 		return sn, ErrorInvalidStoreName(string(sn))
@@ -98,6 +116,19 @@ type User struct {
 	Id                 string                 `json:"id"`
 	Email              string                 `json:"email"`
 	SubscriptionStatus UserSubscriptionStatus `json:"subscription_status"`
+	Plan               string                 `json:"plan,omitempty"`
+	PremiumUntil       *time.Time             `json:"premium_until,omitempty"`
+	Points             *int                   `json:"points,omitempty"`
+	ActiveSlots        *int                   `json:"active_slots,omitempty"`
+	MaxSlots           *int                   `json:"max_slots,omitempty"`
+}
+
+func (u *User) SetPremiumUntil(t time.Time) {
+	if t.IsZero() || t.Unix() <= 0 {
+		return
+	}
+	t = t.UTC()
+	u.PremiumUntil = &t
 }
 
 type GetUserParams struct {
@@ -168,10 +199,26 @@ type AddMagnetData struct {
 	AddedAt time.Time    `json:"added_at"`
 }
 
+type MagnetFileSelectionMode string
+
+const (
+	MagnetFileSelectionModeVideo MagnetFileSelectionMode = "video"
+	MagnetFileSelectionModeAll   MagnetFileSelectionMode = "all"
+)
+
+// MagnetFileSelection selects the files to download. A file is selected if
+// it matches the mode, any of the indices or any of the patterns.
+type MagnetFileSelection struct {
+	Mode     MagnetFileSelectionMode `json:"mode,omitempty"`
+	Indices  []int                   `json:"indices,omitempty"`  // MagnetFile.Idx
+	Patterns []string                `json:"patterns,omitempty"` // glob, matched against path and name
+}
+
 type AddMagnetParams struct {
 	Ctx
 	Magnet   string
 	ClientIP string
+	Files    *MagnetFileSelection // default: store behavior
 }
 
 type GetMagnetData struct {
@@ -200,8 +247,26 @@ type ListMagnetsDataItem struct {
 }
 
 type ListMagnetsData struct {
-	Items      []ListMagnetsDataItem `json:"items"`
-	TotalItems int                   `json:"total_items"`
+	Items       []ListMagnetsDataItem `json:"items"`
+	TotalItems  int                   `json:"total_items"`
+	IsTruncated bool                  `json:"is_truncated,omitempty"` // only a part of the magnets are queried
+}
+
+type ListMagnetsSortBy string
+
+const (
+	ListMagnetsSortByAddedAt ListMagnetsSortBy = "added_at"
+	ListMagnetsSortByName    ListMagnetsSortBy = "name"
+	ListMagnetsSortBySize    ListMagnetsSortBy = "size"
+)
+
+func (sortBy ListMagnetsSortBy) IsValid() bool {
+	switch sortBy {
+	case ListMagnetsSortByAddedAt, ListMagnetsSortByName, ListMagnetsSortBySize:
+		return true
+	default:
+		return false
+	}
 }
 
 type ListMagnetsParams struct {
@@ -209,6 +274,13 @@ type ListMagnetsParams struct {
 	Limit    int // min 1, max 500, default 100
 	Offset   int // default 0
 	ClientIP string
+
+	Query       string         // match all the words in name, case-insensitive
+	Status      []MagnetStatus // match any of the statuses
+	AddedAfter  time.Time
+	AddedBefore time.Time
+	SortBy      ListMagnetsSortBy // default: store order
+	SortDesc    bool
 }
 
 type RemoveMagnetData struct {
@@ -240,3 +312,45 @@ type Store interface {
 	RemoveMagnet(params *RemoveMagnetParams) (*RemoveMagnetData, error)
 	GenerateLink(params *GenerateLinkParams) (*GenerateLinkData, error)
 }
+
+type UnrestrictLinkData struct {
+	Link string `json:"link"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	Host string `json:"host"`
+}
+
+type UnrestrictLinkParams struct {
+	Ctx
+	Link     string
+	Password string
+	ClientIP string
+}
+
+type ListHostsData struct {
+	Items []string `json:"items"` // host domains, e.g. 1fichier.com
+}
+
+type ListHostsParams struct {
+	Ctx
+}
+
+// ListMagnetsQuerier is implemented by stores whose ListMagnets handles the
+// search, filter and sort in ListMagnetsParams on its own. For other stores,
+// it is emulated by listing all the magnets.
+type ListMagnetsQuerier interface {
+	CanQueryListMagnets() bool
+}
+
+// MagnetFileSelector is implemented by stores whose AddMagnet handles the
+// file selection in AddMagnetParams.
+type MagnetFileSelector interface {
+	CanSelectMagnetFiles() bool
+}
+
+// LinkUnrestrictor is implemented by stores that can unrestrict links
+// from third-party file hosters, e.g. 1fichier or rapidgator.
+type LinkUnrestrictor interface {
+	ListHosts(params *ListHostsParams) (*ListHostsData, error)
+	UnrestrictLink(params *UnrestrictLinkParams) (*UnrestrictLinkData, error)
+}
@@ -3,6 +3,7 @@ package torbox
 import (
 	"errors"
 	"net/http"
+	"net/url"
 	"slices"
 	"strconv"
 	"strings"
@@ -91,6 +92,10 @@ func (c *StoreClient) GetUser(params *store.GetUserParams) (*store.User, error)
 	data := &store.User{
 		Id:    strconv.Itoa(res.Data.Id),
 		Email: res.Data.Email,
+		Plan:  res.Data.Plan.String(),
+	}
+	if premiumExpiresAt, err := time.Parse(time.RFC3339, res.Data.PremiumExpiresAt); err == nil {
+		data.SetPremiumUntil(premiumExpiresAt)
 	}
 	if res.Data.Plan == PlanFree {
 		data.SubscriptionStatus = store.UserSubscriptionStatusTrial
@@ -497,3 +502,120 @@ func (c *StoreClient) RemoveMagnet(params *store.RemoveMagnetParams) (*store.Rem
 	data := &store.RemoveMagnetData{Id: params.Id}
 	return data, nil
 }
+
+func (c *StoreClient) ListHosts(params *store.ListHostsParams) (*store.ListHostsData, error) {
+	res, err := c.client.ListWebDLHosters(&ListWebDLHostersParams{
+		Ctx: params.Ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := []string{}
+	for _, hoster := range res.Data {
+		if hoster.Status {
+			items = append(items, hoster.Domains...)
+		}
+	}
+	data := &store.ListHostsData{
+		Items: items,
+	}
+	return data, nil
+}
+
+// getWebDLDownloadId returns the id of the existing web download for the
+// link, or creates a new one.
+func (c *StoreClient) getWebDLDownloadId(params *store.UnrestrictLinkParams) (int, error) {
+	res, err := c.client.ListWebDLDownload(&ListWebDLDownloadParams{
+		Ctx:         params.Ctx,
+		BypassCache: true,
+	})
+	if err != nil {
+		return 0, err
+	}
+	for i := range res.Data {
+		if res.Data[i].OriginalUrl == params.Link {
+			return res.Data[i].Id, nil
+		}
+	}
+
+	cwdl, err := c.client.CreateWebDLDownload(&CreateWebDLDownloadParams{
+		Ctx:      params.Ctx,
+		Link:     params.Link,
+		Password: params.Password,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return cwdl.Data.UsenetDownloadId, nil
+}
+
+func (c *StoreClient) waitForWebDLDownload(ctx store.Ctx, id int, maxRetry int, retryInterval time.Duration) (*WebDLDownload, error) {
+	for retry := 0; ; retry++ {
+		res, err := c.client.GetWebDLDownload(&GetWebDLDownloadParams{
+			Ctx:         ctx,
+			Id:          id,
+			BypassCache: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		webdl := &res.Data
+		if (webdl.DownloadFinished && webdl.DownloadPresent) || retry >= maxRetry {
+			return webdl, nil
+		}
+		time.Sleep(retryInterval)
+	}
+}
+
+func (c *StoreClient) UnrestrictLink(params *store.UnrestrictLinkParams) (*store.UnrestrictLinkData, error) {
+	webdlId, err := c.getWebDLDownloadId(params)
+	if err != nil {
+		return nil, err
+	}
+
+	webdl, err := c.waitForWebDLDownload(params.Ctx, webdlId, 5, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if !webdl.DownloadFinished || !webdl.DownloadPresent {
+		error := core.NewStoreError("link is being downloaded, try later")
+		error.StoreName = string(store.StoreNameTorBox)
+		error.StatusCode = http.StatusTeapot
+		return nil, error
+	}
+
+	var file *WebDLDownloadFile
+	for i := range webdl.Files {
+		f := &webdl.Files[i]
+		if file == nil || f.Size > file.Size {
+			file = f
+		}
+	}
+	if file == nil {
+		error := core.NewAPIError("no file found for link")
+		error.StoreName = string(store.StoreNameTorBox)
+		error.StatusCode = http.StatusNotFound
+		error.Code = core.ErrorCodeNotFound
+		return nil, error
+	}
+
+	rdl, err := c.client.RequestWebDLDownloadLink(&RequestWebDLDownloadLinkParams{
+		Ctx:     params.Ctx,
+		WebDLId: webdlId,
+		FileId:  file.Id,
+		UserIP:  params.ClientIP,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := &store.UnrestrictLinkData{
+		Link: rdl.Data.Link,
+		Name: file.ShortName,
+		Size: file.Size,
+	}
+	if u, err := url.Parse(params.Link); err == nil {
+		data.Host = u.Hostname()
+	}
+	return data, nil
+}
@@ -14,6 +14,21 @@ const (
 	PlanStandard
 )
 
+func (p Plan) String() string {
+	switch p {
+	case PlanFree:
+		return "Free"
+	case PlanEssential:
+		return "Essential"
+	case PlanPro:
+		return "Pro"
+	case PlanStandard:
+		return "Standard"
+	default:
+		return ""
+	}
+}
+
 type GetUserData struct {
 	Id               int             `json:"id"`
 	CreatedAt        string          `json:"created_at"`
@@ -214,3 +214,29 @@ func (c APIClient) RequestWebDLDownloadLink(params *RequestWebDLDownloadLinkPara
 	res, err := c.Request("GET", "/v1/api/webdl/requestdl", params, response)
 	return newAPIResponse(res, RequestDownloadLinkData{Link: response.Data}, response.Detail), err
 }
+
+type WebDLHoster struct {
+	Name                string   `json:"name"`
+	Domains             []string `json:"domains"`
+	Url                 string   `json:"url"`
+	Icon                string   `json:"icon"`
+	Status              bool     `json:"status"`
+	Type                string   `json:"type"`
+	Note                string   `json:"note"`
+	DailyLinkLimit      int      `json:"daily_link_limit"`
+	DailyLinkUsed       int      `json:"daily_link_used"`
+	DailyBandwidthLimit int64    `json:"daily_bandwidth_limit"`
+	DailyBandwidthUsed  int64    `json:"daily_bandwidth_used"`
+}
+
+type ListWebDLHostersData []WebDLHoster
+
+type ListWebDLHostersParams struct {
+	Ctx
+}
+
+func (c APIClient) ListWebDLHosters(params *ListWebDLHostersParams) (APIResponse[ListWebDLHostersData], error) {
+	response := &Response[ListWebDLHostersData]{}
+	res, err := c.Request("GET", "/v1/api/webdl/hosters", params, response)
+	return newAPIResponse(res, response.Data, response.Detail), err
+}
@@ -0,0 +1,176 @@
+package transmission
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/core"
+	"github.com/MunifTanjim/stremthru/internal/cache"
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/internal/request"
+	"github.com/MunifTanjim/stremthru/store"
+)
+
+var DefaultHTTPClient = config.DefaultHTTPClient
+
+const headerSessionId = "X-Transmission-Session-Id"
+
+type APIClientConfig struct {
+	HTTPClient *http.Client
+	UserAgent  string
+}
+
+type APIClient struct {
+	HTTPClient *http.Client
+	agent      string
+	reqQuery   func(query *url.Values, params request.Context)
+	reqHeader  func(query *http.Header, params request.Context)
+
+	sessionIdCache cache.Cache[string]
+}
+
+func NewAPIClient(conf *APIClientConfig) *APIClient {
+	if conf.UserAgent == "" {
+		conf.UserAgent = "stremthru"
+	}
+
+	if conf.HTTPClient == nil {
+		conf.HTTPClient = DefaultHTTPClient
+	}
+
+	c := &APIClient{}
+
+	c.HTTPClient = conf.HTTPClient
+	c.agent = conf.UserAgent
+
+	c.reqQuery = func(query *url.Values, params request.Context) {
+	}
+
+	c.reqHeader = func(header *http.Header, params request.Context) {
+		header.Add("User-Agent", c.agent)
+	}
+
+	c.sessionIdCache = cache.NewCache[string](&cache.CacheConfig{
+		Name:     "store:transmission:sessionId",
+		Lifetime: 30 * time.Minute,
+	})
+
+	return c
+}
+
+type Ctx = request.Ctx
+
+type apiCredential struct {
+	rpcURL   *url.URL
+	username string
+	password string
+}
+
+// authenticate checks that the API key is configured in `STREMTHRU_STORE_AUTH`
+// for a user from `STREMTHRU_PROXY_AUTH`, so that the requests are only sent
+// to the clients set up by the operator.
+func authenticate(apiKey string) error {
+	if config.IsPublicInstance {
+		error := core.NewStoreError("store not available on public instance")
+		error.StoreName = string(store.StoreNameTransmission)
+		error.StatusCode = http.StatusForbidden
+		error.Code = core.ErrorCodeForbidden
+		return error
+	}
+	if !config.StoreAuthToken.HasToken(string(store.StoreNameTransmission), apiKey) {
+		error := core.NewStoreError("invalid api key")
+		error.StoreName = string(store.StoreNameTransmission)
+		error.StatusCode = http.StatusUnauthorized
+		error.Code = core.ErrorCodeUnauthorized
+		return error
+	}
+	return nil
+}
+
+// parseAPIKey parses the API key in `http(s)://username:password@host:port`
+// format. The RPC path defaults to `/transmission/rpc`.
+func parseAPIKey(apiKey string) (*apiCredential, error) {
+	u, err := url.Parse(apiKey)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		err := core.NewStoreError("invalid api key, expected rpc url with credentials")
+		err.StoreName = string(store.StoreNameTransmission)
+		err.StatusCode = http.StatusUnauthorized
+		err.Code = core.ErrorCodeUnauthorized
+		return nil, err
+	}
+	cred := &apiCredential{
+		username: u.User.Username(),
+	}
+	cred.password, _ = u.User.Password()
+	u.User = nil
+	if strings.Trim(u.Path, "/") == "" {
+		u.Path = "/transmission/rpc"
+	}
+	cred.rpcURL = u
+	return cred, nil
+}
+
+type rpcRequest struct {
+	Method    string `json:"method"`
+	Arguments any    `json:"arguments,omitempty"`
+}
+
+// Request calls the RPC endpoint. Transmission responds with 409 and a new
+// session id when it is missing or expired, the request is then retried.
+func (c APIClient) Request(params request.Context, v request.ResponseContainer) (*http.Response, error) {
+	if params == nil {
+		params = &Ctx{}
+	}
+	apiKey := params.GetAPIKey("")
+	if err := authenticate(apiKey); err != nil {
+		return nil, err
+	}
+	cred, err := parseAPIKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *http.Response
+	for attempt := range 2 {
+		req, err := params.NewRequest(cred.rpcURL, http.MethodPost, "", c.reqHeader, c.reqQuery)
+		if err != nil {
+			error := core.NewStoreError("failed to create request")
+			error.StoreName = string(store.StoreNameTransmission)
+			error.Cause = err
+			return nil, error
+		}
+		if cred.username != "" || cred.password != "" {
+			req.SetBasicAuth(cred.username, cred.password)
+		}
+		sessionId := ""
+		if c.sessionIdCache.Get(apiKey, &sessionId) {
+			req.Header.Set(headerSessionId, sessionId)
+		}
+
+		res, err = c.HTTPClient.Do(req)
+		if err == nil && res.StatusCode == http.StatusConflict && attempt == 0 {
+			res.Body.Close()
+			if sessionId := res.Header.Get(headerSessionId); sessionId != "" {
+				c.sessionIdCache.Add(apiKey, sessionId)
+				continue
+			}
+		}
+
+		err = request.ProcessResponseBody(res, err, v)
+		if err != nil {
+			err := UpstreamErrorWithCause(err)
+			err.InjectReq(req)
+			if res != nil && res.StatusCode >= http.StatusBadRequest {
+				err.StatusCode = res.StatusCode
+				if res.StatusCode == http.StatusUnauthorized {
+					err.Code = core.ErrorCodeUnauthorized
+				}
+			}
+			return res, err
+		}
+		break
+	}
+	return res, nil
+}
@@ -0,0 +1,57 @@
+package transmission
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/MunifTanjim/stremthru/core"
+)
+
+type ResponseContainer struct {
+	Result string `json:"result"`
+}
+
+func (e *ResponseContainer) Error() string {
+	ret, _ := json.Marshal(e)
+	return string(ret)
+}
+
+func (r *ResponseContainer) GetError(res *http.Response) error {
+	if res.StatusCode >= http.StatusBadRequest || r.Result != "success" {
+		return r
+	}
+	return nil
+}
+
+func (r *ResponseContainer) Unmarshal(res *http.Response, body []byte, v any) error {
+	contentType := res.Header.Get("Content-Type")
+	if res.StatusCode >= http.StatusBadRequest || !strings.Contains(contentType, "application/json") {
+		r.Result = http.StatusText(res.StatusCode)
+		return nil
+	}
+	return core.UnmarshalJSON(res.StatusCode, body, v)
+}
+
+type rpcResponse[T any] struct {
+	ResponseContainer
+	Arguments T `json:"arguments"`
+}
+
+type APIResponse[T any] struct {
+	Header     http.Header
+	StatusCode int
+	Data       T
+}
+
+func newAPIResponse[T any](res *http.Response, data T) APIResponse[T] {
+	apiResponse := APIResponse[T]{
+		StatusCode: 503,
+		Data:       data,
+	}
+	if res != nil {
+		apiResponse.Header = res.Header
+		apiResponse.StatusCode = res.StatusCode
+	}
+	return apiResponse
+}
@@ -0,0 +1,434 @@
+package transmission
+
+import (
+	"cmp"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/core"
+	store_file "github.com/MunifTanjim/stremthru/internal/store/file"
+	"github.com/MunifTanjim/stremthru/store"
+)
+
+func getMagnetStatusFromTorrent(t *Torrent) store.MagnetStatus {
+	if t.Error != 0 {
+		return store.MagnetStatusFailed
+	}
+	if t.IsCompleted() {
+		return store.MagnetStatusDownloaded
+	}
+	if t.MetadataPercentComplete < 1 {
+		return store.MagnetStatusQueued
+	}
+	switch t.Status {
+	case TorrentStatusCheckWait, TorrentStatusCheck, TorrentStatusDownloadWait:
+		return store.MagnetStatusQueued
+	case TorrentStatusDownload, TorrentStatusStopped:
+		return store.MagnetStatusDownloading
+	case TorrentStatusSeedWait, TorrentStatusSeed:
+		return store.MagnetStatusDownloaded
+	default:
+		return store.MagnetStatusUnknown
+	}
+}
+
+type StoreClientConfig struct {
+	HTTPClient *http.Client
+	UserAgent  string
+}
+
+type StoreClient struct {
+	Name   store.StoreName
+	client *APIClient
+	config *StoreClientConfig
+}
+
+func (s *StoreClient) GetName() store.StoreName {
+	return s.Name
+}
+
+type LockedFileLink string
+
+const lockedFileLinkPrefix = "stremthru://store/transmission/"
+
+func (l LockedFileLink) encodeData(hash string, fileIdx int) string {
+	return core.Base64Encode(hash + ":" + strconv.Itoa(fileIdx))
+}
+
+func (l LockedFileLink) decodeData(encoded string) (hash string, fileIdx int, err error) {
+	decoded, err := core.Base64Decode(encoded)
+	if err != nil {
+		return "", 0, err
+	}
+	hash, idx, found := strings.Cut(decoded, ":")
+	if !found {
+		return "", 0, core.NewAPIError("invalid link")
+	}
+	fileIdx, err = strconv.Atoi(idx)
+	if err != nil {
+		return "", 0, err
+	}
+	return hash, fileIdx, nil
+}
+
+func (l LockedFileLink) Create(hash string, fileIdx int) string {
+	return lockedFileLinkPrefix + l.encodeData(hash, fileIdx)
+}
+
+func (l LockedFileLink) Parse() (hash string, fileIdx int, err error) {
+	encoded := strings.TrimPrefix(string(l), lockedFileLinkPrefix)
+	return l.decodeData(encoded)
+}
+
+func (s *StoreClient) getTorrent(ctx Ctx, hash string) (*Torrent, error) {
+	res, err := s.client.ListTorrents(&ListTorrentsParams{
+		Ctx:    ctx,
+		Hashes: []string{hash},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range res.Data {
+		if strings.EqualFold(res.Data[i].HashString, hash) {
+			return &res.Data[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *StoreClient) getFiles(t *Torrent) []store.MagnetFile {
+	hash := strings.ToLower(t.HashString)
+	source := string(s.GetName().Code())
+	files := make([]store.MagnetFile, len(t.Files))
+	for i := range t.Files {
+		f := &t.Files[i]
+		files[i] = store.MagnetFile{
+			Idx:    i,
+			Link:   LockedFileLink("").Create(hash, i),
+			Path:   f.GetPath(),
+			Name:   f.GetName(),
+			Size:   f.Length,
+			Source: source,
+		}
+	}
+	return files
+}
+
+// selectFiles marks the torrent's files as wanted / unwanted as per the
+// selection. The files are listed once the metadata is fetched, so it waits a
+// while. If no file matches, the torrent is removed if it was just added.
+func (s *StoreClient) selectFiles(ctx Ctx, t *Torrent, selection *store.MagnetFileSelection, isNew bool) (*Torrent, error) {
+	for retry := 0; len(t.Files) == 0 && retry < 5; retry++ {
+		time.Sleep(2 * time.Second)
+		tt, err := s.getTorrent(ctx, t.HashString)
+		if err != nil {
+			return nil, err
+		}
+		if tt != nil {
+			t = tt
+		}
+	}
+	if len(t.Files) == 0 {
+		err := core.NewStoreError("torrent metadata is not available yet, file selection is not applied")
+		err.StatusCode = http.StatusServiceUnavailable
+		err.StoreName = string(store.StoreNameTransmission)
+		return nil, err
+	}
+
+	wanted, unwanted := []int{}, []int{}
+	for i := range t.Files {
+		if selection.IsSelected(i, t.Files[i].GetPath()) {
+			wanted = append(wanted, i)
+		} else {
+			unwanted = append(unwanted, i)
+		}
+	}
+	if len(wanted) == 0 {
+		if isNew {
+			if _, err := s.RemoveMagnet(&store.RemoveMagnetParams{Ctx: ctx, Id: t.HashString}); err != nil {
+				return nil, err
+			}
+		}
+		return nil, store.ErrorNoFileSelected(string(store.StoreNameTransmission))
+	}
+
+	if _, err := s.client.SetTorrentFiles(&SetTorrentFilesParams{
+		Ctx:           ctx,
+		Hash:          t.HashString,
+		FilesWanted:   wanted,
+		FilesUnwanted: unwanted,
+	}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func errorMagnetNotFound() error {
+	err := core.NewAPIError("not found")
+	err.StatusCode = http.StatusNotFound
+	err.StoreName = string(store.StoreNameTransmission)
+	return err
+}
+
+func (s *StoreClient) CanSelectMagnetFiles() bool {
+	return true
+}
+
+func (s *StoreClient) AddMagnet(params *store.AddMagnetParams) (*store.AddMagnetData, error) {
+	magnet, err := core.ParseMagnetLink(params.Magnet)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.client.AddTorrent(&AddTorrentParams{
+		Ctx:      params.Ctx,
+		Filename: magnet.RawLink,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := &store.AddMagnetData{
+		Id:     magnet.Hash,
+		Hash:   magnet.Hash,
+		Magnet: magnet.Link,
+		Name:   magnet.Name,
+		Status: store.MagnetStatusQueued,
+		Files:  []store.MagnetFile{},
+	}
+
+	t, err := s.getTorrent(params.Ctx, magnet.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil && params.Files != nil {
+		// torrent is added asynchronously, it may not be listed yet
+		t = &Torrent{HashString: magnet.Hash}
+	}
+	if t == nil {
+		if added := res.Data.TorrentAdded; added != nil && added.Name != "" {
+			data.Name = added.Name
+		}
+		return data, nil
+	}
+
+	if params.Files != nil {
+		isNew := res.Data.TorrentDuplicate == nil
+		if t, err = s.selectFiles(params.Ctx, t, params.Files, isNew); err != nil {
+			return nil, err
+		}
+	}
+
+	data.Name = t.Name
+	data.Size = t.TotalSize
+	data.Status = getMagnetStatusFromTorrent(t)
+	data.AddedAt = t.GetAddedAt()
+	data.Files = s.getFiles(t)
+	return data, nil
+}
+
+// CheckMagnet reports the completed torrents in the client as cached.
+func (s *StoreClient) CheckMagnet(params *store.CheckMagnetParams) (*store.CheckMagnetData, error) {
+	magnets := make([]core.MagnetLink, len(params.Magnets))
+	hashes := make([]string, len(params.Magnets))
+	for i, m := range params.Magnets {
+		magnet, err := core.ParseMagnetLink(m)
+		if err != nil {
+			return nil, err
+		}
+		magnets[i] = magnet
+		hashes[i] = magnet.Hash
+	}
+
+	res, err := s.client.ListTorrents(&ListTorrentsParams{
+		Ctx:    params.Ctx,
+		Hashes: hashes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	torrentByHash := make(map[string]*Torrent, len(res.Data))
+	for i := range res.Data {
+		torrentByHash[strings.ToLower(res.Data[i].HashString)] = &res.Data[i]
+	}
+
+	data := &store.CheckMagnetData{
+		Items: make([]store.CheckMagnetDataItem, 0, len(magnets)),
+	}
+	for _, magnet := range magnets {
+		item := store.CheckMagnetDataItem{
+			Hash:   magnet.Hash,
+			Magnet: magnet.Link,
+			Status: store.MagnetStatusUnknown,
+			Files:  []store.MagnetFile{},
+		}
+		if t, ok := torrentByHash[magnet.Hash]; ok && t.IsCompleted() {
+			files := s.getFiles(t)
+			for i := range files {
+				files[i].Link = ""
+			}
+			item.Name = t.Name
+			item.Size = t.TotalSize
+			item.Status = store.MagnetStatusCached
+			item.Files = files
+		}
+		data.Items = append(data.Items, item)
+	}
+	return data, nil
+}
+
+func (s *StoreClient) GenerateLink(params *store.GenerateLinkParams) (*store.GenerateLinkData, error) {
+	hash, fileIdx, err := LockedFileLink(params.Link).Parse()
+	if err != nil {
+		error := core.NewAPIError("invalid link")
+		error.StoreName = string(store.StoreNameTransmission)
+		error.StatusCode = http.StatusBadRequest
+		error.Cause = err
+		return nil, error
+	}
+
+	t, err := s.getTorrent(params.Ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, errorMagnetNotFound()
+	}
+
+	if fileIdx < 0 || fileIdx >= len(t.Files) {
+		err := core.NewAPIError("file not found")
+		err.StoreName = string(store.StoreNameTransmission)
+		err.StatusCode = http.StatusNotFound
+		return nil, err
+	}
+	file := &t.Files[fileIdx]
+	if !file.IsCompleted() {
+		err := core.NewAPIError("file not downloaded yet")
+		err.StoreName = string(store.StoreNameTransmission)
+		err.StatusCode = http.StatusConflict
+		err.Code = core.ErrorCodeConflict
+		return nil, err
+	}
+
+	localPath, err := store_file.ResolvePath(path.Join(t.DownloadDir, file.Name))
+	if err != nil {
+		return nil, err
+	}
+	link, err := store_file.CreateLink(localPath)
+	if err != nil {
+		return nil, err
+	}
+	return &store.GenerateLinkData{Link: link}, nil
+}
+
+func (s *StoreClient) GetMagnet(params *store.GetMagnetParams) (*store.GetMagnetData, error) {
+	t, err := s.getTorrent(params.Ctx, params.Id)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, errorMagnetNotFound()
+	}
+	data := &store.GetMagnetData{
+		Id:      strings.ToLower(t.HashString),
+		Hash:    strings.ToLower(t.HashString),
+		Name:    t.Name,
+		Size:    t.TotalSize,
+		Status:  getMagnetStatusFromTorrent(t),
+		Files:   s.getFiles(t),
+		AddedAt: t.GetAddedAt(),
+	}
+	return data, nil
+}
+
+func (s *StoreClient) GetUser(params *store.GetUserParams) (*store.User, error) {
+	cred, err := parseAPIKey(params.GetAPIKey(""))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.client.GetSession(&GetSessionParams{Ctx: params.Ctx}); err != nil {
+		return nil, err
+	}
+	data := &store.User{
+		Id:                 cred.username + "@" + cred.rpcURL.Host,
+		Email:              cred.username,
+		SubscriptionStatus: store.UserSubscriptionStatusPremium,
+	}
+	return data, nil
+}
+
+func (s *StoreClient) CanQueryListMagnets() bool {
+	return true
+}
+
+func (s *StoreClient) ListMagnets(params *store.ListMagnetsParams) (*store.ListMagnetsData, error) {
+	res, err := s.client.ListTorrents(&ListTorrentsParams{
+		Ctx: params.Ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	torrents := res.Data
+	slices.SortStableFunc(torrents, func(a, b Torrent) int {
+		return cmp.Compare(b.AddedDate, a.AddedDate)
+	})
+
+	lm := make([]store.ListMagnetsDataItem, 0, len(torrents))
+	for i := range torrents {
+		t := &torrents[i]
+		lm = append(lm, store.ListMagnetsDataItem{
+			Id:      strings.ToLower(t.HashString),
+			Hash:    strings.ToLower(t.HashString),
+			Name:    t.Name,
+			Size:    t.TotalSize,
+			Status:  getMagnetStatusFromTorrent(t),
+			AddedAt: t.GetAddedAt(),
+		})
+	}
+	lm = store.QueryListMagnetsItems(params, lm)
+
+	totalItems := len(lm)
+	limit := params.Limit
+	if limit == 0 {
+		limit = 100
+	}
+	start := min(params.Offset, totalItems)
+	end := min(start+limit, totalItems)
+
+	data := &store.ListMagnetsData{
+		Items:      lm[start:end],
+		TotalItems: totalItems,
+	}
+	return data, nil
+}
+
+func (s *StoreClient) RemoveMagnet(params *store.RemoveMagnetParams) (*store.RemoveMagnetData, error) {
+	_, err := s.client.RemoveTorrents(&RemoveTorrentsParams{
+		Ctx:             params.Ctx,
+		Hashes:          []string{params.Id},
+		DeleteLocalData: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	data := &store.RemoveMagnetData{
+		Id: params.Id,
+	}
+	return data, nil
+}
+
+func NewStoreClient(config *StoreClientConfig) *StoreClient {
+	c := &StoreClient{}
+	c.client = NewAPIClient(&APIClientConfig{
+		HTTPClient: config.HTTPClient,
+		UserAgent:  config.UserAgent,
+	})
+	c.Name = store.StoreNameTransmission
+	c.config = config
+	return c
+}
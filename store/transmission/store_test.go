@@ -0,0 +1,218 @@
+package transmission
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/MunifTanjim/stremthru/internal/config"
+	"github.com/MunifTanjim/stremthru/store"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	hashCompleted   = "db42af2b6ae098558b98a14cd7874ef64162cac8"
+	hashDownloading = "0123456789abcdef0123456789abcdef01234567"
+	hashNew         = "89abcdef0123456789abcdef0123456789abcdef"
+)
+
+func newStandInServer(t *testing.T) *httptest.Server {
+	torrents := []Torrent{
+		{
+			Id: 1, HashString: hashCompleted, Name: "Movie", TotalSize: 100,
+			PercentDone: 1, MetadataPercentComplete: 1, Status: TorrentStatusSeed, DownloadDir: "/downloads",
+			Files: []TorrentFile{
+				{Name: "Movie/Movie.mkv", Length: 90, BytesCompleted: 90},
+				{Name: "Movie/Movie.srt", Length: 10, BytesCompleted: 10},
+			},
+		},
+		{
+			Id: 2, HashString: hashDownloading, Name: "Show", TotalSize: 200,
+			PercentDone: 0.4, MetadataPercentComplete: 1, Status: TorrentStatusDownload, DownloadDir: "/downloads",
+			Files: []TorrentFile{
+				{Name: "Show.S01E01.mkv", Length: 200, BytesCompleted: 80},
+			},
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/transmission/rpc", func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(headerSessionId) != "session" {
+			w.Header().Set(headerSessionId, "session")
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		req := struct {
+			Method    string         `json:"method"`
+			Arguments map[string]any `json:"arguments"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		arguments := map[string]any{}
+		switch req.Method {
+		case "torrent-get":
+			items := []Torrent{}
+			ids, _ := req.Arguments["ids"].([]any)
+			for _, t := range torrents {
+				if ids == nil || slices.Contains(ids, any(t.HashString)) {
+					items = append(items, t)
+				}
+			}
+			arguments["torrents"] = items
+		case "torrent-add":
+			filename, _ := req.Arguments["filename"].(string)
+			if !strings.Contains(filename, hashNew) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"result":"invalid or corrupt torrent file"}`))
+				return
+			}
+			t := Torrent{Id: 3, HashString: hashNew, Name: "New", Status: TorrentStatusDownloadWait}
+			torrents = append(torrents, t)
+			arguments["torrent-added"] = t
+		case "session-get":
+			arguments["version"] = "4.0.0"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"result":    "success",
+			"arguments": arguments,
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestStoreClient(t *testing.T) (*StoreClient, string) {
+	isPublicInstance := config.IsPublicInstance
+	config.IsPublicInstance = false
+	t.Cleanup(func() {
+		config.IsPublicInstance = isPublicInstance
+	})
+
+	server := newStandInServer(t)
+	apiKey := strings.Replace(server.URL, "http://", "http://admin:secret@", 1)
+	storeAuthToken, hasStoreAuthToken := config.StoreAuthToken["*"]
+	config.StoreAuthToken["*"] = map[string]string{string(store.StoreNameTransmission): apiKey}
+	t.Cleanup(func() {
+		if hasStoreAuthToken {
+			config.StoreAuthToken["*"] = storeAuthToken
+		} else {
+			delete(config.StoreAuthToken, "*")
+		}
+	})
+	return NewStoreClient(&StoreClientConfig{HTTPClient: server.Client()}), apiKey
+}
+
+func TestGetMagnetStatusFromTorrent(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		torrent Torrent
+		status  store.MagnetStatus
+	}{
+		{"metadata", Torrent{MetadataPercentComplete: 0.5, Status: TorrentStatusDownload}, store.MagnetStatusQueued},
+		{"queued", Torrent{MetadataPercentComplete: 1, Status: TorrentStatusDownloadWait}, store.MagnetStatusQueued},
+		{"downloading", Torrent{MetadataPercentComplete: 1, PercentDone: 0.5, Status: TorrentStatusDownload}, store.MagnetStatusDownloading},
+		{"downloaded", Torrent{MetadataPercentComplete: 1, PercentDone: 1, Status: TorrentStatusStopped}, store.MagnetStatusDownloaded},
+		{"seeding", Torrent{MetadataPercentComplete: 1, PercentDone: 1, Status: TorrentStatusSeed}, store.MagnetStatusDownloaded},
+		{"error", Torrent{MetadataPercentComplete: 1, Error: 3, Status: TorrentStatusStopped}, store.MagnetStatusFailed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.status, getMagnetStatusFromTorrent(&tc.torrent))
+		})
+	}
+}
+
+func TestCheckMagnet(t *testing.T) {
+	s, apiKey := newTestStoreClient(t)
+
+	params := &store.CheckMagnetParams{
+		Magnets: []string{hashCompleted, hashDownloading, hashNew},
+	}
+	params.APIKey = apiKey
+	data, err := s.CheckMagnet(params)
+	assert.NoError(t, err)
+	assert.Len(t, data.Items, 3)
+
+	for _, tc := range []struct {
+		hash   string
+		status store.MagnetStatus
+		files  int
+	}{
+		{hashCompleted, store.MagnetStatusCached, 2},
+		{hashDownloading, store.MagnetStatusUnknown, 0},
+		{hashNew, store.MagnetStatusUnknown, 0},
+	} {
+		t.Run(tc.hash, func(t *testing.T) {
+			for _, item := range data.Items {
+				if item.Hash == tc.hash {
+					assert.Equal(t, tc.status, item.Status)
+					assert.Len(t, item.Files, tc.files)
+					for _, f := range item.Files {
+						assert.Empty(t, f.Link)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAddMagnet(t *testing.T) {
+	s, apiKey := newTestStoreClient(t)
+
+	params := &store.AddMagnetParams{Magnet: hashNew}
+	params.APIKey = apiKey
+	data, err := s.AddMagnet(params)
+	assert.NoError(t, err)
+	assert.Equal(t, hashNew, data.Hash)
+	assert.Equal(t, store.MagnetStatusQueued, data.Status)
+
+	params = &store.AddMagnetParams{Magnet: hashDownloading}
+	params.APIKey = apiKey
+	_, err = s.AddMagnet(params)
+	assert.Error(t, err)
+}
+
+func TestGetMagnet(t *testing.T) {
+	s, apiKey := newTestStoreClient(t)
+
+	for _, tc := range []struct {
+		hash   string
+		status store.MagnetStatus
+		paths  []string
+	}{
+		{hashCompleted, store.MagnetStatusDownloaded, []string{"/Movie.mkv", "/Movie.srt"}},
+		{hashDownloading, store.MagnetStatusDownloading, []string{"/Show.S01E01.mkv"}},
+	} {
+		t.Run(tc.hash, func(t *testing.T) {
+			params := &store.GetMagnetParams{Id: tc.hash}
+			params.APIKey = apiKey
+			data, err := s.GetMagnet(params)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.status, data.Status)
+			paths := []string{}
+			for _, f := range data.Files {
+				paths = append(paths, f.Path)
+			}
+			assert.Equal(t, tc.paths, paths)
+		})
+	}
+
+	params := &store.GetMagnetParams{Id: hashNew}
+	params.APIKey = apiKey
+	_, err := s.GetMagnet(params)
+	assert.Error(t, err)
+}
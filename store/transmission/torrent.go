@@ -0,0 +1,193 @@
+package transmission
+
+import (
+	"path"
+	"time"
+
+	"github.com/MunifTanjim/stremthru/internal/util"
+)
+
+type TorrentStatus int
+
+const (
+	TorrentStatusStopped      TorrentStatus = 0
+	TorrentStatusCheckWait    TorrentStatus = 1
+	TorrentStatusCheck        TorrentStatus = 2
+	TorrentStatusDownloadWait TorrentStatus = 3
+	TorrentStatusDownload     TorrentStatus = 4
+	TorrentStatusSeedWait     TorrentStatus = 5
+	TorrentStatusSeed         TorrentStatus = 6
+)
+
+var torrentFields = []string{
+	"id",
+	"hashString",
+	"name",
+	"totalSize",
+	"percentDone",
+	"metadataPercentComplete",
+	"status",
+	"addedDate",
+	"downloadDir",
+	"error",
+	"errorString",
+	"files",
+}
+
+type TorrentFile struct {
+	Name           string `json:"name"`
+	Length         int64  `json:"length"`
+	BytesCompleted int64  `json:"bytesCompleted"`
+}
+
+func (f *TorrentFile) GetPath() string {
+	filePath, _ := util.RemoveRootFolderFromPath(f.Name)
+	if filePath == "" {
+		filePath = "/" + f.Name
+	}
+	return filePath
+}
+
+func (f *TorrentFile) GetName() string {
+	return path.Base(f.Name)
+}
+
+func (f *TorrentFile) IsCompleted() bool {
+	return f.BytesCompleted >= f.Length
+}
+
+type Torrent struct {
+	Id                      int           `json:"id"`
+	HashString              string        `json:"hashString"`
+	Name                    string        `json:"name"`
+	TotalSize               int64         `json:"totalSize"`
+	PercentDone             float64       `json:"percentDone"`
+	MetadataPercentComplete float64       `json:"metadataPercentComplete"`
+	Status                  TorrentStatus `json:"status"`
+	AddedDate               int64         `json:"addedDate"`
+	DownloadDir             string        `json:"downloadDir"`
+	Error                   int           `json:"error"`
+	ErrorString             string        `json:"errorString"`
+	Files                   []TorrentFile `json:"files"`
+}
+
+func (t *Torrent) GetAddedAt() time.Time {
+	return time.Unix(t.AddedDate, 0).UTC()
+}
+
+func (t *Torrent) IsCompleted() bool {
+	return t.PercentDone >= 1 && t.MetadataPercentComplete >= 1
+}
+
+type GetSessionData struct {
+	Version    string `json:"version"`
+	RPCVersion int    `json:"rpc-version"`
+}
+
+type GetSessionParams struct {
+	Ctx
+}
+
+func (c APIClient) GetSession(params *GetSessionParams) (APIResponse[GetSessionData], error) {
+	params.JSON = &rpcRequest{
+		Method: "session-get",
+		Arguments: map[string]any{
+			"fields": []string{"version", "rpc-version"},
+		},
+	}
+	response := &rpcResponse[GetSessionData]{}
+	res, err := c.Request(params, response)
+	return newAPIResponse(res, response.Arguments), err
+}
+
+type ListTorrentsData struct {
+	Torrents []Torrent `json:"torrents"`
+}
+
+type ListTorrentsParams struct {
+	Ctx
+	Hashes []string
+}
+
+func (c APIClient) ListTorrents(params *ListTorrentsParams) (APIResponse[[]Torrent], error) {
+	arguments := map[string]any{
+		"fields": torrentFields,
+	}
+	if len(params.Hashes) > 0 {
+		arguments["ids"] = params.Hashes
+	}
+	params.JSON = &rpcRequest{
+		Method:    "torrent-get",
+		Arguments: arguments,
+	}
+	response := &rpcResponse[ListTorrentsData]{}
+	res, err := c.Request(params, response)
+	return newAPIResponse(res, response.Arguments.Torrents), err
+}
+
+type AddTorrentData struct {
+	TorrentAdded     *Torrent `json:"torrent-added"`
+	TorrentDuplicate *Torrent `json:"torrent-duplicate"`
+}
+
+type AddTorrentParams struct {
+	Ctx
+	Filename string
+}
+
+func (c APIClient) AddTorrent(params *AddTorrentParams) (APIResponse[AddTorrentData], error) {
+	params.JSON = &rpcRequest{
+		Method: "torrent-add",
+		Arguments: map[string]any{
+			"filename": params.Filename,
+		},
+	}
+	response := &rpcResponse[AddTorrentData]{}
+	res, err := c.Request(params, response)
+	return newAPIResponse(res, response.Arguments), err
+}
+
+type SetTorrentFilesParams struct {
+	Ctx
+	Hash          string
+	FilesWanted   []int
+	FilesUnwanted []int
+}
+
+func (c APIClient) SetTorrentFiles(params *SetTorrentFilesParams) (APIResponse[struct{}], error) {
+	arguments := map[string]any{
+		"ids": []string{params.Hash},
+	}
+	if len(params.FilesWanted) > 0 {
+		arguments["files-wanted"] = params.FilesWanted
+	}
+	if len(params.FilesUnwanted) > 0 {
+		arguments["files-unwanted"] = params.FilesUnwanted
+	}
+	params.JSON = &rpcRequest{
+		Method:    "torrent-set",
+		Arguments: arguments,
+	}
+	response := &rpcResponse[struct{}]{}
+	res, err := c.Request(params, response)
+	return newAPIResponse(res, response.Arguments), err
+}
+
+type RemoveTorrentsParams struct {
+	Ctx
+	Hashes          []string
+	DeleteLocalData bool
+}
+
+func (c APIClient) RemoveTorrents(params *RemoveTorrentsParams) (APIResponse[struct{}], error) {
+	params.JSON = &rpcRequest{
+		Method: "torrent-remove",
+		Arguments: map[string]any{
+			"ids":               params.Hashes,
+			"delete-local-data": params.DeleteLocalData,
+		},
+	}
+	response := &rpcResponse[struct{}]{}
+	res, err := c.Request(params, response)
+	return newAPIResponse(res, response.Arguments), err
+}